@@ -6,6 +6,11 @@ interface Point {
   elevation: number;
 }
 
+interface Climb {
+  title: string;
+  points: [number, number][]; // Lat, long coordinates from the start to the top
+}
+
 interface Parameters {
   elementID: string;         // ID of the element to put the map in
   center: [number, number];  // Lat, long coordinate to center the map to
@@ -15,9 +20,12 @@ interface Parameters {
   maxSpeed: number;
   speedName: string;        // Name for speed layer
   elevationName: string;    // Name of elevation layer
+  climbs: Climb[];           // Climbs of the route to highlight
+  climbsName: string;        // Name of the climbs layer
 }
 */
 let hoverMarker;
+let climbLines = [];
 
 function makeMap(params) {
   document.addEventListener("DOMContentLoaded", () => {
@@ -141,18 +149,40 @@ function makeMap(params) {
     }
 
     hoverMarker.addTo(map); // Adding marker to the map
+
+    // Climbs are an overlay on top of the route
+    const overlays = {};
+    if (params.climbs && params.climbs.length > 0) {
+      const climbsLayerGroup = new L.featureGroup();
+      climbLines = params.climbs.map((c) =>
+        L.polyline(c.points, {
+          color: "rgb(220,38,38)",
+          weight: 6,
+          opacity: 0.5,
+        })
+          .bindTooltip(c.title)
+          .addTo(climbsLayerGroup),
+      );
+      climbsLayerGroup.addTo(map);
+      overlays[params.climbsName] = climbsLayerGroup;
+    }
+
     const layerControl = L.control
-      .layers({
-        [params.elevationName]: elevationLayerGroup,
-        [params.speedName]: speedLayerGroup,
-      })
+      .layers(
+        {
+          [params.elevationName]: elevationLayerGroup,
+          [params.speedName]: speedLayerGroup,
+        },
+        overlays,
+      )
       .addTo(map);
     map.fitBounds(group.getBounds(), { animate: false });
   });
 }
 
 function set_marker(title, lat, lon) {
-  if (!hoverMarker) return;
+  // Points without a position are at (0, 0)
+  if (!hoverMarker || (lat == 0 && lon == 0)) return;
 
   if (title != null) {
     hoverMarker.bindTooltip(title);
@@ -169,6 +199,23 @@ function clear_marker() {
   hoverMarker.closeTooltip();
 }
 
+// Highlight the climb with the index on the map
+function highlight_climb(index) {
+  const line = climbLines[index];
+  if (!line) return;
+
+  line.setStyle({ weight: 8, opacity: 1 });
+  line.bringToFront();
+  line.openTooltip();
+}
+
+function clear_climb() {
+  climbLines.forEach((line) => {
+    line.setStyle({ weight: 6, opacity: 0.5 });
+    line.closeTooltip();
+  });
+}
+
 // Determine color for a value; value from 0 to 1
 // Linearly interpolate between blue and green
 function getColor(value) {
@@ -21,24 +21,70 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/import/{program}": {
+        "/equipment": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "List all equipment of the current user",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/app.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "result": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/database.Equipment"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    }
+                }
+            },
             "post": {
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Import a workout",
+                "summary": "Create a piece of equipment",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Program that generates the workout file",
-                        "name": "program",
-                        "in": "path",
-                        "required": true
+                        "description": "The equipment",
+                        "name": "equipment",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/database.Equipment"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
                             "allOf": [
                                 {
@@ -48,7 +94,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "result": {
-                                            "$ref": "#/definitions/database.Workout"
+                                            "$ref": "#/definitions/database.Equipment"
                                         }
                                     }
                                 }
@@ -61,8 +107,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/app.APIResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "422": {
+                        "description": "Unprocessable Entity",
                         "schema": {
                             "$ref": "#/definitions/app.APIResponse"
                         }
@@ -76,21 +122,12 @@ const docTemplate = `{
                 }
             }
         },
-        "/records": {
+        "/equipment/alerts": {
             "get": {
                 "produces": [
                     "application/json"
                 ],
-                "summary": "List all records of the current user for the specified workout type",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Workout type",
-                        "name": "type",
-                        "in": "query",
-                        "required": true
-                    }
-                ],
+                "summary": "List the maintenance items of all active equipment of the current user that are due",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -103,7 +140,10 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "result": {
-                                            "$ref": "#/definitions/database.WorkoutRecord"
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/database.MaintenanceStatus"
+                                            }
                                         }
                                     }
                                 }
@@ -116,12 +156,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/app.APIResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "$ref": "#/definitions/app.APIResponse"
-                        }
-                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -131,24 +165,19 @@ const docTemplate = `{
                 }
             }
         },
-        "/statistics": {
+        "/equipment/{id}": {
             "get": {
                 "produces": [
                     "application/json"
                 ],
-                "summary": "List all statistics of the current user",
+                "summary": "Get all information about a piece of equipment",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Start of time range",
-                        "name": "since",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Bucket size",
-                        "name": "per",
-                        "in": "query"
+                        "type": "integer",
+                        "description": "Equipment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -163,7 +192,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "result": {
-                                            "$ref": "#/definitions/database.Statistics"
+                                            "$ref": "#/definitions/database.Equipment"
                                         }
                                     }
                                 }
@@ -189,20 +218,32 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/totals": {
-            "get": {
+            },
+            "put": {
+                "description": "Only the fields that are given are updated.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "List all totals of the current user for the specified workout type",
+                "summary": "Update a piece of equipment",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Workout type",
-                        "name": "type",
-                        "in": "query"
+                        "type": "integer",
+                        "description": "Equipment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "The fields to update",
+                        "name": "equipment",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/database.Equipment"
+                        }
                     }
                 ],
                 "responses": {
@@ -217,7 +258,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "result": {
-                                            "$ref": "#/definitions/database.Bucket"
+                                            "$ref": "#/definitions/database.Equipment"
                                         }
                                     }
                                 }
@@ -236,6 +277,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/app.APIResponse"
                         }
                     },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -243,14 +290,21 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/whoami": {
-            "get": {
+            },
+            "delete": {
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Show the information of the owner of the API key",
+                "summary": "Delete a piece of equipment",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Equipment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -263,7 +317,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "result": {
-                                            "$ref": "#/definitions/database.User"
+                                            "$ref": "#/definitions/database.Equipment"
                                         }
                                     }
                                 }
@@ -289,14 +343,34 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/workouts": {
-            "get": {
+            },
+            "patch": {
+                "description": "Only the fields that are given are updated.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "List all workouts of the current user",
+                "summary": "Update a piece of equipment",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Equipment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "The fields to update",
+                        "name": "equipment",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/database.Equipment"
+                        }
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -309,10 +383,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "result": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/database.Workout"
-                                            }
+                                            "$ref": "#/definitions/database.Equipment"
                                         }
                                     }
                                 }
@@ -331,6 +402,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/app.APIResponse"
                         }
                     },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -340,25 +417,19 @@ const docTemplate = `{
                 }
             }
         },
-        "/workouts/{id}": {
+        "/equipment/{id}/maintenance": {
             "get": {
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Get all information about a workout",
+                "summary": "Get the usage of a piece of equipment towards its maintenance items, and its service log",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Workout ID",
+                        "description": "Equipment ID",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "boolean",
-                        "description": "Include details",
-                        "name": "details",
-                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -373,7 +444,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "result": {
-                                            "$ref": "#/definitions/database.Workout"
+                                            "$ref": "#/definitions/app.EquipmentMaintenance"
                                         }
                                     }
                                 }
@@ -401,31 +472,62 @@ const docTemplate = `{
                 }
             }
         },
-        "/workouts/{id}/breakdown": {
+        "/exercises": {
             "get": {
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Break down a workdown per units",
+                "summary": "List the exercises in the current user's catalog",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/app.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "result": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/database.Exercise"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/exercises/{id}/history": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get the performance on an exercise in every workout, oldest first",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Workout ID",
+                        "description": "Exercise ID",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Unit",
-                        "name": "unit",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Count",
-                        "name": "count",
-                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -440,7 +542,10 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "result": {
-                                            "$ref": "#/definitions/database.WorkoutBreakdown"
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/database.ExerciseHistoryItem"
+                                            }
                                         }
                                     }
                                 }
@@ -467,218 +572,3243 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "app.APIResponse": {
-            "type": "object",
-            "properties": {
-                "errors": {
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    }
-                },
-                "results": {}
-            }
         },
-        "database.BreakdownItem": {
-            "type": "object",
-            "properties": {
-                "counter": {
-                    "description": "Counter of this item in the list of items",
-                    "type": "integer"
-                },
-                "distance": {
-                    "description": "Distance in this item",
-                    "type": "number"
-                },
-                "duration": {
-                    "description": "Duration in this item",
-                    "type": "integer"
-                },
-                "firstPoint": {
-                    "description": "First GPS point in this item",
+        "/goals": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "List all goals of the current user, with the progress in the current period",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/app.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "result": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/database.GoalProgress"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "The target is in meters for distance goals, and in seconds for duration goals.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Create a goal",
+                "parameters": [
+                    {
+                        "description": "The goal",
+                        "name": "goal",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/database.Goal"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/app.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "result": {
+                                            "$ref": "#/definitions/database.Goal"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/goals/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get a goal, with the progress in the current period",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Goal ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/app.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "result": {
+                                            "$ref": "#/definitions/database.GoalProgress"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Only the fields that are given are updated.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Update a goal",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Goal ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "The fields to update",
+                        "name": "goal",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/database.Goal"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/app.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "result": {
+                                            "$ref": "#/definitions/database.Goal"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Delete a goal",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Goal ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/app.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "result": {
+                                            "$ref": "#/definitions/database.Goal"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Only the fields that are given are updated.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Update a goal",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Goal ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "The fields to update",
+                        "name": "goal",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/database.Goal"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/app.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "result": {
+                                            "$ref": "#/definitions/database.Goal"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/import/{program}": {
+            "post": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Import a workout",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Program that generates the workout file",
+                        "name": "program",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "no-name",
+                        "description": "Name of the imported workout",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "auto",
+                        "description": "Type of the imported workout",
+                        "name": "type",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/app.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "result": {
+                                            "$ref": "#/definitions/database.Workout"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/measurements": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "List all body measurements of the current user, most recent first",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/app.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "result": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/database.Measurement"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "The weight is in kilograms and the waist in centimeters; values that were not measured are left out.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Create a body measurement",
+                "parameters": [
+                    {
+                        "description": "The measurement",
+                        "name": "measurement",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/database.Measurement"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/app.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "result": {
+                                            "$ref": "#/definitions/database.Measurement"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/measurements/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get a body measurement",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Measurement ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/app.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "result": {
+                                            "$ref": "#/definitions/database.Measurement"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Only the fields that are given are updated; a value set to null is removed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Update a body measurement",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Measurement ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "The fields to update",
+                        "name": "measurement",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/database.Measurement"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/app.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "result": {
+                                            "$ref": "#/definitions/database.Measurement"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Delete a body measurement",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Measurement ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/app.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "result": {
+                                            "$ref": "#/definitions/database.Measurement"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Only the fields that are given are updated; a value set to null is removed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Update a body measurement",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Measurement ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "The fields to update",
+                        "name": "measurement",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/database.Measurement"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/app.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "result": {
+                                            "$ref": "#/definitions/database.Measurement"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/profile": {
+            "put": {
+                "description": "Only the fields that are given are updated.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Update the profile of the current user",
+                "parameters": [
+                    {
+                        "description": "The fields to update",
+                        "name": "profile",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/database.Profile"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/app.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "result": {
+                                            "$ref": "#/definitions/database.Profile"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Only the fields that are given are updated.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Update the profile of the current user",
+                "parameters": [
+                    {
+                        "description": "The fields to update",
+                        "name": "profile",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/database.Profile"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/app.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "result": {
+                                            "$ref": "#/definitions/database.Profile"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/profile/preferred-units": {
+            "put": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Update the preferred units of the current user",
+                "parameters": [
+                    {
+                        "description": "The preferred units",
+                        "name": "units",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/database.UserPreferredUnits"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/app.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "result": {
+                                            "$ref": "#/definitions/database.UserPreferredUnits"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/records": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "List all records of the current user for the specified workout type",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Workout type",
+                        "name": "type",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/app.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "result": {
+                                            "$ref": "#/definitions/database.WorkoutRecord"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/statistics": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "List all statistics of the current user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Start of time range",
+                        "name": "since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Bucket size",
+                        "name": "per",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/app.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "result": {
+                                            "$ref": "#/definitions/database.Statistics"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/totals": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "List all totals of the current user for the specified workout type",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Workout type",
+                        "name": "type",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/app.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "result": {
+                                            "$ref": "#/definitions/database.Bucket"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/whoami": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Show the information of the owner of the API key",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/app.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "result": {
+                                            "$ref": "#/definitions/database.User"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/workouts": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "List the workouts of the current user, one page at a time",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Workout type",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only workouts on or after this date (YYYY-MM-DD)",
+                        "name": "since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only workouts on or before this date (YYYY-MM-DD)",
+                        "name": "until",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only workouts that used this equipment",
+                        "name": "equipment",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Minimum distance, in the preferred unit",
+                        "name": "min_distance",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Maximum distance, in the preferred unit",
+                        "name": "max_distance",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Minimum duration, in minutes",
+                        "name": "min_duration",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Maximum duration, in minutes",
+                        "name": "max_duration",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Text to find in the name, notes or address",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort key: date, distance, duration or speed",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort order: asc or desc",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page, starting at 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Workouts per page",
+                        "name": "per_page",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/app.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "result": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/database.Workout"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Distances are in the preferred unit of the current user. Dates are either RFC3339 or \"2006-01-02T15:04\".",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Create a manual workout",
+                "parameters": [
+                    {
+                        "description": "The workout",
+                        "name": "workout",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/app.APIWorkout"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/app.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "result": {
+                                            "$ref": "#/definitions/database.Workout"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/workouts/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get all information about a workout",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Workout ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Include details",
+                        "name": "details",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/app.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "result": {
+                                            "$ref": "#/definitions/database.Workout"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "The date and type are required; other fields that are not given are left untouched.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Update a workout",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Workout ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "The workout",
+                        "name": "workout",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/app.APIWorkout"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/app.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "result": {
+                                            "$ref": "#/definitions/database.Workout"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Delete a workout",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Workout ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/app.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "result": {
+                                            "$ref": "#/definitions/database.Workout"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Only the fields that are given are updated.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Partially update a workout",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Workout ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "The fields to update",
+                        "name": "workout",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/app.APIWorkout"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/app.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "result": {
+                                            "$ref": "#/definitions/database.Workout"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/workouts/{id}/breakdown": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Break down a workdown per units",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Workout ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Unit",
+                        "name": "unit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Count",
+                        "name": "count",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/app.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "result": {
+                                            "$ref": "#/definitions/database.WorkoutBreakdown"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/workouts/{id}/equipment/{equipmentID}": {
+            "post": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Link equipment to a workout",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Workout ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Equipment ID",
+                        "name": "equipmentID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/app.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "result": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/database.Equipment"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Unlink equipment from a workout",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Workout ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Equipment ID",
+                        "name": "equipmentID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/app.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "result": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/database.Equipment"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/workouts/{id}/exercises": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "List the exercises of a workout, with their sets",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Workout ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/app.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "result": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/database.WorkoutExercise"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/workouts/{id}/export": {
+            "get": {
+                "description": "The file is generated from the stored data, so manual workouts can be exported too.",
+                "produces": [
+                    "application/gpx+xml",
+                    "application/vnd.garmin.tcx+xml",
+                    "application/binary"
+                ],
+                "summary": "Export a workout as GPX, TCX or FIT",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Workout ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "The format: gpx (default), tcx or fit",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/workouts/{id}/laps": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "List the laps of a workout, as recorded by the device",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Workout ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/app.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "result": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/database.Lap"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/workouts/{id}/sets": {
+            "post": {
+                "description": "The exercise is looked up by name in the catalog, and added to it if needed. The totals of the workout are updated.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Add a set of an exercise to a workout",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Workout ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "The set",
+                        "name": "set",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/app.ExerciseSetInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/app.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "result": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/database.WorkoutExercise"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/workouts/{id}/sets/{setID}": {
+            "delete": {
+                "description": "The totals of the workout are updated.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Remove a set from a workout",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Workout ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Set ID",
+                        "name": "setID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/app.APIResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "result": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/database.WorkoutExercise"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/app.APIResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "app.APIPagination": {
+            "type": "object",
+            "properties": {
+                "page": {
+                    "description": "The number of this page, starting at 1",
+                    "type": "integer"
+                },
+                "pages": {
+                    "description": "The total number of pages",
+                    "type": "integer"
+                },
+                "per_page": {
+                    "description": "The maximum number of results per page",
+                    "type": "integer"
+                },
+                "total": {
+                    "description": "The total number of results",
+                    "type": "integer"
+                }
+            }
+        },
+        "app.APIResponse": {
+            "type": "object",
+            "properties": {
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "pagination": {
+                    "$ref": "#/definitions/app.APIPagination"
+                },
+                "results": {}
+            }
+        },
+        "app.APIWorkout": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "type": "string"
+                },
+                "distance": {
+                    "type": "number"
+                },
+                "duration_hours": {
+                    "type": "integer"
+                },
+                "duration_minutes": {
+                    "type": "integer"
+                },
+                "duration_seconds": {
+                    "type": "integer"
+                },
+                "elevation_source": {
+                    "$ref": "#/definitions/database.ElevationSource"
+                },
+                "equipment": {
+                    "description": "The IDs of the equipment used for this workout",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "exclude_from_statistics": {
+                    "type": "boolean"
+                },
+                "location": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "notes": {
+                    "type": "string"
+                },
+                "repetitions": {
+                    "type": "integer"
+                },
+                "type": {
+                    "$ref": "#/definitions/database.WorkoutType"
+                },
+                "visibility": {
+                    "$ref": "#/definitions/database.WorkoutVisibility"
+                },
+                "weight": {
+                    "type": "number"
+                }
+            }
+        },
+        "app.EquipmentMaintenance": {
+            "type": "object",
+            "properties": {
+                "services": {
+                    "description": "The services that were done, most recent first",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.EquipmentService"
+                    }
+                },
+                "status": {
+                    "description": "The usage towards every maintenance item",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.MaintenanceStatus"
+                    }
+                }
+            }
+        },
+        "app.ExerciseSetInput": {
+            "type": "object",
+            "properties": {
+                "exercise": {
+                    "description": "The name of the exercise",
+                    "type": "string"
+                },
+                "repetitions": {
+                    "description": "The number of repetitions",
+                    "type": "integer"
+                },
+                "rest": {
+                    "description": "The rest after the set, in seconds, optional",
+                    "type": "integer"
+                },
+                "rpe": {
+                    "description": "The rate of perceived exertion (1-10), optional",
+                    "type": "number"
+                },
+                "weight": {
+                    "description": "The weight, in the user's preferred unit",
+                    "type": "number"
+                }
+            }
+        },
+        "database.BestEffort": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "deletedAt": {
+                    "type": "string"
+                },
+                "distance": {
+                    "description": "The distance of the effort, in meters",
+                    "type": "number"
+                },
+                "duration": {
+                    "description": "The duration of the effort",
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "start": {
+                    "description": "The time the effort started",
+                    "type": "string"
+                },
+                "target": {
+                    "description": "The target distance or duration",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/database.EffortTarget"
+                        }
+                    ]
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "database.BestEffortRecord": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "description": "The timestamp of the workout",
+                    "type": "string"
+                },
+                "distance": {
+                    "description": "The distance of the effort, in meters",
+                    "type": "number"
+                },
+                "duration": {
+                    "description": "The duration of the effort",
+                    "type": "integer"
+                },
+                "id": {
+                    "description": "The workout ID of the record",
+                    "type": "integer"
+                },
+                "target": {
+                    "description": "The target distance or duration",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/database.EffortTarget"
+                        }
+                    ]
+                }
+            }
+        },
+        "database.BreakdownItem": {
+            "type": "object",
+            "properties": {
+                "counter": {
+                    "description": "Counter of this item in the list of items",
+                    "type": "integer"
+                },
+                "distance": {
+                    "description": "Distance in this item",
+                    "type": "number"
+                },
+                "duration": {
+                    "description": "Duration in this item",
+                    "type": "integer"
+                },
+                "firstPoint": {
+                    "description": "First GPS point in this item",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/database.MapPoint"
+                        }
+                    ]
+                },
+                "isBest": {
+                    "description": "Whether this item is the best of the list",
+                    "type": "boolean"
+                },
+                "isWorst": {
+                    "description": "Whether this item is the worst of the list",
+                    "type": "boolean"
+                },
+                "lastPoint": {
+                    "description": "Last GPS point in this item",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/database.MapPoint"
+                        }
+                    ]
+                },
+                "speed": {
+                    "description": "Speed in this item",
+                    "type": "number"
+                },
+                "totalDistance": {
+                    "description": "Total distance in all items up to and including this item",
+                    "type": "number"
+                },
+                "totalDuration": {
+                    "description": "Total duration in all items up to and including this item",
+                    "type": "integer"
+                },
+                "unitCount": {
+                    "description": "Count of the unit per item",
+                    "type": "number"
+                },
+                "unitName": {
+                    "description": "Unit name",
+                    "type": "string"
+                }
+            }
+        },
+        "database.Bucket": {
+            "type": "object",
+            "properties": {
+                "averageSpeed": {
+                    "description": "The average speed in the bucket",
+                    "type": "number"
+                },
+                "averageSpeedNoPause": {
+                    "description": "The average speed without pause in the bucket",
+                    "type": "number"
+                },
+                "bucket": {
+                    "description": "The name of the bucket",
+                    "type": "string"
+                },
+                "calories": {
+                    "description": "The total energy expenditure in the bucket, in kilocalories",
+                    "type": "number"
+                },
+                "distance": {
+                    "description": "The total distance in the bucket",
+                    "type": "number"
+                },
+                "duration": {
+                    "description": "The total duration in the bucket",
+                    "type": "integer"
+                },
+                "maxSpeed": {
+                    "description": "The max speed in the bucket",
+                    "type": "number"
+                },
+                "repetitions": {
+                    "description": "The total number of repetitions in the bucket",
+                    "type": "integer"
+                },
+                "trainingLoad": {
+                    "description": "The total training load in the bucket",
+                    "type": "number"
+                },
+                "up": {
+                    "description": "The total up elevation in the bucket",
+                    "type": "number"
+                },
+                "workoutType": {
+                    "description": "The type of the workout",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/database.WorkoutType"
+                        }
+                    ]
+                },
+                "workouts": {
+                    "description": "The number of workouts in the bucket",
+                    "type": "integer"
+                }
+            }
+        },
+        "database.CaloriesSource": {
+            "type": "string",
+            "enum": [
+                "",
+                "device",
+                "heart-rate",
+                "met"
+            ],
+            "x-enum-comments": {
+                "CaloriesSourceDevice": "Reported by the device in the file",
+                "CaloriesSourceHeartRate": "Estimated from the heart rate",
+                "CaloriesSourceMET": "Estimated from the workout type and duration",
+                "CaloriesSourceNone": "There is no estimate"
+            },
+            "x-enum-varnames": [
+                "CaloriesSourceNone",
+                "CaloriesSourceDevice",
+                "CaloriesSourceHeartRate",
+                "CaloriesSourceMET"
+            ]
+        },
+        "database.Climb": {
+            "type": "object",
+            "properties": {
+                "averageGradient": {
+                    "description": "The average gradient of the climb, in percent",
+                    "type": "number"
+                },
+                "category": {
+                    "description": "The category of the climb",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/database.ClimbCategory"
+                        }
+                    ]
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "deletedAt": {
+                    "type": "string"
+                },
+                "duration": {
+                    "description": "The elapsed time of the climb",
+                    "type": "integer"
+                },
+                "gain": {
+                    "description": "The elevation gain of the climb, in meters",
+                    "type": "number"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "length": {
+                    "description": "The length of the climb, in meters",
+                    "type": "number"
+                },
+                "maxGradient": {
+                    "description": "The steepest gradient over 100 meters of the climb, in percent",
+                    "type": "number"
+                },
+                "number": {
+                    "description": "The number of the climb in the workout, starting at 1",
+                    "type": "integer"
+                },
+                "points": {
+                    "description": "The polyline of the climb, from the start to the top",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.MapCenter"
+                    }
+                },
+                "score": {
+                    "description": "The score of the climb by the user's scoring scheme",
+                    "type": "number"
+                },
+                "start": {
+                    "description": "The time the climb started",
+                    "type": "string"
+                },
+                "startDistance": {
+                    "description": "The distance of the workout at the start of the climb, in meters",
+                    "type": "number"
+                },
+                "topElevation": {
+                    "description": "The elevation of the top of the climb, in meters",
+                    "type": "number"
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "database.ClimbCategory": {
+            "type": "integer",
+            "enum": [
+                0,
+                1,
+                2,
+                3,
+                4,
+                5
+            ],
+            "x-enum-comments": {
+                "ClimbCategory1": "Cat 1",
+                "ClimbCategory2": "Cat 2",
+                "ClimbCategory3": "Cat 3",
+                "ClimbCategory4": "Cat 4",
+                "ClimbCategoryHC": "Hors catégorie",
+                "ClimbCategoryNone": "Not categorised"
+            },
+            "x-enum-varnames": [
+                "ClimbCategoryNone",
+                "ClimbCategory4",
+                "ClimbCategory3",
+                "ClimbCategory2",
+                "ClimbCategory1",
+                "ClimbCategoryHC"
+            ]
+        },
+        "database.ClimbScoring": {
+            "type": "string",
+            "enum": [
+                "gradient",
+                "fiets"
+            ],
+            "x-enum-varnames": [
+                "ClimbScoringGradient",
+                "ClimbScoringFiets"
+            ]
+        },
+        "database.ClimbSettings": {
+            "type": "object",
+            "properties": {
+                "category_1": {
+                    "description": "The minimum score of a Cat 1 climb",
+                    "type": "number"
+                },
+                "category_2": {
+                    "description": "The minimum score of a Cat 2 climb",
+                    "type": "number"
+                },
+                "category_3": {
+                    "description": "The minimum score of a Cat 3 climb",
+                    "type": "number"
+                },
+                "category_4": {
+                    "description": "The minimum score of a Cat 4 climb",
+                    "type": "number"
+                },
+                "category_hc": {
+                    "description": "The minimum score of an HC climb",
+                    "type": "number"
+                },
+                "scoring": {
+                    "description": "The scoring scheme",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/database.ClimbScoring"
+                        }
+                    ]
+                }
+            }
+        },
+        "database.EffortTarget": {
+            "type": "string",
+            "enum": [
+                "1k",
+                "5k",
+                "10k",
+                "half-marathon",
+                "marathon",
+                "5min",
+                "20min",
+                "60min"
+            ],
+            "x-enum-comments": {
+                "Effort10K": "The fastest 10 kilometers",
+                "Effort1K": "The fastest kilometer",
+                "Effort20Min": "The longest distance in 20 minutes",
+                "Effort5K": "The fastest 5 kilometers",
+                "Effort5Min": "The longest distance in 5 minutes",
+                "Effort60Min": "The longest distance in 60 minutes",
+                "EffortHalfMarathon": "The fastest half marathon",
+                "EffortMarathon": "The fastest marathon"
+            },
+            "x-enum-varnames": [
+                "Effort1K",
+                "Effort5K",
+                "Effort10K",
+                "EffortHalfMarathon",
+                "EffortMarathon",
+                "Effort5Min",
+                "Effort20Min",
+                "Effort60Min"
+            ]
+        },
+        "database.ElevationSource": {
+            "type": "string",
+            "enum": [
+                "device",
+                "dem"
+            ],
+            "x-enum-comments": {
+                "ElevationSourceDEM": "The elevation from the DEM tiles on the server",
+                "ElevationSourceDevice": "The elevation recorded by the device"
+            },
+            "x-enum-varnames": [
+                "ElevationSourceDevice",
+                "ElevationSourceDEM"
+            ]
+        },
+        "database.Equipment": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "description": "Whether this equipment is active",
+                    "type": "boolean"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "default_for": {
+                    "description": "Which workout types to add this equipment by default",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.WorkoutType"
+                    }
+                },
+                "deletedAt": {
+                    "type": "string"
+                },
+                "description": {
+                    "description": "More information about the equipment",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "maintenanceItems": {
+                    "description": "What has to be done to the equipment after some usage",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.MaintenanceItem"
+                    }
+                },
+                "name": {
+                    "description": "The name of the gear",
+                    "type": "string"
+                },
+                "services": {
+                    "description": "The services that were done, most recent first",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.EquipmentService"
+                    }
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "userID": {
+                    "description": "The ID of the user who owns the workout",
+                    "type": "integer"
+                },
+                "workouts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.Workout"
+                    }
+                }
+            }
+        },
+        "database.EquipmentService": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "date": {
+                    "description": "When the service was done",
+                    "type": "string"
+                },
+                "deletedAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "maintenance_item": {
+                    "description": "The maintenance item that was done",
                     "allOf": [
                         {
-                            "$ref": "#/definitions/database.MapPoint"
+                            "$ref": "#/definitions/database.MaintenanceItem"
                         }
                     ]
                 },
-                "isBest": {
-                    "description": "Whether this item is the best of the list",
-                    "type": "boolean"
+                "maintenance_item_id": {
+                    "description": "The ID of the maintenance item that was done; empty for other services",
+                    "type": "integer"
                 },
-                "isWorst": {
-                    "description": "Whether this item is the worst of the list",
-                    "type": "boolean"
+                "notes": {
+                    "description": "Notes about the service",
+                    "type": "string"
                 },
-                "lastPoint": {
-                    "description": "Last GPS point in this item",
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "database.Exercise": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "deletedAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "description": "The name of the exercise",
+                    "type": "string"
+                },
+                "notes": {
+                    "description": "Notes about the exercise, eg. the setup",
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "database.ExerciseHistoryItem": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "description": "The date of the workout",
+                    "type": "string"
+                },
+                "estimatedOneRepMax": {
+                    "description": "The best estimated one-rep max",
+                    "type": "number"
+                },
+                "maxWeight": {
+                    "description": "The heaviest weight",
+                    "type": "number"
+                },
+                "repetitions": {
+                    "description": "The total number of repetitions",
+                    "type": "integer"
+                },
+                "sets": {
+                    "description": "The number of sets",
+                    "type": "integer"
+                },
+                "volume": {
+                    "description": "The total weight moved",
+                    "type": "number"
+                },
+                "workoutID": {
+                    "description": "The ID of the workout",
+                    "type": "integer"
+                },
+                "workoutName": {
+                    "description": "The name of the workout",
+                    "type": "string"
+                }
+            }
+        },
+        "database.ExerciseSet": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "deletedAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "number": {
+                    "description": "The number of the set, starting at 1",
+                    "type": "integer"
+                },
+                "repetitions": {
+                    "description": "The number of repetitions",
+                    "type": "integer"
+                },
+                "rest": {
+                    "description": "The rest after the set",
+                    "type": "integer"
+                },
+                "rpe": {
+                    "description": "The rate of perceived exertion (1-10); 0 if not given",
+                    "type": "number"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "weight": {
+                    "description": "The weight, in the user's preferred unit; 0 for body weight exercises",
+                    "type": "number"
+                }
+            }
+        },
+        "database.ExtraMetrics": {
+            "type": "object",
+            "additionalProperties": {
+                "type": "number"
+            }
+        },
+        "database.GPXData": {
+            "type": "object",
+            "properties": {
+                "checksum": {
+                    "description": "The checksum of the content",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "content": {
+                    "description": "The file content",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "deletedAt": {
+                    "type": "string"
+                },
+                "filename": {
+                    "description": "The filename of the file",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "workoutID": {
+                    "description": "The ID of the workout",
+                    "type": "integer"
+                }
+            }
+        },
+        "database.Goal": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "deletedAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "metric": {
+                    "description": "What is counted towards the goal",
                     "allOf": [
                         {
-                            "$ref": "#/definitions/database.MapPoint"
+                            "$ref": "#/definitions/database.GoalMetric"
                         }
                     ]
                 },
-                "speed": {
-                    "description": "Speed in this item",
-                    "type": "number"
+                "name": {
+                    "description": "The name of the goal",
+                    "type": "string"
                 },
-                "totalDistance": {
-                    "description": "Total distance in all items up to and including this item",
+                "period": {
+                    "description": "The period over which progress is counted",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/database.GoalPeriod"
+                        }
+                    ]
+                },
+                "target": {
+                    "description": "The target; in meters for distance, in seconds for duration",
                     "type": "number"
                 },
-                "totalDuration": {
-                    "description": "Total duration in all items up to and including this item",
+                "type": {
+                    "description": "The workout type that counts towards the goal; empty for all types that support the metric",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/database.WorkoutType"
+                        }
+                    ]
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "userID": {
+                    "description": "The ID of the user who owns the goal",
                     "type": "integer"
+                }
+            }
+        },
+        "database.GoalMetric": {
+            "type": "string",
+            "enum": [
+                "distance",
+                "duration",
+                "repetitions",
+                "workouts"
+            ],
+            "x-enum-comments": {
+                "GoalMetricDistance": "The total distance, in meters",
+                "GoalMetricDuration": "The total duration, in seconds",
+                "GoalMetricRepetitions": "The total number of repetitions",
+                "GoalMetricWorkouts": "The number of workouts"
+            },
+            "x-enum-varnames": [
+                "GoalMetricDistance",
+                "GoalMetricDuration",
+                "GoalMetricRepetitions",
+                "GoalMetricWorkouts"
+            ]
+        },
+        "database.GoalPeriod": {
+            "type": "string",
+            "enum": [
+                "week",
+                "month",
+                "year"
+            ],
+            "x-enum-varnames": [
+                "GoalPeriodWeek",
+                "GoalPeriodMonth",
+                "GoalPeriodYear"
+            ]
+        },
+        "database.GoalProgress": {
+            "type": "object",
+            "properties": {
+                "end": {
+                    "description": "The end of the current period",
+                    "type": "string"
                 },
-                "unitCount": {
-                    "description": "Count of the unit per item",
+                "goal": {
+                    "description": "The goal",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/database.Goal"
+                        }
+                    ]
+                },
+                "percentage": {
+                    "description": "The progress, as a percentage of the target",
                     "type": "number"
                 },
-                "unitName": {
-                    "description": "Unit name",
+                "start": {
+                    "description": "The start of the current period",
                     "type": "string"
+                },
+                "value": {
+                    "description": "The progress; in meters for distance, in seconds for duration",
+                    "type": "number"
                 }
             }
         },
-        "database.Bucket": {
+        "database.HeartRateSettings": {
             "type": "object",
             "properties": {
-                "averageSpeed": {
-                    "description": "The average speed in the bucket",
+                "max": {
+                    "description": "The maximum heart rate, in beats per minute",
+                    "type": "integer"
+                },
+                "resting": {
+                    "description": "The resting heart rate, in beats per minute",
+                    "type": "integer"
+                },
+                "zone2": {
+                    "description": "The lower bound of zone 2, in beats per minute",
+                    "type": "integer"
+                },
+                "zone3": {
+                    "description": "The lower bound of zone 3, in beats per minute",
+                    "type": "integer"
+                },
+                "zone4": {
+                    "description": "The lower bound of zone 4, in beats per minute",
+                    "type": "integer"
+                },
+                "zone5": {
+                    "description": "The lower bound of zone 5, in beats per minute",
+                    "type": "integer"
+                }
+            }
+        },
+        "database.Lap": {
+            "type": "object",
+            "properties": {
+                "averageCadence": {
+                    "description": "The average cadence",
                     "type": "number"
                 },
-                "averageSpeedNoPause": {
-                    "description": "The average speed without pause in the bucket",
+                "averageHeartRate": {
+                    "description": "The average heart rate, in beats per minute",
                     "type": "number"
                 },
-                "bucket": {
-                    "description": "The name of the bucket",
+                "createdAt": {
+                    "type": "string"
+                },
+                "deletedAt": {
                     "type": "string"
                 },
                 "distance": {
-                    "description": "The total distance in the bucket",
+                    "description": "The distance of the lap, in meters",
                     "type": "number"
                 },
                 "duration": {
-                    "description": "The total duration in the bucket",
+                    "description": "The duration of the lap, without pauses",
                     "type": "integer"
                 },
-                "maxSpeed": {
-                    "description": "The max speed in the bucket",
-                    "type": "number"
+                "id": {
+                    "type": "integer"
                 },
-                "up": {
-                    "description": "The total up elevation in the bucket",
+                "maxCadence": {
+                    "description": "The maximum cadence",
                     "type": "number"
                 },
-                "workoutType": {
-                    "description": "The type of the workout",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/database.WorkoutType"
-                        }
-                    ]
+                "maxHeartRate": {
+                    "description": "The maximum heart rate, in beats per minute",
+                    "type": "number"
                 },
-                "workouts": {
-                    "description": "The number of workouts in the bucket",
+                "number": {
+                    "description": "The number of the lap, starting at 1",
                     "type": "integer"
+                },
+                "start": {
+                    "description": "The start time of the lap",
+                    "type": "string"
+                },
+                "stop": {
+                    "description": "The end time of the lap",
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
                 }
             }
         },
-        "database.Equipment": {
+        "database.MaintenanceItem": {
             "type": "object",
             "properties": {
-                "active": {
-                    "description": "Whether this equipment is active",
-                    "type": "boolean"
-                },
                 "createdAt": {
                     "type": "string"
                 },
-                "default_for": {
-                    "description": "Which workout types to add this equipment by default",
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/database.WorkoutType"
-                    }
-                },
                 "deletedAt": {
                     "type": "string"
                 },
-                "description": {
-                    "description": "More information about the equipment",
-                    "type": "string"
-                },
                 "id": {
                     "type": "integer"
                 },
+                "interval": {
+                    "description": "The usage after which it has to be done; in meters for distance, in seconds for duration",
+                    "type": "number"
+                },
+                "metric": {
+                    "description": "What the usage is counted in",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/database.MaintenanceMetric"
+                        }
+                    ]
+                },
                 "name": {
-                    "description": "The name of the gear",
+                    "description": "What has to be done",
                     "type": "string"
                 },
+                "retire": {
+                    "description": "Whether the equipment has to be retired, rather than serviced",
+                    "type": "boolean"
+                },
                 "updatedAt": {
                     "type": "string"
-                },
-                "user": {
-                    "$ref": "#/definitions/database.User"
-                },
-                "userID": {
-                    "description": "The ID of the user who owns the workout",
-                    "type": "integer"
-                },
-                "workouts": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/database.Workout"
-                    }
                 }
             }
         },
-        "database.ExtraMetrics": {
-            "type": "object",
-            "additionalProperties": {
-                "type": "number"
-            }
+        "database.MaintenanceMetric": {
+            "type": "string",
+            "enum": [
+                "distance",
+                "duration",
+                "repetitions",
+                "workouts"
+            ],
+            "x-enum-comments": {
+                "MaintenanceMetricDistance": "The distance, in meters",
+                "MaintenanceMetricDuration": "The duration, in seconds",
+                "MaintenanceMetricRepetitions": "The number of repetitions",
+                "MaintenanceMetricWorkouts": "The number of workouts"
+            },
+            "x-enum-varnames": [
+                "MaintenanceMetricDistance",
+                "MaintenanceMetricDuration",
+                "MaintenanceMetricRepetitions",
+                "MaintenanceMetricWorkouts"
+            ]
         },
-        "database.GPXData": {
+        "database.MaintenanceStatus": {
             "type": "object",
             "properties": {
-                "checksum": {
-                    "description": "The checksum of the content",
-                    "type": "array",
-                    "items": {
-                        "type": "integer"
-                    }
-                },
-                "content": {
-                    "description": "The file content",
-                    "type": "array",
-                    "items": {
-                        "type": "integer"
-                    }
-                },
-                "createdAt": {
-                    "type": "string"
+                "equipmentID": {
+                    "description": "The ID of the equipment",
+                    "type": "integer"
                 },
-                "deletedAt": {
-                    "type": "string"
+                "item": {
+                    "description": "The maintenance item",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/database.MaintenanceItem"
+                        }
+                    ]
                 },
-                "filename": {
-                    "description": "The filename of the file",
+                "lastService": {
+                    "description": "When the item was last done; empty if it was never done",
                     "type": "string"
                 },
-                "id": {
-                    "type": "integer"
-                },
-                "updatedAt": {
-                    "type": "string"
+                "percentage": {
+                    "description": "The usage, as a percentage of the interval",
+                    "type": "number"
                 },
-                "workoutID": {
-                    "description": "The ID of the workout",
-                    "type": "integer"
+                "usage": {
+                    "description": "The usage since the item was last done; in meters for distance, in seconds for duration",
+                    "type": "number"
                 }
             }
         },
@@ -710,6 +3840,37 @@ const docTemplate = `{
                     "description": "The generic location of the workout",
                     "type": "string"
                 },
+                "averageHeartRate": {
+                    "description": "The average heart rate of the workout, in beats per minute",
+                    "type": "number"
+                },
+                "averagePower": {
+                    "description": "The average power of the workout, in watts",
+                    "type": "number"
+                },
+                "averageStrokeRate": {
+                    "description": "The average stroke rate of a swim, in strokes per minute",
+                    "type": "number"
+                },
+                "bestEfforts": {
+                    "description": "The best efforts of the workout over standard distances and durations",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.BestEffort"
+                    }
+                },
+                "calories": {
+                    "description": "The energy expenditure of the workout, in kilocalories",
+                    "type": "number"
+                },
+                "caloriesSource": {
+                    "description": "Where the energy expenditure comes from",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/database.CaloriesSource"
+                        }
+                    ]
+                },
                 "center": {
                     "description": "The center of the workout (in coordinates)",
                     "allOf": [
@@ -718,6 +3879,13 @@ const docTemplate = `{
                         }
                     ]
                 },
+                "climbs": {
+                    "description": "The categorised climbs of the workout",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.Climb"
+                    }
+                },
                 "createdAt": {
                     "type": "string"
                 },
@@ -728,6 +3896,10 @@ const docTemplate = `{
                 "deletedAt": {
                     "type": "string"
                 },
+                "demelevation": {
+                    "description": "Whether the elevation comes from the DEM tiles instead of the device",
+                    "type": "boolean"
+                },
                 "details": {
                     "description": "The details of the workout",
                     "allOf": [
@@ -739,10 +3911,36 @@ const docTemplate = `{
                 "id": {
                     "type": "integer"
                 },
+                "intensityFactor": {
+                    "description": "The normalized power as a fraction of the functional threshold power",
+                    "type": "number"
+                },
+                "laps": {
+                    "description": "The laps of the workout, as recorded by the device",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.Lap"
+                    }
+                },
+                "lengths": {
+                    "description": "The lengths of a pool swim, as recorded by the device",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.SwimLength"
+                    }
+                },
                 "maxElevation": {
                     "description": "The maximum elevation of the workout",
                     "type": "number"
                 },
+                "maxHeartRate": {
+                    "description": "The maximum heart rate of the workout, in beats per minute",
+                    "type": "number"
+                },
+                "maxPower": {
+                    "description": "The maximum power of the workout, in watts",
+                    "type": "number"
+                },
                 "maxSpeed": {
                     "description": "The maximum speed of the workout",
                     "type": "number"
@@ -755,10 +3953,44 @@ const docTemplate = `{
                     "description": "The name of the workout",
                     "type": "string"
                 },
+                "normalizedPower": {
+                    "description": "The normalized power of the workout, in watts",
+                    "type": "number"
+                },
                 "pauseDuration": {
                     "description": "The total pause duration of the workout",
                     "type": "integer"
                 },
+                "poolLength": {
+                    "description": "The length of the pool of a pool swim, in meters",
+                    "type": "number"
+                },
+                "poolLengthYards": {
+                    "description": "Whether the pool length was set in yards",
+                    "type": "boolean"
+                },
+                "powerCurve": {
+                    "description": "The best average power over standard durations",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.PowerCurvePoint"
+                    }
+                },
+                "rawStats": {
+                    "description": "The totals of the track before cleaning; nil if the track was not cleaned",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/database.RawTrackStats"
+                        }
+                    ]
+                },
+                "timeInZones": {
+                    "description": "The time spent in each heart rate zone",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
                 "totalDistance": {
                     "description": "The total distance of the workout",
                     "type": "number"
@@ -783,6 +4015,14 @@ const docTemplate = `{
                     "description": "The weight of the workout",
                     "type": "number"
                 },
+                "trainingLoad": {
+                    "description": "The training load (TRIMP) of the workout",
+                    "type": "number"
+                },
+                "trainingStressScore": {
+                    "description": "The training stress score (TSS) of the workout",
+                    "type": "number"
+                },
                 "updatedAt": {
                     "type": "string"
                 },
@@ -808,56 +4048,188 @@ const docTemplate = `{
                     "description": "The ID of the map data these details belong to",
                     "type": "integer"
                 },
-                "points": {
-                    "description": "The GPS points of the workout",
+                "points": {
+                    "description": "The GPS points of the workout",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.MapPoint"
+                    }
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "database.MapPoint": {
+            "type": "object",
+            "properties": {
+                "distance": {
+                    "description": "The distance from the previous point",
+                    "type": "number"
+                },
+                "duration": {
+                    "description": "The duration from the previous point",
+                    "type": "integer"
+                },
+                "extraMetrics": {
+                    "description": "Extra metrics at this point",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/database.ExtraMetrics"
+                        }
+                    ]
+                },
+                "lat": {
+                    "description": "The latitude of the point",
+                    "type": "number"
+                },
+                "lng": {
+                    "description": "The longitude of the point",
+                    "type": "number"
+                },
+                "time": {
+                    "description": "The time the point was recorded",
+                    "type": "string"
+                },
+                "totalDistance": {
+                    "description": "The total distance of the workout up to this point",
+                    "type": "number"
+                },
+                "totalDuration": {
+                    "description": "The total duration of the workout up to this point",
+                    "type": "integer"
+                }
+            }
+        },
+        "database.Measurement": {
+            "type": "object",
+            "properties": {
+                "body_fat": {
+                    "description": "The body fat, in percent",
+                    "type": "number"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "date": {
+                    "description": "When the measurement was taken",
+                    "type": "string"
+                },
+                "deletedAt": {
+                    "type": "string"
+                },
+                "hrv": {
+                    "description": "The heart rate variability (RMSSD), in milliseconds",
+                    "type": "number"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "notes": {
+                    "description": "Notes about the measurement",
+                    "type": "string"
+                },
+                "resting_heart_rate": {
+                    "description": "The resting heart rate, in beats per minute",
+                    "type": "number"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "waist": {
+                    "description": "The waist circumference, in centimeters",
+                    "type": "number"
+                },
+                "weight": {
+                    "description": "The body weight, in kilograms",
+                    "type": "number"
+                }
+            }
+        },
+        "database.MeasurementPoint": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "description": "When the value was measured",
+                    "type": "string"
+                },
+                "value": {
+                    "description": "The value",
+                    "type": "number"
+                }
+            }
+        },
+        "database.MeasurementTrends": {
+            "type": "object",
+            "properties": {
+                "bodyFat": {
+                    "description": "The body fat, in percent",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.MeasurementPoint"
+                    }
+                },
+                "hrv": {
+                    "description": "The heart rate variability, in milliseconds",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.MeasurementPoint"
+                    }
+                },
+                "restingHeartRate": {
+                    "description": "The resting heart rate, in beats per minute",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.MeasurementPoint"
+                    }
+                },
+                "waist": {
+                    "description": "The waist circumference, in centimeters",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/database.MapPoint"
+                        "$ref": "#/definitions/database.MeasurementPoint"
                     }
                 },
-                "updatedAt": {
-                    "type": "string"
+                "weight": {
+                    "description": "The body weight, in kilograms",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.MeasurementPoint"
+                    }
                 }
             }
         },
-        "database.MapPoint": {
+        "database.PowerCurvePoint": {
             "type": "object",
             "properties": {
-                "distance": {
-                    "description": "The distance from the previous point",
-                    "type": "number"
-                },
                 "duration": {
-                    "description": "The duration from the previous point",
+                    "description": "The duration of the effort",
                     "type": "integer"
                 },
-                "extraMetrics": {
-                    "description": "Extra metrics at this point",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/database.ExtraMetrics"
-                        }
-                    ]
-                },
-                "lat": {
-                    "description": "The latitude of the point",
-                    "type": "number"
-                },
-                "lng": {
-                    "description": "The longitude of the point",
+                "power": {
+                    "description": "The average power over the duration, in watts",
                     "type": "number"
-                },
-                "time": {
-                    "description": "The time the point was recorded",
+                }
+            }
+        },
+        "database.PowerCurveRecord": {
+            "type": "object",
+            "properties": {
+                "date": {
+                    "description": "The timestamp of the workout",
                     "type": "string"
                 },
-                "totalDistance": {
-                    "description": "The total distance of the workout up to this point",
-                    "type": "number"
+                "duration": {
+                    "description": "The duration of the effort",
+                    "type": "integer"
                 },
-                "totalDuration": {
-                    "description": "The total duration of the workout up to this point",
+                "id": {
+                    "description": "The workout ID of the record",
                     "type": "integer"
+                },
+                "power": {
+                    "description": "The average power over the duration, in watts",
+                    "type": "number"
                 }
             }
         },
@@ -872,12 +4244,40 @@ const docTemplate = `{
                     "description": "The user's preferred directory for auto-import",
                     "type": "string"
                 },
+                "climbs": {
+                    "description": "The user's climb categorisation settings",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/database.ClimbSettings"
+                        }
+                    ]
+                },
                 "createdAt": {
                     "type": "string"
                 },
+                "default_visibility": {
+                    "description": "The default visibility of new workouts",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/database.WorkoutVisibility"
+                        }
+                    ]
+                },
                 "deletedAt": {
                     "type": "string"
                 },
+                "ftp": {
+                    "description": "The user's functional threshold power, in watts",
+                    "type": "integer"
+                },
+                "heartRate": {
+                    "description": "The user's heart rate settings",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/database.HeartRateSettings"
+                        }
+                    ]
+                },
                 "id": {
                     "type": "integer"
                 },
@@ -897,6 +4297,10 @@ const docTemplate = `{
                         }
                     ]
                 },
+                "share_hide_distance": {
+                    "description": "The distance (in meters) at the start and end of shared workouts that is hidden",
+                    "type": "number"
+                },
                 "socials_disabled": {
                     "description": "Whether social sharing buttons are disabled when viewing a workout",
                     "type": "boolean"
@@ -913,6 +4317,14 @@ const docTemplate = `{
                         }
                     ]
                 },
+                "trackCleaning": {
+                    "description": "The user's GPS track cleaning settings",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/database.TrackCleaningSettings"
+                        }
+                    ]
+                },
                 "updatedAt": {
                     "type": "string"
                 },
@@ -922,6 +4334,31 @@ const docTemplate = `{
                 }
             }
         },
+        "database.RawTrackStats": {
+            "type": "object",
+            "properties": {
+                "maxSpeed": {
+                    "description": "The maximum speed of the raw track",
+                    "type": "number"
+                },
+                "pauseDuration": {
+                    "description": "The total pause duration of the raw track",
+                    "type": "integer"
+                },
+                "removedPoints": {
+                    "description": "The number of points removed by the cleaning",
+                    "type": "integer"
+                },
+                "smoothedPoints": {
+                    "description": "The number of points moved to a stationary position by the cleaning",
+                    "type": "integer"
+                },
+                "totalDistance": {
+                    "description": "The total distance of the raw track",
+                    "type": "number"
+                }
+            }
+        },
         "database.Statistics": {
             "type": "object",
             "properties": {
@@ -939,12 +4376,89 @@ const docTemplate = `{
                         }
                     }
                 },
+                "measurements": {
+                    "description": "The body measurements over the time range",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/database.MeasurementTrends"
+                        }
+                    ]
+                },
+                "trainingLoad": {
+                    "description": "The training load per week",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.WeeklyTrainingLoad"
+                    }
+                },
                 "userID": {
                     "description": "The user ID",
                     "type": "integer"
                 }
             }
         },
+        "database.SwimLength": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "description": "Whether the length was swum; idle lengths are rests",
+                    "type": "boolean"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "deletedAt": {
+                    "type": "string"
+                },
+                "distance": {
+                    "description": "The distance of the length (the pool length), in meters; 0 for rests",
+                    "type": "number"
+                },
+                "duration": {
+                    "description": "The duration of the length",
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "number": {
+                    "description": "The number of the length, starting at 1",
+                    "type": "integer"
+                },
+                "start": {
+                    "description": "The start time of the length",
+                    "type": "string"
+                },
+                "stroke": {
+                    "description": "The stroke type, eg. \"freestyle\"; empty if unknown",
+                    "type": "string"
+                },
+                "strokes": {
+                    "description": "The number of strokes",
+                    "type": "integer"
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "database.TrackCleaningSettings": {
+            "type": "object",
+            "properties": {
+                "enabled": {
+                    "description": "Whether tracks are cleaned",
+                    "type": "boolean"
+                },
+                "max_accuracy": {
+                    "description": "The worst horizontal accuracy of a point that is kept, in meters",
+                    "type": "number"
+                },
+                "stationary_radius": {
+                    "description": "The radius of the jitter around a stationary position, in meters",
+                    "type": "number"
+                }
+            }
+        },
         "database.User": {
             "type": "object",
             "properties": {
@@ -1019,6 +4533,27 @@ const docTemplate = `{
                 }
             }
         },
+        "database.WeeklyTrainingLoad": {
+            "type": "object",
+            "properties": {
+                "acute": {
+                    "description": "The total training load of the week",
+                    "type": "number"
+                },
+                "chronic": {
+                    "description": "The average weekly training load of the last four weeks",
+                    "type": "number"
+                },
+                "ratio": {
+                    "description": "The acute:chronic workload ratio; 0 when there is no chronic load",
+                    "type": "number"
+                },
+                "week": {
+                    "description": "The first day (Monday) of the week",
+                    "type": "string"
+                }
+            }
+        },
         "database.Workout": {
             "type": "object",
             "properties": {
@@ -1044,6 +4579,18 @@ const docTemplate = `{
                     "description": "Whether the workout has been modified and the details should be re-rendered",
                     "type": "boolean"
                 },
+                "duplicateOfID": {
+                    "description": "The ID of the workout this workout looks like another recording of, until it is resolved",
+                    "type": "integer"
+                },
+                "elevationSource": {
+                    "description": "Where the elevation of the points comes from",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/database.ElevationSource"
+                        }
+                    ]
+                },
                 "equipment": {
                     "description": "Which equipment is used for this workout",
                     "type": "array",
@@ -1051,6 +4598,17 @@ const docTemplate = `{
                         "$ref": "#/definitions/database.Equipment"
                     }
                 },
+                "excludeFromStatistics": {
+                    "description": "Whether the workout is left out of the statistics, totals and records",
+                    "type": "boolean"
+                },
+                "exercises": {
+                    "description": "The exercises performed in this workout, in order",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.WorkoutExercise"
+                    }
+                },
                 "gpx": {
                     "description": "The file data associated with the workout",
                     "allOf": [
@@ -1062,6 +4620,17 @@ const docTemplate = `{
                 "id": {
                     "type": "integer"
                 },
+                "leg": {
+                    "description": "The position of the leg in its multisport workout, starting at 1; 0 for other workouts",
+                    "type": "integer"
+                },
+                "legs": {
+                    "description": "The legs of a multisport workout, in order",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.Workout"
+                    }
+                },
                 "name": {
                     "description": "The name of the workout",
                     "type": "string"
@@ -1070,6 +4639,10 @@ const docTemplate = `{
                     "description": "The notes associated with the workout, in markdown",
                     "type": "string"
                 },
+                "parentID": {
+                    "description": "The ID of the multisport workout this workout is a leg of",
+                    "type": "integer"
+                },
                 "type": {
                     "description": "The type of the workout",
                     "allOf": [
@@ -1092,6 +4665,14 @@ const docTemplate = `{
                 "userID": {
                     "description": "The ID of the user who owns the workout",
                     "type": "integer"
+                },
+                "visibility": {
+                    "description": "Who can see the workout",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/database.WorkoutVisibility"
+                        }
+                    ]
                 }
             }
         },
@@ -1109,6 +4690,46 @@ const docTemplate = `{
                 }
             }
         },
+        "database.WorkoutExercise": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "deletedAt": {
+                    "type": "string"
+                },
+                "exercise": {
+                    "description": "The exercise",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/database.Exercise"
+                        }
+                    ]
+                },
+                "exerciseID": {
+                    "description": "The ID of the exercise",
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "position": {
+                    "description": "The position of the exercise in the workout, starting at 1",
+                    "type": "integer"
+                },
+                "sets": {
+                    "description": "The sets, in order",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.ExerciseSet"
+                    }
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
         "database.WorkoutRecord": {
             "type": "object",
             "properties": {
@@ -1116,6 +4737,14 @@ const docTemplate = `{
                     "description": "Whether there is any data in the record",
                     "type": "boolean"
                 },
+                "averagePower": {
+                    "description": "The record with the maximum average power",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/database.float64Record"
+                        }
+                    ]
+                },
                 "averageSpeed": {
                     "description": "The record with the maximum average speed",
                     "allOf": [
@@ -1132,6 +4761,20 @@ const docTemplate = `{
                         }
                     ]
                 },
+                "bestEfforts": {
+                    "description": "The all-time best efforts over standard distances and durations",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.BestEffortRecord"
+                    }
+                },
+                "bestEffortsPerYear": {
+                    "description": "The best efforts per year, most recent year first",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.YearBestEfforts"
+                    }
+                },
                 "distance": {
                     "description": "The record with the maximum distance",
                     "allOf": [
@@ -1148,6 +4791,14 @@ const docTemplate = `{
                         }
                     ]
                 },
+                "maxPower": {
+                    "description": "The record with the maximum max power",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/database.float64Record"
+                        }
+                    ]
+                },
                 "maxSpeed": {
                     "description": "The record with the maximum max speed",
                     "allOf": [
@@ -1156,6 +4807,21 @@ const docTemplate = `{
                         }
                     ]
                 },
+                "normalizedPower": {
+                    "description": "The record with the maximum normalized power",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/database.float64Record"
+                        }
+                    ]
+                },
+                "powerCurve": {
+                    "description": "The all-time best average power over standard durations",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.PowerCurveRecord"
+                    }
+                },
                 "totalUp": {
                     "description": "The record with the maximum up elevation",
                     "allOf": [
@@ -1187,6 +4853,8 @@ const docTemplate = `{
                 "kayaking",
                 "golfing",
                 "hiking",
+                "multisport",
+                "transition",
                 "push-ups",
                 "weight lifting"
             ],
@@ -1201,10 +4869,48 @@ const docTemplate = `{
                 "WorkoutTypeKayaking",
                 "WorkoutTypeGolfing",
                 "WorkoutTypeHiking",
+                "WorkoutTypeMultisport",
+                "WorkoutTypeTransition",
                 "WorkoutTypePushups",
                 "WorkoutTypeWeightLifting"
             ]
         },
+        "database.WorkoutVisibility": {
+            "type": "string",
+            "enum": [
+                "private",
+                "instance",
+                "public",
+                "instance"
+            ],
+            "x-enum-comments": {
+                "WorkoutVisibilityInstance": "Every user of this instance can see the workout",
+                "WorkoutVisibilityPrivate": "Only the owner can see the workout",
+                "WorkoutVisibilityPublic": "Everyone can see the workout"
+            },
+            "x-enum-varnames": [
+                "WorkoutVisibilityPrivate",
+                "WorkoutVisibilityInstance",
+                "WorkoutVisibilityPublic",
+                "DefaultWorkoutVisibility"
+            ]
+        },
+        "database.YearBestEfforts": {
+            "type": "object",
+            "properties": {
+                "bestEfforts": {
+                    "description": "The best effort per target",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.BestEffortRecord"
+                    }
+                },
+                "year": {
+                    "description": "The year",
+                    "type": "integer"
+                }
+            }
+        },
         "database.durationRecord": {
             "type": "object",
             "properties": {
@@ -1,6 +1,9 @@
 package app
 
 import (
+	"archive/zip"
+	"bytes"
+	"errors"
 	"net/http"
 
 	"github.com/jovandeginste/workout-tracker/pkg/database"
@@ -13,8 +16,13 @@ func (a *App) adminRoutes(e *echo.Group) *echo.Group {
 
 	adminGroup.GET("", a.adminRootHandler).Name = "admin"
 	adminGroup.POST("/config", a.adminConfigUpdateHandler).Name = "admin-config-update"
+	adminGroup.POST("/workout-types", a.adminWorkoutTypeCreateHandler).Name = "admin-workout-type-create"
+	adminGroup.POST("/workout-types/:id/delete", a.adminWorkoutTypeDeleteHandler).Name = "admin-workout-type-delete"
+	adminGroup.POST("/workout-type-mappings", a.adminWorkoutTypeMappingCreateHandler).Name = "admin-workout-type-mapping-create"
+	adminGroup.POST("/workout-type-mappings/:id/delete", a.adminWorkoutTypeMappingDeleteHandler).Name = "admin-workout-type-mapping-delete"
 
 	adminUsersGroup := adminGroup.Group("/users")
+	adminUsersGroup.POST("/import", a.adminUserImportHandler).Name = "admin-user-import"
 	adminUsersGroup.GET("/:id/edit", a.adminUserEditHandler).Name = "admin-user-edit"
 	adminUsersGroup.POST("/:id", a.adminUserUpdateHandler).Name = "admin-user-update"
 	adminUsersGroup.POST("/:id/delete", a.adminUserDeleteHandler).Name = "admin-user-delete"
@@ -32,6 +40,19 @@ func (a *App) adminRootHandler(c echo.Context) error {
 		return a.redirectWithError(c, a.echo.Reverse("user-signout"), err)
 	}
 
+	types, err := database.GetCustomWorkoutTypes(a.db)
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("user-signout"), err)
+	}
+
+	mappings, err := database.GetWorkoutTypeMappings(a.db)
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("user-signout"), err)
+	}
+
+	data["workoutTypes"] = types
+	data["workoutTypeMappings"] = mappings
+
 	return c.Render(http.StatusOK, "admin_root.html", data)
 }
 
@@ -83,6 +104,60 @@ func (a *App) adminUserDeleteHandler(c echo.Context) error {
 	return c.Redirect(http.StatusFound, a.echo.Reverse("admin"))
 }
 
+// adminUserImportHandler creates a new user from an account archive
+func (a *App) adminUserImportHandler(c echo.Context) error {
+	file, err := c.FormFile("archive")
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("admin"), err)
+	}
+
+	content, err := uploadedFile(file)
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("admin"), err)
+	}
+
+	z, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("admin"), err)
+	}
+
+	m, err := database.ReadArchiveManifest(z)
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("admin"), err)
+	}
+
+	u := &database.User{
+		Username: c.FormValue("username"),
+		Name:     m.Name,
+		Active:   true,
+	}
+
+	if u.Username == "" {
+		u.Username = m.Username
+	}
+
+	if err := u.SetPassword(c.FormValue("password")); err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("admin"), err)
+	}
+
+	if err := u.Create(a.db); err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("admin"), err)
+	}
+
+	result, err := u.ImportArchive(a.db, bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		if delErr := u.Delete(a.db); delErr != nil {
+			err = errors.Join(err, delErr)
+		}
+
+		return a.redirectWithError(c, a.echo.Reverse("admin"), err)
+	}
+
+	a.setArchiveImportNotice(c, result)
+
+	return c.Redirect(http.StatusFound, a.echo.Reverse("admin-user-show", u.ID))
+}
+
 func (a *App) adminConfigUpdateHandler(c echo.Context) error {
 	var cnf database.Config
 
@@ -0,0 +1,226 @@
+package app
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/jovandeginste/workout-tracker/pkg/database"
+	"github.com/labstack/echo/v4"
+)
+
+// apiEquipmentListHandler lists current user's equipment
+// @Summary      List all equipment of the current user
+// @Produce      json
+// @Success      200  {object}  APIResponse{result=[]database.Equipment}
+// @Failure      400  {object}  APIResponse
+// @Failure      500  {object}  APIResponse
+// @Router       /equipment [get]
+func (a *App) apiEquipmentListHandler(c echo.Context) error {
+	resp := APIResponse{}
+
+	e, err := a.getCurrentUser(c).GetAllEquipment(a.db)
+	if err != nil {
+		return a.renderAPIError(c, resp, err)
+	}
+
+	resp.Results = e
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// apiEquipmentHandler returns all information about a piece of equipment
+// @Summary      Get all information about a piece of equipment
+// @Param        id  path  int  true  "Equipment ID"
+// @Produce      json
+// @Success      200  {object}  APIResponse{result=database.Equipment}
+// @Failure      400  {object}  APIResponse
+// @Failure      404  {object}  APIResponse
+// @Failure      500  {object}  APIResponse
+// @Router       /equipment/{id} [get]
+func (a *App) apiEquipmentHandler(c echo.Context) error {
+	resp := APIResponse{}
+
+	e, err := getAPIOwned(a, c, "id", (*database.User).GetEquipment)
+	if err != nil {
+		return a.renderAPIError(c, resp, err)
+	}
+
+	resp.Results = e
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// apiEquipmentCreateHandler creates a piece of equipment
+// @Summary      Create a piece of equipment
+// @Param        equipment  body  database.Equipment  true  "The equipment"
+// @Accept       json
+// @Produce      json
+// @Success      201  {object}  APIResponse{result=database.Equipment}
+// @Failure      400  {object}  APIResponse
+// @Failure      422  {object}  APIResponse
+// @Failure      500  {object}  APIResponse
+// @Router       /equipment [post]
+func (a *App) apiEquipmentCreateHandler(c echo.Context) error {
+	resp := APIResponse{}
+
+	e := &database.Equipment{Active: true}
+	if err := c.Bind(e); err != nil {
+		return a.renderAPIError(c, resp, err)
+	}
+
+	e.ID = 0
+	e.UserID = a.getCurrentUser(c).ID
+
+	if err := validateEquipment(a.getCurrentUser(c), e); err != nil {
+		return a.renderAPIError(c, resp, err)
+	}
+
+	if err := e.Save(a.db); err != nil {
+		return a.renderAPIError(c, resp, err)
+	}
+
+	resp.Results = e
+
+	return c.JSON(http.StatusCreated, resp)
+}
+
+// apiEquipmentUpdateHandler updates a piece of equipment
+// @Summary      Update a piece of equipment
+// @Description  Only the fields that are given are updated.
+// @Param        id         path  int                 true  "Equipment ID"
+// @Param        equipment  body  database.Equipment  true  "The fields to update"
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  APIResponse{result=database.Equipment}
+// @Failure      400  {object}  APIResponse
+// @Failure      404  {object}  APIResponse
+// @Failure      422  {object}  APIResponse
+// @Failure      500  {object}  APIResponse
+// @Router       /equipment/{id} [put]
+// @Router       /equipment/{id} [patch]
+func (a *App) apiEquipmentUpdateHandler(c echo.Context) error {
+	resp := APIResponse{}
+
+	e, err := getAPIOwned(a, c, "id", (*database.User).GetEquipment)
+	if err != nil {
+		return a.renderAPIError(c, resp, err)
+	}
+
+	id, userID := e.ID, e.UserID
+
+	if err := c.Bind(e); err != nil {
+		return a.renderAPIError(c, resp, err)
+	}
+
+	e.ID, e.UserID = id, userID
+
+	if err := validateEquipment(a.getCurrentUser(c), e); err != nil {
+		return a.renderAPIError(c, resp, err)
+	}
+
+	if err := e.Save(a.db); err != nil {
+		return a.renderAPIError(c, resp, err)
+	}
+
+	resp.Results = e
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// apiEquipmentDeleteHandler deletes a piece of equipment
+// @Summary      Delete a piece of equipment
+// @Param        id  path  int  true  "Equipment ID"
+// @Produce      json
+// @Success      200  {object}  APIResponse{result=database.Equipment}
+// @Failure      400  {object}  APIResponse
+// @Failure      404  {object}  APIResponse
+// @Failure      500  {object}  APIResponse
+// @Router       /equipment/{id} [delete]
+func (a *App) apiEquipmentDeleteHandler(c echo.Context) error {
+	resp := APIResponse{}
+
+	e, err := getAPIOwned(a, c, "id", (*database.User).GetEquipment)
+	if err != nil {
+		return a.renderAPIError(c, resp, err)
+	}
+
+	if err := e.Delete(a.db); err != nil {
+		return a.renderAPIError(c, resp, err)
+	}
+
+	resp.Results = e
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+func validateEquipment(u *database.User, e *database.Equipment) error {
+	if e.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidInput)
+	}
+
+	for _, wt := range e.DefaultFor {
+		if !u.CanUseWorkoutType(wt) {
+			return fmt.Errorf("%w: invalid type: %q", ErrInvalidInput, wt)
+		}
+	}
+
+	return nil
+}
+
+// apiEquipmentMaintenanceHandler returns the maintenance status of a piece of
+// equipment
+// @Summary      Get the usage of a piece of equipment towards its maintenance items, and its service log
+// @Param        id  path  int  true  "Equipment ID"
+// @Produce      json
+// @Success      200  {object}  APIResponse{result=EquipmentMaintenance}
+// @Failure      400  {object}  APIResponse
+// @Failure      404  {object}  APIResponse
+// @Failure      500  {object}  APIResponse
+// @Router       /equipment/{id}/maintenance [get]
+func (a *App) apiEquipmentMaintenanceHandler(c echo.Context) error {
+	resp := APIResponse{}
+
+	e, err := getAPIOwned(a, c, "id", (*database.User).GetEquipment)
+	if err != nil {
+		return a.renderAPIError(c, resp, err)
+	}
+
+	e, err = database.GetEquipment(a.db, int(e.ID))
+	if err != nil {
+		return a.renderAPIError(c, resp, err)
+	}
+
+	resp.Results = EquipmentMaintenance{
+		Status:   e.MaintenanceStatus(),
+		Services: e.Services,
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// apiEquipmentAlertsHandler returns the maintenance items that are due
+// @Summary      List the maintenance items of all active equipment of the current user that are due
+// @Produce      json
+// @Success      200  {object}  APIResponse{result=[]database.MaintenanceStatus}
+// @Failure      400  {object}  APIResponse
+// @Failure      500  {object}  APIResponse
+// @Router       /equipment/alerts [get]
+func (a *App) apiEquipmentAlertsHandler(c echo.Context) error {
+	resp := APIResponse{}
+
+	alerts, err := a.getCurrentUser(c).GetMaintenanceAlerts(a.db)
+	if err != nil {
+		return a.renderAPIError(c, resp, err)
+	}
+
+	resp.Results = alerts
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// EquipmentMaintenance is the maintenance status and service log of a piece
+// of equipment
+type EquipmentMaintenance struct {
+	Status   []database.MaintenanceStatus `json:"status"`   // The usage towards every maintenance item
+	Services []database.EquipmentService  `json:"services"` // The services that were done, most recent first
+}
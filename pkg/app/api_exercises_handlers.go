@@ -0,0 +1,166 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/jovandeginste/workout-tracker/pkg/database"
+	"github.com/labstack/echo/v4"
+)
+
+// apiExercisesHandler lists the exercises in the current user's catalog
+// @Summary      List the exercises in the current user's catalog
+// @Produce      json
+// @Success      200  {object}  APIResponse{result=[]database.Exercise}
+// @Failure      400  {object}  APIResponse
+// @Failure      500  {object}  APIResponse
+// @Router       /exercises [get]
+func (a *App) apiExercisesHandler(c echo.Context) error {
+	resp := APIResponse{}
+
+	e, err := a.getCurrentUser(c).GetExercises(a.db)
+	if err != nil {
+		return a.renderAPIError(c, resp, err)
+	}
+
+	resp.Results = e
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// apiExerciseHistoryHandler returns the history of an exercise
+// @Summary      Get the performance on an exercise in every workout, oldest first
+// @Param        id  path  int  true  "Exercise ID"
+// @Produce      json
+// @Success      200  {object}  APIResponse{result=[]database.ExerciseHistoryItem}
+// @Failure      400  {object}  APIResponse
+// @Failure      404  {object}  APIResponse
+// @Failure      500  {object}  APIResponse
+// @Router       /exercises/{id}/history [get]
+func (a *App) apiExerciseHistoryHandler(c echo.Context) error {
+	resp := APIResponse{}
+
+	e, err := getAPIOwned(a, c, "id", (*database.User).GetExercise)
+	if err != nil {
+		return a.renderAPIError(c, resp, err)
+	}
+
+	h, err := e.History(a.db)
+	if err != nil {
+		return a.renderAPIError(c, resp, err)
+	}
+
+	resp.Results = h
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// apiWorkoutExercisesHandler returns the exercises of a workout
+// @Summary      List the exercises of a workout, with their sets
+// @Param        id  path  int  true  "Workout ID"
+// @Produce      json
+// @Success      200  {object}  APIResponse{result=[]database.WorkoutExercise}
+// @Failure      400  {object}  APIResponse
+// @Failure      403  {object}  APIResponse
+// @Failure      404  {object}  APIResponse
+// @Failure      500  {object}  APIResponse
+// @Router       /workouts/{id}/exercises [get]
+func (a *App) apiWorkoutExercisesHandler(c echo.Context) error {
+	resp := APIResponse{}
+
+	w, err := a.getAPIWorkout(c, a.db)
+	if err != nil {
+		return a.renderAPIError(c, resp, err)
+	}
+
+	exercises := []database.WorkoutExercise{}
+	if w.Exercises != nil {
+		exercises = w.Exercises
+	}
+
+	resp.Results = exercises
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// apiWorkoutSetCreateHandler adds a set to a workout
+// @Summary      Add a set of an exercise to a workout
+// @Description  The exercise is looked up by name in the catalog, and added to it if needed. The totals of the workout are updated.
+// @Param        id   path  int               true  "Workout ID"
+// @Param        set  body  ExerciseSetInput  true  "The set"
+// @Accept       json
+// @Produce      json
+// @Success      201  {object}  APIResponse{result=[]database.WorkoutExercise}
+// @Failure      400  {object}  APIResponse
+// @Failure      403  {object}  APIResponse
+// @Failure      404  {object}  APIResponse
+// @Failure      422  {object}  APIResponse
+// @Failure      500  {object}  APIResponse
+// @Router       /workouts/{id}/sets [post]
+func (a *App) apiWorkoutSetCreateHandler(c echo.Context) error {
+	resp := APIResponse{}
+
+	w, err := a.getAPIWorkout(c, a.db.Preload("Data"))
+	if err != nil {
+		return a.renderAPIError(c, resp, err)
+	}
+
+	var i ExerciseSetInput
+
+	if err := c.Bind(&i); err != nil {
+		return a.renderAPIError(c, resp, err)
+	}
+
+	if err := i.AddTo(a.db, a.getCurrentUser(c), w); err != nil {
+		return a.renderAPIError(c, resp, apiExerciseError(err))
+	}
+
+	resp.Results = w.Exercises
+
+	return c.JSON(http.StatusCreated, resp)
+}
+
+// apiWorkoutSetDeleteHandler removes a set from a workout
+// @Summary      Remove a set from a workout
+// @Description  The totals of the workout are updated.
+// @Param        id     path  int  true  "Workout ID"
+// @Param        setID  path  int  true  "Set ID"
+// @Produce      json
+// @Success      200  {object}  APIResponse{result=[]database.WorkoutExercise}
+// @Failure      400  {object}  APIResponse
+// @Failure      403  {object}  APIResponse
+// @Failure      404  {object}  APIResponse
+// @Failure      500  {object}  APIResponse
+// @Router       /workouts/{id}/sets/{setID} [delete]
+func (a *App) apiWorkoutSetDeleteHandler(c echo.Context) error {
+	resp := APIResponse{}
+
+	w, err := a.getAPIWorkout(c, a.db.Preload("Data"))
+	if err != nil {
+		return a.renderAPIError(c, resp, err)
+	}
+
+	id, err := strconv.Atoi(c.Param("setID"))
+	if err != nil {
+		return a.renderAPIError(c, resp, err)
+	}
+
+	if err := w.DeleteSet(a.db, uint(id)); err != nil {
+		return a.renderAPIError(c, resp, err)
+	}
+
+	resp.Results = w.Exercises
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// apiExerciseError marks errors caused by the input as invalid input
+func apiExerciseError(err error) error {
+	if errors.Is(err, database.ErrInvalidExercise) || errors.Is(err, database.ErrNoRepetitionWorkout) {
+		return fmt.Errorf("%w: %w", ErrInvalidInput, err)
+	}
+
+	return err
+}
@@ -0,0 +1,173 @@
+package app
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/jovandeginste/workout-tracker/pkg/database"
+	"github.com/labstack/echo/v4"
+)
+
+// apiGoalsHandler lists current user's goals, with their progress
+// @Summary      List all goals of the current user, with the progress in the current period
+// @Produce      json
+// @Success      200  {object}  APIResponse{result=[]database.GoalProgress}
+// @Failure      400  {object}  APIResponse
+// @Failure      500  {object}  APIResponse
+// @Router       /goals [get]
+func (a *App) apiGoalsHandler(c echo.Context) error {
+	resp := APIResponse{}
+
+	p, err := a.getCurrentUser(c).GetGoalsProgress(time.Now())
+	if err != nil {
+		return a.renderAPIError(c, resp, err)
+	}
+
+	resp.Results = p
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// apiGoalHandler returns a goal, with its progress
+// @Summary      Get a goal, with the progress in the current period
+// @Param        id  path  int  true  "Goal ID"
+// @Produce      json
+// @Success      200  {object}  APIResponse{result=database.GoalProgress}
+// @Failure      400  {object}  APIResponse
+// @Failure      404  {object}  APIResponse
+// @Failure      500  {object}  APIResponse
+// @Router       /goals/{id} [get]
+func (a *App) apiGoalHandler(c echo.Context) error {
+	resp := APIResponse{}
+
+	g, err := getAPIOwned(a, c, "id", (*database.User).GetGoal)
+	if err != nil {
+		return a.renderAPIError(c, resp, err)
+	}
+
+	p, err := a.getCurrentUser(c).GetGoalProgress(g, time.Now())
+	if err != nil {
+		return a.renderAPIError(c, resp, err)
+	}
+
+	resp.Results = p
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// apiGoalCreateHandler creates a goal
+// @Summary      Create a goal
+// @Description  The target is in meters for distance goals, and in seconds for duration goals.
+// @Param        goal  body  database.Goal  true  "The goal"
+// @Accept       json
+// @Produce      json
+// @Success      201  {object}  APIResponse{result=database.Goal}
+// @Failure      400  {object}  APIResponse
+// @Failure      422  {object}  APIResponse
+// @Failure      500  {object}  APIResponse
+// @Router       /goals [post]
+func (a *App) apiGoalCreateHandler(c echo.Context) error {
+	resp := APIResponse{}
+
+	g := &database.Goal{}
+	if err := c.Bind(g); err != nil {
+		return a.renderAPIError(c, resp, err)
+	}
+
+	g.ID = 0
+	g.UserID = a.getCurrentUser(c).ID
+
+	if err := validateGoal(a.getCurrentUser(c), g); err != nil {
+		return a.renderAPIError(c, resp, err)
+	}
+
+	if err := g.Save(a.db); err != nil {
+		return a.renderAPIError(c, resp, err)
+	}
+
+	resp.Results = g
+
+	return c.JSON(http.StatusCreated, resp)
+}
+
+// apiGoalUpdateHandler updates a goal
+// @Summary      Update a goal
+// @Description  Only the fields that are given are updated.
+// @Param        id    path  int            true  "Goal ID"
+// @Param        goal  body  database.Goal  true  "The fields to update"
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  APIResponse{result=database.Goal}
+// @Failure      400  {object}  APIResponse
+// @Failure      404  {object}  APIResponse
+// @Failure      422  {object}  APIResponse
+// @Failure      500  {object}  APIResponse
+// @Router       /goals/{id} [put]
+// @Router       /goals/{id} [patch]
+func (a *App) apiGoalUpdateHandler(c echo.Context) error {
+	resp := APIResponse{}
+
+	g, err := getAPIOwned(a, c, "id", (*database.User).GetGoal)
+	if err != nil {
+		return a.renderAPIError(c, resp, err)
+	}
+
+	id, userID := g.ID, g.UserID
+
+	if err := c.Bind(g); err != nil {
+		return a.renderAPIError(c, resp, err)
+	}
+
+	g.ID, g.UserID = id, userID
+
+	if err := validateGoal(a.getCurrentUser(c), g); err != nil {
+		return a.renderAPIError(c, resp, err)
+	}
+
+	if err := g.Save(a.db); err != nil {
+		return a.renderAPIError(c, resp, err)
+	}
+
+	resp.Results = g
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// apiGoalDeleteHandler deletes a goal
+// @Summary      Delete a goal
+// @Param        id  path  int  true  "Goal ID"
+// @Produce      json
+// @Success      200  {object}  APIResponse{result=database.Goal}
+// @Failure      400  {object}  APIResponse
+// @Failure      404  {object}  APIResponse
+// @Failure      500  {object}  APIResponse
+// @Router       /goals/{id} [delete]
+func (a *App) apiGoalDeleteHandler(c echo.Context) error {
+	resp := APIResponse{}
+
+	g, err := getAPIOwned(a, c, "id", (*database.User).GetGoal)
+	if err != nil {
+		return a.renderAPIError(c, resp, err)
+	}
+
+	if err := g.Delete(a.db); err != nil {
+		return a.renderAPIError(c, resp, err)
+	}
+
+	resp.Results = g
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+func validateGoal(u *database.User, g *database.Goal) error {
+	if g.Type != "" && !u.CanUseWorkoutType(g.Type) {
+		return fmt.Errorf("%w: invalid type: %q", ErrInvalidInput, g.Type)
+	}
+
+	if err := g.Validate(); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidInput, err)
+	}
+
+	return nil
+}
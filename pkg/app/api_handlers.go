@@ -11,13 +11,26 @@ import (
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	"github.com/labstack/gommon/log"
+	"gorm.io/gorm"
 )
 
-var ErrInvalidAPIKey = errors.New("invalid API key")
+var (
+	ErrInvalidAPIKey = errors.New("invalid API key")
+	ErrNotOwner      = errors.New("you are not the owner of this resource")
+	ErrInvalidInput  = errors.New("invalid input")
+)
 
 type APIResponse struct {
-	Errors  []string    `json:"errors"`
-	Results interface{} `json:"results"`
+	Errors     []string       `json:"errors"`
+	Results    interface{}    `json:"results"`
+	Pagination *APIPagination `json:"pagination,omitempty"`
+}
+
+type APIPagination struct {
+	Page    int   `json:"page"`     // The number of this page, starting at 1
+	PerPage int   `json:"per_page"` // The maximum number of results per page
+	Pages   int   `json:"pages"`    // The total number of pages
+	Total   int64 `json:"total"`    // The total number of results
 }
 
 // @title Workout Tracker
@@ -61,9 +74,45 @@ func (a *App) apiRoutes(e *echo.Group) {
 	}))
 
 	apiGroup.GET("/whoami", a.apiWhoamiHandler).Name = "api-whoami"
+	apiGroup.PUT("/profile", a.apiProfileUpdateHandler).Name = "api-profile-update"
+	apiGroup.PATCH("/profile", a.apiProfileUpdateHandler).Name = "api-profile-patch"
+	apiGroup.PUT("/profile/preferred-units", a.apiProfilePreferredUnitsUpdateHandler).Name = "api-profile-preferred-units-update"
 	apiGroup.GET("/workouts", a.apiWorkoutsHandler).Name = "api-workouts"
+	apiGroup.POST("/workouts", a.apiWorkoutCreateHandler).Name = "api-workouts-create"
 	apiGroup.GET("/workouts/:id", a.apiWorkoutHandler).Name = "api-workout"
+	apiGroup.PUT("/workouts/:id", a.apiWorkoutReplaceHandler).Name = "api-workout-update"
+	apiGroup.PATCH("/workouts/:id", a.apiWorkoutPatchHandler).Name = "api-workout-patch"
+	apiGroup.DELETE("/workouts/:id", a.apiWorkoutDeleteHandler).Name = "api-workout-delete"
 	apiGroup.GET("/workouts/:id/breakdown", a.apiWorkoutBreakdownHandler).Name = "api-workout-breakdown"
+	apiGroup.GET("/workouts/:id/export", a.apiWorkoutExportHandler).Name = "api-workout-export"
+	apiGroup.GET("/workouts/:id/laps", a.apiWorkoutLapsHandler).Name = "api-workout-laps"
+	apiGroup.GET("/workouts/:id/exercises", a.apiWorkoutExercisesHandler).Name = "api-workout-exercises"
+	apiGroup.POST("/workouts/:id/sets", a.apiWorkoutSetCreateHandler).Name = "api-workout-set-create"
+	apiGroup.DELETE("/workouts/:id/sets/:setID", a.apiWorkoutSetDeleteHandler).Name = "api-workout-set-delete"
+	apiGroup.POST("/workouts/:id/equipment/:equipmentID", a.apiWorkoutEquipmentLinkHandler).Name = "api-workout-equipment-link"
+	apiGroup.DELETE("/workouts/:id/equipment/:equipmentID", a.apiWorkoutEquipmentUnlinkHandler).Name = "api-workout-equipment-unlink"
+	apiGroup.GET("/equipment", a.apiEquipmentListHandler).Name = "api-equipment"
+	apiGroup.POST("/equipment", a.apiEquipmentCreateHandler).Name = "api-equipment-create"
+	apiGroup.GET("/equipment/alerts", a.apiEquipmentAlertsHandler).Name = "api-equipment-alerts"
+	apiGroup.GET("/equipment/:id", a.apiEquipmentHandler).Name = "api-equipment-show"
+	apiGroup.GET("/equipment/:id/maintenance", a.apiEquipmentMaintenanceHandler).Name = "api-equipment-maintenance"
+	apiGroup.PUT("/equipment/:id", a.apiEquipmentUpdateHandler).Name = "api-equipment-update"
+	apiGroup.PATCH("/equipment/:id", a.apiEquipmentUpdateHandler).Name = "api-equipment-patch"
+	apiGroup.DELETE("/equipment/:id", a.apiEquipmentDeleteHandler).Name = "api-equipment-delete"
+	apiGroup.GET("/exercises", a.apiExercisesHandler).Name = "api-exercises"
+	apiGroup.GET("/exercises/:id/history", a.apiExerciseHistoryHandler).Name = "api-exercise-history"
+	apiGroup.GET("/goals", a.apiGoalsHandler).Name = "api-goals"
+	apiGroup.POST("/goals", a.apiGoalCreateHandler).Name = "api-goals-create"
+	apiGroup.GET("/goals/:id", a.apiGoalHandler).Name = "api-goal-show"
+	apiGroup.PUT("/goals/:id", a.apiGoalUpdateHandler).Name = "api-goal-update"
+	apiGroup.PATCH("/goals/:id", a.apiGoalUpdateHandler).Name = "api-goal-patch"
+	apiGroup.DELETE("/goals/:id", a.apiGoalDeleteHandler).Name = "api-goal-delete"
+	apiGroup.GET("/measurements", a.apiMeasurementsHandler).Name = "api-measurements"
+	apiGroup.POST("/measurements", a.apiMeasurementCreateHandler).Name = "api-measurements-create"
+	apiGroup.GET("/measurements/:id", a.apiMeasurementHandler).Name = "api-measurement-show"
+	apiGroup.PUT("/measurements/:id", a.apiMeasurementUpdateHandler).Name = "api-measurement-update"
+	apiGroup.PATCH("/measurements/:id", a.apiMeasurementUpdateHandler).Name = "api-measurement-patch"
+	apiGroup.DELETE("/measurements/:id", a.apiMeasurementDeleteHandler).Name = "api-measurement-delete"
 	apiGroup.GET("/statistics", a.apiStatisticsHandler).Name = "api-statistics"
 	apiGroup.GET("/totals", a.apiTotalsHandler).Name = "api-totals"
 	apiGroup.GET("/records", a.apiRecordsHandler).Name = "api-records"
@@ -99,22 +148,52 @@ func (a *App) apiWhoamiHandler(c echo.Context) error {
 }
 
 // apiWorkoutsHandler lists current user's workouts
-// @Summary      List all workouts of the current user
+// @Summary      List the workouts of the current user, one page at a time
+// @Param        type          query  string  false  "Workout type"
+// @Param        since         query  string  false  "Only workouts on or after this date (YYYY-MM-DD)"
+// @Param        until         query  string  false  "Only workouts on or before this date (YYYY-MM-DD)"
+// @Param        equipment     query  int     false  "Only workouts that used this equipment"
+// @Param        min_distance  query  number  false  "Minimum distance, in the preferred unit"
+// @Param        max_distance  query  number  false  "Maximum distance, in the preferred unit"
+// @Param        min_duration  query  number  false  "Minimum duration, in minutes"
+// @Param        max_duration  query  number  false  "Maximum duration, in minutes"
+// @Param        q             query  string  false  "Text to find in the name, notes or address"
+// @Param        sort          query  string  false  "Sort key: date, distance, duration or speed"
+// @Param        order         query  string  false  "Sort order: asc or desc"
+// @Param        page          query  int     false  "Page, starting at 1"
+// @Param        per_page      query  int     false  "Workouts per page"
 // @Produce      json
 // @Success      200  {object}  APIResponse{result=[]database.Workout}
 // @Failure      400  {object}  APIResponse
-// @Failure      404  {object}  APIResponse
+// @Failure      422  {object}  APIResponse
 // @Failure      500  {object}  APIResponse
 // @Router       /workouts [get]
 func (a *App) apiWorkoutsHandler(c echo.Context) error {
 	resp := APIResponse{}
+	u := a.getCurrentUser(c)
 
-	w, err := a.getCurrentUser(c).GetWorkouts(a.db)
+	params := WorkoutListParams{}
+	if err := c.Bind(&params); err != nil {
+		return a.renderAPIError(c, resp, err)
+	}
+
+	q, err := params.ToQuery(u)
 	if err != nil {
-		resp.Errors = append(resp.Errors, err.Error())
+		return a.renderAPIError(c, resp, err)
 	}
 
-	resp.Results = w
+	p, err := u.GetWorkoutsPage(a.db, q)
+	if err != nil {
+		return a.renderAPIError(c, resp, err)
+	}
+
+	resp.Results = p.Workouts
+	resp.Pagination = &APIPagination{
+		Page:    p.Page,
+		PerPage: p.PerPage,
+		Pages:   p.Pages(),
+		Total:   p.Total,
+	}
 
 	return c.JSON(http.StatusOK, resp)
 }
@@ -218,11 +297,6 @@ func (a *App) apiStatisticsHandler(c echo.Context) error {
 func (a *App) apiWorkoutBreakdownHandler(c echo.Context) error {
 	resp := APIResponse{}
 
-	id, err := strconv.Atoi(c.Param("id"))
-	if err != nil {
-		return a.renderAPIError(c, resp, err)
-	}
-
 	config := struct {
 		Unit  string  `query:"unit"`
 		Count float64 `query:"count"`
@@ -230,13 +304,13 @@ func (a *App) apiWorkoutBreakdownHandler(c echo.Context) error {
 		Unit:  "km",
 		Count: 1.0,
 	}
-	if err = c.Bind(&config); err != nil {
+	if err := c.Bind(&config); err != nil {
 		return a.renderAPIError(c, resp, err)
 	}
 
-	w, err := a.getCurrentUser(c).GetWorkout(a.db, id)
+	w, err := a.getAPIWorkout(c, a.db)
 	if err != nil {
-		resp.Errors = append(resp.Errors, err.Error())
+		return a.renderAPIError(c, resp, err)
 	}
 
 	resp.Results, err = w.StatisticsPer(config.Count, config.Unit)
@@ -247,6 +321,34 @@ func (a *App) apiWorkoutBreakdownHandler(c echo.Context) error {
 	return c.JSON(http.StatusOK, resp)
 }
 
+// apiWorkoutLapsHandler returns the laps of a workout
+// @Summary      List the laps of a workout, as recorded by the device
+// @Param        id      path       int     true  "Workout ID"
+// @Produce      json
+// @Success      200  {object}  APIResponse{result=[]database.Lap}
+// @Failure      400  {object}  APIResponse
+// @Failure      403  {object}  APIResponse
+// @Failure      404  {object}  APIResponse
+// @Failure      500  {object}  APIResponse
+// @Router       /workouts/{id}/laps [get]
+func (a *App) apiWorkoutLapsHandler(c echo.Context) error {
+	resp := APIResponse{}
+
+	w, err := a.getAPIWorkout(c, a.db.Preload("Data.Laps"))
+	if err != nil {
+		return a.renderAPIError(c, resp, err)
+	}
+
+	laps := []database.Lap{}
+	if w.Data != nil && w.Data.Laps != nil {
+		laps = w.Data.Laps
+	}
+
+	resp.Results = laps
+
+	return c.JSON(http.StatusOK, resp)
+}
+
 // apiWorkoutHandler returns all information about a workout
 // @Summary      Get all information about a workout
 // @Param        id      path       int     true  "Workout ID"
@@ -260,13 +362,8 @@ func (a *App) apiWorkoutBreakdownHandler(c echo.Context) error {
 func (a *App) apiWorkoutHandler(c echo.Context) error {
 	resp := APIResponse{}
 
-	id, err := strconv.Atoi(c.Param("id"))
-	if err != nil {
-		return a.renderAPIError(c, resp, err)
-	}
-
 	details := false
-	if err = echo.QueryParamsBinder(c).Bool("details", &details).BindError(); err != nil {
+	if err := echo.QueryParamsBinder(c).Bool("details", &details).BindError(); err != nil {
 		return a.renderAPIError(c, resp, err)
 	}
 
@@ -275,9 +372,9 @@ func (a *App) apiWorkoutHandler(c echo.Context) error {
 		db = db.Preload("Data.Details")
 	}
 
-	w, err := a.getCurrentUser(c).GetWorkout(db, id)
+	w, err := a.getAPIWorkout(c, db)
 	if err != nil {
-		resp.Errors = append(resp.Errors, err.Error())
+		return a.renderAPIError(c, resp, err)
 	}
 
 	resp.Results = w
@@ -288,8 +385,8 @@ func (a *App) apiWorkoutHandler(c echo.Context) error {
 // apiImportHandler imports a workout
 // @Summary      Import a workout
 // @Param        program path  string true "Program that generates the workout file"
-// @Param        name query    string "no-name" "Name of the imported workout"
-// @Param        type query    string "auto" "Type of the imported workout"
+// @Param        name query    string false "Name of the imported workout" default(no-name)
+// @Param        type query    string false "Type of the imported workout" default(auto)
 // @Produce      json
 // @Success      200  {object}  APIResponse{result=database.Workout}
 // @Failure      400  {object}  APIResponse
@@ -320,5 +417,52 @@ func (a *App) apiImportHandler(c echo.Context) error {
 func (a *App) renderAPIError(c echo.Context, resp APIResponse, err error) error {
 	resp.Errors = append(resp.Errors, err.Error())
 
-	return c.JSON(http.StatusBadRequest, resp)
+	return c.JSON(apiErrorStatus(err), resp)
+}
+
+// apiErrorStatus returns the HTTP status code that matches the error
+func apiErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound), errors.Is(err, database.ErrWorkoutNotVisible):
+		return http.StatusNotFound
+	case errors.Is(err, ErrNotOwner):
+		return http.StatusForbidden
+	case errors.Is(err, ErrInvalidInput):
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+// getAPIWorkout returns the workout from the path if it belongs to the current
+// user; if the workout belongs to someone else, but the current user can see
+// it, ErrNotOwner is returned
+func (a *App) getAPIWorkout(c echo.Context, db *gorm.DB) (*database.Workout, error) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return nil, err
+	}
+
+	u := a.getCurrentUser(c)
+
+	w, err := u.GetWorkout(db, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		if other, otherErr := database.GetWorkout(a.db, id); otherErr == nil && other.IsVisibleTo(u) {
+			return nil, ErrNotOwner
+		}
+	}
+
+	return w, err
+}
+
+// getAPIOwned returns the resource with the ID in the path parameter, through
+// the lookup of the current user; resources of other users are not found, so
+// their IDs are never revealed
+func getAPIOwned[T any](a *App, c echo.Context, param string, get func(*database.User, *gorm.DB, int) (*T, error)) (*T, error) {
+	id, err := strconv.Atoi(c.Param(param))
+	if err != nil {
+		return nil, err
+	}
+
+	return get(a.getCurrentUser(c), a.db, id)
 }
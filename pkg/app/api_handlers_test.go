@@ -0,0 +1,392 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jovandeginste/workout-tracker/pkg/database"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func apiUser(t *testing.T, a *App, username string) *database.User {
+	u := defaultUser(a.db)
+	u.Username = username
+	u.Active = true
+	require.NoError(t, u.Create(a.db))
+
+	u, err := database.GetUser(a.db, username)
+	require.NoError(t, err)
+
+	return u
+}
+
+func apiRequest(t *testing.T, a *App, u *database.User, h echo.HandlerFunc, method, body string, params ...string) (int, APIResponse) {
+	req := httptest.NewRequest(method, "/api/v1", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+	rec := httptest.NewRecorder()
+
+	c := a.echo.NewContext(req, rec)
+	c.Set("user_info", u)
+
+	names, values := []string{}, []string{}
+
+	for i := 0; i+1 < len(params); i += 2 {
+		names = append(names, params[i])
+		values = append(values, params[i+1])
+	}
+
+	c.SetParamNames(names...)
+	c.SetParamValues(values...)
+
+	require.NoError(t, h(c))
+
+	resp := APIResponse{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+
+	return rec.Code, resp
+}
+
+func TestAPI_WorkoutCRUD(t *testing.T) {
+	a := configuredApp(t)
+	u := apiUser(t, a, "api-user")
+	other := apiUser(t, a, "other-user")
+
+	code, resp := apiRequest(t, a, u, a.apiWorkoutCreateHandler, http.MethodPost, `{"name": "api workout"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, code)
+	assert.NotEmpty(t, resp.Errors)
+
+	code, _ = apiRequest(t, a, u, a.apiWorkoutCreateHandler, http.MethodPost,
+		`{"date": "2024-01-02T10:00:00Z", "type": "invalid"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, code)
+
+	code, resp = apiRequest(t, a, u, a.apiWorkoutCreateHandler, http.MethodPost,
+		`{"name": "api workout", "date": "2024-01-02T10:00:00Z", "type": "running", "distance": 5, "duration_minutes": 30}`)
+	require.Equal(t, http.StatusCreated, code, resp.Errors)
+
+	ws, err := u.GetWorkouts(a.db)
+	require.NoError(t, err)
+	require.Len(t, ws, 1)
+	assert.Equal(t, "api workout", ws[0].Name)
+	assert.InDelta(t, 5000, ws[0].Data.TotalDistance, 0.1)
+
+	id := strconv.FormatUint(uint64(ws[0].ID), 10)
+
+	code, resp = apiRequest(t, a, u, a.apiWorkoutPatchHandler, http.MethodPatch, `{"name": "renamed"}`, "id", id)
+	require.Equal(t, http.StatusOK, code, resp.Errors)
+
+	w, err := u.GetWorkout(a.db, int(ws[0].ID))
+	require.NoError(t, err)
+	assert.Equal(t, "renamed", w.Name)
+	assert.InDelta(t, 5000, w.Data.TotalDistance, 0.1)
+	assert.NotNil(t, w.Date)
+
+	code, _ = apiRequest(t, a, u, a.apiWorkoutReplaceHandler, http.MethodPut, `{"name": "no date"}`, "id", id)
+	assert.Equal(t, http.StatusUnprocessableEntity, code)
+
+	code, _ = apiRequest(t, a, u, a.apiWorkoutHandler, http.MethodGet, "", "id", "12345")
+	assert.Equal(t, http.StatusNotFound, code)
+
+	code, _ = apiRequest(t, a, other, a.apiWorkoutDeleteHandler, http.MethodDelete, "", "id", id)
+	assert.Equal(t, http.StatusForbidden, code)
+
+	code, _ = apiRequest(t, a, u, a.apiWorkoutDeleteHandler, http.MethodDelete, "", "id", id)
+	assert.Equal(t, http.StatusOK, code)
+
+	code, _ = apiRequest(t, a, u, a.apiWorkoutHandler, http.MethodGet, "", "id", id)
+	assert.Equal(t, http.StatusNotFound, code)
+}
+
+func TestAPI_EquipmentCRUD(t *testing.T) {
+	a := configuredApp(t)
+	u := apiUser(t, a, "api-user")
+	other := apiUser(t, a, "other-user")
+
+	code, _ := apiRequest(t, a, u, a.apiEquipmentCreateHandler, http.MethodPost, `{"description": "no name"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, code)
+
+	code, resp := apiRequest(t, a, u, a.apiEquipmentCreateHandler, http.MethodPost,
+		`{"name": "shoes", "default_for": ["running"]}`)
+	require.Equal(t, http.StatusCreated, code, resp.Errors)
+
+	es, err := u.GetAllEquipment(a.db)
+	require.NoError(t, err)
+	require.Len(t, es, 1)
+
+	eid := strconv.FormatUint(uint64(es[0].ID), 10)
+
+	code, _ = apiRequest(t, a, u, a.apiEquipmentUpdateHandler, http.MethodPatch, `{"description": "red"}`, "id", eid)
+	assert.Equal(t, http.StatusOK, code)
+
+	e, err := u.GetEquipment(a.db, int(es[0].ID))
+	require.NoError(t, err)
+	assert.Equal(t, "shoes", e.Name)
+	assert.Equal(t, "red", e.Description)
+
+	code, _ = apiRequest(t, a, other, a.apiEquipmentHandler, http.MethodGet, "", "id", eid)
+	assert.Equal(t, http.StatusNotFound, code)
+
+	code, resp = apiRequest(t, a, u, a.apiWorkoutCreateHandler, http.MethodPost,
+		`{"date": "2024-01-02T10:00:00Z", "type": "running", "equipment": [`+eid+`]}`)
+	require.Equal(t, http.StatusCreated, code, resp.Errors)
+
+	ws, err := u.GetWorkouts(a.db)
+	require.NoError(t, err)
+	require.Len(t, ws, 1)
+
+	wid := strconv.FormatUint(uint64(ws[0].ID), 10)
+
+	w, err := u.GetWorkout(a.db, int(ws[0].ID))
+	require.NoError(t, err)
+	assert.Len(t, w.Equipment, 1)
+
+	code, _ = apiRequest(t, a, u, a.apiWorkoutEquipmentUnlinkHandler, http.MethodDelete, "", "id", wid, "equipmentID", eid)
+	assert.Equal(t, http.StatusOK, code)
+
+	w, err = u.GetWorkout(a.db, int(ws[0].ID))
+	require.NoError(t, err)
+	assert.Empty(t, w.Equipment)
+
+	code, _ = apiRequest(t, a, u, a.apiWorkoutEquipmentLinkHandler, http.MethodPost, "", "id", wid, "equipmentID", eid)
+	assert.Equal(t, http.StatusOK, code)
+
+	w, err = u.GetWorkout(a.db, int(ws[0].ID))
+	require.NoError(t, err)
+	assert.Len(t, w.Equipment, 1)
+
+	code, _ = apiRequest(t, a, u, a.apiEquipmentDeleteHandler, http.MethodDelete, "", "id", eid)
+	assert.Equal(t, http.StatusOK, code)
+
+	code, _ = apiRequest(t, a, u, a.apiEquipmentHandler, http.MethodGet, "", "id", eid)
+	assert.Equal(t, http.StatusNotFound, code)
+}
+
+func TestAPI_GoalCRUD(t *testing.T) {
+	a := configuredApp(t)
+	u := apiUser(t, a, "api-user")
+	other := apiUser(t, a, "other-user")
+
+	code, _ := apiRequest(t, a, u, a.apiGoalCreateHandler, http.MethodPost, `{"name": "no target", "metric": "workouts", "period": "week"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, code)
+
+	code, _ = apiRequest(t, a, u, a.apiGoalCreateHandler, http.MethodPost,
+		`{"name": "push-ups", "type": "push-ups", "metric": "distance", "period": "week", "target": 10}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, code)
+
+	code, resp := apiRequest(t, a, u, a.apiGoalCreateHandler, http.MethodPost,
+		`{"name": "often", "metric": "workouts", "period": "week", "target": 3}`)
+	require.Equal(t, http.StatusCreated, code, resp.Errors)
+
+	code, resp = apiRequest(t, a, u, a.apiWorkoutCreateHandler, http.MethodPost,
+		`{"date": "`+time.Now().UTC().Format(time.RFC3339)+`", "type": "running"}`)
+	require.Equal(t, http.StatusCreated, code, resp.Errors)
+
+	gs, err := u.GetGoalsProgress(time.Now())
+	require.NoError(t, err)
+	require.Len(t, gs, 1)
+	assert.InDelta(t, 1, gs[0].Value, 0.1)
+
+	gid := strconv.FormatUint(uint64(gs[0].Goal.ID), 10)
+
+	code, _ = apiRequest(t, a, u, a.apiGoalUpdateHandler, http.MethodPatch, `{"target": 1}`, "id", gid)
+	assert.Equal(t, http.StatusOK, code)
+
+	code, resp = apiRequest(t, a, u, a.apiGoalsHandler, http.MethodGet, "")
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, resp.Results, 1)
+	assert.InDelta(t, 100, resp.Results.([]any)[0].(map[string]any)["Percentage"], 0.1)
+
+	code, _ = apiRequest(t, a, other, a.apiGoalHandler, http.MethodGet, "", "id", gid)
+	assert.Equal(t, http.StatusNotFound, code)
+
+	code, _ = apiRequest(t, a, u, a.apiGoalDeleteHandler, http.MethodDelete, "", "id", gid)
+	assert.Equal(t, http.StatusOK, code)
+
+	code, _ = apiRequest(t, a, u, a.apiGoalHandler, http.MethodGet, "", "id", gid)
+	assert.Equal(t, http.StatusNotFound, code)
+}
+
+func TestAPI_WorkoutSets(t *testing.T) {
+	a := configuredApp(t)
+	u := apiUser(t, a, "api-user")
+	other := apiUser(t, a, "other-user")
+
+	code, resp := apiRequest(t, a, u, a.apiWorkoutCreateHandler, http.MethodPost,
+		`{"date": "`+time.Now().UTC().Format(time.RFC3339)+`", "type": "weight lifting"}`)
+	require.Equal(t, http.StatusCreated, code, resp.Errors)
+
+	wid := strconv.FormatFloat(resp.Results.(map[string]any)["ID"].(float64), 'f', 0, 64)
+
+	code, _ = apiRequest(t, a, u, a.apiWorkoutSetCreateHandler, http.MethodPost, `{"exercise": "Squat", "repetitions": 0}`, "id", wid)
+	assert.Equal(t, http.StatusUnprocessableEntity, code)
+
+	code, resp = apiRequest(t, a, u, a.apiWorkoutSetCreateHandler, http.MethodPost,
+		`{"exercise": "Squat", "repetitions": 5, "weight": 100, "rest": 120}`, "id", wid)
+	require.Equal(t, http.StatusCreated, code, resp.Errors)
+	require.Len(t, resp.Results, 1)
+
+	code, _ = apiRequest(t, a, other, a.apiWorkoutSetCreateHandler, http.MethodPost,
+		`{"exercise": "Squat", "repetitions": 5, "weight": 100}`, "id", wid)
+	assert.Equal(t, http.StatusForbidden, code)
+
+	code, resp = apiRequest(t, a, u, a.apiWorkoutHandler, http.MethodGet, "", "id", wid)
+	require.Equal(t, http.StatusOK, code, resp.Errors)
+
+	data := resp.Results.(map[string]any)["Data"].(map[string]any)
+	assert.InDelta(t, 5, data["TotalRepetitions"], 0.1)
+	assert.InDelta(t, 500, data["TotalWeight"], 0.1)
+
+	code, resp = apiRequest(t, a, u, a.apiExercisesHandler, http.MethodGet, "")
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, resp.Results, 1)
+
+	eid := strconv.FormatFloat(resp.Results.([]any)[0].(map[string]any)["ID"].(float64), 'f', 0, 64)
+
+	code, resp = apiRequest(t, a, u, a.apiExerciseHistoryHandler, http.MethodGet, "", "id", eid)
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, resp.Results, 1)
+	assert.InDelta(t, 116.7, resp.Results.([]any)[0].(map[string]any)["EstimatedOneRepMax"], 0.1)
+
+	code, _ = apiRequest(t, a, other, a.apiExerciseHistoryHandler, http.MethodGet, "", "id", eid)
+	assert.Equal(t, http.StatusNotFound, code)
+
+	code, resp = apiRequest(t, a, u, a.apiWorkoutExercisesHandler, http.MethodGet, "", "id", wid)
+	require.Equal(t, http.StatusOK, code)
+
+	sets := resp.Results.([]any)[0].(map[string]any)["Sets"].([]any)
+	sid := strconv.FormatFloat(sets[0].(map[string]any)["ID"].(float64), 'f', 0, 64)
+
+	code, resp = apiRequest(t, a, u, a.apiWorkoutSetDeleteHandler, http.MethodDelete, "", "id", wid, "setID", sid)
+	require.Equal(t, http.StatusOK, code, resp.Errors)
+	assert.Empty(t, resp.Results)
+
+	code, resp = apiRequest(t, a, u, a.apiWorkoutHandler, http.MethodGet, "", "id", wid)
+	require.Equal(t, http.StatusOK, code, resp.Errors)
+	assert.InDelta(t, 0, resp.Results.(map[string]any)["Data"].(map[string]any)["TotalRepetitions"], 0.1)
+}
+
+func TestAPI_ProfileUpdate(t *testing.T) {
+	a := configuredApp(t)
+	u := apiUser(t, a, "api-user")
+
+	code, _ := apiRequest(t, a, u, a.apiProfileUpdateHandler, http.MethodPatch, `{"Timezone": "Nowhere/Invalid"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, code)
+
+	code, resp := apiRequest(t, a, u, a.apiProfileUpdateHandler, http.MethodPatch, `{"Timezone": "Europe/Brussels"}`)
+	require.Equal(t, http.StatusOK, code, resp.Errors)
+
+	code, _ = apiRequest(t, a, u, a.apiProfilePreferredUnitsUpdateHandler, http.MethodPut, `{"distance": "lightyear"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, code)
+
+	code, _ = apiRequest(t, a, u, a.apiProfilePreferredUnitsUpdateHandler, http.MethodPut, `{"distance": "mi"}`)
+	assert.Equal(t, http.StatusOK, code)
+
+	u, err := database.GetUser(a.db, "api-user")
+	require.NoError(t, err)
+	assert.Equal(t, "Europe/Brussels", u.Profile.Timezone)
+	assert.Equal(t, "mi", u.PreferredUnits().Distance())
+}
+
+func TestAPI_WorkoutsPagination(t *testing.T) {
+	a := configuredApp(t)
+	u := apiUser(t, a, "api-user")
+
+	for _, d := range []string{"2024-01-01", "2024-01-02", "2024-01-03"} {
+		code, resp := apiRequest(t, a, u, a.apiWorkoutCreateHandler, http.MethodPost,
+			`{"date": "`+d+`T10:00:00Z", "type": "running"}`)
+		require.Equal(t, http.StatusCreated, code, resp.Errors)
+	}
+
+	code, resp := apiRequest(t, a, u, a.apiWorkoutsHandler, http.MethodGet, "")
+	require.Equal(t, http.StatusOK, code, resp.Errors)
+	require.NotNil(t, resp.Pagination)
+	assert.Equal(t, 1, resp.Pagination.Page)
+	assert.Equal(t, 1, resp.Pagination.Pages)
+	assert.Equal(t, int64(3), resp.Pagination.Total)
+}
+
+func TestAPI_EquipmentMaintenance(t *testing.T) {
+	a := configuredApp(t)
+	u := apiUser(t, a, "api-user")
+	other := apiUser(t, a, "other-user")
+
+	code, resp := apiRequest(t, a, u, a.apiEquipmentCreateHandler, http.MethodPost, `{"name": "shoes", "active": true}`)
+	require.Equal(t, http.StatusCreated, code, resp.Errors)
+
+	es, err := u.GetAllEquipment(a.db)
+	require.NoError(t, err)
+	require.Len(t, es, 1)
+
+	eid := strconv.FormatUint(uint64(es[0].ID), 10)
+
+	item := &database.MaintenanceItem{EquipmentID: es[0].ID, Name: "retire", Metric: database.MaintenanceMetricWorkouts, Interval: 1, Retire: true}
+	require.NoError(t, item.Save(a.db))
+
+	code, resp = apiRequest(t, a, u, a.apiEquipmentAlertsHandler, http.MethodGet, "")
+	require.Equal(t, http.StatusOK, code, resp.Errors)
+	assert.Empty(t, resp.Results)
+
+	code, resp = apiRequest(t, a, u, a.apiWorkoutCreateHandler, http.MethodPost,
+		`{"date": "2024-01-02T10:00:00Z", "type": "running", "equipment": [`+eid+`]}`)
+	require.Equal(t, http.StatusCreated, code, resp.Errors)
+
+	code, resp = apiRequest(t, a, u, a.apiEquipmentAlertsHandler, http.MethodGet, "")
+	require.Equal(t, http.StatusOK, code, resp.Errors)
+	require.Len(t, resp.Results, 1)
+
+	code, resp = apiRequest(t, a, u, a.apiEquipmentMaintenanceHandler, http.MethodGet, "", "id", eid)
+	require.Equal(t, http.StatusOK, code, resp.Errors)
+
+	status := resp.Results.(map[string]any)["status"].([]any)
+	require.Len(t, status, 1)
+	assert.InDelta(t, 100, status[0].(map[string]any)["Percentage"], 0.1)
+
+	code, _ = apiRequest(t, a, other, a.apiEquipmentMaintenanceHandler, http.MethodGet, "", "id", eid)
+	assert.Equal(t, http.StatusNotFound, code)
+}
+
+func TestAPI_MeasurementCRUD(t *testing.T) {
+	a := configuredApp(t)
+	u := apiUser(t, a, "api-user")
+	other := apiUser(t, a, "other-user")
+
+	code, _ := apiRequest(t, a, u, a.apiMeasurementCreateHandler, http.MethodPost, `{"date": "2024-01-01T07:00:00Z"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, code)
+
+	code, resp := apiRequest(t, a, u, a.apiMeasurementCreateHandler, http.MethodPost,
+		`{"date": "2024-01-01T07:00:00Z", "weight": 80.5, "resting_heart_rate": 52}`)
+	require.Equal(t, http.StatusCreated, code, resp.Errors)
+
+	mid := strconv.FormatFloat(resp.Results.(map[string]any)["ID"].(float64), 'f', 0, 64)
+
+	code, resp = apiRequest(t, a, u, a.apiMeasurementUpdateHandler, http.MethodPatch, `{"body_fat": 18, "weight": null}`, "id", mid)
+	require.Equal(t, http.StatusOK, code, resp.Errors)
+
+	code, resp = apiRequest(t, a, u, a.apiMeasurementHandler, http.MethodGet, "", "id", mid)
+	require.Equal(t, http.StatusOK, code, resp.Errors)
+
+	m := resp.Results.(map[string]any)
+	assert.InDelta(t, 18, m["body_fat"], 0.01)
+	assert.InDelta(t, 52, m["resting_heart_rate"], 0.01)
+	assert.NotContains(t, m, "weight")
+
+	code, _ = apiRequest(t, a, other, a.apiMeasurementHandler, http.MethodGet, "", "id", mid)
+	assert.Equal(t, http.StatusNotFound, code)
+
+	code, resp = apiRequest(t, a, u, a.apiMeasurementsHandler, http.MethodGet, "")
+	require.Equal(t, http.StatusOK, code, resp.Errors)
+	assert.Len(t, resp.Results, 1)
+
+	code, _ = apiRequest(t, a, u, a.apiMeasurementDeleteHandler, http.MethodDelete, "", "id", mid)
+	assert.Equal(t, http.StatusOK, code)
+
+	code, _ = apiRequest(t, a, u, a.apiMeasurementHandler, http.MethodGet, "", "id", mid)
+	assert.Equal(t, http.StatusNotFound, code)
+}
@@ -0,0 +1,163 @@
+package app
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/jovandeginste/workout-tracker/pkg/database"
+	"github.com/labstack/echo/v4"
+)
+
+// apiMeasurementsHandler lists current user's measurements
+// @Summary      List all body measurements of the current user, most recent first
+// @Produce      json
+// @Success      200  {object}  APIResponse{result=[]database.Measurement}
+// @Failure      400  {object}  APIResponse
+// @Failure      500  {object}  APIResponse
+// @Router       /measurements [get]
+func (a *App) apiMeasurementsHandler(c echo.Context) error {
+	resp := APIResponse{}
+
+	m, err := a.getCurrentUser(c).GetMeasurements(a.db)
+	if err != nil {
+		return a.renderAPIError(c, resp, err)
+	}
+
+	resp.Results = m
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// apiMeasurementHandler returns a measurement
+// @Summary      Get a body measurement
+// @Param        id  path  int  true  "Measurement ID"
+// @Produce      json
+// @Success      200  {object}  APIResponse{result=database.Measurement}
+// @Failure      400  {object}  APIResponse
+// @Failure      404  {object}  APIResponse
+// @Failure      500  {object}  APIResponse
+// @Router       /measurements/{id} [get]
+func (a *App) apiMeasurementHandler(c echo.Context) error {
+	resp := APIResponse{}
+
+	m, err := getAPIOwned(a, c, "id", (*database.User).GetMeasurement)
+	if err != nil {
+		return a.renderAPIError(c, resp, err)
+	}
+
+	resp.Results = m
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// apiMeasurementCreateHandler creates a measurement
+// @Summary      Create a body measurement
+// @Description  The weight is in kilograms and the waist in centimeters; values that were not measured are left out.
+// @Param        measurement  body  database.Measurement  true  "The measurement"
+// @Accept       json
+// @Produce      json
+// @Success      201  {object}  APIResponse{result=database.Measurement}
+// @Failure      400  {object}  APIResponse
+// @Failure      422  {object}  APIResponse
+// @Failure      500  {object}  APIResponse
+// @Router       /measurements [post]
+func (a *App) apiMeasurementCreateHandler(c echo.Context) error {
+	resp := APIResponse{}
+
+	m := &database.Measurement{}
+	if err := c.Bind(m); err != nil {
+		return a.renderAPIError(c, resp, err)
+	}
+
+	m.ID = 0
+	m.UserID = a.getCurrentUser(c).ID
+
+	if err := validateMeasurement(m); err != nil {
+		return a.renderAPIError(c, resp, err)
+	}
+
+	if err := m.Save(a.db); err != nil {
+		return a.renderAPIError(c, resp, err)
+	}
+
+	resp.Results = m
+
+	return c.JSON(http.StatusCreated, resp)
+}
+
+// apiMeasurementUpdateHandler updates a measurement
+// @Summary      Update a body measurement
+// @Description  Only the fields that are given are updated; a value set to null is removed.
+// @Param        id           path  int                   true  "Measurement ID"
+// @Param        measurement  body  database.Measurement  true  "The fields to update"
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  APIResponse{result=database.Measurement}
+// @Failure      400  {object}  APIResponse
+// @Failure      404  {object}  APIResponse
+// @Failure      422  {object}  APIResponse
+// @Failure      500  {object}  APIResponse
+// @Router       /measurements/{id} [put]
+// @Router       /measurements/{id} [patch]
+func (a *App) apiMeasurementUpdateHandler(c echo.Context) error {
+	resp := APIResponse{}
+
+	m, err := getAPIOwned(a, c, "id", (*database.User).GetMeasurement)
+	if err != nil {
+		return a.renderAPIError(c, resp, err)
+	}
+
+	id, userID := m.ID, m.UserID
+
+	if err := c.Bind(m); err != nil {
+		return a.renderAPIError(c, resp, err)
+	}
+
+	m.ID, m.UserID = id, userID
+
+	if err := validateMeasurement(m); err != nil {
+		return a.renderAPIError(c, resp, err)
+	}
+
+	if err := m.Save(a.db); err != nil {
+		return a.renderAPIError(c, resp, err)
+	}
+
+	resp.Results = m
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// apiMeasurementDeleteHandler deletes a measurement
+// @Summary      Delete a body measurement
+// @Param        id  path  int  true  "Measurement ID"
+// @Produce      json
+// @Success      200  {object}  APIResponse{result=database.Measurement}
+// @Failure      400  {object}  APIResponse
+// @Failure      404  {object}  APIResponse
+// @Failure      500  {object}  APIResponse
+// @Router       /measurements/{id} [delete]
+func (a *App) apiMeasurementDeleteHandler(c echo.Context) error {
+	resp := APIResponse{}
+
+	m, err := getAPIOwned(a, c, "id", (*database.User).GetMeasurement)
+	if err != nil {
+		return a.renderAPIError(c, resp, err)
+	}
+
+	if err := m.Delete(a.db); err != nil {
+		return a.renderAPIError(c, resp, err)
+	}
+
+	resp.Results = m
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+func validateMeasurement(m *database.Measurement) error {
+	if err := m.Validate(); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidInput, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,124 @@
+package app
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/jovandeginste/workout-tracker/pkg/database"
+	"github.com/labstack/echo/v4"
+)
+
+// apiProfileUpdateHandler updates the current user's profile
+// @Summary      Update the profile of the current user
+// @Description  Only the fields that are given are updated.
+// @Param        profile  body  database.Profile  true  "The fields to update"
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  APIResponse{result=database.Profile}
+// @Failure      400  {object}  APIResponse
+// @Failure      422  {object}  APIResponse
+// @Failure      500  {object}  APIResponse
+// @Router       /profile [put]
+// @Router       /profile [patch]
+func (a *App) apiProfileUpdateHandler(c echo.Context) error {
+	resp := APIResponse{}
+	u := a.getCurrentUser(c)
+
+	p := u.Profile
+	if err := c.Bind(&p); err != nil {
+		return a.renderAPIError(c, resp, err)
+	}
+
+	p.Model = u.Profile.Model
+	p.UserID = u.ID
+
+	if err := validateProfile(&p); err != nil {
+		return a.renderAPIError(c, resp, err)
+	}
+
+	if err := p.Save(a.db); err != nil {
+		return a.renderAPIError(c, resp, err)
+	}
+
+	if p.AffectsWorkoutData(u.Profile) {
+		if err := u.MarkWorkoutsDirty(a.db); err != nil {
+			return a.renderAPIError(c, resp, err)
+		}
+	}
+
+	u.Profile = p
+	resp.Results = p
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// apiProfilePreferredUnitsUpdateHandler updates the current user's preferred units
+// @Summary      Update the preferred units of the current user
+// @Param        units  body  database.UserPreferredUnits  true  "The preferred units"
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  APIResponse{result=database.UserPreferredUnits}
+// @Failure      400  {object}  APIResponse
+// @Failure      422  {object}  APIResponse
+// @Failure      500  {object}  APIResponse
+// @Router       /profile/preferred-units [put]
+func (a *App) apiProfilePreferredUnitsUpdateHandler(c echo.Context) error {
+	resp := APIResponse{}
+	u := a.getCurrentUser(c)
+
+	units := database.UserPreferredUnits{}
+	if err := c.Bind(&units); err != nil {
+		return a.renderAPIError(c, resp, err)
+	}
+
+	if !units.IsValid() {
+		return a.renderAPIError(c, resp, fmt.Errorf("%w: invalid unit", ErrInvalidInput))
+	}
+
+	u.Profile.PreferredUnits = units
+
+	if err := u.Profile.Save(a.db); err != nil {
+		return a.renderAPIError(c, resp, err)
+	}
+
+	resp.Results = units
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+func validateProfile(p *database.Profile) error {
+	if p.DefaultVisibility != "" && !p.DefaultVisibility.IsValid() {
+		return fmt.Errorf("%w: invalid visibility: %q", ErrInvalidInput, p.DefaultVisibility)
+	}
+
+	if p.TotalsShow != "" && !p.TotalsShow.IsValid() {
+		return fmt.Errorf("%w: invalid type: %q", ErrInvalidInput, p.TotalsShow)
+	}
+
+	if _, err := time.LoadLocation(p.Timezone); err != nil {
+		return fmt.Errorf("%w: invalid time zone: %q", ErrInvalidInput, p.Timezone)
+	}
+
+	if !p.PreferredUnits.IsValid() {
+		return fmt.Errorf("%w: invalid unit", ErrInvalidInput)
+	}
+
+	if p.ShareHideDistance < 0 || p.FTP < 0 {
+		return fmt.Errorf("%w: values can not be negative", ErrInvalidInput)
+	}
+
+	if err := p.HeartRate.Validate(); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidInput, err)
+	}
+
+	if err := p.TrackCleaning.Validate(); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidInput, err)
+	}
+
+	if err := p.Climbs.Validate(); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidInput, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,278 @@
+package app
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/jovandeginste/workout-tracker/pkg/database"
+	"github.com/labstack/echo/v4"
+)
+
+// APIWorkout is the JSON equivalent of the manual workout form
+type APIWorkout struct {
+	ManualWorkout
+
+	EquipmentIDs *[]uint `json:"equipment"` // The IDs of the equipment used for this workout
+}
+
+// apiWorkoutCreateHandler creates a manual workout
+// @Summary      Create a manual workout
+// @Description  Distances are in the preferred unit of the current user. Dates are either RFC3339 or "2006-01-02T15:04".
+// @Param        workout  body  APIWorkout  true  "The workout"
+// @Accept       json
+// @Produce      json
+// @Success      201  {object}  APIResponse{result=database.Workout}
+// @Failure      400  {object}  APIResponse
+// @Failure      422  {object}  APIResponse
+// @Failure      500  {object}  APIResponse
+// @Router       /workouts [post]
+func (a *App) apiWorkoutCreateHandler(c echo.Context) error {
+	resp := APIResponse{}
+	u := a.getCurrentUser(c)
+
+	d := &APIWorkout{ManualWorkout: ManualWorkout{units: u.PreferredUnits()}}
+	if err := c.Bind(d); err != nil {
+		return a.renderAPIError(c, resp, err)
+	}
+
+	if err := d.Validate(u, true); err != nil {
+		return a.renderAPIError(c, resp, err)
+	}
+
+	workout := &database.Workout{}
+	d.Update(workout)
+
+	workout.User = u
+	workout.UserID = u.ID
+	workout.Data.Creator = "api"
+
+	if workout.Visibility == "" {
+		workout.Visibility = u.Profile.DefaultVisibility.OrDefault()
+	}
+
+	if workout.Name == "" {
+		workout.Name = workout.Type.String() + " - " + workout.Date.Format(time.RFC3339)
+	}
+
+	if err := workout.Save(a.db); err != nil {
+		return a.renderAPIError(c, resp, err)
+	}
+
+	if err := a.replaceWorkoutEquipment(u, workout, d.EquipmentIDs); err != nil {
+		return a.renderAPIError(c, resp, err)
+	}
+
+	resp.Results = workout
+
+	return c.JSON(http.StatusCreated, resp)
+}
+
+// apiWorkoutReplaceHandler updates all fields of a workout
+// @Summary      Update a workout
+// @Description  The date and type are required; other fields that are not given are left untouched.
+// @Param        id       path  int         true  "Workout ID"
+// @Param        workout  body  APIWorkout  true  "The workout"
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  APIResponse{result=database.Workout}
+// @Failure      400  {object}  APIResponse
+// @Failure      403  {object}  APIResponse
+// @Failure      404  {object}  APIResponse
+// @Failure      422  {object}  APIResponse
+// @Failure      500  {object}  APIResponse
+// @Router       /workouts/{id} [put]
+func (a *App) apiWorkoutReplaceHandler(c echo.Context) error {
+	return a.apiWorkoutUpdate(c, true)
+}
+
+// apiWorkoutPatchHandler updates some fields of a workout
+// @Summary      Partially update a workout
+// @Description  Only the fields that are given are updated.
+// @Param        id       path  int         true  "Workout ID"
+// @Param        workout  body  APIWorkout  true  "The fields to update"
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  APIResponse{result=database.Workout}
+// @Failure      400  {object}  APIResponse
+// @Failure      403  {object}  APIResponse
+// @Failure      404  {object}  APIResponse
+// @Failure      422  {object}  APIResponse
+// @Failure      500  {object}  APIResponse
+// @Router       /workouts/{id} [patch]
+func (a *App) apiWorkoutPatchHandler(c echo.Context) error {
+	return a.apiWorkoutUpdate(c, false)
+}
+
+func (a *App) apiWorkoutUpdate(c echo.Context, full bool) error {
+	resp := APIResponse{}
+	u := a.getCurrentUser(c)
+
+	workout, err := a.getAPIWorkout(c, a.db)
+	if err != nil {
+		return a.renderAPIError(c, resp, err)
+	}
+
+	d := &APIWorkout{ManualWorkout: ManualWorkout{units: u.PreferredUnits()}}
+	if err := c.Bind(d); err != nil {
+		return a.renderAPIError(c, resp, err)
+	}
+
+	if err := d.Validate(u, full); err != nil {
+		return a.renderAPIError(c, resp, err)
+	}
+
+	d.Update(workout)
+
+	if err := workout.Save(a.db); err != nil {
+		return a.renderAPIError(c, resp, err)
+	}
+
+	if err := a.replaceWorkoutEquipment(u, workout, d.EquipmentIDs); err != nil {
+		return a.renderAPIError(c, resp, err)
+	}
+
+	resp.Results = workout
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// apiWorkoutDeleteHandler deletes a workout
+// @Summary      Delete a workout
+// @Param        id  path  int  true  "Workout ID"
+// @Produce      json
+// @Success      200  {object}  APIResponse{result=database.Workout}
+// @Failure      400  {object}  APIResponse
+// @Failure      403  {object}  APIResponse
+// @Failure      404  {object}  APIResponse
+// @Failure      500  {object}  APIResponse
+// @Router       /workouts/{id} [delete]
+func (a *App) apiWorkoutDeleteHandler(c echo.Context) error {
+	resp := APIResponse{}
+
+	workout, err := a.getAPIWorkout(c, a.db)
+	if err != nil {
+		return a.renderAPIError(c, resp, err)
+	}
+
+	if err := workout.Delete(a.db); err != nil {
+		return a.renderAPIError(c, resp, err)
+	}
+
+	resp.Results = workout
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// apiWorkoutEquipmentLinkHandler adds equipment to a workout
+// @Summary      Link equipment to a workout
+// @Param        id           path  int  true  "Workout ID"
+// @Param        equipmentID  path  int  true  "Equipment ID"
+// @Produce      json
+// @Success      200  {object}  APIResponse{result=[]database.Equipment}
+// @Failure      400  {object}  APIResponse
+// @Failure      403  {object}  APIResponse
+// @Failure      404  {object}  APIResponse
+// @Failure      500  {object}  APIResponse
+// @Router       /workouts/{id}/equipment/{equipmentID} [post]
+func (a *App) apiWorkoutEquipmentLinkHandler(c echo.Context) error { //nolint:dupl
+	resp := APIResponse{}
+
+	workout, e, err := a.getAPIWorkoutAndEquipment(c)
+	if err != nil {
+		return a.renderAPIError(c, resp, err)
+	}
+
+	if err := a.db.Model(workout).Association("Equipment").Append(e); err != nil {
+		return a.renderAPIError(c, resp, err)
+	}
+
+	resp.Results = workout.Equipment
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// apiWorkoutEquipmentUnlinkHandler removes equipment from a workout
+// @Summary      Unlink equipment from a workout
+// @Param        id           path  int  true  "Workout ID"
+// @Param        equipmentID  path  int  true  "Equipment ID"
+// @Produce      json
+// @Success      200  {object}  APIResponse{result=[]database.Equipment}
+// @Failure      400  {object}  APIResponse
+// @Failure      403  {object}  APIResponse
+// @Failure      404  {object}  APIResponse
+// @Failure      500  {object}  APIResponse
+// @Router       /workouts/{id}/equipment/{equipmentID} [delete]
+func (a *App) apiWorkoutEquipmentUnlinkHandler(c echo.Context) error { //nolint:dupl
+	resp := APIResponse{}
+
+	workout, e, err := a.getAPIWorkoutAndEquipment(c)
+	if err != nil {
+		return a.renderAPIError(c, resp, err)
+	}
+
+	if err := a.db.Model(workout).Association("Equipment").Delete(e); err != nil {
+		return a.renderAPIError(c, resp, err)
+	}
+
+	resp.Results = workout.Equipment
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+func (a *App) getAPIWorkoutAndEquipment(c echo.Context) (*database.Workout, *database.Equipment, error) {
+	workout, err := a.getAPIWorkout(c, a.db)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	e, err := getAPIOwned(a, c, "equipmentID", (*database.User).GetEquipment)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return workout, e, nil
+}
+
+// replaceWorkoutEquipment replaces the equipment of the workout, if a list of
+// equipment IDs was given
+func (a *App) replaceWorkoutEquipment(u *database.User, w *database.Workout, ids *[]uint) error {
+	if ids == nil {
+		return nil
+	}
+
+	equipment, err := database.GetEquipmentByIDs(a.db, u.ID, *ids)
+	if err != nil {
+		return err
+	}
+
+	return a.db.Model(w).Association("Equipment").Replace(equipment)
+}
+
+// apiWorkoutExportHandler exports a workout to a file
+// @Summary      Export a workout as GPX, TCX or FIT
+// @Description  The file is generated from the stored data, so manual workouts can be exported too.
+// @Param        id      path   int     true   "Workout ID"
+// @Param        format  query  string  false  "The format: gpx (default), tcx or fit"
+// @Produce      application/gpx+xml
+// @Produce      application/vnd.garmin.tcx+xml
+// @Produce      application/binary
+// @Success      200
+// @Failure      400  {object}  APIResponse
+// @Failure      403  {object}  APIResponse
+// @Failure      404  {object}  APIResponse
+// @Failure      422  {object}  APIResponse
+// @Router       /workouts/{id}/export [get]
+func (a *App) apiWorkoutExportHandler(c echo.Context) error {
+	resp := APIResponse{}
+
+	w, err := a.getAPIWorkout(c, a.db)
+	if err != nil {
+		return a.renderAPIError(c, resp, err)
+	}
+
+	if err := a.streamWorkoutExport(c, w); err != nil {
+		return a.renderAPIError(c, resp, err)
+	}
+
+	return nil
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/cat-dealer/go-rand/v2"
 	"github.com/fsouza/slognil"
 	"github.com/jovandeginste/workout-tracker/pkg/database"
+	"github.com/jovandeginste/workout-tracker/pkg/dem"
 	"github.com/jovandeginste/workout-tracker/pkg/geocoder"
 	"github.com/labstack/echo/v4"
 	"github.com/lmittmann/tint"
@@ -97,6 +98,10 @@ func (a *App) Configure() error {
 		return err
 	}
 
+	if err := a.ConfigureElevation(); err != nil {
+		return err
+	}
+
 	if err := a.Config.UpdateFromDatabase(a.db); err != nil {
 		return err
 	}
@@ -113,6 +118,20 @@ func (a *App) ConfigureGeocoder() error {
 	return nil
 }
 
+func (a *App) ConfigureElevation() error {
+	if a.Config.ElevationTiles != "" {
+		if _, err := os.Stat(a.Config.ElevationTiles); err != nil {
+			return err
+		}
+
+		a.logger.Info("Reading elevation from the DEM tiles in " + a.Config.ElevationTiles)
+	}
+
+	dem.SetDirectory(a.logger, a.Config.ElevationTiles)
+
+	return nil
+}
+
 func (a *App) ConfigureDatabase() error {
 	a.logger.Info("Connecting to the database '" + a.Config.DatabaseDriver + "': " + a.Config.DSN)
 
@@ -0,0 +1,53 @@
+package app
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/jovandeginste/workout-tracker/pkg/database"
+	"github.com/labstack/echo/v4"
+)
+
+// climbsHandler lists the hills the user climbed, optionally only those of at
+// least a category
+func (a *App) climbsHandler(c echo.Context) error {
+	data := a.defaultData(c)
+
+	var params struct {
+		Category database.ClimbCategory `query:"category"`
+	}
+
+	if err := c.Bind(&params); err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("dashboard"), err)
+	}
+
+	hills, err := a.getCurrentUser(c).GetClimbs(a.db, params.Category)
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("dashboard"), err)
+	}
+
+	data["hills"] = hills
+	data["category"] = params.Category
+
+	return c.Render(http.StatusOK, "climbs_list.html", data)
+}
+
+// climbShowHandler shows a climb, with all the user's ascents of the same hill
+func (a *App) climbShowHandler(c echo.Context) error {
+	data := a.defaultData(c)
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("climbs"), err)
+	}
+
+	climb, hill, err := a.getCurrentUser(c).GetClimb(a.db, id)
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("climbs"), err)
+	}
+
+	data["climb"] = climb
+	data["hill"] = hill
+
+	return c.Render(http.StatusOK, "climbs_show.html", data)
+}
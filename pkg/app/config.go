@@ -26,6 +26,7 @@ func (a *App) ReadConfiguration() error {
 		"dsn",
 		"registration_disabled",
 		"socials_disabled",
+		"elevation_tiles",
 	} {
 		if err := viper.BindEnv(envVar); err != nil {
 			return err
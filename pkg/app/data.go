@@ -5,6 +5,7 @@ import (
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/jovandeginste/workout-tracker/pkg/database"
+	"gorm.io/gorm"
 
 	"github.com/labstack/echo/v4"
 )
@@ -58,12 +59,12 @@ func (a *App) defaultData(c echo.Context) map[string]interface{} {
 	return data
 }
 
-func (a *App) addWorkouts(u *database.User, data map[string]interface{}) error {
+func (a *App) addWorkouts(viewer, u *database.User, data map[string]interface{}) error {
 	if u == nil {
 		return nil
 	}
 
-	w, err := u.GetWorkouts(a.db)
+	w, err := u.GetWorkouts(a.db.Scopes(database.WorkoutsVisibleTo(viewer)))
 	if err != nil {
 		return err
 	}
@@ -73,8 +74,8 @@ func (a *App) addWorkouts(u *database.User, data map[string]interface{}) error {
 	return nil
 }
 
-func (a *App) addRecentWorkouts(data map[string]interface{}) error {
-	w, err := database.GetRecentWorkouts(a.db, 20)
+func (a *App) addRecentWorkouts(viewer *database.User, data map[string]interface{}) error {
+	w, err := database.GetRecentWorkouts(a.db.Scopes(database.WorkoutsVisibleTo(viewer)), 20)
 	if err != nil {
 		return err
 	}
@@ -109,6 +110,26 @@ func (a *App) getWorkout(c echo.Context) (*database.Workout, error) {
 	return w, nil
 }
 
+// getVisibleWorkout returns the workout from the path, if the current user is
+// allowed to see it; the workout does not need to belong to the current user
+func (a *App) getVisibleWorkout(c echo.Context, db *gorm.DB) (*database.Workout, error) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return nil, err
+	}
+
+	w, err := database.GetWorkout(db, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !w.IsVisibleTo(a.getCurrentUser(c)) {
+		return nil, database.ErrWorkoutNotVisible
+	}
+
+	return w, nil
+}
+
 func (a *App) addAllEquipment(u *database.User, data map[string]interface{}) error {
 	if u == nil {
 		return nil
@@ -137,3 +158,47 @@ func (a *App) getEquipment(c echo.Context) (*database.Equipment, error) {
 
 	return w, nil
 }
+
+// getSegment returns the segment from the path, if it belongs to the current
+// user
+func (a *App) getSegment(c echo.Context) (*database.Segment, error) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := a.getCurrentUser(c).GetSegment(a.db, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (a *App) getExercise(c echo.Context) (*database.Exercise, error) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return nil, err
+	}
+
+	e, err := a.getCurrentUser(c).GetExercise(a.db, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+func (a *App) getMeasurement(c echo.Context) (*database.Measurement, error) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := a.getCurrentUser(c).GetMeasurement(a.db, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
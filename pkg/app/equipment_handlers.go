@@ -2,7 +2,9 @@ package app
 
 import (
 	"net/http"
+	"slices"
 	"strconv"
+	"time"
 
 	"github.com/jovandeginste/workout-tracker/pkg/database"
 	"github.com/labstack/echo/v4"
@@ -48,6 +50,11 @@ func (a *App) equipmentShowHandler(c echo.Context) error {
 		return a.redirectWithError(c, "/equipment", err)
 	}
 
+	u := a.getCurrentUser(c)
+	e.Workouts = slices.DeleteFunc(e.Workouts, func(w database.Workout) bool {
+		return !w.IsVisibleTo(u)
+	})
+
 	data["equipment"] = e
 
 	return c.Render(http.StatusOK, "equipment_show.html", data)
@@ -106,3 +113,117 @@ func (a *App) equipmentEditHandler(c echo.Context) error {
 
 	return c.Render(http.StatusOK, "equipment_edit.html", data)
 }
+
+func (a *App) equipmentMaintenanceCreateHandler(c echo.Context) error {
+	e, err := a.getEquipment(c)
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("equipment-show", c.Param("id")), err)
+	}
+
+	i := &database.MaintenanceItem{}
+	if err := c.Bind(i); err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("equipment-show", c.Param("id")), err)
+	}
+
+	i.EquipmentID = e.ID
+
+	switch i.Metric {
+	case database.MaintenanceMetricDistance:
+		i.Interval = a.getCurrentUser(c).PreferredUnits().DistanceToDatabase(i.Interval)
+	case database.MaintenanceMetricDuration:
+		i.Interval *= time.Hour.Seconds()
+	}
+
+	if err := i.Save(a.db); err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("equipment-show", c.Param("id")), err)
+	}
+
+	a.setNotice(c, "The maintenance item '%s' has been added.", i.Name)
+
+	return c.Redirect(http.StatusFound, a.echo.Reverse("equipment-show", c.Param("id")))
+}
+
+func (a *App) equipmentMaintenanceDeleteHandler(c echo.Context) error {
+	e, err := a.getEquipment(c)
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("equipment-show", c.Param("id")), err)
+	}
+
+	id, err := strconv.Atoi(c.Param("item"))
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("equipment-show", c.Param("id")), err)
+	}
+
+	i, err := e.GetMaintenanceItem(a.db, id)
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("equipment-show", c.Param("id")), err)
+	}
+
+	if err := i.Delete(a.db); err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("equipment-show", c.Param("id")), err)
+	}
+
+	a.setNotice(c, "The maintenance item '%s' has been deleted.", i.Name)
+
+	return c.Redirect(http.StatusFound, a.echo.Reverse("equipment-show", c.Param("id")))
+}
+
+// equipmentServiceCreateHandler logs a service of the equipment; when a
+// maintenance item is given, its usage starts over
+func (a *App) equipmentServiceCreateHandler(c echo.Context) error {
+	e, err := a.getEquipment(c)
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("equipment-show", c.Param("id")), err)
+	}
+
+	s := &database.EquipmentService{}
+	if err := c.Bind(s); err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("equipment-show", c.Param("id")), err)
+	}
+
+	s.EquipmentID = e.ID
+
+	if s.MaintenanceItemID != nil {
+		if _, err := e.GetMaintenanceItem(a.db, int(*s.MaintenanceItemID)); err != nil {
+			return a.redirectWithError(c, a.echo.Reverse("equipment-show", c.Param("id")), err)
+		}
+	}
+
+	if d := c.FormValue("date"); d != "" {
+		s.Date, err = time.ParseInLocation(htmlDateFormat, d, a.getCurrentUser(c).Timezone())
+		if err != nil {
+			return a.redirectWithError(c, a.echo.Reverse("equipment-show", c.Param("id")), err)
+		}
+	}
+
+	if err := s.Save(a.db); err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("equipment-show", c.Param("id")), err)
+	}
+
+	a.setNotice(c, "The service of '%s' has been logged.", e.Name)
+
+	return c.Redirect(http.StatusFound, a.echo.Reverse("equipment-show", c.Param("id")))
+}
+
+func (a *App) equipmentServiceDeleteHandler(c echo.Context) error {
+	e, err := a.getEquipment(c)
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("equipment-show", c.Param("id")), err)
+	}
+
+	id, err := strconv.Atoi(c.Param("service"))
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("equipment-show", c.Param("id")), err)
+	}
+
+	s, err := e.GetService(a.db, id)
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("equipment-show", c.Param("id")), err)
+	}
+
+	if err := s.Delete(a.db); err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("equipment-show", c.Param("id")), err)
+	}
+
+	return c.Redirect(http.StatusFound, a.echo.Reverse("equipment-show", c.Param("id")))
+}
@@ -0,0 +1,130 @@
+package app
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/jovandeginste/workout-tracker/pkg/database"
+	"github.com/labstack/echo/v4"
+)
+
+func (a *App) exercisesHandler(c echo.Context) error {
+	data := a.defaultData(c)
+
+	e, err := a.getCurrentUser(c).GetExercises(a.db)
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("dashboard"), err)
+	}
+
+	data["exercises"] = e
+
+	return c.Render(http.StatusOK, "exercises_list.html", data)
+}
+
+func (a *App) exerciseShowHandler(c echo.Context) error {
+	data := a.defaultData(c)
+
+	e, err := a.getExercise(c)
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("exercises"), err)
+	}
+
+	h, err := e.History(a.db)
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("exercises"), err)
+	}
+
+	data["exercise"] = e
+	data["history"] = h
+
+	return c.Render(http.StatusOK, "exercises_show.html", data)
+}
+
+func (a *App) exerciseCreateHandler(c echo.Context) error {
+	e := &database.Exercise{}
+
+	if err := c.Bind(e); err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("exercises"), err)
+	}
+
+	e.UserID = a.getCurrentUser(c).ID
+
+	if err := e.Save(a.db); err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("exercises"), err)
+	}
+
+	a.setNotice(c, "The exercise '%s' has been created.", e.Name)
+
+	return c.Redirect(http.StatusFound, a.echo.Reverse("exercises"))
+}
+
+func (a *App) exerciseUpdateHandler(c echo.Context) error {
+	e, err := a.getExercise(c)
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("exercises"), err)
+	}
+
+	if err := c.Bind(e); err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("exercise-show", c.Param("id")), err)
+	}
+
+	if err := e.Save(a.db); err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("exercise-show", c.Param("id")), err)
+	}
+
+	a.setNotice(c, "The exercise '%s' has been updated.", e.Name)
+
+	return c.Redirect(http.StatusFound, a.echo.Reverse("exercise-show", c.Param("id")))
+}
+
+func (a *App) exerciseDeleteHandler(c echo.Context) error {
+	e, err := a.getExercise(c)
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("exercises"), err)
+	}
+
+	if err := e.Delete(a.db); err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("exercise-show", c.Param("id")), err)
+	}
+
+	a.setNotice(c, "The exercise '%s' has been deleted.", e.Name)
+
+	return c.Redirect(http.StatusFound, a.echo.Reverse("exercises"))
+}
+
+func (a *App) workoutSetCreateHandler(c echo.Context) error {
+	w, err := a.getWorkout(c)
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("workout-show", c.Param("id")), err)
+	}
+
+	var i ExerciseSetInput
+
+	if err := c.Bind(&i); err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("workout-show", c.Param("id")), err)
+	}
+
+	if err := i.AddTo(a.db, a.getCurrentUser(c), w); err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("workout-show", c.Param("id")), err)
+	}
+
+	return c.Redirect(http.StatusFound, a.echo.Reverse("workout-show", c.Param("id")))
+}
+
+func (a *App) workoutSetDeleteHandler(c echo.Context) error {
+	w, err := a.getWorkout(c)
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("workout-show", c.Param("id")), err)
+	}
+
+	id, err := strconv.Atoi(c.Param("set"))
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("workout-show", c.Param("id")), err)
+	}
+
+	if err := w.DeleteSet(a.db, uint(id)); err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("workout-show", c.Param("id")), err)
+	}
+
+	return c.Redirect(http.StatusFound, a.echo.Reverse("workout-show", c.Param("id")))
+}
@@ -4,6 +4,7 @@ import (
 	"cmp"
 	"errors"
 	"net/http"
+	"time"
 
 	"github.com/jovandeginste/workout-tracker/pkg/geocoder"
 	"github.com/labstack/echo/v4"
@@ -35,7 +36,7 @@ func (a *App) dashboardHandler(c echo.Context) error {
 		return a.redirectWithError(c, a.echo.Reverse("user-signout"), ErrUserNotFound)
 	}
 
-	if err := a.addWorkouts(u, data); err != nil {
+	if err := a.addWorkouts(u, u, data); err != nil {
 		return a.redirectWithError(c, a.echo.Reverse("user-signout"), err)
 	}
 
@@ -43,11 +44,17 @@ func (a *App) dashboardHandler(c echo.Context) error {
 		return a.redirectWithError(c, a.echo.Reverse("user-signout"), err)
 	}
 
-	if err := a.addRecentWorkouts(data); err != nil {
+	if err := a.addRecentWorkouts(u, data); err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("user-signout"), err)
+	}
+
+	goals, err := u.GetGoalsProgress(time.Now())
+	if err != nil {
 		return a.redirectWithError(c, a.echo.Reverse("user-signout"), err)
 	}
 
 	data["user"] = u
+	data["goals"] = goals
 
 	return c.Render(http.StatusOK, "user_show.html", data)
 }
@@ -0,0 +1,146 @@
+package app
+
+import (
+	"bytes"
+	"net/http"
+	"time"
+
+	"github.com/jovandeginste/workout-tracker/pkg/database"
+	"github.com/labstack/echo/v4"
+)
+
+// measurementParams are the fields of the measurement form; empty fields were
+// not measured
+type measurementParams struct {
+	Date             string `form:"date"`
+	Weight           string `form:"weight"`
+	BodyFat          string `form:"body_fat"`
+	RestingHeartRate string `form:"resting_heart_rate"`
+	HRV              string `form:"hrv"`
+	Waist            string `form:"waist"`
+	Notes            string `form:"notes"`
+}
+
+// measurement converts the form to a measurement of the user; the weight is
+// in the user's preferred unit and the date in the user's timezone
+func (p *measurementParams) measurement(u *database.User) (*database.Measurement, error) {
+	m := &database.Measurement{UserID: u.ID, Date: time.Now(), Notes: p.Notes}
+
+	if p.Date != "" {
+		d, err := time.ParseInLocation(htmlDateFormat, p.Date, u.Timezone())
+		if err != nil {
+			return nil, err
+		}
+
+		m.Date = d
+	}
+
+	for _, f := range []struct {
+		value string
+		dst   **float64
+	}{
+		{p.Weight, &m.Weight},
+		{p.BodyFat, &m.BodyFat},
+		{p.RestingHeartRate, &m.RestingHeartRate},
+		{p.HRV, &m.HRV},
+		{p.Waist, &m.Waist},
+	} {
+		v, err := database.ParseMeasurementValue(f.value)
+		if err != nil {
+			return nil, err
+		}
+
+		*f.dst = v
+	}
+
+	if m.Weight != nil {
+		w := u.PreferredUnits().WeightToDatabase(*m.Weight)
+		m.Weight = &w
+	}
+
+	return m, nil
+}
+
+func (a *App) measurementsHandler(c echo.Context) error {
+	data := a.defaultData(c)
+	u := a.getCurrentUser(c)
+
+	m, err := u.GetMeasurements(a.db)
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("dashboard"), err)
+	}
+
+	w, err := u.LatestWeight(a.db)
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("dashboard"), err)
+	}
+
+	data["measurements"] = m
+	data["latestWeight"] = w
+
+	return c.Render(http.StatusOK, "measurements_list.html", data)
+}
+
+func (a *App) measurementCreateHandler(c echo.Context) error {
+	var params measurementParams
+
+	if err := c.Bind(&params); err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("measurements"), err)
+	}
+
+	m, err := params.measurement(a.getCurrentUser(c))
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("measurements"), err)
+	}
+
+	if err := m.Save(a.db); err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("measurements"), err)
+	}
+
+	a.setNotice(c, "The measurement has been added.")
+
+	return c.Redirect(http.StatusFound, a.echo.Reverse("measurements"))
+}
+
+func (a *App) measurementImportHandler(c echo.Context) error {
+	file, err := c.FormFile("file")
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("measurements"), err)
+	}
+
+	content, err := uploadedFile(file)
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("measurements"), err)
+	}
+
+	u := a.getCurrentUser(c)
+
+	m, err := database.ParseMeasurementsCSV(bytes.NewReader(content), *u.PreferredUnits(), u.Timezone())
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("measurements"), err)
+	}
+
+	n, err := u.ImportMeasurements(a.db, m)
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("measurements"), err)
+	}
+
+	a.setNotice(c, "Imported %d measurement(s).", n)
+
+	return c.Redirect(http.StatusFound, a.echo.Reverse("measurements"))
+}
+
+func (a *App) measurementDeleteHandler(c echo.Context) error {
+	m, err := a.getMeasurement(c)
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("measurements"), err)
+	}
+
+	if err := m.Delete(a.db); err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("measurements"), err)
+	}
+
+	a.setNotice(c, "The measurement has been deleted.")
+
+	return c.Redirect(http.StatusFound, a.echo.Reverse("measurements"))
+}
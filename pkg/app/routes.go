@@ -71,6 +71,9 @@ func (a *App) ConfigureWebserver() error {
 	userGroup.POST("/register", a.userRegisterHandler).Name = "user-register"
 	userGroup.GET("/signout", a.userSignoutHandler).Name = "user-signout"
 
+	publicGroup.GET("/share/:token", a.workoutsShareHandler).Name = "workout-share"
+	publicGroup.GET("/public/workouts/:id", a.workoutsPublicHandler).Name = "workout-public"
+
 	sec := a.secureRoutes(publicGroup)
 	a.adminRoutes(sec)
 
@@ -124,8 +127,18 @@ func (a *App) secureRoutes(e *echo.Group) *echo.Group {
 	selfGroup.POST("/profile", a.userProfileUpdateHandler).Name = "user-profile-update"
 	selfGroup.POST("/profile/preferred-units", a.userProfilePreferredUnitsUpdateHandler).Name = "user-profile-preferred-units-update"
 	selfGroup.POST("/refresh", a.userRefreshHandler).Name = "user-refresh"
+	selfGroup.GET("/export", a.userExportHandler).Name = "user-export"
+	selfGroup.POST("/import", a.userImportHandler).Name = "user-import"
 	selfGroup.POST("/reset-api-key", a.userProfileResetAPIKeyHandler).Name = "user-profile-reset-api-key"
 	selfGroup.POST("/update-version", a.userUpdateVersion).Name = "user-update-version"
+	selfGroup.POST("/privacy-zones", a.userPrivacyZoneCreateHandler).Name = "user-privacy-zone-create"
+	selfGroup.POST("/privacy-zones/:id/delete", a.userPrivacyZoneDeleteHandler).Name = "user-privacy-zone-delete"
+	selfGroup.POST("/goals", a.userGoalCreateHandler).Name = "user-goal-create"
+	selfGroup.POST("/goals/:id/delete", a.userGoalDeleteHandler).Name = "user-goal-delete"
+	selfGroup.POST("/workout-types", a.userWorkoutTypeCreateHandler).Name = "user-workout-type-create"
+	selfGroup.POST("/workout-types/:id/delete", a.userWorkoutTypeDeleteHandler).Name = "user-workout-type-delete"
+	selfGroup.POST("/workout-type-mappings", a.userWorkoutTypeMappingCreateHandler).Name = "user-workout-type-mapping-create"
+	selfGroup.POST("/workout-type-mappings/:id/delete", a.userWorkoutTypeMappingDeleteHandler).Name = "user-workout-type-mapping-delete"
 
 	usersGroup := secureGroup.Group("/users")
 	usersGroup.GET("/:id", a.userShowHandler).Name = "user-show"
@@ -137,8 +150,20 @@ func (a *App) secureRoutes(e *echo.Group) *echo.Group {
 	workoutsGroup.POST("/:id", a.workoutsUpdateHandler).Name = "workout-update"
 	workoutsGroup.GET("/:id/download", a.workoutsDownloadHandler).Name = "workout-download"
 	workoutsGroup.GET("/:id/edit", a.workoutsEditHandler).Name = "workout-edit"
+	workoutsGroup.GET("/:id/export", a.workoutsExportHandler).Name = "workout-export"
 	workoutsGroup.POST("/:id/delete", a.workoutsDeleteHandler).Name = "workout-delete"
 	workoutsGroup.POST("/:id/refresh", a.workoutsRefreshHandler).Name = "workout-refresh"
+	workoutsGroup.GET("/:id/track", a.workoutsTrackHandler).Name = "workout-track"
+	workoutsGroup.POST("/:id/trim", a.workoutsTrimHandler).Name = "workout-trim"
+	workoutsGroup.POST("/:id/split", a.workoutsSplitHandler).Name = "workout-split"
+	workoutsGroup.POST("/:id/merge", a.workoutsMergeHandler).Name = "workout-merge"
+	workoutsGroup.POST("/:id/restore", a.workoutsRestoreHandler).Name = "workout-restore"
+	workoutsGroup.POST("/:id/duplicate", a.workoutsDuplicateHandler).Name = "workout-duplicate"
+	workoutsGroup.POST("/:id/share", a.workoutsShareCreateHandler).Name = "workout-share-create"
+	workoutsGroup.POST("/:id/share/delete", a.workoutsShareDeleteHandler).Name = "workout-share-delete"
+	workoutsGroup.POST("/:id/segments", a.segmentCreateHandler).Name = "workout-segment-create"
+	workoutsGroup.POST("/:id/sets", a.workoutSetCreateHandler).Name = "workout-set-create"
+	workoutsGroup.POST("/:id/sets/:set/delete", a.workoutSetDeleteHandler).Name = "workout-set-delete"
 	workoutsGroup.GET("/add", a.workoutsAddHandler).Name = "workout-add"
 	workoutsGroup.GET("/form", a.workoutsFormHandler).Name = "workout-form"
 
@@ -150,6 +175,33 @@ func (a *App) secureRoutes(e *echo.Group) *echo.Group {
 	equipmentGroup.GET("/:id/edit", a.equipmentEditHandler).Name = "equipment-edit"
 	equipmentGroup.POST("/:id/delete", a.equipmentDeleteHandler).Name = "equipment-delete"
 	equipmentGroup.GET("/add", a.equipmentAddHandler).Name = "equipment-add"
+	equipmentGroup.POST("/:id/maintenance", a.equipmentMaintenanceCreateHandler).Name = "equipment-maintenance-create"
+	equipmentGroup.POST("/:id/maintenance/:item/delete", a.equipmentMaintenanceDeleteHandler).Name = "equipment-maintenance-delete"
+	equipmentGroup.POST("/:id/services", a.equipmentServiceCreateHandler).Name = "equipment-service-create"
+	equipmentGroup.POST("/:id/services/:service/delete", a.equipmentServiceDeleteHandler).Name = "equipment-service-delete"
+
+	segmentsGroup := secureGroup.Group("/segments")
+	segmentsGroup.GET("", a.segmentsHandler).Name = "segments"
+	segmentsGroup.GET("/:id", a.segmentShowHandler).Name = "segment-show"
+	segmentsGroup.POST("/:id/refresh", a.segmentRefreshHandler).Name = "segment-refresh"
+	segmentsGroup.POST("/:id/delete", a.segmentDeleteHandler).Name = "segment-delete"
+
+	climbsGroup := secureGroup.Group("/climbs")
+	climbsGroup.GET("", a.climbsHandler).Name = "climbs"
+	climbsGroup.GET("/:id", a.climbShowHandler).Name = "climb-show"
+
+	exercisesGroup := secureGroup.Group("/exercises")
+	exercisesGroup.GET("", a.exercisesHandler).Name = "exercises"
+	exercisesGroup.POST("", a.exerciseCreateHandler).Name = "exercise-create"
+	exercisesGroup.GET("/:id", a.exerciseShowHandler).Name = "exercise-show"
+	exercisesGroup.POST("/:id", a.exerciseUpdateHandler).Name = "exercise-update"
+	exercisesGroup.POST("/:id/delete", a.exerciseDeleteHandler).Name = "exercise-delete"
+
+	measurementsGroup := secureGroup.Group("/measurements")
+	measurementsGroup.GET("", a.measurementsHandler).Name = "measurements"
+	measurementsGroup.POST("", a.measurementCreateHandler).Name = "measurement-create"
+	measurementsGroup.POST("/import", a.measurementImportHandler).Name = "measurement-import"
+	measurementsGroup.POST("/:id/delete", a.measurementDeleteHandler).Name = "measurement-delete"
 
 	return secureGroup
 }
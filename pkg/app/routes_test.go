@@ -3,7 +3,9 @@ package app
 import (
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/jovandeginste/workout-tracker/pkg/database"
 	session "github.com/spazzymoto/echo-scs-session"
@@ -153,3 +155,60 @@ func TestRoute_NoUserAccessLoginLang(t *testing.T) {
 		})
 	}
 }
+
+func TestRoute_NoUserUnknownShareToken(t *testing.T) {
+	t.Run("should redirect", func(t *testing.T) {
+		a := configuredApp(t)
+
+		e := a.echo
+
+		req := httptest.NewRequest(http.MethodGet, e.Reverse("workout-share", "unknown"), nil)
+		rec := httptest.NewRecorder()
+
+		c := e.NewContext(req, rec)
+		c.SetParamNames("token")
+		c.SetParamValues("unknown")
+
+		s := session.LoadAndSave(a.sessionManager)
+		h := s(a.workoutsShareHandler)
+
+		require.NoError(t, h(c))
+		assert.Equal(t, http.StatusFound, rec.Code)
+	})
+}
+
+func TestRoute_NoUserPublicWorkout(t *testing.T) {
+	a := configuredApp(t)
+	u := apiUser(t, a, "public-user")
+
+	d := time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC)
+	w := &database.Workout{
+		UserID: u.ID, Name: "public walk", Type: database.WorkoutTypeWalking, Date: &d,
+		Visibility: database.WorkoutVisibilityPublic, Data: &database.MapData{TotalDistance: 3000, TotalDuration: time.Hour},
+	}
+	require.NoError(t, w.Create(a.db))
+
+	get := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, a.echo.Reverse("workout-public", w.ID), nil)
+		rec := httptest.NewRecorder()
+
+		c := a.echo.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues(strconv.FormatUint(uint64(w.ID), 10))
+
+		s := session.LoadAndSave(a.sessionManager)
+		require.NoError(t, s(a.workoutsPublicHandler)(c))
+
+		return rec
+	}
+
+	rec := get()
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "public walk")
+
+	// Workouts for the users of the instance need a login
+	require.NoError(t, a.db.Model(w).Update("visibility", database.WorkoutVisibilityInstance).Error)
+
+	rec = get()
+	assert.Equal(t, http.StatusFound, rec.Code)
+}
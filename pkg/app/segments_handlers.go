@@ -0,0 +1,127 @@
+package app
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/jovandeginste/workout-tracker/pkg/database"
+	"github.com/labstack/echo/v4"
+)
+
+func (a *App) segmentsHandler(c echo.Context) error {
+	data := a.defaultData(c)
+
+	segments, err := database.GetSegments(a.db)
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("dashboard"), err)
+	}
+
+	data["segments"] = segments
+
+	return c.Render(http.StatusOK, "segments_list.html", data)
+}
+
+func (a *App) segmentShowHandler(c echo.Context) error {
+	data := a.defaultData(c)
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("segments"), err)
+	}
+
+	s, err := database.GetSegment(a.db, id)
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("segments"), err)
+	}
+
+	efforts, err := s.GetEffortsFor(a.db, a.getCurrentUser(c))
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("segments"), err)
+	}
+
+	leaderboard, err := s.GetLeaderboard(a.db, a.getCurrentUser(c))
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("segments"), err)
+	}
+
+	data["segment"] = s
+	data["efforts"] = efforts
+	data["leaderboard"] = leaderboard
+
+	return c.Render(http.StatusOK, "segments_show.html", data)
+}
+
+// segmentCreateHandler creates a segment from a stretch of a workout, and
+// matches all workouts against it
+func (a *App) segmentCreateHandler(c echo.Context) error {
+	w, err := a.getWorkout(c)
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("workout-show", c.Param("id")), err)
+	}
+
+	var params struct {
+		Name string  `form:"name"`
+		From float64 `form:"from"`
+		To   float64 `form:"to"`
+	}
+
+	if err := c.Bind(&params); err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("workout-show", c.Param("id")), err)
+	}
+
+	u := a.getCurrentUser(c)
+
+	zones, err := u.GetPrivacyZones(a.db)
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("workout-show", c.Param("id")), err)
+	}
+
+	units := u.PreferredUnits()
+
+	s, err := database.NewSegment(w, zones, params.Name, units.DistanceToDatabase(params.From), units.DistanceToDatabase(params.To))
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("workout-show", c.Param("id")), err)
+	}
+
+	if err := s.Save(a.db); err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("workout-show", c.Param("id")), err)
+	}
+
+	if err := s.MatchWorkouts(a.db); err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("segment-show", s.ID), err)
+	}
+
+	a.setNotice(c, "The segment '%s' has been created.", s.Name)
+
+	return c.Redirect(http.StatusFound, a.echo.Reverse("segment-show", s.ID))
+}
+
+func (a *App) segmentRefreshHandler(c echo.Context) error {
+	s, err := a.getSegment(c)
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("segment-show", c.Param("id")), err)
+	}
+
+	if err := s.MatchWorkouts(a.db); err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("segment-show", c.Param("id")), err)
+	}
+
+	a.setNotice(c, "The segment '%s' has been refreshed.", s.Name)
+
+	return c.Redirect(http.StatusFound, a.echo.Reverse("segment-show", c.Param("id")))
+}
+
+func (a *App) segmentDeleteHandler(c echo.Context) error {
+	s, err := a.getSegment(c)
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("segment-show", c.Param("id")), err)
+	}
+
+	if err := s.Delete(a.db); err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("segment-show", c.Param("id")), err)
+	}
+
+	a.setNotice(c, "The segment '%s' has been deleted.", s.Name)
+
+	return c.Redirect(http.StatusFound, a.echo.Reverse("segments"))
+}
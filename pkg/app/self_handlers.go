@@ -1,7 +1,11 @@
 package app
 
 import (
+	"bytes"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/jovandeginste/workout-tracker/pkg/database"
 	"github.com/labstack/echo/v4"
@@ -9,6 +13,34 @@ import (
 
 func (a *App) userProfileHandler(c echo.Context) error {
 	data := a.defaultData(c)
+
+	z, err := a.getCurrentUser(c).GetPrivacyZones(a.db)
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("dashboard"), err)
+	}
+
+	data["privacyZones"] = z
+
+	g, err := a.getCurrentUser(c).GetGoals(a.db)
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("dashboard"), err)
+	}
+
+	data["goals"] = g
+
+	types, err := a.getCurrentUser(c).GetCustomWorkoutTypes(a.db)
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("dashboard"), err)
+	}
+
+	mappings, err := a.getCurrentUser(c).GetWorkoutTypeMappings(a.db)
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("dashboard"), err)
+	}
+
+	data["workoutTypes"] = types
+	data["workoutTypeMappings"] = mappings
+
 	return c.Render(http.StatusOK, "user_profile.html", data)
 }
 
@@ -38,6 +70,7 @@ func (a *App) userProfilePreferredUnitsUpdateHandler(c echo.Context) error {
 func (a *App) userProfileUpdateHandler(c echo.Context) error {
 	u := a.getCurrentUser(c)
 	p := &u.Profile
+	old := *p
 
 	p.ResetBools()
 
@@ -46,11 +79,32 @@ func (a *App) userProfileUpdateHandler(c echo.Context) error {
 	}
 
 	p.UserID = u.ID
+	p.DefaultVisibility = p.DefaultVisibility.OrDefault()
+	p.ShareHideDistance = max(p.ShareHideDistance, 0)
+	p.FTP = max(p.FTP, 0)
+
+	if err := p.HeartRate.Validate(); err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("user-profile"), err)
+	}
+
+	if err := p.TrackCleaning.Validate(); err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("user-profile"), err)
+	}
+
+	if err := p.Climbs.Validate(); err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("user-profile"), err)
+	}
 
 	if err := u.Profile.Save(a.db); err != nil {
 		return a.redirectWithError(c, a.echo.Reverse("user-profile"), err)
 	}
 
+	if p.AffectsWorkoutData(old) {
+		if err := u.MarkWorkoutsDirty(a.db); err != nil {
+			return a.redirectWithError(c, a.echo.Reverse("user-profile"), err)
+		}
+	}
+
 	if err := a.setUser(c); err != nil {
 		return a.redirectWithError(c, a.echo.Reverse("user-profile"), err)
 	}
@@ -60,6 +114,93 @@ func (a *App) userProfileUpdateHandler(c echo.Context) error {
 	return c.Redirect(http.StatusFound, a.echo.Reverse("user-profile"))
 }
 
+func (a *App) userPrivacyZoneCreateHandler(c echo.Context) error {
+	u := a.getCurrentUser(c)
+	z := &database.PrivacyZone{}
+
+	if err := c.Bind(z); err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("user-profile"), err)
+	}
+
+	z.UserID = u.ID
+
+	if err := z.Save(a.db); err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("user-profile"), err)
+	}
+
+	a.setNotice(c, "The privacy zone '%s' has been created.", z.Name)
+
+	return c.Redirect(http.StatusFound, a.echo.Reverse("user-profile"))
+}
+
+func (a *App) userPrivacyZoneDeleteHandler(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("user-profile"), err)
+	}
+
+	z, err := a.getCurrentUser(c).GetPrivacyZone(a.db, id)
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("user-profile"), err)
+	}
+
+	if err := z.Delete(a.db); err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("user-profile"), err)
+	}
+
+	a.setNotice(c, "The privacy zone '%s' has been deleted.", z.Name)
+
+	return c.Redirect(http.StatusFound, a.echo.Reverse("user-profile"))
+}
+
+// userGoalCreateHandler creates a goal; the target is given in the user's
+// preferred distance unit for distance goals, and in hours for duration goals
+func (a *App) userGoalCreateHandler(c echo.Context) error {
+	u := a.getCurrentUser(c)
+	g := &database.Goal{}
+
+	if err := c.Bind(g); err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("user-profile"), err)
+	}
+
+	g.UserID = u.ID
+
+	switch g.Metric {
+	case database.GoalMetricDistance:
+		g.Target = u.PreferredUnits().DistanceToDatabase(g.Target)
+	case database.GoalMetricDuration:
+		g.Target *= time.Hour.Seconds()
+	}
+
+	if err := g.Save(a.db); err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("user-profile"), err)
+	}
+
+	a.setNotice(c, "The goal '%s' has been created.", g.Name)
+
+	return c.Redirect(http.StatusFound, a.echo.Reverse("user-profile"))
+}
+
+func (a *App) userGoalDeleteHandler(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("user-profile"), err)
+	}
+
+	g, err := a.getCurrentUser(c).GetGoal(a.db, id)
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("user-profile"), err)
+	}
+
+	if err := g.Delete(a.db); err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("user-profile"), err)
+	}
+
+	a.setNotice(c, "The goal '%s' has been deleted.", g.Name)
+
+	return c.Redirect(http.StatusFound, a.echo.Reverse("user-profile"))
+}
+
 func (a *App) userProfileResetAPIKeyHandler(c echo.Context) error {
 	u := a.getCurrentUser(c)
 
@@ -74,6 +215,53 @@ func (a *App) userProfileResetAPIKeyHandler(c echo.Context) error {
 	return c.Redirect(http.StatusFound, a.echo.Reverse("user-profile"))
 }
 
+func (a *App) userExportHandler(c echo.Context) error {
+	u := a.getCurrentUser(c)
+
+	buf := &bytes.Buffer{}
+	if err := u.ExportArchive(a.db, buf); err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("user-profile"), err)
+	}
+
+	filename := "workout-tracker-" + u.Username + "-" + time.Now().Format("2006-01-02") + ".zip"
+	c.Response().Header().Set(echo.HeaderContentDisposition, "attachment; filename=\""+filename+"\"")
+
+	return c.Blob(http.StatusOK, "application/zip", buf.Bytes())
+}
+
+func (a *App) userImportHandler(c echo.Context) error {
+	file, err := c.FormFile("archive")
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("user-profile"), err)
+	}
+
+	content, err := uploadedFile(file)
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("user-profile"), err)
+	}
+
+	result, err := a.getCurrentUser(c).ImportArchive(a.db, bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("user-profile"), err)
+	}
+
+	if err := a.setUser(c); err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("user-profile"), err)
+	}
+
+	a.setArchiveImportNotice(c, result)
+
+	return c.Redirect(http.StatusFound, a.echo.Reverse("user-profile"))
+}
+
+func (a *App) setArchiveImportNotice(c echo.Context, result *database.ArchiveImportResult) {
+	a.setNotice(c, "Imported %d workout(s) and %d equipment.", result.Workouts, result.Equipment)
+
+	if len(result.Duplicates) > 0 {
+		a.setError(c, "Skipped %d duplicate workout(s): %s", len(result.Duplicates), strings.Join(result.Duplicates, "; "))
+	}
+}
+
 func (a *App) userRefreshHandler(c echo.Context) error {
 	u := a.getCurrentUser(c)
 
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/Masterminds/sprig/v3"
+	"github.com/jovandeginste/workout-tracker/pkg/converters"
 	"github.com/jovandeginste/workout-tracker/pkg/database"
 	"github.com/jovandeginste/workout-tracker/pkg/templatehelpers"
 	"github.com/labstack/echo/v4"
@@ -33,7 +34,7 @@ func (t *Template) Render(w io.Writer, name string, data interface{}, ctx echo.C
 	u := t.app.getCurrentUser(ctx)
 
 	r.Funcs(template.FuncMap{
-		"i18n":         tr.Getf,
+		"i18n":         workoutTypeLabels(tr.Language().String(), tr.Getf),
 		"language":     tr.Language().String,
 		"humanizer":    func() *humanize.Humanizer { return h },
 		"RelativeDate": h.NaturalTime,
@@ -45,6 +46,10 @@ func (t *Template) Render(w io.Writer, name string, data interface{}, ctx echo.C
 		"HumanDistance":  templatehelpers.HumanDistanceFor(u.PreferredUnits().Distance()),
 		"HumanSpeed":     templatehelpers.HumanSpeedFor(u.PreferredUnits().Speed()),
 		"HumanTempo":     templatehelpers.HumanTempoFor(u.PreferredUnits().Distance()),
+		"HumanSwimTempo": templatehelpers.HumanSwimTempoFor(u.PreferredUnits().Distance()),
+		"HumanWeight":    templatehelpers.HumanWeightFor(u.PreferredUnits().Weight()),
+
+		"workoutTypes": u.WorkoutTypes,
 	})
 
 	return r.ExecuteTemplate(w, name, data)
@@ -54,6 +59,34 @@ func echoFunc(key string, _ ...interface{}) string {
 	return key
 }
 
+// workoutTypeLabels wraps the translation function, so that the names of
+// custom workout types are shown with their label in the language
+func workoutTypeLabels(lang string, tr func(string, ...interface{}) string) func(string, ...interface{}) string {
+	return func(key string, args ...interface{}) string {
+		if l, ok := database.WorkoutType(key).Label(lang); ok {
+			return l
+		}
+
+		return tr(key, args...)
+	}
+}
+
+// iconFor returns the icon of custom workout types, and the built-in icons
+// for everything else
+func iconFor(what string) template.HTML {
+	wt := database.WorkoutType(what)
+
+	if icon := wt.CustomIcon(); icon != "" {
+		return templatehelpers.IconFor(icon)
+	}
+
+	if wt.IsValid() && !wt.IsBuiltin() {
+		return templatehelpers.IconFor("workout")
+	}
+
+	return templatehelpers.IconFor(what)
+}
+
 func (a *App) viewTemplateFunctions() template.FuncMap {
 	h := a.humanizer.CreateHumanizer(language.English)
 
@@ -69,13 +102,21 @@ func (a *App) viewTemplateFunctions() template.FuncMap {
 
 		"supportedLanguages":    a.translator.SupportedLanguages,
 		"workoutTypes":          database.WorkoutTypes,
+		"workoutVisibilities":   database.WorkoutVisibilities,
+		"workoutOrders":         database.WorkoutOrders,
+		"exportFormats":         converters.ExportFormats,
+		"goalMetrics":           database.GoalMetrics,
+		"goalPeriods":           database.GoalPeriods,
+		"sportIcons":            templatehelpers.SportIcons,
+		"maintenanceMetrics":    database.MaintenanceMetrics,
+		"climbCategories":       database.ClimbCategories,
 		"statisticSinceOptions": statisticSinceOptions,
 		"statisticPerOptions":   statisticPerOptions,
 
 		"NumericDuration":         templatehelpers.NumericDuration,
 		"CountryCodeToFlag":       templatehelpers.CountryCodeToFlag,
 		"HumanDuration":           templatehelpers.HumanDuration,
-		"IconFor":                 templatehelpers.IconFor,
+		"IconFor":                 iconFor,
 		"BoolToHTML":              templatehelpers.BoolToHTML,
 		"BoolToCheckbox":          templatehelpers.BoolToCheckbox,
 		"BuildDecoratedAttribute": templatehelpers.BuildDecoratedAttribute,
@@ -87,6 +128,8 @@ func (a *App) viewTemplateFunctions() template.FuncMap {
 		"HumanDistance":  templatehelpers.HumanDistanceKM,
 		"HumanSpeed":     templatehelpers.HumanSpeedKPH,
 		"HumanTempo":     templatehelpers.HumanTempoKM,
+		"HumanSwimTempo": templatehelpers.HumanSwimTempo100M,
+		"HumanWeight":    templatehelpers.HumanWeightKG,
 
 		"RelativeDate": h.NaturalTime,
 
@@ -99,7 +99,7 @@ func (a *App) userShowHandler(c echo.Context) error {
 
 	data["user"] = u
 
-	if err := a.addWorkouts(u, data); err != nil {
+	if err := a.addWorkouts(a.getCurrentUser(c), u, data); err != nil {
 		return a.redirectWithError(c, a.echo.Reverse("user-signout"), err)
 	}
 
@@ -0,0 +1,182 @@
+package app
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/jovandeginste/workout-tracker/pkg/database"
+	"github.com/labstack/echo/v4"
+)
+
+// bindWorkoutType reads a custom workout type from the form; the labels are
+// given per language, as label_<language>
+func bindWorkoutType(c echo.Context) (*database.CustomWorkoutType, error) {
+	ct := &database.CustomWorkoutType{}
+
+	if err := c.Bind(ct); err != nil {
+		return nil, err
+	}
+
+	params, err := c.FormParams()
+	if err != nil {
+		return nil, err
+	}
+
+	ct.Labels = map[string]string{}
+
+	for k, v := range params {
+		if lang, ok := strings.CutPrefix(k, "label_"); ok && len(v) > 0 {
+			ct.Labels[lang] = v[0]
+		}
+	}
+
+	return ct, nil
+}
+
+func (a *App) adminWorkoutTypeCreateHandler(c echo.Context) error {
+	ct, err := bindWorkoutType(c)
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("admin"), err)
+	}
+
+	if err := ct.Save(a.db); err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("admin"), err)
+	}
+
+	a.setNotice(c, "The workout type '%s' has been created.", ct.Name)
+
+	return c.Redirect(http.StatusFound, a.echo.Reverse("admin"))
+}
+
+func (a *App) adminWorkoutTypeDeleteHandler(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("admin"), err)
+	}
+
+	ct, err := database.GetCustomWorkoutType(a.db, id)
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("admin"), err)
+	}
+
+	if err := ct.Delete(a.db); err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("admin"), err)
+	}
+
+	a.setNotice(c, "The workout type '%s' has been deleted.", ct.Name)
+
+	return c.Redirect(http.StatusFound, a.echo.Reverse("admin"))
+}
+
+func (a *App) adminWorkoutTypeMappingCreateHandler(c echo.Context) error {
+	m := &database.WorkoutTypeMapping{}
+
+	if err := c.Bind(m); err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("admin"), err)
+	}
+
+	if err := m.Save(a.db); err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("admin"), err)
+	}
+
+	a.setNotice(c, "Workouts with sport '%s' will be detected as '%s'.", m.SportName, m.Type)
+
+	return c.Redirect(http.StatusFound, a.echo.Reverse("admin"))
+}
+
+func (a *App) adminWorkoutTypeMappingDeleteHandler(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("admin"), err)
+	}
+
+	m, err := database.GetWorkoutTypeMapping(a.db, id)
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("admin"), err)
+	}
+
+	if err := m.Delete(a.db); err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("admin"), err)
+	}
+
+	return c.Redirect(http.StatusFound, a.echo.Reverse("admin"))
+}
+
+func (a *App) userWorkoutTypeCreateHandler(c echo.Context) error {
+	ct, err := bindWorkoutType(c)
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("user-profile"), err)
+	}
+
+	ct.UserID = &a.getCurrentUser(c).ID
+
+	if err := ct.Save(a.db); err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("user-profile"), err)
+	}
+
+	a.setNotice(c, "The workout type '%s' has been created.", ct.Name)
+
+	return c.Redirect(http.StatusFound, a.echo.Reverse("user-profile"))
+}
+
+func (a *App) userWorkoutTypeDeleteHandler(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("user-profile"), err)
+	}
+
+	ct, err := a.getCurrentUser(c).GetCustomWorkoutType(a.db, id)
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("user-profile"), err)
+	}
+
+	if err := ct.Delete(a.db); err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("user-profile"), err)
+	}
+
+	a.setNotice(c, "The workout type '%s' has been deleted.", ct.Name)
+
+	return c.Redirect(http.StatusFound, a.echo.Reverse("user-profile"))
+}
+
+func (a *App) userWorkoutTypeMappingCreateHandler(c echo.Context) error {
+	u := a.getCurrentUser(c)
+	m := &database.WorkoutTypeMapping{}
+
+	if err := c.Bind(m); err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("user-profile"), err)
+	}
+
+	m.UserID = &u.ID
+
+	if !u.CanUseWorkoutType(m.Type) {
+		return a.redirectWithError(c, a.echo.Reverse("user-profile"), database.ErrInvalidWorkoutType)
+	}
+
+	if err := m.Save(a.db); err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("user-profile"), err)
+	}
+
+	a.setNotice(c, "Workouts with sport '%s' will be detected as '%s'.", m.SportName, m.Type)
+
+	return c.Redirect(http.StatusFound, a.echo.Reverse("user-profile"))
+}
+
+func (a *App) userWorkoutTypeMappingDeleteHandler(c echo.Context) error {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("user-profile"), err)
+	}
+
+	m, err := a.getCurrentUser(c).GetWorkoutTypeMapping(a.db, id)
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("user-profile"), err)
+	}
+
+	if err := m.Delete(a.db); err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("user-profile"), err)
+	}
+
+	return c.Redirect(http.StatusFound, a.echo.Reverse("user-profile"))
+}
@@ -1,6 +1,7 @@
 package app
 
 import (
+	"fmt"
 	"io"
 	"mime/multipart"
 	"net/http"
@@ -10,6 +11,7 @@ import (
 	"github.com/jovandeginste/workout-tracker/pkg/database"
 	"github.com/jovandeginste/workout-tracker/pkg/geocoder"
 	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
 )
 
 const (
@@ -33,32 +35,110 @@ func uploadedFile(file *multipart.FileHeader) ([]byte, error) {
 }
 
 type ManualWorkout struct {
-	Name            *string               `form:"name"`
-	Date            *string               `form:"date"`
-	Location        *string               `form:"location"`
-	DurationHours   *int                  `form:"duration_hours"`
-	DurationMinutes *int                  `form:"duration_minutes"`
-	DurationSeconds *int                  `form:"duration_seconds"`
-	Distance        *float64              `form:"distance"`
-	Repetitions     *int                  `form:"repetitions"`
-	Weight          *float64              `form:"weight"`
-	Notes           *string               `form:"notes"`
-	Type            *database.WorkoutType `form:"type"`
+	Name                  *string                     `form:"name" json:"name"`
+	Date                  *string                     `form:"date" json:"date"`
+	Location              *string                     `form:"location" json:"location"`
+	DurationHours         *int                        `form:"duration_hours" json:"duration_hours"`
+	DurationMinutes       *int                        `form:"duration_minutes" json:"duration_minutes"`
+	DurationSeconds       *int                        `form:"duration_seconds" json:"duration_seconds"`
+	Distance              *float64                    `form:"distance" json:"distance"`
+	Repetitions           *int                        `form:"repetitions" json:"repetitions"`
+	Weight                *float64                    `form:"weight" json:"weight"`
+	Notes                 *string                     `form:"notes" json:"notes"`
+	Type                  *database.WorkoutType       `form:"type" json:"type"`
+	Visibility            *database.WorkoutVisibility `form:"visibility" json:"visibility"`
+	ElevationSource       *database.ElevationSource   `form:"elevation_source" json:"elevation_source"`
+	ExcludeFromStatistics *bool                       `form:"exclude_from_statistics" json:"exclude_from_statistics"`
 
 	units *database.UserPreferredUnits
 }
 
+// ExerciseSetInput is a set of an exercise, as given in the web form or the
+// API; the exercise is looked up by name in the user's catalog, and added to
+// it if needed
+type ExerciseSetInput struct {
+	Exercise    string  `form:"exercise" json:"exercise"`       // The name of the exercise
+	Repetitions int     `form:"repetitions" json:"repetitions"` // The number of repetitions
+	Weight      float64 `form:"weight" json:"weight"`           // The weight, in the user's preferred unit
+	RPE         float64 `form:"rpe" json:"rpe"`                 // The rate of perceived exertion (1-10), optional
+	Rest        int     `form:"rest" json:"rest"`               // The rest after the set, in seconds, optional
+}
+
+func (i *ExerciseSetInput) ToSet() *database.ExerciseSet {
+	return &database.ExerciseSet{
+		Repetitions: i.Repetitions,
+		Weight:      i.Weight,
+		RPE:         i.RPE,
+		Rest:        time.Duration(i.Rest) * time.Second,
+	}
+}
+
+// AddTo adds the set to the workout of the user
+func (i *ExerciseSetInput) AddTo(db *gorm.DB, u *database.User, w *database.Workout) error {
+	e, err := u.GetOrCreateExercise(db, i.Exercise)
+	if err != nil {
+		return err
+	}
+
+	return w.AddSet(db, e, i.ToSet())
+}
+
 func (m *ManualWorkout) ToDate() *time.Time {
 	if m.Date == nil {
 		return nil
 	}
 
-	d, err := time.Parse(htmlDateFormat, *m.Date)
-	if err != nil {
-		return nil
+	for _, f := range []string{htmlDateFormat, time.RFC3339} {
+		if d, err := time.Parse(f, *m.Date); err == nil {
+			return &d
+		}
 	}
 
-	return &d
+	return nil
+}
+
+// Validate checks the values that were given; values that were not given are
+// not checked, unless they are required to create a new workout
+func (m *ManualWorkout) Validate(u *database.User, create bool) error {
+	if create {
+		if m.Date == nil {
+			return fmt.Errorf("%w: date is required", ErrInvalidInput)
+		}
+
+		if m.Type == nil {
+			return fmt.Errorf("%w: type is required", ErrInvalidInput)
+		}
+	}
+
+	if m.Date != nil && m.ToDate() == nil {
+		return fmt.Errorf("%w: invalid date: %q", ErrInvalidInput, *m.Date)
+	}
+
+	if err := m.validateType(u); err != nil {
+		return err
+	}
+
+	if m.Visibility != nil && !m.Visibility.IsValid() {
+		return fmt.Errorf("%w: invalid visibility: %q", ErrInvalidInput, *m.Visibility)
+	}
+
+	if m.ElevationSource != nil && !m.ElevationSource.IsValid() {
+		return fmt.Errorf("%w: invalid elevation source: %q", ErrInvalidInput, *m.ElevationSource)
+	}
+
+	for _, v := range []*int{m.DurationHours, m.DurationMinutes, m.DurationSeconds, m.Repetitions} {
+		if v != nil && *v < 0 {
+			return fmt.Errorf("%w: values can not be negative", ErrInvalidInput)
+		}
+	}
+
+	for _, v := range []*float64{m.Distance, m.Weight} {
+		if v != nil && *v < 0 {
+			return fmt.Errorf("%w: values can not be negative", ErrInvalidInput)
+		}
+	}
+
+	return nil
 }
 
 func (m *ManualWorkout) ToDistance() *float64 {
@@ -93,6 +173,14 @@ func (m *ManualWorkout) ToDuration() *time.Duration {
 	return &totalDuration
 }
 
+func (m *ManualWorkout) ToVisibility() *database.WorkoutVisibility {
+	if m.Visibility == nil || !m.Visibility.IsValid() {
+		return nil
+	}
+
+	return m.Visibility
+}
+
 func setIfNotNil[T any](dst *T, src *T) {
 	if src == nil {
 		return
@@ -101,24 +189,47 @@ func setIfNotNil[T any](dst *T, src *T) {
 	*dst = *src
 }
 
+// validateType checks that the user can use the type, if one was given
+func (m *ManualWorkout) validateType(u *database.User) error {
+	if m.Type != nil && !u.CanUseWorkoutType(*m.Type) {
+		return fmt.Errorf("%w: invalid type: %q", ErrInvalidInput, *m.Type)
+	}
+
+	return nil
+}
+
 func (m *ManualWorkout) Update(w *database.Workout) {
 	if w.Data == nil {
 		w.Data = &database.MapData{}
 	}
 
-	dDate := m.ToDate()
-
 	setIfNotNil(&w.Name, m.Name)
 	setIfNotNil(&w.Notes, m.Notes)
-	setIfNotNil(&w.Date, &dDate)
+
+	if d := m.ToDate(); d != nil {
+		w.Date = d
+	}
+
 	setIfNotNil(&w.Type, m.Type)
+	setIfNotNil(&w.Visibility, m.ToVisibility())
+
+	if m.ElevationSource != nil && m.ElevationSource.IsValid() && *m.ElevationSource != w.ElevationSource {
+		// The elevation of the points is resampled when the workout is refreshed
+		w.ElevationSource = *m.ElevationSource
+		w.Dirty = w.HasFile()
+	}
 
+	setIfNotNil(&w.ExcludeFromStatistics, m.ExcludeFromStatistics)
 	setIfNotNil(&w.Data.AddressString, m.Location)
 	setIfNotNil(&w.Data.TotalDistance, m.ToDistance())
 	setIfNotNil(&w.Data.TotalDuration, m.ToDuration())
 	setIfNotNil(&w.Data.TotalRepetitions, m.Repetitions)
 	setIfNotNil(&w.Data.TotalWeight, m.Weight)
 
+	if m.Location == nil {
+		return
+	}
+
 	a, err := geocoder.Find(*m.Location)
 	if err != nil {
 		w.Data.Address = nil
@@ -140,6 +251,10 @@ func (a *App) addWorkout(c echo.Context) error {
 		return a.redirectWithError(c, "/workouts", err)
 	}
 
+	if err := d.validateType(a.getCurrentUser(c)); err != nil {
+		return a.redirectWithError(c, "/workouts", err)
+	}
+
 	workout := &database.Workout{}
 	d.Update(workout)
 
@@ -147,6 +262,10 @@ func (a *App) addWorkout(c echo.Context) error {
 	workout.UserID = a.getCurrentUser(c).ID
 	workout.Data.Creator = "web-interface"
 
+	if workout.Visibility == "" {
+		workout.Visibility = workout.User.Profile.DefaultVisibility.OrDefault()
+	}
+
 	var equipmentIDS struct {
 		EquipmentIDs []uint `form:"equipment"`
 	}
@@ -184,7 +303,12 @@ func (a *App) workoutsUpdateHandler(c echo.Context) error {
 		return a.redirectWithError(c, "/workouts", err)
 	}
 
+	if err := d.validateType(a.getCurrentUser(c)); err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("workout-edit", c.Param("id")), err)
+	}
+
 	d.Update(workout)
+	workout.UpdateStrengthTotals()
 
 	var equipmentIDS struct {
 		EquipmentIDs []uint `form:"equipment"`
@@ -222,6 +346,7 @@ func (a *App) addWorkoutFromFile(c echo.Context) error {
 
 	msg := []string{}
 	errMsg := []string{}
+	duplicates := []string{}
 
 	for _, file := range files {
 		content, parseErr := uploadedFile(file)
@@ -240,13 +365,21 @@ func (a *App) addWorkoutFromFile(c echo.Context) error {
 		}
 
 		msg = append(msg, w.Name)
+
+		if w.IsDuplicate() {
+			duplicates = append(duplicates, w.Name)
+		}
 	}
 
 	if len(errMsg) > 0 {
 		a.setError(c, "Encountered %d problems while adding workouts: %s", len(errMsg), strings.Join(errMsg, "; "))
 	}
 
-	if len(msg) > 0 {
+	switch {
+	case len(duplicates) > 0:
+		a.setNotice(c, "Added %d new workout(s): %s; these look like duplicates of other workouts: %s",
+			len(msg), strings.Join(msg, "; "), strings.Join(duplicates, "; "))
+	case len(msg) > 0:
 		a.setNotice(c, "Added %d new workout(s): %s", len(msg), strings.Join(msg, "; "))
 	}
 
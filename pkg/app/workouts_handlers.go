@@ -2,12 +2,14 @@ package app
 
 import (
 	"bytes"
-	"errors"
+	"fmt"
 	"net/http"
 	"path"
+	"slices"
 	"strconv"
 	"time"
 
+	"github.com/jovandeginste/workout-tracker/pkg/converters"
 	"github.com/jovandeginste/workout-tracker/pkg/database"
 	"github.com/labstack/echo/v4"
 )
@@ -15,31 +17,156 @@ import (
 func (a *App) workoutsHandler(c echo.Context) error {
 	data := a.defaultData(c)
 
-	if err := a.addWorkouts(a.getCurrentUser(c), data); err != nil {
+	u := a.getCurrentUser(c)
+	params := WorkoutListParams{}
+
+	if err := c.Bind(&params); err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("dashboard"), err)
+	}
+
+	q, err := params.ToQuery(u)
+	if err != nil {
 		return a.redirectWithError(c, a.echo.Reverse("dashboard"), err)
 	}
 
+	p, err := u.GetWorkoutsPage(a.db, q)
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("dashboard"), err)
+	}
+
+	if err := a.addAllEquipment(u, data); err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("dashboard"), err)
+	}
+
+	data["workouts"] = p.Workouts
+	data["workoutsPage"] = p
+	data["filters"] = params
+
 	return c.Render(http.StatusOK, "workouts_list.html", data)
 }
 
 func (a *App) workoutsShowHandler(c echo.Context) error {
 	data := a.defaultData(c)
 
-	id, err := strconv.Atoi(c.Param("id"))
+	w, err := a.getVisibleWorkout(c, a.db.Preload("GPX").Preload("Data.Details").Preload("Data.Laps").Preload("Data.Lengths").Preload("Data.Climbs").Preload("SegmentEfforts.Segment"))
 	if err != nil {
 		return a.redirectWithError(c, "/workouts", err)
 	}
 
-	w, err := database.GetWorkoutDetails(a.db, id)
-	if err != nil {
+	if err := w.LoadExercises(a.db); err != nil {
 		return a.redirectWithError(c, "/workouts", err)
 	}
 
+	if u := a.getCurrentUser(c); u != nil && u.ID == w.UserID && w.Type.IsRepetition() {
+		e, err := u.GetExercises(a.db)
+		if err != nil {
+			return a.redirectWithError(c, "/workouts", err)
+		}
+
+		data["exercises"] = e
+	}
+
+	if u := a.getCurrentUser(c); u != nil && u.ID == w.UserID && len(w.Equipment) > 0 {
+		alerts, err := u.GetMaintenanceAlerts(a.db)
+		if err != nil {
+			return a.redirectWithError(c, "/workouts", err)
+		}
+
+		data["maintenanceAlerts"] = slices.DeleteFunc(alerts, func(s database.MaintenanceStatus) bool {
+			return !slices.ContainsFunc(w.Equipment, func(e database.Equipment) bool { return e.ID == s.Equipment.ID })
+		})
+	}
+
+	if u := a.getCurrentUser(c); u != nil && u.ID == w.UserID && w.IsDuplicate() {
+		// The mark is removed when the other workout is deleted
+		if other, err := u.GetWorkout(a.db, int(*w.DuplicateOfID)); err == nil {
+			data["duplicateOf"] = other
+		}
+	}
+
 	data["workout"] = w
 
+	if w.HasShareToken() {
+		data["shareURL"] = c.Scheme() + "://" + c.Request().Host + a.echo.Reverse("workout-share", *w.ShareToken)
+	}
+
+	if w.Visibility == database.WorkoutVisibilityPublic {
+		data["publicURL"] = c.Scheme() + "://" + c.Request().Host + a.echo.Reverse("workout-public", w.ID)
+	}
+
+	return c.Render(http.StatusOK, "workouts_show.html", data)
+}
+
+// workoutsShareHandler shows a workout to anyone who has its share link,
+// without the locations the owner wants to keep private
+func (a *App) workoutsShareHandler(c echo.Context) error {
+	data := a.defaultData(c)
+
+	w, err := database.GetWorkoutByShareToken(a.db, c.Param("token"))
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("user-login"), err)
+	}
+
+	w.HidePrivateLocations()
+
+	data["workout"] = w
+	data["shared"] = true
+
 	return c.Render(http.StatusOK, "workouts_show.html", data)
 }
 
+// workoutsPublicHandler shows a public workout to anyone, without signing in
+// and without the locations the owner wants to keep private
+func (a *App) workoutsPublicHandler(c echo.Context) error {
+	data := a.defaultData(c)
+
+	// This route is not behind the login, so there is no current user and
+	// only public workouts are visible
+	w, err := a.getVisibleWorkout(c, a.db.
+		Preload("Data.Details").Preload("Data.Laps").Preload("Data.Lengths").Preload("Data.Climbs").
+		Preload("User.Profile").Preload("User.PrivacyZones"))
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("user-login"), err)
+	}
+
+	w.HidePrivateLocations()
+
+	data["workout"] = w
+	data["shared"] = true
+
+	return c.Render(http.StatusOK, "workouts_show.html", data)
+}
+
+func (a *App) workoutsShareCreateHandler(c echo.Context) error {
+	workout, err := a.getWorkout(c)
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("workout-show", c.Param("id")), err)
+	}
+
+	if err := workout.GenerateShareToken(a.db); err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("workout-show", c.Param("id")), err)
+	}
+
+	a.setNotice(c, "A new share link for the workout '%s' has been created.", workout.Name)
+
+	return c.Redirect(http.StatusFound, a.echo.Reverse("workout-show", c.Param("id")))
+}
+
+func (a *App) workoutsShareDeleteHandler(c echo.Context) error {
+	workout, err := a.getWorkout(c)
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("workout-show", c.Param("id")), err)
+	}
+
+	if err := workout.RevokeShareToken(a.db); err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("workout-show", c.Param("id")), err)
+	}
+
+	a.setNotice(c, "The share link for the workout '%s' has been revoked.", workout.Name)
+
+	return c.Redirect(http.StatusFound, a.echo.Reverse("workout-show", c.Param("id")))
+}
+
 func (a *App) workoutsAddHandler(c echo.Context) error {
 	data := a.defaultData(c)
 	return c.Render(http.StatusOK, "workouts_add.html", data)
@@ -64,6 +191,10 @@ func (a *App) workoutsFormHandler(c echo.Context) error {
 		w.Type = database.WorkoutType(c.FormValue("type"))
 	}
 
+	if w.Visibility == "" {
+		w.Visibility = a.getCurrentUser(c).Profile.DefaultVisibility.OrDefault()
+	}
+
 	if w.Date == nil {
 		t := time.Now()
 		w.Date = &t
@@ -111,6 +242,24 @@ func (a *App) workoutsRefreshHandler(c echo.Context) error {
 	return c.Redirect(http.StatusFound, a.echo.Reverse("workout-show", c.Param("id")))
 }
 
+// workoutsDuplicateHandler resolves a workout that looks like another
+// recording of another workout, in the way that was chosen
+func (a *App) workoutsDuplicateHandler(c echo.Context) error {
+	workout, err := a.getWorkout(c)
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("workout-show", c.Param("id")), err)
+	}
+
+	remaining, err := workout.ResolveDuplicate(a.db, database.DuplicateResolution(c.FormValue("resolution")))
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("workout-show", c.Param("id")), err)
+	}
+
+	a.setNotice(c, "The duplicate workout '%s' has been resolved.", workout.Name)
+
+	return c.Redirect(http.StatusFound, a.echo.Reverse("workout-show", remaining.ID))
+}
+
 func (a *App) workoutsDownloadHandler(c echo.Context) error {
 	workout, err := a.getWorkout(c)
 	if err != nil {
@@ -118,7 +267,12 @@ func (a *App) workoutsDownloadHandler(c echo.Context) error {
 	}
 
 	if !workout.HasFile() {
-		return a.redirectWithError(c, "/workouts", errors.New("workout has no content"))
+		// There is no original file (eg. a manual workout), so we generate one
+		if err := a.streamWorkoutExport(c, workout); err != nil {
+			return a.redirectWithError(c, "/workouts", err)
+		}
+
+		return nil
 	}
 
 	basename := path.Base(workout.GPX.Filename)
@@ -128,6 +282,41 @@ func (a *App) workoutsDownloadHandler(c echo.Context) error {
 	return c.Stream(http.StatusOK, "application/binary", bytes.NewReader(workout.GPX.Content))
 }
 
+func (a *App) workoutsExportHandler(c echo.Context) error {
+	workout, err := a.getWorkout(c)
+	if err != nil {
+		return a.redirectWithError(c, "/workouts", err)
+	}
+
+	if err := a.streamWorkoutExport(c, workout); err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("workout-show", c.Param("id")), err)
+	}
+
+	return nil
+}
+
+// streamWorkoutExport sends the workout as a file, in the format given by the
+// "format" query parameter (GPX by default)
+func (a *App) streamWorkoutExport(c echo.Context, workout *database.Workout) error {
+	format := converters.ExportFormatGPX
+	if f := c.QueryParam("format"); f != "" {
+		format = converters.ExportFormat(f)
+	}
+
+	if !format.IsValid() {
+		return fmt.Errorf("%w: %w: %q", ErrInvalidInput, converters.ErrUnsupportedFormat, format)
+	}
+
+	content, err := workout.Export(format)
+	if err != nil {
+		return err
+	}
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, "attachment; filename=\""+workout.ExportFilename(format)+"\"")
+
+	return c.Blob(http.StatusOK, format.ContentType(), content)
+}
+
 func (a *App) workoutsEditHandler(c echo.Context) error {
 	data := a.defaultData(c)
 
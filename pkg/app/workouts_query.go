@@ -0,0 +1,143 @@
+package app
+
+import (
+	"fmt"
+	"html/template"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/jovandeginste/workout-tracker/pkg/database"
+)
+
+const queryDateFormat = "2006-01-02"
+
+// WorkoutListParams are the query parameters to filter, sort and paginate a
+// list of workouts, both in the web interface and the API
+type WorkoutListParams struct {
+	Type        string  `query:"type"`         // Only workouts of this type
+	Since       string  `query:"since"`        // Only workouts on or after this date (YYYY-MM-DD)
+	Until       string  `query:"until"`        // Only workouts on or before this date (YYYY-MM-DD)
+	Equipment   uint    `query:"equipment"`    // Only workouts that used this equipment
+	MinDistance float64 `query:"min_distance"` // The minimum distance, in the user's preferred unit
+	MaxDistance float64 `query:"max_distance"` // The maximum distance, in the user's preferred unit
+	MinDuration float64 `query:"min_duration"` // The minimum duration, in minutes
+	MaxDuration float64 `query:"max_duration"` // The maximum duration, in minutes
+	Search      string  `query:"q"`            // Text to find in the name, notes or address
+	Sort        string  `query:"sort"`         // Sort by date, distance, duration or speed
+	Order       string  `query:"order"`        // Sort "asc" or "desc"
+	Page        int     `query:"page"`         // The page to show, starting at 1
+	PerPage     int     `query:"per_page"`     // The number of workouts per page
+}
+
+func parseQueryDate(s string, loc *time.Location) (*time.Time, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	d, err := time.ParseInLocation(queryDateFormat, s, loc)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid date: %q", ErrInvalidInput, s)
+	}
+
+	return &d, nil
+}
+
+// ToQuery converts the parameters to a database query, using the user's
+// preferred units and time zone
+func (p *WorkoutListParams) ToQuery(u *database.User) (database.WorkoutQuery, error) {
+	q := database.WorkoutQuery{
+		Type:        database.AsWorkoutType(p.Type),
+		EquipmentID: p.Equipment,
+		Search:      p.Search,
+		OrderBy:     database.WorkoutOrder(p.Sort),
+		Page:        p.Page,
+		PerPage:     p.PerPage,
+	}
+
+	switch p.Order {
+	case "", "desc":
+	case "asc":
+		q.Ascending = true
+	default:
+		return q, fmt.Errorf("%w: invalid order: %q", ErrInvalidInput, p.Order)
+	}
+
+	var err error
+
+	if q.Since, err = parseQueryDate(p.Since, u.Timezone()); err != nil {
+		return q, err
+	}
+
+	if q.Until, err = parseQueryDate(p.Until, u.Timezone()); err != nil {
+		return q, err
+	}
+
+	if q.Until != nil {
+		// Include the whole day
+		end := q.Until.AddDate(0, 0, 1)
+		q.Until = &end
+	}
+
+	units := u.PreferredUnits()
+
+	if p.MinDistance > 0 {
+		q.MinDistance = units.DistanceToDatabase(p.MinDistance)
+	}
+
+	if p.MaxDistance > 0 {
+		q.MaxDistance = units.DistanceToDatabase(p.MaxDistance)
+	}
+
+	q.MinDuration = time.Duration(p.MinDuration * float64(time.Minute))
+	q.MaxDuration = time.Duration(p.MaxDuration * float64(time.Minute))
+
+	if err := q.Normalize(); err != nil {
+		return q, fmt.Errorf("%w: %w", ErrInvalidInput, err)
+	}
+
+	return q, nil
+}
+
+func (p WorkoutListParams) values() url.Values {
+	v := url.Values{}
+
+	set := func(key, value string) {
+		if value != "" && value != "0" {
+			v.Set(key, value)
+		}
+	}
+
+	set("type", p.Type)
+	set("since", p.Since)
+	set("until", p.Until)
+	set("equipment", strconv.FormatUint(uint64(p.Equipment), 10))
+	set("min_distance", strconv.FormatFloat(p.MinDistance, 'f', -1, 64))
+	set("max_distance", strconv.FormatFloat(p.MaxDistance, 'f', -1, 64))
+	set("min_duration", strconv.FormatFloat(p.MinDuration, 'f', -1, 64))
+	set("max_duration", strconv.FormatFloat(p.MaxDuration, 'f', -1, 64))
+	set("q", p.Search)
+	set("sort", p.Sort)
+	set("order", p.Order)
+	set("per_page", strconv.Itoa(p.PerPage))
+
+	return v
+}
+
+// PageURL returns the URL to another page of the same list
+func (p WorkoutListParams) PageURL(base string, page int) template.URL {
+	v := p.values()
+	v.Set("page", strconv.Itoa(page))
+
+	return template.URL(base + "?" + v.Encode()) //nolint:gosec
+}
+
+// IsFiltered returns whether any filter is set
+func (p WorkoutListParams) IsFiltered() bool {
+	v := p.values()
+	v.Del("sort")
+	v.Del("order")
+	v.Del("per_page")
+
+	return len(v) > 0
+}
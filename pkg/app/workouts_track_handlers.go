@@ -0,0 +1,158 @@
+package app
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/jovandeginste/workout-tracker/pkg/database"
+	"github.com/labstack/echo/v4"
+)
+
+// TrackEditInput is the input of the forms to trim, split and merge the track
+// of a workout; positions in the track are given in minutes since the start
+// and in the user's preferred distance unit
+type TrackEditInput struct {
+	StartMinutes  float64 `form:"start_minutes"`
+	StartDistance float64 `form:"start_distance"`
+	EndMinutes    float64 `form:"end_minutes"`
+	EndDistance   float64 `form:"end_distance"`
+	AtMinutes     float64 `form:"at_minutes"`
+	AtDistance    float64 `form:"at_distance"`
+	Workouts      []int   `form:"workouts"` // The IDs of the workouts to merge
+
+	units *database.UserPreferredUnits
+}
+
+func (i *TrackEditInput) offset(minutes, distance float64) database.TrackOffset {
+	return database.TrackOffset{
+		Duration: time.Duration(minutes * float64(time.Minute)),
+		Distance: i.units.DistanceToDatabase(distance),
+	}
+}
+
+func (i *TrackEditInput) Start() database.TrackOffset {
+	return i.offset(i.StartMinutes, i.StartDistance)
+}
+
+func (i *TrackEditInput) End() database.TrackOffset {
+	return i.offset(i.EndMinutes, i.EndDistance)
+}
+
+func (i *TrackEditInput) At() database.TrackOffset {
+	return i.offset(i.AtMinutes, i.AtDistance)
+}
+
+func (a *App) workoutTrackInput(c echo.Context) (*TrackEditInput, error) {
+	i := &TrackEditInput{units: a.getCurrentUser(c).PreferredUnits()}
+	if err := c.Bind(i); err != nil {
+		return nil, err
+	}
+
+	return i, nil
+}
+
+func (a *App) workoutsTrackHandler(c echo.Context) error {
+	data := a.defaultData(c)
+
+	workout, err := a.getWorkout(c)
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("workout-show", c.Param("id")), err)
+	}
+
+	candidates, err := workout.MergeCandidates(a.db)
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("workout-show", c.Param("id")), err)
+	}
+
+	data["workout"] = workout
+	data["candidates"] = candidates
+
+	return c.Render(http.StatusOK, "workouts_track.html", data)
+}
+
+func (a *App) workoutsTrimHandler(c echo.Context) error {
+	workout, err := a.getWorkout(c)
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("workout-track", c.Param("id")), err)
+	}
+
+	i, err := a.workoutTrackInput(c)
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("workout-track", c.Param("id")), err)
+	}
+
+	if err := workout.Trim(a.db, i.Start(), i.End()); err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("workout-track", c.Param("id")), err)
+	}
+
+	a.setNotice(c, "The workout '%s' has been trimmed.", workout.Name)
+
+	return c.Redirect(http.StatusFound, a.echo.Reverse("workout-show", c.Param("id")))
+}
+
+func (a *App) workoutsSplitHandler(c echo.Context) error {
+	workout, err := a.getWorkout(c)
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("workout-track", c.Param("id")), err)
+	}
+
+	i, err := a.workoutTrackInput(c)
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("workout-track", c.Param("id")), err)
+	}
+
+	second, err := workout.Split(a.db, i.At())
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("workout-track", c.Param("id")), err)
+	}
+
+	a.setNotice(c, "The workout '%s' has been split; the second part is '%s'.", workout.Name, second.Name)
+
+	return c.Redirect(http.StatusFound, a.echo.Reverse("workout-show", c.Param("id")))
+}
+
+func (a *App) workoutsMergeHandler(c echo.Context) error {
+	workout, err := a.getWorkout(c)
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("workout-track", c.Param("id")), err)
+	}
+
+	i, err := a.workoutTrackInput(c)
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("workout-track", c.Param("id")), err)
+	}
+
+	others := make([]*database.Workout, 0, len(i.Workouts))
+
+	for _, id := range i.Workouts {
+		o, err := a.getCurrentUser(c).GetWorkout(a.db, id)
+		if err != nil {
+			return a.redirectWithError(c, a.echo.Reverse("workout-track", c.Param("id")), err)
+		}
+
+		others = append(others, o)
+	}
+
+	if err := workout.Merge(a.db, others); err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("workout-track", c.Param("id")), err)
+	}
+
+	a.setNotice(c, "%d workouts have been merged into '%s'.", len(others), workout.Name)
+
+	return c.Redirect(http.StatusFound, a.echo.Reverse("workout-show", c.Param("id")))
+}
+
+func (a *App) workoutsRestoreHandler(c echo.Context) error {
+	workout, err := a.getWorkout(c)
+	if err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("workout-track", c.Param("id")), err)
+	}
+
+	if err := workout.RestoreOriginal(a.db); err != nil {
+		return a.redirectWithError(c, a.echo.Reverse("workout-track", c.Param("id")), err)
+	}
+
+	a.setNotice(c, "The original file of the workout '%s' has been restored.", workout.Name)
+
+	return c.Redirect(http.StatusFound, a.echo.Reverse("workout-show", c.Param("id")))
+}
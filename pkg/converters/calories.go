@@ -0,0 +1,68 @@
+package converters
+
+import (
+	"bytes"
+	"encoding/xml"
+	"path"
+
+	"github.com/galeone/tcx"
+	"github.com/tormoder/fit"
+)
+
+// ParseCalories returns the energy expenditure reported by the device, in
+// kilocalories; only FIT and TCX files contain it, other files (and files
+// without it) return 0
+func ParseCalories(filename string, content []byte) (float64, error) {
+	switch path.Ext(filename) {
+	case ".fit":
+		return ParseFitCalories(content)
+	case ".tcx":
+		return ParseTCXCalories(content)
+	default:
+		return 0, nil
+	}
+}
+
+func ParseFitCalories(fitFile []byte) (float64, error) {
+	f, err := fit.Decode(bytes.NewReader(fitFile))
+	if err != nil {
+		return 0, err
+	}
+
+	m, err := f.Activity()
+	if err != nil {
+		return 0, err
+	}
+
+	total := 0.0
+
+	for _, s := range m.Sessions {
+		if s.TotalCalories != 0xFFFF {
+			total += float64(s.TotalCalories)
+		}
+	}
+
+	return total, nil
+}
+
+func ParseTCXCalories(tcxFile []byte) (float64, error) {
+	var t tcx.TCXDB
+
+	if err := xml.Unmarshal(tcxFile, &t); err != nil {
+		return 0, err
+	}
+
+	if t.Acts == nil {
+		return 0, nil
+	}
+
+	total := 0.0
+
+	for _, a := range t.Acts.Act {
+		for _, l := range a.Laps {
+			total += l.Calories
+		}
+	}
+
+	return total, nil
+}
@@ -0,0 +1,115 @@
+package converters
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+var ErrUnsupportedFormat = errors.New("unsupported export format")
+
+type ExportFormat string
+
+const (
+	ExportFormatGPX ExportFormat = "gpx" // GPX 1.1, with Garmin track point extensions
+	ExportFormatTCX ExportFormat = "tcx" // Garmin Training Center XML
+	ExportFormatFIT ExportFormat = "fit" // Garmin Flexible and Interoperable Data Transfer
+)
+
+func ExportFormats() []ExportFormat {
+	return []ExportFormat{ExportFormatGPX, ExportFormatTCX, ExportFormatFIT}
+}
+
+func (f ExportFormat) String() string {
+	return string(f)
+}
+
+func (f ExportFormat) IsValid() bool {
+	return slices.Contains(ExportFormats(), f)
+}
+
+// Extension returns the file extension for the format, including the dot
+func (f ExportFormat) Extension() string {
+	return "." + f.String()
+}
+
+// ContentType returns the MIME type for the format
+func (f ExportFormat) ContentType() string {
+	switch f {
+	case ExportFormatGPX:
+		return "application/gpx+xml"
+	case ExportFormatTCX:
+		return "application/vnd.garmin.tcx+xml"
+	default:
+		return "application/binary"
+	}
+}
+
+// Activity is a format independent representation of a workout, used to
+// export it to any of the supported formats
+type Activity struct {
+	Name          string          // The name of the activity
+	Type          string          // The workout type, eg. "running"
+	Notes         string          // The notes of the activity
+	Start         time.Time       // The start time of the activity
+	TotalDistance float64         // The total distance, in meters
+	TotalDuration time.Duration   // The total duration
+	Points        []ActivityPoint // The recorded points, if any
+}
+
+// ActivityPoint is a single recorded point of an activity; metrics that were
+// not recorded are nil
+type ActivityPoint struct {
+	Time          time.Time // The time the point was recorded
+	Lat           float64   // The latitude of the point
+	Lng           float64   // The longitude of the point
+	TotalDistance float64   // The total distance up to this point, in meters
+	Elevation     *float64  // The elevation, in meters
+	HeartRate     *float64  // The heart rate, in beats per minute
+	Cadence       *float64  // The cadence, in revolutions or steps per minute
+	Power         *float64  // The power, in watts
+}
+
+// HasPosition returns whether the point was recorded with a position; points
+// without a position are at (0, 0)
+func (p *ActivityPoint) HasPosition() bool {
+	return p.Lat != 0 || p.Lng != 0
+}
+
+// End returns the end time of the activity
+func (a *Activity) End() time.Time {
+	if l := len(a.Points); l > 0 && a.Points[l-1].Time.After(a.Start) {
+		return a.Points[l-1].Time
+	}
+
+	return a.Start.Add(a.TotalDuration)
+}
+
+// Export encodes the activity in the given format
+func Export(format ExportFormat, a *Activity) ([]byte, error) {
+	switch format {
+	case ExportFormatGPX:
+		return ExportGPX(a)
+	case ExportFormatTCX:
+		return ExportTCX(a)
+	case ExportFormatFIT:
+		return ExportFIT(a)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, format)
+	}
+}
+
+// sportName returns the name of the sport for the workout type, as used by
+// Garmin
+func sportName(workoutType string) string {
+	switch strings.ToLower(workoutType) {
+	case "running":
+		return "Running"
+	case "cycling":
+		return "Biking"
+	default:
+		return "Other"
+	}
+}
@@ -2,56 +2,186 @@ package converters
 
 import (
 	"bytes"
+	"encoding/binary"
 	"encoding/xml"
 	"fmt"
 	"math"
 	"strconv"
+	"time"
 
 	"github.com/tkrajina/gpxgo/gpx"
 	"github.com/tormoder/fit"
 )
 
 func ParseFit(fitFile []byte) (*gpx.GPX, error) {
-	// Decode the FIT file data
-	f, err := fit.Decode(bytes.NewReader(fitFile))
+	f, m, err := decodeFitActivity(fitFile)
 	if err != nil {
 		return nil, err
 	}
 
-	gpxFile := &gpx.GPX{
-		Name:    f.FileId.TimeCreated.String(),
-		Time:    &f.FileId.TimeCreated,
-		Creator: f.FileId.Manufacturer.String(),
+	sport := m.Sessions[0].Sport.String()
+	if len(m.Sessions) > 1 {
+		// The sessions are the legs of a multisport activity, eg. a triathlon
+		sport = fit.SportMultisport.String()
 	}
 
-	m, err := f.Activity()
+	return fitRecordsAsGPX(f, m.Sessions[0].SportProfileName, sport, m.Records), nil
+}
+
+// SplitFitSessions splits a multi-session FIT file, eg. the swim, transitions,
+// bike and run of a triathlon, in a FIT file per session; each file has the
+// session with its records, laps, lengths and events. Files with a single
+// session return nil
+func SplitFitSessions(fitFile []byte) ([][]byte, error) {
+	f, m, err := decodeFitActivity(fitFile)
 	if err != nil {
 		return nil, err
 	}
 
-	if len(m.Sessions) == 0 {
-		return nil, fmt.Errorf("no sessions found")
+	if len(m.Sessions) < 2 {
+		return nil, nil
 	}
 
-	gpxFile.AppendTrack(&gpx.GPXTrack{
-		Name: m.Sessions[0].SportProfileName,
-		Type: m.Sessions[0].Sport.String(),
-	})
+	sessions := make([][]byte, 0, len(m.Sessions))
+
+	for i, s := range m.Sessions {
+		start, end := s.StartTime, s.Timestamp
+		if end.Before(start) {
+			end = start.Add(time.Duration(validOrZero(s.GetTotalElapsedTimeScaled()) * float64(time.Second)))
+		}
+
+		inSession := func(t time.Time) bool {
+			if t.Before(start) || t.After(end) {
+				return false
+			}
+
+			// A message at the boundary of two sessions belongs to the next one
+			return i+1 == len(m.Sessions) || t.Before(m.Sessions[i+1].StartTime)
+		}
+
+		content, err := fitSessionFile(f, m, s, inSession)
+		if err != nil {
+			return nil, err
+		}
+
+		sessions = append(sessions, content)
+	}
+
+	return sessions, nil
+}
+
+// fitSessionFile encodes the session as a FIT file of its own, with the
+// messages of the activity that are in the session
+func fitSessionFile(f *fit.File, m *fit.ActivityFile, s *fit.SessionMsg, inSession func(time.Time) bool) ([]byte, error) {
+	sf, err := fit.NewFile(fit.FileTypeActivity, fit.NewHeader(fit.V20, true))
+	if err != nil {
+		return nil, err
+	}
+
+	sf.FileId = f.FileId
+	sf.FileId.TimeCreated = s.StartTime
+
+	act, err := sf.Activity()
+	if err != nil {
+		return nil, err
+	}
 
 	for _, r := range m.Records {
-		if r.PositionLat.Invalid() ||
-			r.PositionLong.Invalid() {
-			continue
+		if inSession(r.Timestamp) {
+			act.Records = append(act.Records, r)
+		}
+	}
+
+	for _, l := range m.Laps {
+		if inSession(l.StartTime) {
+			act.Laps = append(act.Laps, l)
+		}
+	}
+
+	for _, l := range m.Lengths {
+		if inSession(l.StartTime) {
+			act.Lengths = append(act.Lengths, l)
 		}
+	}
+
+	for _, e := range m.Events {
+		if inSession(e.Timestamp) {
+			act.Events = append(act.Events, e)
+		}
+	}
+
+	session := *s
+	session.MessageIndex = 0
+	session.FirstLapIndex = 0
+	session.NumLaps = uint16(len(act.Laps))
+	act.Sessions = []*fit.SessionMsg{&session}
+
+	if m.Activity != nil {
+		a := *m.Activity
+		a.NumSessions = 1
+		act.Activity = &a
+	}
 
+	buf := &bytes.Buffer{}
+	if err := fit.Encode(buf, sf, binary.LittleEndian); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// decodeFitActivity decodes the FIT file as an activity with at least one
+// session
+func decodeFitActivity(fitFile []byte) (*fit.File, *fit.ActivityFile, error) {
+	f, err := fit.Decode(bytes.NewReader(fitFile))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	m, err := f.Activity()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(m.Sessions) == 0 {
+		return nil, nil, fmt.Errorf("no sessions found")
+	}
+
+	return f, m, nil
+}
+
+// fitRecordsAsGPX converts the records to a GPX with a single track
+func fitRecordsAsGPX(f *fit.File, name, sport string, records []*fit.RecordMsg) *gpx.GPX {
+	gpxFile := &gpx.GPX{
+		Name:    f.FileId.TimeCreated.String(),
+		Time:    &f.FileId.TimeCreated,
+		Creator: f.FileId.Manufacturer.String(),
+	}
+
+	gpxFile.AppendTrack(&gpx.GPXTrack{
+		Name: name,
+		Type: sport,
+	})
+
+	for _, r := range records {
 		p := &gpx.GPXPoint{
 			Timestamp: r.Timestamp,
 			Point: gpx.Point{
-				Latitude:  r.PositionLat.Degrees(),
-				Longitude: r.PositionLong.Degrees(),
+				Latitude:  math.NaN(),
+				Longitude: math.NaN(),
 			},
 		}
 
+		if !r.PositionLat.Invalid() && !r.PositionLong.Invalid() {
+			p.Latitude = r.PositionLat.Degrees()
+			p.Longitude = r.PositionLong.Degrees()
+		} else {
+			// Records without a position (indoor workouts, or before the GPS
+			// has a fix) are kept; their distance comes from the distance or
+			// speed fields
+			fitDistanceExtensions(p, r)
+		}
+
 		if a := r.GetEnhancedAltitudeScaled(); !math.IsNaN(a) {
 			p.Elevation = *gpx.NewNullableFloat64(a)
 		}
@@ -68,8 +198,44 @@ func ParseFit(fitFile []byte) (*gpx.GPX, error) {
 			})
 		}
 
+		if r.Power != 0xFFFF {
+			p.Extensions.Nodes = append(p.Extensions.Nodes, gpx.ExtensionNode{
+				XMLName: xml.Name{Local: "power"}, Data: strconv.Itoa(int(r.Power)),
+			})
+		}
+
 		gpxFile.AppendPoint(p)
 	}
 
-	return gpxFile, nil
+	return gpxFile
+}
+
+// fitDistanceExtensions adds the distance (in m) and speed (in m/s) of the
+// record to the point, if the record has them
+func fitDistanceExtensions(p *gpx.GPXPoint, r *fit.RecordMsg) {
+	if d := r.GetDistanceScaled(); !math.IsNaN(d) {
+		p.Extensions.Nodes = append(p.Extensions.Nodes, gpx.ExtensionNode{
+			XMLName: xml.Name{Local: "distance"}, Data: formatFloat(d),
+		})
+	}
+
+	if v := fitSpeed(r); !math.IsNaN(v) {
+		p.Extensions.Nodes = append(p.Extensions.Nodes, gpx.ExtensionNode{
+			XMLName: xml.Name{Local: "speed"}, Data: formatFloat(v),
+		})
+	}
+}
+
+// fitSpeed returns the speed of the record in m/s, or NaN if the record has no
+// speed
+func fitSpeed(r *fit.RecordMsg) float64 {
+	if v := r.GetEnhancedSpeedScaled(); !math.IsNaN(v) {
+		return v
+	}
+
+	return r.GetSpeedScaled()
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
 }
@@ -0,0 +1,149 @@
+package converters
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"strings"
+
+	"github.com/tormoder/fit"
+)
+
+// ExportFIT encodes the activity as a FIT activity file, with a single session
+// and lap
+func ExportFIT(a *Activity) ([]byte, error) {
+	f, err := fit.NewFile(fit.FileTypeActivity, fit.NewHeader(fit.V20, true))
+	if err != nil {
+		return nil, err
+	}
+
+	f.FileId.Manufacturer = fit.ManufacturerDevelopment
+	f.FileId.TimeCreated = a.Start
+
+	act, err := f.Activity()
+	if err != nil {
+		return nil, err
+	}
+
+	start, end := a.Start, a.End()
+	sport := fitSport(a.Type)
+	elapsed := fitScaled(end.Sub(start).Seconds(), 1000)
+	timer := fitScaled(a.TotalDuration.Seconds(), 1000)
+	distance := fitScaled(a.TotalDistance, 100)
+
+	ev := fit.NewEventMsg()
+	ev.Timestamp = start
+	ev.Event = fit.EventTimer
+	ev.EventType = fit.EventTypeStart
+	act.Events = append(act.Events, ev)
+
+	for _, p := range a.Points {
+		r := fit.NewRecordMsg()
+		r.Timestamp = p.Time
+		r.Distance = fitScaled(p.TotalDistance, 100)
+
+		if p.HasPosition() {
+			r.PositionLat = fit.NewLatitudeDegrees(p.Lat)
+			r.PositionLong = fit.NewLongitudeDegrees(p.Lng)
+		}
+
+		if p.Elevation != nil {
+			alt := fitScaled(*p.Elevation+500, 5)
+			r.EnhancedAltitude = alt
+			r.Altitude = uint16(min(alt, math.MaxUint16-1))
+		}
+
+		if p.HeartRate != nil {
+			r.HeartRate = uint8(min(*p.HeartRate, math.MaxUint8-1))
+		}
+
+		if p.Cadence != nil {
+			r.Cadence = uint8(min(*p.Cadence, math.MaxUint8-1))
+		}
+
+		if p.Power != nil {
+			r.Power = uint16(min(*p.Power, math.MaxUint16-1))
+		}
+
+		act.Records = append(act.Records, r)
+	}
+
+	ev = fit.NewEventMsg()
+	ev.Timestamp = end
+	ev.Event = fit.EventTimer
+	ev.EventType = fit.EventTypeStopAll
+	act.Events = append(act.Events, ev)
+
+	lap := fit.NewLapMsg()
+	lap.MessageIndex = 0
+	lap.Timestamp = end
+	lap.StartTime = start
+	lap.Event = fit.EventLap
+	lap.EventType = fit.EventTypeStop
+	lap.Sport = sport
+	lap.TotalElapsedTime = elapsed
+	lap.TotalTimerTime = timer
+	lap.TotalDistance = distance
+	act.Laps = append(act.Laps, lap)
+
+	session := fit.NewSessionMsg()
+	session.MessageIndex = 0
+	session.Timestamp = end
+	session.StartTime = start
+	session.Event = fit.EventSession
+	session.EventType = fit.EventTypeStop
+	session.Sport = sport
+	session.TotalElapsedTime = elapsed
+	session.TotalTimerTime = timer
+	session.TotalDistance = distance
+	session.FirstLapIndex = 0
+	session.NumLaps = 1
+	act.Sessions = append(act.Sessions, session)
+
+	act.Activity = fit.NewActivityMsg()
+	act.Activity.Timestamp = end
+	act.Activity.TotalTimerTime = timer
+	act.Activity.NumSessions = 1
+	act.Activity.Type = fit.ActivityModeManual
+	act.Activity.Event = fit.EventActivity
+	act.Activity.EventType = fit.EventTypeStop
+
+	buf := &bytes.Buffer{}
+	if err := fit.Encode(buf, f, binary.LittleEndian); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// fitScaled converts a value to the integer representation used by FIT
+func fitScaled(v, scale float64) uint32 {
+	return uint32(max(0, min(math.Round(v*scale), math.MaxUint32-1)))
+}
+
+func fitSport(workoutType string) fit.Sport {
+	switch strings.ToLower(workoutType) {
+	case "running":
+		return fit.SportRunning
+	case "cycling":
+		return fit.SportCycling
+	case "walking":
+		return fit.SportWalking
+	case "hiking":
+		return fit.SportHiking
+	case "swimming":
+		return fit.SportSwimming
+	case "skiing":
+		return fit.SportAlpineSkiing
+	case "snowboarding":
+		return fit.SportSnowboarding
+	case "kayaking":
+		return fit.SportKayaking
+	case "golfing":
+		return fit.SportGolf
+	case "push-ups", "weight lifting":
+		return fit.SportTraining
+	default:
+		return fit.SportGeneric
+	}
+}
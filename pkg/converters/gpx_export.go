@@ -0,0 +1,116 @@
+package converters
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+const (
+	gpxNamespace                       = "http://www.topografix.com/GPX/1/1"
+	garminTrackPointExtensionNamespace = "http://www.garmin.com/xmlschemas/TrackPointExtension/v1"
+)
+
+// gpxFile is the GPX 1.1 document written on export; the gpx package always
+// writes the position of a point, which points of indoor activities do not have
+type gpxFile struct {
+	XMLName      xml.Name    `xml:"gpx"`
+	Namespace    string      `xml:"xmlns,attr"`
+	TPXNamespace string      `xml:"xmlns:gpxtpx,attr"`
+	Version      string      `xml:"version,attr"`
+	Creator      string      `xml:"creator,attr"`
+	Metadata     gpxMetadata `xml:"metadata"`
+	Track        gpxTrack    `xml:"trk"`
+}
+
+type gpxMetadata struct {
+	Name        string    `xml:"name,omitempty"`
+	Description string    `xml:"desc,omitempty"`
+	Time        time.Time `xml:"time"`
+}
+
+type gpxTrack struct {
+	Name   string     `xml:"name,omitempty"`
+	Type   string     `xml:"type,omitempty"`
+	Points []gpxPoint `xml:"trkseg>trkpt"`
+}
+
+type gpxPoint struct {
+	Lat        *float64       `xml:"lat,attr,omitempty"`
+	Lng        *float64       `xml:"lon,attr,omitempty"`
+	Elevation  *float64       `xml:"ele,omitempty"`
+	Time       time.Time      `xml:"time"`
+	Extensions *gpxExtensions `xml:"extensions,omitempty"`
+}
+
+type gpxExtensions struct {
+	TrackPoint *gpxTrackPointExtension `xml:"gpxtpx:TrackPointExtension,omitempty"`
+	Power      *int                    `xml:"power,omitempty"`
+}
+
+type gpxTrackPointExtension struct {
+	HeartRate *int `xml:"gpxtpx:hr,omitempty"`
+	Cadence   *int `xml:"gpxtpx:cad,omitempty"`
+}
+
+// ExportGPX encodes the activity as GPX 1.1; heart rate and cadence are
+// added as Garmin track point extensions, and power as a power extension
+func ExportGPX(a *Activity) ([]byte, error) {
+	g := gpxFile{
+		Namespace:    gpxNamespace,
+		TPXNamespace: garminTrackPointExtensionNamespace,
+		Version:      "1.1",
+		Creator:      "Workout Tracker",
+		Metadata: gpxMetadata{
+			Name:        a.Name,
+			Description: a.Notes,
+			Time:        a.Start.UTC(),
+		},
+		Track: gpxTrack{
+			Name: a.Name,
+			Type: a.Type,
+		},
+	}
+
+	for _, p := range a.Points {
+		pt := gpxPoint{
+			Time:      p.Time.UTC(),
+			Elevation: p.Elevation,
+		}
+
+		if p.HasPosition() {
+			pt.Lat, pt.Lng = &p.Lat, &p.Lng
+		}
+
+		ext := gpxExtensions{Power: intPtr(p.Power)}
+
+		if p.HeartRate != nil || p.Cadence != nil {
+			ext.TrackPoint = &gpxTrackPointExtension{
+				HeartRate: intPtr(p.HeartRate),
+				Cadence:   intPtr(p.Cadence),
+			}
+		}
+
+		if ext.TrackPoint != nil || ext.Power != nil {
+			pt.Extensions = &ext
+		}
+
+		g.Track.Points = append(g.Track.Points, pt)
+	}
+
+	b, err := xml.MarshalIndent(g, "", "\t")
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), b...), nil
+}
+
+func intPtr(f *float64) *int {
+	if f == nil {
+		return nil
+	}
+
+	i := int(*f)
+
+	return &i
+}
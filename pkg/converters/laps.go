@@ -0,0 +1,165 @@
+package converters
+
+import (
+	"bytes"
+	"encoding/xml"
+	"math"
+	"path"
+	"time"
+
+	"github.com/galeone/tcx"
+	"github.com/tormoder/fit"
+)
+
+// Lap is a lap or split as recorded by the device; metrics that were not
+// recorded are 0
+type Lap struct {
+	Start            time.Time     // The start time of the lap
+	Stop             time.Time     // The end time of the lap
+	Distance         float64       // The distance of the lap, in meters
+	Duration         time.Duration // The duration of the lap, without pauses
+	AverageHeartRate float64       // The average heart rate, in beats per minute
+	MaxHeartRate     float64       // The maximum heart rate, in beats per minute
+	AverageCadence   float64       // The average cadence
+	MaxCadence       float64       // The maximum cadence
+}
+
+// ParseLaps returns the laps recorded in the file; only FIT and TCX files
+// contain laps, other files return no laps
+func ParseLaps(filename string, content []byte) ([]Lap, error) {
+	switch path.Ext(filename) {
+	case ".fit":
+		return ParseFitLaps(content)
+	case ".tcx":
+		return ParseTCXLaps(content)
+	default:
+		return nil, nil
+	}
+}
+
+func ParseFitLaps(fitFile []byte) ([]Lap, error) {
+	f, err := fit.Decode(bytes.NewReader(fitFile))
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := f.Activity()
+	if err != nil {
+		return nil, err
+	}
+
+	laps := make([]Lap, 0, len(m.Laps))
+
+	for _, l := range m.Laps {
+		lap := Lap{
+			Start:            l.StartTime,
+			Stop:             l.Timestamp,
+			Distance:         validOrZero(l.GetTotalDistanceScaled()),
+			Duration:         time.Duration(validOrZero(l.GetTotalTimerTimeScaled()) * float64(time.Second)),
+			AverageHeartRate: validUint8(l.AvgHeartRate),
+			MaxHeartRate:     validUint8(l.MaxHeartRate),
+			AverageCadence:   validUint8(l.AvgCadence),
+			MaxCadence:       validUint8(l.MaxCadence),
+		}
+
+		if lap.Duration == 0 {
+			lap.Duration = lap.Stop.Sub(lap.Start)
+		}
+
+		laps = append(laps, lap)
+	}
+
+	return laps, nil
+}
+
+func ParseTCXLaps(tcxFile []byte) ([]Lap, error) {
+	var t tcx.TCXDB
+
+	if err := xml.Unmarshal(tcxFile, &t); err != nil {
+		return nil, err
+	}
+
+	if t.Acts == nil {
+		return nil, nil
+	}
+
+	laps := []Lap{}
+
+	for _, a := range t.Acts.Act {
+		for _, l := range a.Laps {
+			start, err := time.Parse(time.RFC3339, l.Start)
+			if err != nil {
+				return nil, err
+			}
+
+			lap := Lap{
+				Start:    start,
+				Stop:     start.Add(time.Duration(l.TotalTime * float64(time.Second))),
+				Distance: l.Dist,
+				Duration: time.Duration(l.TotalTime * float64(time.Second)),
+			}
+
+			if l.Trk != nil {
+				// The heart rate and cadence are only available per point
+				hr, cad := &average{}, &average{}
+
+				for _, p := range l.Trk.Pt {
+					hr.add(p.HR)
+					cad.add(p.Cad)
+				}
+
+				lap.AverageHeartRate, lap.MaxHeartRate = hr.value(), hr.max
+				lap.AverageCadence, lap.MaxCadence = cad.value(), cad.max
+
+				if n := len(l.Trk.Pt); n > 0 && l.Trk.Pt[n-1].Time.After(start) {
+					lap.Stop = l.Trk.Pt[n-1].Time
+				}
+			}
+
+			laps = append(laps, lap)
+		}
+	}
+
+	return laps, nil
+}
+
+func validOrZero(f float64) float64 {
+	if math.IsNaN(f) {
+		return 0
+	}
+
+	return f
+}
+
+func validUint8(v uint8) float64 {
+	if v == 0xFF {
+		return 0
+	}
+
+	return float64(v)
+}
+
+// average keeps the average and maximum of positive values
+type average struct {
+	sum   float64
+	count int
+	max   float64
+}
+
+func (a *average) add(v float64) {
+	if v <= 0 {
+		return
+	}
+
+	a.sum += v
+	a.count++
+	a.max = max(a.max, v)
+}
+
+func (a *average) value() float64 {
+	if a.count == 0 {
+		return 0
+	}
+
+	return a.sum / float64(a.count)
+}
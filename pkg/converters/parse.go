@@ -29,3 +29,14 @@ func Parse(filename string, content []byte) (*gpx.GPX, error) {
 		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFile, filename)
 	}
 }
+
+// SplitSessions returns a file per session of a multi-session file, eg. the
+// legs of a triathlon; only FIT files have sessions, other files and files
+// with a single session return nil
+func SplitSessions(filename string, content []byte) ([][]byte, error) {
+	if path.Ext(filename) != ".fit" {
+		return nil, nil
+	}
+
+	return SplitFitSessions(content)
+}
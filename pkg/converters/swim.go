@@ -0,0 +1,105 @@
+package converters
+
+import (
+	"bytes"
+	"path"
+	"time"
+
+	"github.com/tormoder/fit"
+)
+
+// SwimLength is a single length of a pool swim, as recorded by the device
+type SwimLength struct {
+	Start    time.Time     // The start time of the length
+	Duration time.Duration // The duration of the length
+	Stroke   string        // The stroke type, eg. "freestyle"; empty if unknown
+	Strokes  int           // The number of strokes
+	Active   bool          // Whether the length was swum; idle lengths are rests
+}
+
+// SwimPool is the pool of a pool swim, and the lengths swum in it
+type SwimPool struct {
+	Length  float64      // The length of the pool, in meters
+	Yards   bool         // Whether the pool length was set in yards
+	Lengths []SwimLength // The lengths, in order
+}
+
+// ParseSwimPool returns the pool and lengths recorded in the file; only FIT
+// files contain lengths, other files and workouts that are not pool swims
+// return nil
+func ParseSwimPool(filename string, content []byte) (*SwimPool, error) {
+	if path.Ext(filename) != ".fit" {
+		return nil, nil
+	}
+
+	return ParseFitSwimPool(content)
+}
+
+func ParseFitSwimPool(fitFile []byte) (*SwimPool, error) {
+	f, err := fit.Decode(bytes.NewReader(fitFile))
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := f.Activity()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(m.Lengths) == 0 {
+		return nil, nil
+	}
+
+	p := &SwimPool{}
+
+	for _, s := range m.Sessions {
+		if l := validOrZero(s.GetPoolLengthScaled()); l > 0 {
+			p.Length = l
+			p.Yards = s.PoolLengthUnit == fit.DisplayMeasureStatute
+
+			break
+		}
+	}
+
+	for _, l := range m.Lengths {
+		length := SwimLength{
+			Start:    l.StartTime,
+			Duration: time.Duration(validOrZero(l.GetTotalTimerTimeScaled()) * float64(time.Second)),
+			Stroke:   swimStroke(l.SwimStroke),
+			Active:   l.LengthType == fit.LengthTypeActive,
+		}
+
+		if l.TotalStrokes != 0xFFFF {
+			length.Strokes = int(l.TotalStrokes)
+		}
+
+		if length.Duration == 0 {
+			length.Duration = time.Duration(validOrZero(l.GetTotalElapsedTimeScaled()) * float64(time.Second))
+		}
+
+		p.Lengths = append(p.Lengths, length)
+	}
+
+	return p, nil
+}
+
+func swimStroke(s fit.SwimStroke) string {
+	switch s {
+	case fit.SwimStrokeFreestyle:
+		return "freestyle"
+	case fit.SwimStrokeBackstroke:
+		return "backstroke"
+	case fit.SwimStrokeBreaststroke:
+		return "breaststroke"
+	case fit.SwimStrokeButterfly:
+		return "butterfly"
+	case fit.SwimStrokeDrill:
+		return "drill"
+	case fit.SwimStrokeMixed:
+		return "mixed"
+	case fit.SwimStrokeIm:
+		return "im"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,96 @@
+package converters
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+const garminActivityExtensionNamespace = "http://www.garmin.com/xmlschemas/ActivityExtension/v2"
+
+// tcxFile is the Training Center XML document written on export; the tcx
+// package always writes the position of a point, which points of indoor
+// activities do not have
+type tcxFile struct {
+	XMLName      xml.Name      `xml:"http://www.garmin.com/xmlschemas/TrainingCenterDatabase/v2 TrainingCenterDatabase"`
+	TPXNamespace string        `xml:"xmlns:ns3,attr"`
+	Activities   []tcxActivity `xml:"Activities>Activity"`
+}
+
+type tcxActivity struct {
+	Sport string    `xml:"Sport,attr"`
+	ID    time.Time `xml:"Id"`
+	Laps  []tcxLap  `xml:"Lap"`
+	Notes string    `xml:",omitempty"`
+}
+
+type tcxLap struct {
+	Start         string          `xml:"StartTime,attr"`
+	TotalTime     float64         `xml:"TotalTimeSeconds"`
+	Distance      float64         `xml:"DistanceMeters"`
+	Intensity     string          `xml:"Intensity"`
+	TriggerMethod string          `xml:"TriggerMethod"`
+	Points        []tcxTrackpoint `xml:"Track>Trackpoint,omitempty"`
+}
+
+type tcxTrackpoint struct {
+	Time      time.Time    `xml:"Time"`
+	Position  *tcxPosition `xml:"Position,omitempty"`
+	Altitude  *float64     `xml:"AltitudeMeters,omitempty"`
+	Distance  float64      `xml:"DistanceMeters"`
+	HeartRate *int         `xml:"HeartRateBpm>Value,omitempty"`
+	Cadence   *int         `xml:"Cadence,omitempty"`
+	Power     *int         `xml:"Extensions>ns3:TPX>ns3:Watts,omitempty"`
+}
+
+type tcxPosition struct {
+	Lat float64 `xml:"LatitudeDegrees"`
+	Lng float64 `xml:"LongitudeDegrees"`
+}
+
+// ExportTCX encodes the activity as a Training Center XML file, with a single
+// lap containing all points; power is added as a Garmin activity extension
+func ExportTCX(a *Activity) ([]byte, error) {
+	lap := tcxLap{
+		Start:         a.Start.UTC().Format(time.RFC3339),
+		TotalTime:     a.TotalDuration.Seconds(),
+		Distance:      a.TotalDistance,
+		Intensity:     "Active",
+		TriggerMethod: "Manual",
+	}
+
+	for _, p := range a.Points {
+		pt := tcxTrackpoint{
+			Time:      p.Time.UTC(),
+			Altitude:  p.Elevation,
+			Distance:  p.TotalDistance,
+			HeartRate: intPtr(p.HeartRate),
+			Cadence:   intPtr(p.Cadence),
+			Power:     intPtr(p.Power),
+		}
+
+		if p.HasPosition() {
+			pt.Position = &tcxPosition{Lat: p.Lat, Lng: p.Lng}
+		}
+
+		lap.Points = append(lap.Points, pt)
+	}
+
+	t := tcxFile{
+		TPXNamespace: garminActivityExtensionNamespace,
+		Activities: []tcxActivity{
+			{
+				Sport: sportName(a.Type),
+				ID:    a.Start.UTC(),
+				Laps:  []tcxLap{lap},
+				Notes: a.Notes,
+			},
+		},
+	}
+
+	b, err := xml.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), b...), nil
+}
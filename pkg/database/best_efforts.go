@@ -0,0 +1,311 @@
+package database
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// EffortTarget is a standard distance or duration for which the best effort of
+// a workout is tracked
+type EffortTarget string
+
+const (
+	Effort1K           EffortTarget = "1k"            // The fastest kilometer
+	Effort5K           EffortTarget = "5k"            // The fastest 5 kilometers
+	Effort10K          EffortTarget = "10k"           // The fastest 10 kilometers
+	EffortHalfMarathon EffortTarget = "half-marathon" // The fastest half marathon
+	EffortMarathon     EffortTarget = "marathon"      // The fastest marathon
+	Effort5Min         EffortTarget = "5min"          // The longest distance in 5 minutes
+	Effort20Min        EffortTarget = "20min"         // The longest distance in 20 minutes
+	Effort60Min        EffortTarget = "60min"         // The longest distance in 60 minutes
+)
+
+func EffortTargets() []EffortTarget {
+	return []EffortTarget{
+		Effort1K, Effort5K, Effort10K, EffortHalfMarathon, EffortMarathon,
+		Effort5Min, Effort20Min, Effort60Min,
+	}
+}
+
+func (e EffortTarget) String() string {
+	return string(e)
+}
+
+// Distance returns the distance of the target, in meters; it is 0 for targets
+// over a fixed duration
+func (e EffortTarget) Distance() float64 {
+	switch e {
+	case Effort1K:
+		return 1000
+	case Effort5K:
+		return 5000
+	case Effort10K:
+		return 10000
+	case EffortHalfMarathon:
+		return 21097.5
+	case EffortMarathon:
+		return 42195
+	default:
+		return 0
+	}
+}
+
+// Duration returns the duration of the target; it is 0 for targets over a
+// fixed distance
+func (e EffortTarget) Duration() time.Duration {
+	switch e {
+	case Effort5Min:
+		return 5 * time.Minute
+	case Effort20Min:
+		return 20 * time.Minute
+	case Effort60Min:
+		return 60 * time.Minute
+	default:
+		return 0
+	}
+}
+
+// IsDistance returns whether the target is a fixed distance, where the fastest
+// time is best
+func (e EffortTarget) IsDistance() bool {
+	return e.Distance() > 0
+}
+
+// Label returns a human readable name of the target
+func (e EffortTarget) Label() string {
+	switch e {
+	case Effort1K:
+		return "1 km"
+	case Effort5K:
+		return "5 km"
+	case Effort10K:
+		return "10 km"
+	case EffortHalfMarathon:
+		return "Half marathon"
+	case EffortMarathon:
+		return "Marathon"
+	case Effort5Min:
+		return "5 minutes"
+	case Effort20Min:
+		return "20 minutes"
+	case Effort60Min:
+		return "60 minutes"
+	default:
+		return e.String()
+	}
+}
+
+// BestEffort is the best contiguous effort of a workout for a target
+type BestEffort struct {
+	gorm.Model
+	MapDataID uint          `gorm:"not null;index" json:"-"` // The ID of the map data this effort belongs to
+	Target    EffortTarget  `gorm:"not null;index"`          // The target distance or duration
+	Start     time.Time     // The time the effort started
+	Distance  float64       // The distance of the effort, in meters
+	Duration  time.Duration // The duration of the effort
+}
+
+func (e *BestEffort) AverageSpeed() float64 {
+	if e.Duration <= 0 {
+		return 0
+	}
+
+	return e.Distance / e.Duration.Seconds()
+}
+
+// better returns whether the effort is better than the other effort for the
+// same target
+func (e *BestEffort) better(other *BestEffort) bool {
+	if e.Target.IsDistance() {
+		return e.Duration < other.Duration
+	}
+
+	return e.Distance > other.Distance
+}
+
+// UpdateBestEfforts calculates the best efforts for all targets from the
+// points, using a sliding window
+func (m *MapData) UpdateBestEfforts() {
+	m.BestEfforts = nil
+
+	if m.Details == nil || len(m.Details.Points) < 2 {
+		return
+	}
+
+	for _, t := range EffortTargets() {
+		if e := bestEffort(m.Details.Points, t); e != nil {
+			m.BestEfforts = append(m.BestEfforts, *e)
+		}
+	}
+}
+
+// bestEffort returns the best effort for the target, or nil if the points
+// don't cover the target; the window is scaled to the exact target
+func bestEffort(points []MapPoint, t EffortTarget) *BestEffort {
+	var best *BestEffort
+
+	covered := func(from, to *MapPoint) float64 {
+		if t.IsDistance() {
+			return to.TotalDistance - from.TotalDistance
+		}
+
+		return (to.TotalDuration - from.TotalDuration).Seconds()
+	}
+
+	target := t.Distance()
+	if !t.IsDistance() {
+		target = t.Duration().Seconds()
+	}
+
+	i := 0
+
+	for j := 1; j < len(points); j++ {
+		// Shrink the window as long as it still covers the target
+		for i+1 < j && covered(&points[i+1], &points[j]) >= target {
+			i++
+		}
+
+		c := covered(&points[i], &points[j])
+		if c < target {
+			continue
+		}
+
+		distance := points[j].TotalDistance - points[i].TotalDistance
+		duration := points[j].TotalDuration - points[i].TotalDuration
+
+		if distance <= 0 || duration <= 0 {
+			continue
+		}
+
+		e := &BestEffort{
+			Target:   t,
+			Start:    points[i].Time,
+			Distance: distance * target / c,
+			Duration: time.Duration(float64(duration) * target / c),
+		}
+
+		if t.IsDistance() {
+			e.Distance = target
+		} else {
+			e.Duration = t.Duration()
+		}
+
+		if best == nil || e.better(best) {
+			best = e
+		}
+	}
+
+	return best
+}
+
+// deleteBestEfforts removes the stored best efforts of the map data, before
+// they are replaced
+func (m *MapData) deleteBestEfforts(db *gorm.DB) error {
+	if m.ID == 0 {
+		return nil
+	}
+
+	return db.Unscoped().Where(&BestEffort{MapDataID: m.ID}).Delete(&BestEffort{}).Error
+}
+
+// BestEffortRecord is the best effort for a target over a number of workouts
+type BestEffortRecord struct {
+	Target   EffortTarget  // The target distance or duration
+	Distance float64       // The distance of the effort, in meters
+	Duration time.Duration // The duration of the effort
+	Date     time.Time     // The timestamp of the workout
+	ID       uint          // The workout ID of the record
+}
+
+func (r *BestEffortRecord) AverageSpeed() float64 {
+	if r.Duration <= 0 {
+		return 0
+	}
+
+	return r.Distance / r.Duration.Seconds()
+}
+
+// YearBestEfforts are the best efforts of a single year
+type YearBestEfforts struct {
+	Year        int                // The year
+	BestEfforts []BestEffortRecord // The best effort per target
+}
+
+// getBestEfforts returns the all-time best efforts for the workout type, and
+// the best efforts per year (most recent year first)
+func (u *User) getBestEfforts(t WorkoutType) ([]BestEffortRecord, []YearBestEfforts, error) {
+	var efforts []BestEffortRecord
+
+	err := u.db.
+		Table("best_efforts").
+		Joins("join map_data on map_data.id = best_efforts.map_data_id").
+		Joins("join workouts on workouts.id = map_data.workout_id").
+		Where("workouts.user_id = ?", u.ID).
+		Where("workouts.type = ?", t).
+		Where("best_efforts.deleted_at IS NULL AND map_data.deleted_at IS NULL AND workouts.deleted_at IS NULL").
+		Scopes(countedInStatistics).
+		Select(
+			"best_efforts.target as target",
+			"best_efforts.distance as distance",
+			"best_efforts.duration as duration",
+			"workouts.date as date",
+			"workouts.id as id",
+		).
+		Scan(&efforts).Error
+	if err != nil {
+		return nil, nil, err
+	}
+
+	allTime := map[EffortTarget]BestEffortRecord{}
+	perYear := map[int]map[EffortTarget]BestEffortRecord{}
+
+	for _, e := range efforts {
+		if current, ok := allTime[e.Target]; !ok || e.better(&current) {
+			allTime[e.Target] = e
+		}
+
+		year := e.Date.Year()
+		if perYear[year] == nil {
+			perYear[year] = map[EffortTarget]BestEffortRecord{}
+		}
+
+		if current, ok := perYear[year][e.Target]; !ok || e.better(&current) {
+			perYear[year][e.Target] = e
+		}
+	}
+
+	years := make([]YearBestEfforts, 0, len(perYear))
+	for year, records := range perYear {
+		years = append(years, YearBestEfforts{Year: year, BestEfforts: orderedBestEfforts(records)})
+	}
+
+	slices.SortFunc(years, func(a, b YearBestEfforts) int {
+		return cmp.Compare(b.Year, a.Year)
+	})
+
+	return orderedBestEfforts(allTime), years, nil
+}
+
+func (r *BestEffortRecord) better(other *BestEffortRecord) bool {
+	if r.Target.IsDistance() {
+		return r.Duration < other.Duration
+	}
+
+	return r.Distance > other.Distance
+}
+
+// orderedBestEfforts returns the records in the order of the targets
+func orderedBestEfforts(records map[EffortTarget]BestEffortRecord) []BestEffortRecord {
+	r := []BestEffortRecord{}
+
+	for _, t := range EffortTargets() {
+		if e, ok := records[t]; ok {
+			r = append(r, e)
+		}
+	}
+
+	return r
+}
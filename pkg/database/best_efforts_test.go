@@ -0,0 +1,147 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jovandeginste/workout-tracker/pkg/converters"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// effortPoints returns a point every 10 seconds, covering the given distance
+// (in meters) in each interval
+func effortPoints(distances ...float64) []MapPoint {
+	start := time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC)
+	points := []MapPoint{{Time: start}}
+
+	for i, d := range distances {
+		prev := points[len(points)-1]
+		points = append(points, MapPoint{
+			Time:          start.Add(time.Duration(i+1) * 10 * time.Second),
+			Distance:      d,
+			TotalDistance: prev.TotalDistance + d,
+			Duration:      10 * time.Second,
+			TotalDuration: prev.TotalDuration + 10*time.Second,
+		})
+	}
+
+	return points
+}
+
+func repeated(v float64, n int) []float64 {
+	r := make([]float64, n)
+	for i := range r {
+		r[i] = v
+	}
+
+	return r
+}
+
+func TestEffortTarget(t *testing.T) {
+	assert.True(t, Effort5K.IsDistance())
+	assert.InDelta(t, 42195, EffortMarathon.Distance(), 0.1)
+	assert.False(t, Effort20Min.IsDistance())
+	assert.Equal(t, 20*time.Minute, Effort20Min.Duration())
+	assert.Equal(t, "Half marathon", EffortHalfMarathon.Label())
+}
+
+func TestBestEffort_SlidingWindow(t *testing.T) {
+	// 10 minutes at 3 m/s, 5 minutes at 5 m/s, 10 minutes at 3 m/s
+	distances := append(append(repeated(30, 60), repeated(50, 30)...), repeated(30, 60)...)
+	m := &MapData{Details: &MapDataDetails{Points: effortPoints(distances...)}}
+
+	m.UpdateBestEfforts()
+
+	efforts := map[EffortTarget]BestEffort{}
+	for _, e := range m.BestEfforts {
+		efforts[e.Target] = e
+	}
+
+	require.Contains(t, efforts, Effort1K)
+	assert.Equal(t, 200*time.Second, efforts[Effort1K].Duration)
+	assert.InDelta(t, 1000, efforts[Effort1K].Distance, 0.1)
+	assert.Equal(t, time.Date(2024, 1, 2, 10, 10, 0, 0, time.UTC), efforts[Effort1K].Start)
+
+	require.Contains(t, efforts, Effort5Min)
+	assert.InDelta(t, 1500, efforts[Effort5Min].Distance, 0.1)
+	assert.Equal(t, 5*time.Minute, efforts[Effort5Min].Duration)
+
+	require.Contains(t, efforts, Effort20Min)
+	assert.InDelta(t, 4200, efforts[Effort20Min].Distance, 0.1)
+
+	assert.NotContains(t, efforts, Effort10K)
+	assert.NotContains(t, efforts, Effort60Min)
+}
+
+func TestBestEffort_NoPoints(t *testing.T) {
+	m := &MapData{}
+	m.UpdateBestEfforts()
+
+	assert.Empty(t, m.BestEfforts)
+}
+
+func TestUser_GetRecordsBestEfforts(t *testing.T) {
+	db := createMemoryDB(t)
+
+	u := defaultUser()
+	require.NoError(t, u.Create(db))
+
+	for i, speed := range []float64{30, 40, 35} {
+		d := time.Date(2022+i/2, 5, 1+i, 10, 0, 0, 0, time.UTC)
+		m := &MapData{TotalDistance: 100, Details: &MapDataDetails{Points: effortPoints(repeated(speed, 60)...)}}
+		m.UpdateBestEfforts()
+
+		w := &Workout{UserID: u.ID, Name: "effort", Type: WorkoutTypeRunning, Date: &d, Data: m}
+		require.NoError(t, w.Create(db))
+	}
+
+	u, err := GetUserByID(db, int(u.ID))
+	require.NoError(t, err)
+
+	r, err := u.GetRecords(WorkoutTypeRunning)
+	require.NoError(t, err)
+
+	require.NotEmpty(t, r.BestEfforts)
+	assert.Equal(t, Effort1K, r.BestEfforts[0].Target)
+	assert.Equal(t, 250*time.Second, r.BestEfforts[0].Duration)
+	assert.Equal(t, 2022, r.BestEfforts[0].Date.Year())
+
+	require.Len(t, r.BestEffortsPerYear, 2)
+	assert.Equal(t, 2023, r.BestEffortsPerYear[0].Year)
+	assert.Equal(t, Effort1K, r.BestEffortsPerYear[0].BestEfforts[0].Target)
+	assert.InDelta(t, 1000/3.5, r.BestEffortsPerYear[0].BestEfforts[0].Duration.Seconds(), 0.01)
+}
+
+func TestWorkout_UpdateDataReplacesBestEfforts(t *testing.T) {
+	db := createMemoryDB(t)
+
+	u := defaultUser()
+	require.NoError(t, u.Create(db))
+
+	start := time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC)
+	a := &converters.Activity{Type: "running", Start: start}
+
+	for i := range 60 {
+		a.Points = append(a.Points, converters.ActivityPoint{
+			Time:          start.Add(time.Duration(i) * 10 * time.Second),
+			Lat:           51 + float64(i)*0.0003,
+			Lng:           4,
+			TotalDistance: float64(i) * 33,
+		})
+	}
+
+	content, err := converters.ExportFIT(a)
+	require.NoError(t, err)
+
+	w, err := u.AddWorkout(db, WorkoutTypeRunning, "", "efforts.fit", content)
+	require.NoError(t, err)
+	require.NotEmpty(t, w.Data.BestEfforts)
+
+	require.NoError(t, w.UpdateData(db))
+	require.NoError(t, w.UpdateData(db))
+
+	var count int64
+	require.NoError(t, db.Model(&BestEffort{}).Count(&count).Error)
+	assert.Equal(t, int64(len(w.Data.BestEfforts)), count)
+}
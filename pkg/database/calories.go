@@ -0,0 +1,177 @@
+package database
+
+import (
+	"time"
+
+	"github.com/jovandeginste/workout-tracker/pkg/converters"
+	"gorm.io/gorm"
+)
+
+// DefaultBodyWeight is the body weight when the user never measured it, in
+// kilograms
+const DefaultBodyWeight = 70
+
+// CaloriesSource is where the energy expenditure of a workout comes from
+type CaloriesSource string
+
+const (
+	CaloriesSourceNone      CaloriesSource = ""           // There is no estimate
+	CaloriesSourceDevice    CaloriesSource = "device"     // Reported by the device in the file
+	CaloriesSourceHeartRate CaloriesSource = "heart-rate" // Estimated from the heart rate
+	CaloriesSourceMET       CaloriesSource = "met"        // Estimated from the workout type and duration
+)
+
+func (s CaloriesSource) String() string {
+	return string(s)
+}
+
+// workoutTypeMETs are the metabolic equivalents of the built-in workout types,
+// at a moderate intensity, from the Compendium of Physical Activities
+var workoutTypeMETs = map[WorkoutType]float64{
+	WorkoutTypeRunning:       9.8,
+	WorkoutTypeCycling:       7.5,
+	WorkoutTypeWalking:       3.5,
+	WorkoutTypeSkiing:        7.0,
+	WorkoutTypeSnowboarding:  5.3,
+	WorkoutTypeSwimming:      7.0,
+	WorkoutTypeKayaking:      5.0,
+	WorkoutTypeGolfing:       4.8,
+	WorkoutTypeHiking:        6.0,
+	WorkoutTypePushups:       3.8,
+	WorkoutTypeWeightLifting: 5.0,
+}
+
+// MET returns the metabolic equivalent of the workout type; it is 0 for types
+// without one
+func (wt WorkoutType) MET() float64 {
+	return workoutTypeMETs[wt]
+}
+
+// metCalories returns the energy spent at the MET for the duration, in
+// kilocalories
+func metCalories(met, weight float64, d time.Duration) float64 {
+	return met * weight * d.Hours()
+}
+
+// heartRateMET estimates the MET at the heart rate: the heart rate reserve is
+// taken as the fraction of the VO2 reserve, and VO2max is estimated from the
+// maximum and resting heart rate (Uth et al.)
+func (s HeartRateSettings) heartRateMET(hr float64) float64 {
+	rest := float64(s.RestingOrDefault())
+	maxHR := float64(s.MaxOrDefault())
+
+	reserve := (hr - rest) / (maxHR - rest)
+	reserve = max(0, min(1, reserve))
+
+	vo2max := 15.3 * maxHR / rest
+
+	return 1 + reserve*(vo2max/3.5-1)
+}
+
+// heartRateCalories estimates the energy spent from the heart rate of the
+// points, in kilocalories; it is 0 if there is no heart rate
+func (m *MapData) heartRateCalories(s HeartRateSettings, weight float64) float64 {
+	if m.Details == nil {
+		return 0
+	}
+
+	total := 0.0
+
+	for _, p := range m.Details.Points {
+		hr := p.ExtraMetrics.Get("heart-rate")
+		if hr <= 0 || p.Duration <= 0 || p.Duration > maxHeartRateInterval {
+			continue
+		}
+
+		total += metCalories(s.heartRateMET(hr), weight, p.Duration)
+	}
+
+	return total
+}
+
+// UpdateCalories estimates the energy expenditure of the workout: a value
+// reported by the device is kept, else it is estimated from the heart rate of
+// the points, else from the MET of the workout type and the duration without
+// pauses. An estimate from the heart rate is kept when the points are not
+// loaded.
+func (m *MapData) UpdateCalories(wt WorkoutType, s HeartRateSettings, weight float64) {
+	switch {
+	case m.CaloriesSource == CaloriesSourceDevice:
+		return
+	case m.Details == nil && m.CaloriesSource == CaloriesSourceHeartRate:
+		return
+	}
+
+	if c := m.heartRateCalories(s, weight); c > 0 {
+		m.Calories, m.CaloriesSource = c, CaloriesSourceHeartRate
+		return
+	}
+
+	if c := metCalories(wt.MET(), weight, m.TotalDuration-m.PauseDuration); c > 0 {
+		m.Calories, m.CaloriesSource = c, CaloriesSourceMET
+		return
+	}
+
+	m.Calories, m.CaloriesSource = 0, CaloriesSourceNone
+}
+
+// setDeviceCalories stores the energy expenditure reported in the file, if
+// there is one
+func (m *MapData) setDeviceCalories(filename string, content []byte) error {
+	c, err := converters.ParseCalories(filename, content)
+	if err != nil {
+		return err
+	}
+
+	if c > 0 {
+		m.Calories, m.CaloriesSource = c, CaloriesSourceDevice
+	}
+
+	return nil
+}
+
+// bodyWeight returns the body weight of the owner of the workout at the date
+// of the workout, in kilograms
+func (w *Workout) bodyWeight(db *gorm.DB) (float64, error) {
+	u := &User{Model: gorm.Model{ID: w.UserID}}
+
+	at := time.Now()
+	if w.Date != nil {
+		at = *w.Date
+	}
+
+	weight, err := u.WeightAt(db, at)
+	if err != nil {
+		return 0, err
+	}
+
+	if weight <= 0 {
+		return DefaultBodyWeight, nil
+	}
+
+	return weight, nil
+}
+
+// updateCalories estimates the energy expenditure of the workout, with the
+// heart rate settings and body weight of its owner
+func (w *Workout) updateCalories(db *gorm.DB) error {
+	if w.Data == nil {
+		return nil
+	}
+
+	db = db.Session(&gorm.Session{NewDB: true})
+
+	hr, err := w.heartRateSettings(db)
+	if err != nil {
+		return err
+	}
+
+	weight, err := w.bodyWeight(db)
+	if err != nil {
+		return err
+	}
+
+	w.Data.UpdateCalories(w.Type, hr, weight)
+
+	return nil
+}
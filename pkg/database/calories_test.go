@@ -0,0 +1,85 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMapData_UpdateCalories(t *testing.T) {
+	s := HeartRateSettings{}
+
+	// Without heart rate, the MET of the workout type is used
+	m := &MapData{TotalDuration: time.Hour + 10*time.Minute, PauseDuration: 10 * time.Minute}
+	m.UpdateCalories(WorkoutTypeRunning, s, 70)
+	assert.Equal(t, CaloriesSourceMET, m.CaloriesSource)
+	assert.InDelta(t, 9.8*70, m.Calories, 0.01)
+
+	// Workout types without a MET have no estimate
+	m.UpdateCalories(WorkoutType("unknown"), s, 70)
+	assert.Equal(t, CaloriesSourceNone, m.CaloriesSource)
+	assert.Zero(t, m.Calories)
+
+	// The heart rate is preferred over the MET
+	m.Details = heartRatePoints(150, 150, 150)
+	m.UpdateCalories(WorkoutTypeRunning, s, 70)
+	assert.Equal(t, CaloriesSourceHeartRate, m.CaloriesSource)
+	assert.InDelta(t, metCalories(s.heartRateMET(150), 70, time.Minute), m.Calories, 0.01)
+
+	// The estimate is kept when the points are not loaded
+	c := m.Calories
+	m.Details = nil
+	m.UpdateCalories(WorkoutTypeRunning, s, 70)
+	assert.Equal(t, CaloriesSourceHeartRate, m.CaloriesSource)
+	assert.InDelta(t, c, m.Calories, 0.01)
+
+	// A value from the device is never overwritten
+	m.Calories, m.CaloriesSource = 432, CaloriesSourceDevice
+	m.UpdateCalories(WorkoutTypeRunning, s, 70)
+	assert.InDelta(t, 432, m.Calories, 0.01)
+}
+
+func TestHeartRateSettings_HeartRateMET(t *testing.T) {
+	s := HeartRateSettings{}
+
+	assert.InDelta(t, 1, s.heartRateMET(0), 0.01)
+	assert.Less(t, s.heartRateMET(120), s.heartRateMET(160))
+	assert.InDelta(t, s.heartRateMET(float64(s.MaxOrDefault())), s.heartRateMET(250), 0.01)
+}
+
+func TestWorkout_Calories(t *testing.T) {
+	db := createMemoryDB(t)
+
+	u := defaultUser()
+	require.NoError(t, u.Create(db))
+
+	d := time.Now().UTC().AddDate(0, 0, -10)
+
+	w := &Workout{UserID: u.ID, Name: "run", Type: WorkoutTypeRunning, Date: &d, Data: &MapData{TotalDuration: time.Hour}}
+	require.NoError(t, w.Create(db))
+	assert.InDelta(t, 9.8*DefaultBodyWeight, w.Data.Calories, 0.01)
+
+	// A measured weight is used once it is known
+	require.NoError(t, (&Measurement{UserID: u.ID, Date: d.AddDate(0, 0, -1), Weight: ptr(80)}).Save(db))
+	require.NoError(t, w.Save(db))
+	assert.InDelta(t, 9.8*80, w.Data.Calories, 0.01)
+
+	d2 := d.AddDate(0, 0, 1)
+	w2 := &Workout{UserID: u.ID, Name: "run", Type: WorkoutTypeRunning, Date: &d2, Data: &MapData{TotalDuration: 30 * time.Minute}}
+	require.NoError(t, w2.Create(db))
+
+	u, err := GetUserByID(db, int(u.ID))
+	require.NoError(t, err)
+
+	stats, err := u.GetStatistics(StatConfig{Since: "3 months", Per: "month"})
+	require.NoError(t, err)
+
+	total := 0.0
+	for _, b := range stats.Buckets[WorkoutTypeRunning] {
+		total += b.Calories
+	}
+
+	assert.InDelta(t, 9.8*80*1.5, total, 0.01)
+}
@@ -0,0 +1,538 @@
+package database
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/tkrajina/gpxgo/gpx"
+	"gorm.io/gorm"
+)
+
+const (
+	// climbSmoothingDistance is the distance before and after a point over
+	// which the elevation is averaged, in meters
+	climbSmoothingDistance = 50.0
+	// climbMaxDip is the largest descent (in meters) within a climb; a longer
+	// descent ends the climb
+	climbMaxDip = 15.0
+	// climbMinLength is the minimum length of a climb, in meters
+	climbMinLength = 500.0
+	// climbMinGradient is the minimum average gradient of a climb, in percent
+	climbMinGradient = 3.0
+	// climbEdgeGradient is the gradient (in percent) below which the flat
+	// start and end of a climb are trimmed
+	climbEdgeGradient = 2.0
+	// climbGradientWindow is the distance over which the gradient is
+	// measured, for trimming and for the maximum gradient, in meters
+	climbGradientWindow = 100.0
+	// climbTrackPoints is the number of points the polyline of a climb is
+	// reduced to
+	climbTrackPoints = 100
+	// ClimbMatchRadius is how close (in meters) the start and the end of two
+	// climbs must be, for them to be ascents of the same hill
+	ClimbMatchRadius = 250.0
+)
+
+var ErrInvalidClimbSettings = errors.New("invalid climb settings")
+
+// ClimbCategory is the difficulty of a climb, from Cat 4 (the easiest) to HC
+// (hors catégorie, the hardest)
+type ClimbCategory int
+
+const (
+	ClimbCategoryNone ClimbCategory = iota // Not categorised
+	ClimbCategory4                         // Cat 4
+	ClimbCategory3                         // Cat 3
+	ClimbCategory2                         // Cat 2
+	ClimbCategory1                         // Cat 1
+	ClimbCategoryHC                        // Hors catégorie
+)
+
+// ClimbCategories returns the categories, from the easiest to the hardest
+func ClimbCategories() []ClimbCategory {
+	return []ClimbCategory{ClimbCategory4, ClimbCategory3, ClimbCategory2, ClimbCategory1, ClimbCategoryHC}
+}
+
+func (c ClimbCategory) String() string {
+	switch c {
+	case ClimbCategory4:
+		return "Cat 4"
+	case ClimbCategory3:
+		return "Cat 3"
+	case ClimbCategory2:
+		return "Cat 2"
+	case ClimbCategory1:
+		return "Cat 1"
+	case ClimbCategoryHC:
+		return "HC"
+	default:
+		return ""
+	}
+}
+
+// ClimbScoring is the scheme that scores a climb, to categorise it
+type ClimbScoring string
+
+const (
+	// ClimbScoringGradient scores a climb by its length (in meters) times its
+	// average gradient (in percent)
+	ClimbScoringGradient ClimbScoring = "gradient"
+	// ClimbScoringFiets scores a climb by the FIETS index: the square of the
+	// elevation gain divided by ten times the length, plus the altitude of
+	// the top above 1000 meters in kilometers
+	ClimbScoringFiets ClimbScoring = "fiets"
+)
+
+func (s ClimbScoring) String() string {
+	return string(s)
+}
+
+// defaultClimbThresholds are the minimum scores of Cat 4 to HC, per scoring
+// scheme
+var defaultClimbThresholds = map[ClimbScoring][5]float64{
+	ClimbScoringGradient: {8000, 16000, 32000, 64000, 80000},
+	ClimbScoringFiets:    {0.5, 1, 2, 4, 6.5},
+}
+
+// ClimbScorings returns the known scoring schemes
+func ClimbScorings() []ClimbScoring {
+	return []ClimbScoring{ClimbScoringGradient, ClimbScoringFiets}
+}
+
+// ClimbSettings configures how detected climbs are scored and categorised;
+// zero values fall back to the defaults of the scoring scheme
+type ClimbSettings struct {
+	Scoring       ClimbScoring `form:"climb_scoring" json:"scoring"`         // The scoring scheme
+	Category4     float64      `form:"climb_category_4" json:"category_4"`   // The minimum score of a Cat 4 climb
+	Category3     float64      `form:"climb_category_3" json:"category_3"`   // The minimum score of a Cat 3 climb
+	Category2     float64      `form:"climb_category_2" json:"category_2"`   // The minimum score of a Cat 2 climb
+	Category1     float64      `form:"climb_category_1" json:"category_1"`   // The minimum score of a Cat 1 climb
+	HorsCategorie float64      `form:"climb_category_hc" json:"category_hc"` // The minimum score of an HC climb
+}
+
+func (s ClimbSettings) ScoringOrDefault() ClimbScoring {
+	if s.Scoring == "" {
+		return ClimbScoringGradient
+	}
+
+	return s.Scoring
+}
+
+// Thresholds returns the minimum scores of Cat 4 to HC
+func (s ClimbSettings) Thresholds() [5]float64 {
+	t := defaultClimbThresholds[s.ScoringOrDefault()]
+
+	for i, v := range []float64{s.Category4, s.Category3, s.Category2, s.Category1, s.HorsCategorie} {
+		if v > 0 {
+			t[i] = v
+		}
+	}
+
+	return t
+}
+
+// Validate checks that the scoring scheme is known, and that the thresholds
+// are not negative and ascend from Cat 4 to HC
+func (s ClimbSettings) Validate() error {
+	if s.Scoring != "" && !slices.Contains(ClimbScorings(), s.Scoring) {
+		return ErrInvalidClimbSettings
+	}
+
+	if s.Category4 < 0 || s.Category3 < 0 || s.Category2 < 0 || s.Category1 < 0 || s.HorsCategorie < 0 {
+		return ErrInvalidClimbSettings
+	}
+
+	t := s.Thresholds()
+	for i := 1; i < len(t); i++ {
+		if t[i] <= t[i-1] {
+			return ErrInvalidClimbSettings
+		}
+	}
+
+	return nil
+}
+
+// Score returns the score of a climb by the scoring scheme
+func (s ClimbSettings) Score(length, gain, top float64) float64 {
+	if length <= 0 {
+		return 0
+	}
+
+	if s.ScoringOrDefault() == ClimbScoringFiets {
+		return gain*gain/(length*10) + max(0, (top-1000)/1000)
+	}
+
+	return length * (gain / length * 100)
+}
+
+// Category returns the category of a climb with the score
+func (s ClimbSettings) Category(score float64) ClimbCategory {
+	c := ClimbCategoryNone
+
+	for i, t := range s.Thresholds() {
+		if score >= t {
+			c = ClimbCategories()[i]
+		}
+	}
+
+	return c
+}
+
+// Climb is a categorised climb in the track of a workout
+type Climb struct {
+	gorm.Model
+	MapDataID       uint          `gorm:"not null;index" json:"-"` // The ID of the map data this climb belongs to
+	Number          int           // The number of the climb in the workout, starting at 1
+	Category        ClimbCategory `gorm:"index"` // The category of the climb
+	Score           float64       // The score of the climb by the user's scoring scheme
+	Start           time.Time     // The time the climb started
+	StartDistance   float64       // The distance of the workout at the start of the climb, in meters
+	Length          float64       // The length of the climb, in meters
+	Gain            float64       // The elevation gain of the climb, in meters
+	TopElevation    float64       // The elevation of the top of the climb, in meters
+	AverageGradient float64       // The average gradient of the climb, in percent
+	MaxGradient     float64       // The steepest gradient over 100 meters of the climb, in percent
+	Duration        time.Duration // The elapsed time of the climb
+	Points          []MapCenter   `gorm:"serializer:json" json:",omitempty"` // The polyline of the climb, from the start to the top
+}
+
+// VAM returns the vertical ascent speed of the climb, in meters per hour
+func (c *Climb) VAM() float64 {
+	if c.Duration <= 0 {
+		return 0
+	}
+
+	return c.Gain / c.Duration.Hours()
+}
+
+func (c *Climb) AverageSpeed() float64 {
+	if c.Duration <= 0 {
+		return 0
+	}
+
+	return c.Length / c.Duration.Seconds()
+}
+
+// StartPoint returns the position of the start of the climb
+func (c *Climb) StartPoint() MapCenter {
+	if len(c.Points) == 0 {
+		return MapCenter{}
+	}
+
+	return c.Points[0]
+}
+
+// EndPoint returns the position of the top of the climb
+func (c *Climb) EndPoint() MapCenter {
+	if len(c.Points) == 0 {
+		return MapCenter{}
+	}
+
+	return c.Points[len(c.Points)-1]
+}
+
+// SameHill returns whether both climbs start and end at the same place
+func (c *Climb) SameHill(other *Climb) bool {
+	if len(c.Points) == 0 || len(other.Points) == 0 {
+		return false
+	}
+
+	s, os := c.StartPoint(), other.StartPoint()
+	e, oe := c.EndPoint(), other.EndPoint()
+
+	return gpx.HaversineDistance(s.Lat, s.Lng, os.Lat, os.Lng) <= ClimbMatchRadius &&
+		gpx.HaversineDistance(e.Lat, e.Lng, oe.Lat, oe.Lng) <= ClimbMatchRadius
+}
+
+// elevationPoint is a point of the track with a position and an elevation
+type elevationPoint struct {
+	point     *MapPoint
+	elevation float64 // The smoothed elevation, in meters
+}
+
+// smoothedElevation returns the points with a position and an elevation, with
+// the elevation averaged over the surrounding distance
+func smoothedElevation(points []MapPoint) []elevationPoint {
+	var r []elevationPoint
+
+	for i := range points {
+		e, ok := points[i].ExtraMetrics["elevation"]
+		if !ok || math.IsNaN(e) || !points[i].HasPosition() {
+			continue
+		}
+
+		r = append(r, elevationPoint{point: &points[i], elevation: e})
+	}
+
+	raw := make([]float64, len(r))
+	for i := range r {
+		raw[i] = r[i].elevation
+	}
+
+	from, to, sum := 0, 0, 0.0
+
+	for i := range r {
+		d := r[i].point.TotalDistance
+
+		for to < len(r) && r[to].point.TotalDistance <= d+climbSmoothingDistance {
+			sum += raw[to]
+			to++
+		}
+
+		for r[from].point.TotalDistance < d-climbSmoothingDistance {
+			sum -= raw[from]
+			from++
+		}
+
+		r[i].elevation = sum / float64(to-from)
+	}
+
+	return r
+}
+
+// gradientAfter returns the gradient (in percent) from the point over the
+// gradient window, or to the last point if that is closer
+func gradientAfter(points []elevationPoint, from, last int) float64 {
+	j := from
+	for j < last && points[j].point.TotalDistance-points[from].point.TotalDistance < climbGradientWindow {
+		j++
+	}
+
+	return gradient(points, from, j)
+}
+
+// gradientBefore returns the gradient (in percent) to the point over the
+// gradient window, or from the first point if that is closer
+func gradientBefore(points []elevationPoint, first, to int) float64 {
+	i := to
+	for i > first && points[to].point.TotalDistance-points[i].point.TotalDistance < climbGradientWindow {
+		i--
+	}
+
+	return gradient(points, i, to)
+}
+
+func gradient(points []elevationPoint, from, to int) float64 {
+	d := points[to].point.TotalDistance - points[from].point.TotalDistance
+	if d <= 0 {
+		return 0
+	}
+
+	return (points[to].elevation - points[from].elevation) / d * 100
+}
+
+// UpdateClimbs detects the climbs in the elevation of the points, and
+// categorises them with the settings; climbs that don't reach Cat 4 are left
+// out
+func (m *MapData) UpdateClimbs(s ClimbSettings) {
+	m.Climbs = nil
+
+	if m.Details == nil {
+		return
+	}
+
+	points := smoothedElevation(m.Details.Points)
+	if len(points) < 2 {
+		return
+	}
+
+	low, top := 0, 0
+
+	for i := 1; i < len(points); i++ {
+		switch {
+		case points[i].elevation >= points[top].elevation:
+			top = i
+		case points[top].elevation-points[i].elevation > climbMaxDip || points[i].elevation < points[low].elevation:
+			m.addClimb(points, low, top, s)
+			low, top = i, i
+		}
+	}
+
+	m.addClimb(points, low, top, s)
+}
+
+// addClimb adds the climb between the points, after trimming its flat start
+// and end, if it is long and steep enough to be categorised
+func (m *MapData) addClimb(points []elevationPoint, low, top int, s ClimbSettings) {
+	for low < top && gradientAfter(points, low, top) < climbEdgeGradient {
+		low++
+	}
+
+	for top > low && gradientBefore(points, low, top) < climbEdgeGradient {
+		top--
+	}
+
+	start, end := points[low].point, points[top].point
+	length := end.TotalDistance - start.TotalDistance
+	gain := points[top].elevation - points[low].elevation
+
+	if length < climbMinLength || gain/length*100 < climbMinGradient {
+		return
+	}
+
+	score := s.Score(length, gain, points[top].elevation)
+
+	category := s.Category(score)
+	if category == ClimbCategoryNone {
+		return
+	}
+
+	c := Climb{
+		Number:          len(m.Climbs) + 1,
+		Category:        category,
+		Score:           score,
+		Start:           start.Time,
+		StartDistance:   start.TotalDistance,
+		Length:          length,
+		Gain:            gain,
+		TopElevation:    points[top].elevation,
+		AverageGradient: gain / length * 100,
+		MaxGradient:     gain / length * 100,
+		Duration:        end.TotalDuration - start.TotalDuration,
+	}
+
+	for i := low; i <= top; i++ {
+		c.MaxGradient = max(c.MaxGradient, gradientAfter(points, i, top))
+	}
+
+	step := max(1, (top-low+climbTrackPoints-2)/(climbTrackPoints-1))
+
+	for i := low; i <= top; i += step {
+		c.Points = append(c.Points, MapCenter{Lat: points[i].point.Lat, Lng: points[i].point.Lng})
+	}
+
+	if (top-low)%step != 0 {
+		c.Points = append(c.Points, MapCenter{Lat: end.Lat, Lng: end.Lng})
+	}
+
+	m.Climbs = append(m.Climbs, c)
+}
+
+// deleteClimbs removes the stored climbs of the map data, before they are
+// replaced
+func (m *MapData) deleteClimbs(db *gorm.DB) error {
+	if m.ID == 0 {
+		return nil
+	}
+
+	return db.Unscoped().Where(&Climb{MapDataID: m.ID}).Delete(&Climb{}).Error
+}
+
+// ClimbAscent is a climb of a workout of the user, with the workout it was
+// climbed in
+type ClimbAscent struct {
+	Climb
+	WorkoutID   uint        // The ID of the workout
+	WorkoutName string      // The name of the workout
+	WorkoutType WorkoutType // The type of the workout
+	Date        *time.Time  // The date of the workout
+	Location    string      // The generic location of the workout
+}
+
+// ClimbHill is a hill the user climbed, with all ascents, most recent first
+type ClimbHill struct {
+	Ascents []ClimbAscent // The ascents of the hill
+}
+
+// Latest returns the most recent ascent of the hill
+func (h *ClimbHill) Latest() *ClimbAscent {
+	return &h.Ascents[0]
+}
+
+// Fastest returns the ascent of the hill with the shortest duration
+func (h *ClimbHill) Fastest() *ClimbAscent {
+	f := &h.Ascents[0]
+
+	for i := range h.Ascents {
+		if h.Ascents[i].Duration < f.Duration {
+			f = &h.Ascents[i]
+		}
+	}
+
+	return f
+}
+
+// IsFastest returns whether the climb with the ID is the fastest ascent of
+// the hill
+func (h *ClimbHill) IsFastest(id uint) bool {
+	return h.Fastest().ID == id
+}
+
+// climbAscents returns the query of the climbs of the user's workouts, with
+// their workout
+func (u *User) climbAscents(db *gorm.DB) *gorm.DB {
+	return db.
+		Table("climbs").
+		Joins("join map_data on map_data.id = climbs.map_data_id").
+		Joins("join workouts on workouts.id = map_data.workout_id").
+		Where("workouts.user_id = ?", u.ID).
+		Where("climbs.deleted_at IS NULL AND map_data.deleted_at IS NULL AND workouts.deleted_at IS NULL").
+		Select(
+			"climbs.*",
+			"workouts.id as workout_id",
+			"workouts.name as workout_name",
+			"workouts.type as workout_type",
+			"workouts.date as date",
+			"map_data.address_string as location",
+		)
+}
+
+// GetClimbs returns the climbs of the user's workouts of at least the
+// category, grouped per hill; the most recently climbed hill comes first
+func (u *User) GetClimbs(db *gorm.DB, category ClimbCategory) ([]*ClimbHill, error) {
+	var ascents []ClimbAscent
+
+	if err := u.climbAscents(db).
+		Where("climbs.category >= ?", category).
+		Order("workouts.date DESC, climbs.number").
+		Find(&ascents).Error; err != nil {
+		return nil, err
+	}
+
+	var hills []*ClimbHill
+
+	for _, a := range ascents {
+		i := slices.IndexFunc(hills, func(h *ClimbHill) bool {
+			return h.Latest().SameHill(&a.Climb)
+		})
+
+		if i < 0 {
+			hills = append(hills, &ClimbHill{})
+			i = len(hills) - 1
+		}
+
+		hills[i].Ascents = append(hills[i].Ascents, a)
+	}
+
+	return hills, nil
+}
+
+// GetClimb returns the climb of the user's workouts with the ID, and all
+// ascents of the same hill by the user, most recent first
+func (u *User) GetClimb(db *gorm.DB, id int) (*ClimbAscent, *ClimbHill, error) {
+	var c ClimbAscent
+
+	if err := u.climbAscents(db).Where("climbs.id = ?", id).Take(&c).Error; err != nil {
+		return nil, nil, err
+	}
+
+	var all []ClimbAscent
+
+	if err := u.climbAscents(db).
+		Order("workouts.date DESC, climbs.number").
+		Find(&all).Error; err != nil {
+		return nil, nil, err
+	}
+
+	h := &ClimbHill{}
+
+	for _, a := range all {
+		if a.ID == c.ID || c.SameHill(&a.Climb) {
+			h.Ascents = append(h.Ascents, a)
+		}
+	}
+
+	return &c, h, nil
+}
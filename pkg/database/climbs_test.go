@@ -0,0 +1,132 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tkrajina/gpxgo/gpx"
+	"gorm.io/gorm"
+)
+
+// hillTrack returns a track going north at 5 m/s for 5 km: 1 km flat at 100
+// meters, 2 km climbing at the gradient (in percent), then 2 km flat
+func hillTrack(gradient float64) *gpx.GPX {
+	g := straightTrack(1001, 5, nil)
+
+	for i := range g.Tracks[0].Segments[0].Points {
+		d := float64(i) * 5
+		climbed := min(max(d-1000, 0), 2000)
+
+		g.Tracks[0].Segments[0].Points[i].Elevation = *gpx.NewNullableFloat64(100 + climbed*gradient/100)
+	}
+
+	return g
+}
+
+func addHill(t *testing.T, db *gorm.DB, u *User, gradient float64, delay time.Duration) *Workout {
+	t.Helper()
+
+	g := hillTrack(gradient)
+	for i := range g.Tracks[0].Segments[0].Points {
+		g.Tracks[0].Segments[0].Points[i].Timestamp = g.Tracks[0].Segments[0].Points[i].Timestamp.Add(delay)
+	}
+
+	content, err := g.ToXml(gpx.ToXmlParams{Version: "1.1"})
+	require.NoError(t, err)
+
+	w, err := u.AddWorkout(db, WorkoutTypeCycling, "", "hill.gpx", content)
+	require.NoError(t, err)
+
+	return w
+}
+
+func TestMapData_UpdateClimbs(t *testing.T) {
+	m := gpxAsMapData(hillTrack(6))
+	m.UpdateClimbs(ClimbSettings{})
+
+	require.Len(t, m.Climbs, 1)
+
+	c := m.Climbs[0]
+	assert.Equal(t, 1, c.Number)
+	assert.Equal(t, ClimbCategory4, c.Category)
+	assert.InDelta(t, 1000, c.StartDistance, 100)
+	assert.InDelta(t, 2000, c.Length, 150)
+	assert.InDelta(t, 120, c.Gain, 5)
+	assert.InDelta(t, 6, c.AverageGradient, 0.5)
+	assert.InDelta(t, 6, c.MaxGradient, 0.5)
+	assert.InDelta(t, 220, c.TopElevation, 5)
+	assert.InDelta(t, 400, c.Duration.Seconds(), 30)
+	assert.InDelta(t, 1050, c.VAM(), 80)
+	assert.LessOrEqual(t, len(c.Points), climbTrackPoints+1)
+	assert.InDelta(t, m.Details.Points[200].Lat, c.StartPoint().Lat, 0.001)
+
+	// Too gentle to be a climb
+	m = gpxAsMapData(hillTrack(2))
+	m.UpdateClimbs(ClimbSettings{})
+	assert.Empty(t, m.Climbs)
+
+	// The same hill is harder with lower thresholds
+	m = gpxAsMapData(hillTrack(6))
+	m.UpdateClimbs(ClimbSettings{Category3: 10000, Category2: 11000, Category1: 12500, HorsCategorie: 20000})
+	require.Len(t, m.Climbs, 1)
+	assert.Equal(t, ClimbCategory2, m.Climbs[0].Category)
+}
+
+func TestClimbSettings(t *testing.T) {
+	s := ClimbSettings{}
+	require.NoError(t, s.Validate())
+	assert.Equal(t, ClimbScoringGradient, s.ScoringOrDefault())
+	assert.Equal(t, ClimbCategoryNone, s.Category(7999))
+	assert.Equal(t, ClimbCategory4, s.Category(8000))
+	assert.Equal(t, ClimbCategoryHC, s.Category(100000))
+	assert.InDelta(t, 12000, s.Score(2000, 120, 220), 0.01)
+
+	s = ClimbSettings{Scoring: ClimbScoringFiets}
+	require.NoError(t, s.Validate())
+	assert.InDelta(t, 0.72, s.Score(2000, 120, 220), 0.01)
+	assert.InDelta(t, 1.72, s.Score(2000, 120, 2000), 0.01)
+	assert.Equal(t, ClimbCategory3, s.Category(1.72))
+
+	require.ErrorIs(t, ClimbSettings{Scoring: "steepness"}.Validate(), ErrInvalidClimbSettings)
+	require.ErrorIs(t, ClimbSettings{Category4: -1}.Validate(), ErrInvalidClimbSettings)
+	require.ErrorIs(t, ClimbSettings{Category3: 90000}.Validate(), ErrInvalidClimbSettings)
+}
+
+func TestUser_GetClimbs(t *testing.T) {
+	db := createMemoryDB(t)
+
+	u := defaultUser()
+	require.NoError(t, u.Create(db))
+
+	first := addHill(t, db, u, 6, 0)
+	addHill(t, db, u, 6, 24*time.Hour)
+	addHill(t, db, u, 2, 48*time.Hour)
+
+	w, err := GetWorkoutDetails(db.Preload("Data.Climbs"), int(first.ID))
+	require.NoError(t, err)
+	require.Len(t, w.Data.Climbs, 1)
+
+	hills, err := u.GetClimbs(db, ClimbCategory4)
+	require.NoError(t, err)
+	require.Len(t, hills, 1)
+	require.Len(t, hills[0].Ascents, 2)
+	assert.Equal(t, first.ID, hills[0].Ascents[1].WorkoutID)
+	assert.True(t, hills[0].Latest().Date.After(*hills[0].Ascents[1].Date))
+
+	hills, err = u.GetClimbs(db, ClimbCategory3)
+	require.NoError(t, err)
+	assert.Empty(t, hills)
+
+	c, hill, err := u.GetClimb(db, int(w.Data.Climbs[0].ID))
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, c.WorkoutID)
+	assert.Len(t, hill.Ascents, 2)
+
+	other := &User{Username: "other-user", Password: "other-password", Name: "other"}
+	require.NoError(t, other.Create(db))
+
+	_, _, err = other.GetClimb(db, int(c.ID))
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+}
@@ -23,6 +23,7 @@ type Config struct {
 	JWTEncryptionKey string `mapstructure:"jwt_encryption_key" gorm:"-"`
 	DatabaseDriver   string `mapstructure:"database_driver" gorm:"-"`
 	DSN              string `mapstructure:"dsn" gorm:"-"`
+	ElevationTiles   string `mapstructure:"elevation_tiles" gorm:"-"` // The directory with DEM tiles (HGT or GeoTIFF) to correct elevation
 }
 
 func getConfig(db *gorm.DB) (*Config, error) {
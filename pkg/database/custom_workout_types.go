@@ -0,0 +1,416 @@
+package database
+
+import (
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/jovandeginste/workout-tracker/pkg/templatehelpers"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidWorkoutType = errors.New("invalid workout type")
+	ErrWorkoutTypeExists  = errors.New("workout type already exists")
+	ErrWorkoutTypeInUse   = errors.New("workout type is still used by workouts")
+	ErrMappingExists      = errors.New("sport name is already mapped")
+)
+
+// CustomWorkoutType is a workout type that is defined in the database, either
+// by an admin for the whole instance, or by a user for themselves
+type CustomWorkoutType struct {
+	gorm.Model
+	UserID     *uint             `gorm:"index" json:"-"`                         // The ID of the user who defined the type; empty for instance-wide types
+	Name       WorkoutType       `gorm:"not null;index" form:"name" json:"name"` // The key of the type, eg. "rowing"
+	Icon       string            `form:"icon" json:"icon"`                       // The icon of the type, one of templatehelpers.SportIcons
+	Labels     map[string]string `gorm:"serializer:json" json:"labels"`          // The label of the type, per language
+	Location   bool              `form:"location" json:"location"`               // Whether workouts of this type have a track
+	Distance   bool              `form:"distance" json:"distance"`               // Whether workouts of this type have a distance
+	Repetition bool              `form:"repetition" json:"repetition"`           // Whether workouts of this type have repetitions
+	Weight     bool              `form:"weight" json:"weight"`                   // Whether workouts of this type have a weight
+	User       *User             `json:"-"`                                      // The user who defined the type
+}
+
+// WorkoutTypeMapping maps a sport name, as found in GPX or FIT files, to a
+// workout type when the type is auto-detected
+type WorkoutTypeMapping struct {
+	gorm.Model
+	UserID    *uint       `gorm:"index" json:"-"`                               // The ID of the user who defined the mapping; empty for instance-wide mappings
+	SportName string      `gorm:"not null" form:"sport_name" json:"sport_name"` // The sport name in the file, case insensitive
+	Type      WorkoutType `gorm:"not null" form:"type" json:"type"`             // The workout type to use
+	User      *User       `json:"-"`                                            // The user who defined the mapping
+}
+
+func (ct *CustomWorkoutType) Configuration() WorkoutTypeConfiguration {
+	return WorkoutTypeConfiguration{
+		Location:   ct.Location,
+		Distance:   ct.Distance,
+		Repetition: ct.Repetition,
+		Weight:     ct.Weight,
+	}
+}
+
+// IsPersonal returns whether the type was defined by a user for themselves
+func (ct *CustomWorkoutType) IsPersonal() bool {
+	return ct.UserID != nil
+}
+
+// normalize cleans up the name and makes the classes consistent with the
+// built-in types: a track implies a distance and a weight implies repetitions
+func (ct *CustomWorkoutType) normalize() {
+	ct.Name = WorkoutType(strings.ToLower(strings.TrimSpace(ct.Name.String())))
+	ct.Distance = ct.Distance || ct.Location
+	ct.Repetition = ct.Repetition || ct.Weight
+
+	for lang, label := range ct.Labels {
+		if strings.TrimSpace(label) == "" {
+			delete(ct.Labels, lang)
+		}
+	}
+}
+
+func (ct *CustomWorkoutType) Validate() error {
+	if ct.Name == "" || ct.Name == WorkoutTypeAutoDetect || ct.Name.IsBuiltin() {
+		return ErrInvalidWorkoutType
+	}
+
+	if ct.Icon != "" && !slices.Contains(templatehelpers.SportIcons(), ct.Icon) {
+		return ErrInvalidWorkoutType
+	}
+
+	return nil
+}
+
+func (ct *CustomWorkoutType) Save(db *gorm.DB) error {
+	ct.normalize()
+
+	if err := ct.Validate(); err != nil {
+		return err
+	}
+
+	// Users may each define a personal type with the same name, but no name
+	// can be both an instance-wide type and a personal type
+	q := db.Model(&CustomWorkoutType{}).Where("name = ? AND id <> ?", ct.Name, ct.ID)
+	if ct.UserID != nil {
+		q = q.Where("user_id IS NULL OR user_id = ?", *ct.UserID)
+	}
+
+	var count int64
+
+	if err := q.Count(&count).Error; err != nil {
+		return err
+	}
+
+	if count > 0 {
+		return ErrWorkoutTypeExists
+	}
+
+	if err := db.Save(ct).Error; err != nil {
+		return err
+	}
+
+	return LoadWorkoutTypes(db)
+}
+
+// Delete removes the type, unless workouts still use it; a personal type is
+// only checked against the workouts of its user
+func (ct *CustomWorkoutType) Delete(db *gorm.DB) error {
+	q := db.Model(&Workout{}).Where(&Workout{Type: ct.Name})
+	if ct.UserID != nil {
+		q = q.Where(&Workout{UserID: *ct.UserID})
+	}
+
+	var count int64
+
+	if err := q.Count(&count).Error; err != nil {
+		return err
+	}
+
+	if count > 0 {
+		return ErrWorkoutTypeInUse
+	}
+
+	if err := db.Unscoped().Delete(ct).Error; err != nil {
+		return err
+	}
+
+	return LoadWorkoutTypes(db)
+}
+
+func (m *WorkoutTypeMapping) Save(db *gorm.DB) error {
+	m.SportName = strings.ToLower(strings.TrimSpace(m.SportName))
+
+	if m.SportName == "" || !m.Type.IsValid() {
+		return ErrInvalidWorkoutType
+	}
+
+	q := db.Model(&WorkoutTypeMapping{}).Where("sport_name = ? AND id <> ?", m.SportName, m.ID)
+	if m.UserID == nil {
+		q = q.Where("user_id IS NULL")
+	} else {
+		q = q.Where("user_id = ?", *m.UserID)
+	}
+
+	var count int64
+
+	if err := q.Count(&count).Error; err != nil {
+		return err
+	}
+
+	if count > 0 {
+		return ErrMappingExists
+	}
+
+	if err := db.Save(m).Error; err != nil {
+		return err
+	}
+
+	return LoadWorkoutTypes(db)
+}
+
+func (m *WorkoutTypeMapping) Delete(db *gorm.DB) error {
+	if err := db.Unscoped().Delete(m).Error; err != nil {
+		return err
+	}
+
+	return LoadWorkoutTypes(db)
+}
+
+// LoadWorkoutTypes reads the custom workout types and sport name mappings
+// from the database, and resets the collections derived from them
+func LoadWorkoutTypes(db *gorm.DB) error {
+	var (
+		types    []CustomWorkoutType
+		mappings []WorkoutTypeMapping
+	)
+
+	if err := db.Order("name").Order("id").Find(&types).Error; err != nil {
+		return err
+	}
+
+	if err := db.Order("sport_name").Find(&mappings).Error; err != nil {
+		return err
+	}
+
+	workoutTypesLock.Lock()
+	defer workoutTypesLock.Unlock()
+
+	customWorkoutTypes = make(map[WorkoutType][]CustomWorkoutType, len(types))
+	for _, ct := range types {
+		customWorkoutTypes[ct.Name] = append(customWorkoutTypes[ct.Name], ct)
+	}
+
+	workoutTypeMappings = mappings
+	workoutTypes = nil
+	workoutTypesByClass = nil
+
+	return nil
+}
+
+// customWorkoutType returns the custom type with the name, if there is one;
+// when several users defined a personal type with the name, it returns the
+// oldest one
+func customWorkoutType(wt WorkoutType) (CustomWorkoutType, bool) {
+	workoutTypesLock.RLock()
+	defer workoutTypesLock.RUnlock()
+
+	types := customWorkoutTypes[wt]
+	if len(types) == 0 {
+		return CustomWorkoutType{}, false
+	}
+
+	return types[0], true
+}
+
+// CustomIcon returns the icon of a custom workout type; it is empty for
+// built-in types and custom types without an icon
+func (wt WorkoutType) CustomIcon() string {
+	ct, ok := customWorkoutType(wt)
+	if !ok {
+		return ""
+	}
+
+	return ct.Icon
+}
+
+// Label returns the label of a custom workout type in the language, falling
+// back to English; ok is false if there is no such label
+func (wt WorkoutType) Label(lang string) (string, bool) {
+	ct, ok := customWorkoutType(wt)
+	if !ok {
+		return "", false
+	}
+
+	if l, ok := ct.Labels[lang]; ok {
+		return l, true
+	}
+
+	if base, _, found := strings.Cut(lang, "-"); found {
+		if l, ok := ct.Labels[base]; ok {
+			return l, true
+		}
+	}
+
+	l, ok := ct.Labels["en"]
+
+	return l, ok
+}
+
+// availableTo returns whether the user may use the workout type; built-in and
+// instance-wide types are available to everyone, personal types only to the
+// user who defined them
+func (wt WorkoutType) availableTo(u *User) bool {
+	workoutTypesLock.RLock()
+	defer workoutTypesLock.RUnlock()
+
+	types, ok := customWorkoutTypes[wt]
+	if !ok {
+		return wt.IsBuiltin()
+	}
+
+	for _, ct := range types {
+		if ct.UserID == nil || (u != nil && *ct.UserID == u.ID) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// WorkoutTypes returns the workout types the user can pick: the built-in
+// types, the instance-wide types and the user's own types
+func (u *User) WorkoutTypes() []WorkoutType {
+	r := []WorkoutType{}
+
+	for _, wt := range WorkoutTypes() {
+		if wt.availableTo(u) {
+			r = append(r, wt)
+		}
+	}
+
+	return r
+}
+
+// CanUseWorkoutType returns whether the workout type is valid for the user
+func (u *User) CanUseWorkoutType(wt WorkoutType) bool {
+	return wt.IsValid() && wt.availableTo(u)
+}
+
+// GetCustomWorkoutTypes returns the instance-wide custom workout types
+func GetCustomWorkoutTypes(db *gorm.DB) ([]CustomWorkoutType, error) {
+	var t []CustomWorkoutType
+
+	if err := db.Where("user_id IS NULL").Order("name").Find(&t).Error; err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+func GetCustomWorkoutType(db *gorm.DB, id int) (*CustomWorkoutType, error) {
+	var t CustomWorkoutType
+
+	if err := db.Where("user_id IS NULL").First(&t, id).Error; err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+// GetCustomWorkoutTypes returns the user's personal workout types
+func (u *User) GetCustomWorkoutTypes(db *gorm.DB) ([]CustomWorkoutType, error) {
+	var t []CustomWorkoutType
+
+	if err := db.Where("user_id = ?", u.ID).Order("name").Find(&t).Error; err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+func (u *User) GetCustomWorkoutType(db *gorm.DB, id int) (*CustomWorkoutType, error) {
+	var t CustomWorkoutType
+
+	if err := db.Where("user_id = ?", u.ID).First(&t, id).Error; err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+// GetWorkoutTypeMappings returns the instance-wide sport name mappings
+func GetWorkoutTypeMappings(db *gorm.DB) ([]WorkoutTypeMapping, error) {
+	var m []WorkoutTypeMapping
+
+	if err := db.Where("user_id IS NULL").Order("sport_name").Find(&m).Error; err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+func GetWorkoutTypeMapping(db *gorm.DB, id int) (*WorkoutTypeMapping, error) {
+	var m WorkoutTypeMapping
+
+	if err := db.Where("user_id IS NULL").First(&m, id).Error; err != nil {
+		return nil, err
+	}
+
+	return &m, nil
+}
+
+// GetWorkoutTypeMappings returns the user's personal sport name mappings
+func (u *User) GetWorkoutTypeMappings(db *gorm.DB) ([]WorkoutTypeMapping, error) {
+	var m []WorkoutTypeMapping
+
+	if err := db.Where("user_id = ?", u.ID).Order("sport_name").Find(&m).Error; err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+func (u *User) GetWorkoutTypeMapping(db *gorm.DB, id int) (*WorkoutTypeMapping, error) {
+	var m WorkoutTypeMapping
+
+	if err := db.Where("user_id = ?", u.ID).First(&m, id).Error; err != nil {
+		return nil, err
+	}
+
+	return &m, nil
+}
+
+// workoutTypeFromSportName returns the workout type for a sport name found in
+// a file; the user's own mappings win over the instance-wide mappings, which
+// win over custom types with that name and the built-in names
+func (u *User) workoutTypeFromSportName(name string) (WorkoutType, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return WorkoutTypeAutoDetect, false
+	}
+
+	var personal, instance WorkoutType
+
+	workoutTypesLock.RLock()
+	for _, m := range workoutTypeMappings {
+		if m.SportName != name {
+			continue
+		}
+
+		switch {
+		case m.UserID == nil:
+			instance = m.Type
+		case u != nil && *m.UserID == u.ID:
+			personal = m.Type
+		}
+	}
+	workoutTypesLock.RUnlock()
+
+	for _, wt := range []WorkoutType{personal, instance, WorkoutType(name)} {
+		if wt != "" && u.CanUseWorkoutType(wt) {
+			return wt, true
+		}
+	}
+
+	return workoutTypeFromGpxTrackType(name)
+}
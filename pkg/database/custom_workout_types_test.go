@@ -0,0 +1,143 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCustomWorkoutType_Save(t *testing.T) {
+	db := createMemoryDB(t)
+
+	ct := &CustomWorkoutType{Name: " Rowing ", Location: true, Icon: "water"}
+	require.NoError(t, ct.Save(db))
+
+	assert.Equal(t, WorkoutType("rowing"), ct.Name)
+	assert.True(t, ct.Distance)
+	assert.True(t, WorkoutType("rowing").IsValid())
+	assert.True(t, WorkoutType("rowing").IsLocation())
+	assert.False(t, WorkoutType("rowing").IsBuiltin())
+	assert.Contains(t, DistanceWorkoutTypes(), WorkoutType("rowing"))
+	assert.NotContains(t, RepetitionWorkoutTypes(), WorkoutType("rowing"))
+	assert.Equal(t, "water", WorkoutType("rowing").CustomIcon())
+
+	assert.ErrorIs(t, (&CustomWorkoutType{Name: "rowing"}).Save(db), ErrWorkoutTypeExists)
+	assert.ErrorIs(t, (&CustomWorkoutType{Name: "running"}).Save(db), ErrInvalidWorkoutType)
+	assert.ErrorIs(t, (&CustomWorkoutType{Name: "auto"}).Save(db), ErrInvalidWorkoutType)
+	assert.ErrorIs(t, (&CustomWorkoutType{Name: "darts", Icon: "nope"}).Save(db), ErrInvalidWorkoutType)
+}
+
+func TestCustomWorkoutType_Delete(t *testing.T) {
+	db := createMemoryDB(t)
+
+	u := defaultUser()
+	require.NoError(t, u.Create(db))
+
+	ct := &CustomWorkoutType{Name: "climbing", Repetition: true}
+	require.NoError(t, ct.Save(db))
+
+	d := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	w := &Workout{UserID: u.ID, Name: "climb", Type: "climbing", Date: &d, Data: &MapData{}}
+	require.NoError(t, w.Create(db))
+
+	assert.ErrorIs(t, ct.Delete(db), ErrWorkoutTypeInUse)
+
+	require.NoError(t, w.Delete(db))
+	require.NoError(t, ct.Delete(db))
+	assert.False(t, WorkoutType("climbing").IsValid())
+}
+
+func TestCustomWorkoutType_Personal(t *testing.T) {
+	db := createMemoryDB(t)
+
+	u1 := defaultUser()
+	require.NoError(t, u1.Create(db))
+
+	u2 := defaultUser()
+	u2.Username = "other"
+	require.NoError(t, u2.Create(db))
+
+	require.NoError(t, (&CustomWorkoutType{Name: "padel", UserID: &u1.ID}).Save(db))
+	require.NoError(t, (&CustomWorkoutType{Name: "curling"}).Save(db))
+
+	assert.Contains(t, u1.WorkoutTypes(), WorkoutType("padel"))
+	assert.Contains(t, u1.WorkoutTypes(), WorkoutType("curling"))
+	assert.Contains(t, u1.WorkoutTypes(), WorkoutTypeRunning)
+	assert.NotContains(t, u2.WorkoutTypes(), WorkoutType("padel"))
+	assert.Contains(t, u2.WorkoutTypes(), WorkoutType("curling"))
+
+	assert.True(t, u1.CanUseWorkoutType("padel"))
+	assert.False(t, u2.CanUseWorkoutType("padel"))
+	assert.False(t, u2.CanUseWorkoutType("unknown"))
+
+	// Personal names are per user, but never clash with instance-wide types
+	require.NoError(t, (&CustomWorkoutType{Name: "padel", UserID: &u2.ID}).Save(db))
+	assert.True(t, u2.CanUseWorkoutType("padel"))
+	assert.ErrorIs(t, (&CustomWorkoutType{Name: "padel", UserID: &u1.ID}).Save(db), ErrWorkoutTypeExists)
+	assert.ErrorIs(t, (&CustomWorkoutType{Name: "curling", UserID: &u1.ID}).Save(db), ErrWorkoutTypeExists)
+	assert.ErrorIs(t, (&CustomWorkoutType{Name: "padel"}).Save(db), ErrWorkoutTypeExists)
+
+	_, err := u1.AddWorkout(db, "padel", "", "padel.gpx", []byte(GpxSample1))
+	require.NoError(t, err)
+
+	_, err = u1.AddWorkout(db, "unknown", "", "unknown.gpx", []byte(GpxSample1))
+	require.ErrorIs(t, err, ErrInvalidWorkoutType)
+}
+
+func TestWorkoutType_Label(t *testing.T) {
+	db := createMemoryDB(t)
+
+	ct := &CustomWorkoutType{Name: "rowing", Labels: map[string]string{"en": "Rowing", "nl": "Roeien", "fr": " "}}
+	require.NoError(t, ct.Save(db))
+
+	l, ok := WorkoutType("rowing").Label("nl")
+	assert.True(t, ok)
+	assert.Equal(t, "Roeien", l)
+
+	l, ok = WorkoutType("rowing").Label("nl-BE")
+	assert.True(t, ok)
+	assert.Equal(t, "Roeien", l)
+
+	l, ok = WorkoutType("rowing").Label("fr")
+	assert.True(t, ok)
+	assert.Equal(t, "Rowing", l)
+
+	_, ok = WorkoutTypeRunning.Label("en")
+	assert.False(t, ok)
+}
+
+func TestUser_WorkoutTypeFromSportName(t *testing.T) {
+	db := createMemoryDB(t)
+
+	u1 := defaultUser()
+	require.NoError(t, u1.Create(db))
+
+	u2 := defaultUser()
+	u2.Username = "other"
+	require.NoError(t, u2.Create(db))
+
+	require.NoError(t, (&CustomWorkoutType{Name: "rowing", Location: true}).Save(db))
+	require.NoError(t, (&CustomWorkoutType{Name: "sup", Location: true, UserID: &u1.ID}).Save(db))
+	require.NoError(t, (&WorkoutTypeMapping{SportName: "Paddling", Type: "rowing"}).Save(db))
+	require.NoError(t, (&WorkoutTypeMapping{SportName: "paddling", Type: "sup", UserID: &u1.ID}).Save(db))
+
+	assert.ErrorIs(t, (&WorkoutTypeMapping{SportName: "PADDLING", Type: "kayaking"}).Save(db), ErrMappingExists)
+
+	wt, ok := u1.workoutTypeFromSportName("paddling")
+	assert.True(t, ok)
+	assert.Equal(t, WorkoutType("sup"), wt)
+
+	wt, ok = u2.workoutTypeFromSportName("paddling")
+	assert.True(t, ok)
+	assert.Equal(t, WorkoutType("rowing"), wt)
+
+	wt, ok = u2.workoutTypeFromSportName("Rowing")
+	assert.True(t, ok)
+	assert.Equal(t, WorkoutType("rowing"), wt)
+
+	wt, ok = u2.workoutTypeFromSportName("running")
+	assert.True(t, ok)
+	assert.Equal(t, WorkoutTypeRunning, wt)
+}
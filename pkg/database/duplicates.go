@@ -0,0 +1,376 @@
+package database
+
+import (
+	"encoding/xml"
+	"errors"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/tkrajina/gpxgo/gpx"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrNotADuplicate             = errors.New("the workout is not marked as a duplicate")
+	ErrInvalidDuplicateSelection = errors.New("invalid way to resolve the duplicate")
+)
+
+const (
+	// duplicateMinOverlap is the part of the shorter workout that has to be at
+	// the same time as the other workout
+	duplicateMinOverlap = 0.8
+	// duplicateMaxDistance is the distance (in meters) between the points of
+	// both workouts at the same time, for the points to match
+	duplicateMaxDistance = 100.0
+	// duplicateMinMatching is the part of the compared points that have to
+	// match, for the tracks to match
+	duplicateMinMatching = 0.8
+	// duplicateSamples is the number of points of a track that are compared
+	// with the other track
+	duplicateSamples = 50
+	// duplicateMaxGap is the largest time between two points of both tracks,
+	// to compare them
+	duplicateMaxGap = 30 * time.Second
+	// mergeMetricsMaxGap is the largest time between two points of both
+	// workouts, to copy the metrics of one to the other
+	mergeMetricsMaxGap = 5 * time.Second
+)
+
+// mergedMetrics are the metrics of sensors that are copied from a duplicate
+// workout
+var mergedMetrics = []string{"heart-rate", "cadence", "power"}
+
+// DuplicateResolution is the way to resolve a workout that duplicates another
+// workout
+type DuplicateResolution string
+
+const (
+	DuplicateKeepThis   DuplicateResolution = "keep-this"   // Delete the other workout
+	DuplicateKeepOther  DuplicateResolution = "keep-other"  // Delete this workout
+	DuplicateMergeThis  DuplicateResolution = "merge-this"  // Copy the metrics of the other workout into this one, and delete the other
+	DuplicateMergeOther DuplicateResolution = "merge-other" // Copy the metrics of this workout into the other one, and delete this one
+	DuplicateExclude    DuplicateResolution = "exclude"     // Keep both, but exclude this workout from the statistics
+	DuplicateDismiss    DuplicateResolution = "dismiss"     // The workouts are not duplicates
+)
+
+func (r DuplicateResolution) IsValid() bool {
+	return slices.Contains([]DuplicateResolution{
+		DuplicateKeepThis, DuplicateKeepOther, DuplicateMergeThis,
+		DuplicateMergeOther, DuplicateExclude, DuplicateDismiss,
+	}, r)
+}
+
+// countedInStatistics is a gorm scope that leaves out the workouts that are
+// excluded from the statistics, totals and records
+func countedInStatistics(db *gorm.DB) *gorm.DB {
+	return db.Where("workouts.exclude_from_statistics = ?", false)
+}
+
+// IsDuplicate returns whether the workout looks like another recording of
+// another workout of the user
+func (w *Workout) IsDuplicate() bool {
+	return w.DuplicateOfID != nil
+}
+
+// timeWindow returns the start and the end of the workout
+func (w *Workout) timeWindow() (time.Time, time.Time) {
+	if w.Date == nil {
+		return time.Time{}, time.Time{}
+	}
+
+	if w.Data == nil {
+		return *w.Date, *w.Date
+	}
+
+	return *w.Date, w.Date.Add(w.Data.TotalDuration)
+}
+
+// overlaps returns whether most of the shorter of both workouts is at the same
+// time as the other workout
+func (w *Workout) overlaps(o *Workout) bool {
+	start, end := w.timeWindow()
+	oStart, oEnd := o.timeWindow()
+
+	shortest := min(end.Sub(start), oEnd.Sub(oStart))
+	if shortest <= 0 {
+		return false
+	}
+
+	overlap := minTime(end, oEnd).Sub(maxTime(start, oStart))
+
+	return overlap.Seconds() >= duplicateMinOverlap*shortest.Seconds()
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+
+	return b
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+
+	return b
+}
+
+// positionedPoints returns the points of the workout with a position; points
+// without a position are stored at (0, 0)
+func positionedPoints(m *MapData) []MapPoint {
+	if m == nil || m.Details == nil {
+		return nil
+	}
+
+	return slices.DeleteFunc(slices.Clone(m.Details.Points), func(p MapPoint) bool {
+		return p.Lat == 0 && p.Lng == 0
+	})
+}
+
+// nearestInTime returns the index of the point closest in time to t, if it is
+// within the gap; the points are ordered by time
+func nearestInTime(n int, at func(int) time.Time, t time.Time, gap time.Duration) (int, bool) {
+	i := sort.Search(n, func(i int) bool { return !at(i).Before(t) })
+
+	best, bestGap := -1, gap
+
+	for _, j := range []int{i - 1, i} {
+		if j < 0 || j >= n {
+			continue
+		}
+
+		if d := at(j).Sub(t).Abs(); d <= bestGap {
+			best, bestGap = j, d
+		}
+	}
+
+	return best, best >= 0
+}
+
+// tracksMatch returns whether both tracks are at the same place at the same
+// time; when one of the workouts has no positions, the tracks can not be
+// compared and they match
+func tracksMatch(a, b *MapData) bool {
+	pa, pb := positionedPoints(a), positionedPoints(b)
+	if len(pa) == 0 || len(pb) == 0 {
+		return true
+	}
+
+	var compared, matching int
+
+	for i := 0; i < len(pa); i += max(1, len(pa)/duplicateSamples) {
+		p := pa[i]
+
+		j, ok := nearestInTime(len(pb), func(j int) time.Time { return pb[j].Time }, p.Time, duplicateMaxGap)
+		if !ok {
+			continue
+		}
+
+		compared++
+
+		if gpx.HaversineDistance(p.Lat, p.Lng, pb[j].Lat, pb[j].Lng) <= duplicateMaxDistance {
+			matching++
+		}
+	}
+
+	return compared > 0 && float64(matching) >= duplicateMinMatching*float64(compared)
+}
+
+// FindDuplicates returns the other workouts of the owner that were recorded at
+// the same time as the workout, at the same place
+func (w *Workout) FindDuplicates(db *gorm.DB) ([]*Workout, error) {
+	if w.Date == nil || w.IsLeg() {
+		return nil, nil
+	}
+
+	start, end := w.timeWindow()
+
+	// A candidate started at most as long before the workout as the longest
+	// workout of the owner lasts
+	longest, err := longestDuration(db, w.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []*Workout
+
+	q := db.Preload("Data.Details").
+		Where(&Workout{UserID: w.UserID}).
+		Where("id <> ? AND parent_id IS NULL", w.ID).
+		Where("date BETWEEN ? AND ?", start.Add(-longest), end).
+		Order("date")
+
+	if err := q.Find(&candidates).Error; err != nil {
+		return nil, err
+	}
+
+	return slices.DeleteFunc(candidates, func(o *Workout) bool {
+		oStart, oEnd := o.timeWindow()
+		if oStart.After(end) || oEnd.Before(start) {
+			return true
+		}
+
+		return !w.overlaps(o) || !tracksMatch(w.Data, o.Data)
+	}), nil
+}
+
+// longestDuration returns the duration of the longest workout of the user
+func longestDuration(db *gorm.DB, userID uint) (time.Duration, error) {
+	var longest int64
+
+	err := db.Model(&MapData{}).
+		Joins("join workouts on workouts.id = map_data.workout_id").
+		Where("workouts.user_id = ?", userID).
+		Select("coalesce(max(map_data.total_duration), 0)").
+		Scan(&longest).Error
+
+	return time.Duration(longest), err
+}
+
+// markDuplicate marks the workout as a duplicate of the first other workout of
+// the owner that it duplicates, if any
+func (w *Workout) markDuplicate(db *gorm.DB) error {
+	duplicates, err := w.FindDuplicates(db)
+	if err != nil || len(duplicates) == 0 {
+		return err
+	}
+
+	return db.Model(w).Update("duplicate_of_id", duplicates[0].ID).Error
+}
+
+// clearDuplicateMarks removes the marks of the workouts that duplicate the
+// workout, eg. because it is deleted
+func (w *Workout) clearDuplicateMarks(db *gorm.DB) error {
+	return db.Model(&Workout{}).Where("duplicate_of_id = ?", w.ID).Update("duplicate_of_id", nil).Error
+}
+
+// mergePoint copies the position, elevation and sensor metrics of the source
+// that the point does not have
+func mergePoint(p *gpx.GPXPoint, source *gpx.GPXPoint) {
+	if !pointHasDistance(*p) && pointHasDistance(*source) {
+		p.Latitude, p.Longitude = source.Latitude, source.Longitude
+	}
+
+	if p.Elevation.Null() && source.Elevation.NotNull() {
+		p.Elevation = source.Elevation
+	}
+
+	have, metrics := ExtraMetrics{}, ExtraMetrics{}
+	have.ParseGPXExtensions(p.Extensions)
+	metrics.ParseGPXExtensions(source.Extensions)
+
+	for _, k := range mergedMetrics {
+		v, ok := metrics[k]
+		if _, exists := have[k]; exists || !ok {
+			continue
+		}
+
+		p.Extensions.Nodes = append(p.Extensions.Nodes, gpx.ExtensionNode{
+			XMLName: xml.Name{Local: k}, Data: strconv.FormatFloat(v, 'f', -1, 64),
+		})
+	}
+}
+
+// MergeMetrics copies the metrics of the other workout into the workout: every
+// point gets the position, elevation and sensor metrics it does not have from
+// the point of the other workout at the same time; the other workout is
+// deleted, its equipment is kept
+func (w *Workout) MergeMetrics(db *gorm.DB, other *Workout) error {
+	if other.UserID != w.UserID {
+		return ErrMergeOtherUser
+	}
+
+	g, err := w.editableGPX()
+	if err != nil {
+		return err
+	}
+
+	og, err := other.editableGPX()
+	if err != nil {
+		return err
+	}
+
+	source := trackPoints(og)
+	slices.SortStableFunc(source, func(a, b trackPoint) int {
+		return a.point.Timestamp.Compare(b.point.Timestamp)
+	})
+
+	at := func(i int) time.Time { return source[i].point.Timestamp }
+
+	for ti := range g.Tracks {
+		for si := range g.Tracks[ti].Segments {
+			for pi := range g.Tracks[ti].Segments[si].Points {
+				p := &g.Tracks[ti].Segments[si].Points[pi]
+
+				if i, ok := nearestInTime(len(source), at, p.Timestamp, mergeMetricsMaxGap); ok {
+					mergePoint(p, &source[i].point)
+				}
+			}
+		}
+	}
+
+	equipment := uniqueEquipment(append(slices.Clone(w.Equipment), other.Equipment...))
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := other.Delete(tx); err != nil {
+			return err
+		}
+
+		if err := w.setDerivedGPX(g); err != nil {
+			return err
+		}
+
+		if err := tx.Model(w).Association("Equipment").Replace(equipment); err != nil {
+			return err
+		}
+
+		w.DuplicateOfID = nil
+
+		return w.saveTrack(tx)
+	})
+}
+
+// ResolveDuplicate resolves the workout that duplicates another workout; the
+// workout that remains is returned
+func (w *Workout) ResolveDuplicate(db *gorm.DB, r DuplicateResolution) (*Workout, error) {
+	if !r.IsValid() {
+		return nil, ErrInvalidDuplicateSelection
+	}
+
+	if !w.IsDuplicate() {
+		return nil, ErrNotADuplicate
+	}
+
+	switch r {
+	case DuplicateDismiss:
+		return w, db.Model(w).Update("duplicate_of_id", nil).Error
+	case DuplicateExclude:
+		return w, db.Model(w).Updates(map[string]any{"duplicate_of_id": nil, "exclude_from_statistics": true}).Error
+	}
+
+	other, err := GetWorkoutDetails(db, int(*w.DuplicateOfID))
+	if err != nil {
+		return nil, err
+	}
+
+	switch r {
+	case DuplicateKeepThis:
+		return w, db.Transaction(func(tx *gorm.DB) error {
+			if err := other.Delete(tx); err != nil {
+				return err
+			}
+
+			return tx.Model(w).Update("duplicate_of_id", nil).Error
+		})
+	case DuplicateKeepOther:
+		return other, w.Delete(db)
+	case DuplicateMergeThis:
+		return w, w.MergeMetrics(db, other)
+	default:
+		return other, other.MergeMetrics(db, w)
+	}
+}
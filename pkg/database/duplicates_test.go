@@ -0,0 +1,183 @@
+package database
+
+import (
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tkrajina/gpxgo/gpx"
+	"gorm.io/gorm"
+)
+
+// addRecording adds a workout with a track going north at 3 m/s, starting later
+// by the delay and east of the other tracks by the offset (in meters); a
+// recording from a watch has a heart rate
+func addRecording(t *testing.T, db *gorm.DB, u *User, delay time.Duration, offset float64, watch bool) *Workout {
+	t.Helper()
+
+	offsets := map[int]float64{}
+	for i := range 101 {
+		offsets[i] = offset
+	}
+
+	g := straightTrack(101, 3, offsets)
+
+	for i := range g.Tracks[0].Segments[0].Points {
+		p := &g.Tracks[0].Segments[0].Points[i]
+		p.Timestamp = p.Timestamp.Add(delay)
+
+		if watch {
+			p.Extensions.Nodes = append(p.Extensions.Nodes, gpx.ExtensionNode{
+				XMLName: xml.Name{Local: "hr"}, Data: "150",
+			})
+		}
+	}
+
+	content, err := g.ToXml(gpx.ToXmlParams{Version: "1.1"})
+	require.NoError(t, err)
+
+	w, err := u.AddWorkout(db, WorkoutTypeRunning, "", "recording.gpx", content)
+	require.NoError(t, err)
+
+	return w
+}
+
+func TestWorkout_FindDuplicates(t *testing.T) {
+	db := createMemoryDB(t)
+
+	u := defaultUser()
+	require.NoError(t, u.Create(db))
+
+	watch := addRecording(t, db, u, 0, 0, true)
+	assert.False(t, watch.IsDuplicate())
+
+	phone := addRecording(t, db, u, 3*time.Second, 10, false)
+	require.True(t, phone.IsDuplicate())
+	assert.Equal(t, watch.ID, *phone.DuplicateOfID)
+
+	// A track at the same time somewhere else, and a track at the same place
+	// later, are not duplicates
+	assert.False(t, addRecording(t, db, u, 5*time.Second, 5000, false).IsDuplicate())
+	assert.False(t, addRecording(t, db, u, time.Hour, 0, false).IsDuplicate())
+
+	duplicates, err := watch.FindDuplicates(db)
+	require.NoError(t, err)
+	require.Len(t, duplicates, 1)
+	assert.Equal(t, phone.ID, duplicates[0].ID)
+}
+
+func TestWorkout_FindDuplicatesLongRecording(t *testing.T) {
+	db := createMemoryDB(t)
+
+	u := defaultUser()
+	require.NoError(t, u.Create(db))
+
+	w := addRecording(t, db, u, 0, 0, true)
+
+	// A recording of 30 hours without a track, that started more than a day
+	// before the workout
+	d := w.Date.Add(-26 * time.Hour)
+	long := &Workout{UserID: u.ID, Name: "ultra", Type: WorkoutTypeHiking, Date: &d, Data: &MapData{TotalDuration: 30 * time.Hour}}
+	require.NoError(t, long.Create(db))
+
+	duplicates, err := w.FindDuplicates(db)
+	require.NoError(t, err)
+	require.Len(t, duplicates, 1)
+	assert.Equal(t, long.ID, duplicates[0].ID)
+
+	// A recording that ended before the workout started is not a duplicate
+	d = w.Date.Add(-40 * time.Hour)
+	before := &Workout{UserID: u.ID, Name: "before", Type: WorkoutTypeHiking, Date: &d, Data: &MapData{TotalDuration: 30 * time.Hour}}
+	require.NoError(t, before.Create(db))
+
+	duplicates, err = w.FindDuplicates(db)
+	require.NoError(t, err)
+	require.Len(t, duplicates, 1)
+}
+
+func TestWorkout_ResolveDuplicateMerge(t *testing.T) {
+	db := createMemoryDB(t)
+
+	u := defaultUser()
+	require.NoError(t, u.Create(db))
+
+	watch := addRecording(t, db, u, 0, 0, true)
+	phone := addRecording(t, db, u, 3*time.Second, 10, false)
+	require.False(t, phone.HasHeartRate())
+
+	_, err := phone.ResolveDuplicate(db, DuplicateResolution("both"))
+	require.ErrorIs(t, err, ErrInvalidDuplicateSelection)
+
+	w, err := phone.ResolveDuplicate(db, DuplicateMergeThis)
+	require.NoError(t, err)
+	assert.Equal(t, phone.ID, w.ID)
+
+	w, err = GetWorkoutDetails(db, int(phone.ID))
+	require.NoError(t, err)
+
+	assert.False(t, w.IsDuplicate())
+	assert.True(t, w.HasOriginal())
+	assert.True(t, w.HasHeartRate())
+	assert.Len(t, w.Data.Details.Points, 101)
+	assert.InDelta(t, 150, w.Data.Details.Points[50].ExtraMetrics.Get("heart-rate"), 0.01)
+
+	_, err = GetWorkout(db, int(watch.ID))
+	require.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	_, err = w.ResolveDuplicate(db, DuplicateKeepThis)
+	require.ErrorIs(t, err, ErrNotADuplicate)
+}
+
+func TestWorkout_ResolveDuplicateExclude(t *testing.T) {
+	db := createMemoryDB(t)
+
+	u := defaultUser()
+	require.NoError(t, u.Create(db))
+
+	addRecording(t, db, u, 0, 0, true)
+	phone := addRecording(t, db, u, 3*time.Second, 10, false)
+
+	u, err := GetUserByID(db, int(u.ID))
+	require.NoError(t, err)
+
+	totals, err := u.GetTotals(WorkoutTypeRunning)
+	require.NoError(t, err)
+	assert.Equal(t, 2, totals.Workouts)
+
+	_, err = phone.ResolveDuplicate(db, DuplicateExclude)
+	require.NoError(t, err)
+
+	totals, err = u.GetTotals(WorkoutTypeRunning)
+	require.NoError(t, err)
+	assert.Equal(t, 1, totals.Workouts)
+	assert.InDelta(t, 300, totals.Distance, 1)
+
+	w, err := GetWorkout(db, int(phone.ID))
+	require.NoError(t, err)
+	assert.False(t, w.IsDuplicate())
+	assert.True(t, w.ExcludeFromStatistics)
+}
+
+func TestWorkout_DeleteClearsDuplicateMarks(t *testing.T) {
+	db := createMemoryDB(t)
+
+	u := defaultUser()
+	require.NoError(t, u.Create(db))
+
+	watch := addRecording(t, db, u, 0, 0, true)
+	phone := addRecording(t, db, u, 3*time.Second, 10, false)
+
+	w, err := phone.ResolveDuplicate(db, DuplicateKeepOther)
+	require.NoError(t, err)
+	assert.Equal(t, watch.ID, w.ID)
+
+	phone = addRecording(t, db, u, 3*time.Second, 10, false)
+	require.True(t, phone.IsDuplicate())
+	require.NoError(t, watch.Delete(db))
+
+	w, err = GetWorkout(db, int(phone.ID))
+	require.NoError(t, err)
+	assert.False(t, w.IsDuplicate())
+}
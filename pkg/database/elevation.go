@@ -0,0 +1,107 @@
+package database
+
+import (
+	"math"
+
+	"github.com/jovandeginste/workout-tracker/pkg/dem"
+)
+
+// ElevationSource is where the elevation of the points of a workout comes from
+type ElevationSource string
+
+const (
+	ElevationSourceDevice ElevationSource = "device" // The elevation recorded by the device
+	ElevationSourceDEM    ElevationSource = "dem"    // The elevation from the DEM tiles on the server
+)
+
+func (s ElevationSource) String() string {
+	return string(s)
+}
+
+func (s ElevationSource) IsValid() bool {
+	return s == ElevationSourceDevice || s == ElevationSourceDEM
+}
+
+// defaultElevationSource returns the elevation source of a new workout: the DEM
+// tiles, if they are configured and the device probably has no barometer
+func defaultElevationSource(creator string) ElevationSource {
+	if dem.Enabled() && creatorNeedsCorrection(creator) {
+		return ElevationSourceDEM
+	}
+
+	return ElevationSourceDevice
+}
+
+// UpdateElevation replaces the elevation of the points with the elevation from
+// the DEM tiles, if that is the source, and calculates the elevation totals
+// from the points; when a point is not covered by the tiles, the elevation of
+// the device is kept for the whole track
+func (m *MapData) UpdateElevation(s ElevationSource) {
+	m.DEMElevation = false
+
+	if s != ElevationSourceDEM || !dem.Enabled() || m.Details == nil {
+		return
+	}
+
+	elevations := make([]float64, len(m.Details.Points))
+	found := false
+
+	for i, p := range m.Details.Points {
+		elevations[i] = math.NaN()
+
+		if !p.HasPosition() {
+			continue
+		}
+
+		e, err := dem.Elevation(p.Lat, p.Lng)
+		if err != nil {
+			return
+		}
+
+		elevations[i] = e
+		found = true
+	}
+
+	if !found {
+		return
+	}
+
+	for i, e := range elevations {
+		if !math.IsNaN(e) {
+			m.Details.Points[i].ExtraMetrics.Set("elevation", e)
+		}
+	}
+
+	m.updateElevationFromPoints()
+	m.DEMElevation = true
+}
+
+// updateElevationFromPoints calculates the minimum and maximum elevation and
+// the total climb and descent from the elevation of the points with a position
+func (m *MapData) updateElevationFromPoints() {
+	m.MinElevation, m.MaxElevation, m.TotalUp, m.TotalDown = 0, 0, 0, 0
+
+	prev := math.NaN()
+
+	for _, p := range m.Details.Points {
+		e, ok := p.ExtraMetrics["elevation"]
+		if !ok || !p.HasPosition() {
+			continue
+		}
+
+		if math.IsNaN(prev) {
+			m.MinElevation, m.MaxElevation = e, e
+		} else {
+			m.MinElevation = min(m.MinElevation, e)
+			m.MaxElevation = max(m.MaxElevation, e)
+
+			if e > prev {
+				m.TotalUp += e - prev
+			} else {
+				m.TotalDown += prev - e
+			}
+		}
+
+		prev = e
+	}
+}
@@ -0,0 +1,118 @@
+package database
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jovandeginste/workout-tracker/pkg/dem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tkrajina/gpxgo/gpx"
+)
+
+// demTiles configures a directory with an HGT tile N50E004, with samples 0.1
+// degree apart; the elevation rises 100m per sample to the east
+func demTiles(t *testing.T) {
+	t.Helper()
+
+	const size = 11
+
+	dir := t.TempDir()
+	content := make([]byte, 2*size*size)
+
+	for i := range size * size {
+		binary.BigEndian.PutUint16(content[2*i:], uint16(100+100*(i%size)))
+	}
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "N50E004.hgt"), content, 0o600))
+
+	dem.SetDirectory(nil, dir)
+	t.Cleanup(func() { dem.SetDirectory(nil, "") })
+}
+
+// eastwardTrack returns a GPX going east from the longitude, with a point every
+// 0.1 degree and a noisy elevation
+func eastwardTrack(creator string, lng float64) *gpx.GPX {
+	g := &gpx.GPX{Creator: creator}
+	g.AppendTrack(&gpx.GPXTrack{})
+
+	start := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+
+	for i := range 5 {
+		p := &gpx.GPXPoint{
+			Timestamp: start.Add(time.Duration(i) * time.Minute),
+			Point: gpx.Point{
+				Latitude:  50.5,
+				Longitude: lng + float64(i)*0.1,
+			},
+		}
+		p.Elevation.SetValue(float64(50 * (i % 2)))
+
+		g.AppendPoint(p)
+	}
+
+	return g
+}
+
+func TestDefaultElevationSource(t *testing.T) {
+	assert.Equal(t, ElevationSourceDevice, defaultElevationSource("Test phone"))
+
+	demTiles(t)
+
+	assert.Equal(t, ElevationSourceDEM, defaultElevationSource("Test phone"))
+	assert.Equal(t, ElevationSourceDevice, defaultElevationSource("Garmin"))
+}
+
+func TestMapData_UpdateElevation(t *testing.T) {
+	demTiles(t)
+
+	m := gpxAsMapData(eastwardTrack("Test phone", 4.1))
+	m.UpdateElevation(ElevationSourceDEM)
+
+	require.True(t, m.DEMElevation)
+	assert.InDelta(t, 200, m.MinElevation, 0.01)
+	assert.InDelta(t, 600, m.MaxElevation, 0.01)
+	assert.InDelta(t, 400, m.TotalUp, 0.01)
+	assert.InDelta(t, 0, m.TotalDown, 0.01)
+	assert.InDelta(t, 400, m.Details.Points[2].ExtraMetrics.Get("elevation"), 0.01)
+
+	// The device elevation is kept when the track leaves the tiles
+	m = gpxAsMapData(eastwardTrack("Test phone", 4.7))
+	m.UpdateElevation(ElevationSourceDEM)
+
+	assert.False(t, m.DEMElevation)
+	assert.Less(t, m.MaxElevation, 200.0)
+}
+
+func TestWorkout_ElevationSource(t *testing.T) {
+	demTiles(t)
+
+	db := createMemoryDB(t)
+
+	u := defaultUser()
+	require.NoError(t, u.Create(db))
+
+	content, err := eastwardTrack("Test phone", 4.1).ToXml(gpx.ToXmlParams{Version: "1.1"})
+	require.NoError(t, err)
+
+	w, err := u.AddWorkout(db, WorkoutTypeCycling, "", "phone.gpx", content)
+	require.NoError(t, err)
+
+	assert.Equal(t, ElevationSourceDEM, w.ElevationSource)
+	assert.True(t, w.Data.DEMElevation)
+	assert.InDelta(t, 400, w.Data.TotalUp, 0.01)
+
+	// Switching to the device elevation resamples the points on refresh
+	w.ElevationSource = ElevationSourceDevice
+	require.NoError(t, w.UpdateData(db))
+
+	w, err = GetWorkoutDetails(db, int(w.ID))
+	require.NoError(t, err)
+
+	assert.Equal(t, ElevationSourceDevice, w.ElevationSource)
+	assert.False(t, w.Data.DEMElevation)
+	assert.Less(t, w.Data.MaxElevation, 200.0)
+}
@@ -10,14 +10,16 @@ import (
 
 type Equipment struct {
 	gorm.Model
-	Name        string        `gorm:"not null;uniqueIndex" json:"name" form:"name"`          // The name of the gear
-	UserID      uint          `gorm:"not null;index"`                                        // The ID of the user who owns the workout
-	Description string        `gorm:"" json:"description" form:"description"`                // More information about the equipment
-	Active      bool          `gorm:"default:true" json:"active" form:"active"`              // Whether this equipment is active
-	DefaultFor  []WorkoutType `gorm:"serializer:json;column:default_for" form:"default_for"` // Which workout types to add this equipment by default
+	Name        string        `gorm:"not null;uniqueIndex" json:"name" form:"name"`                             // The name of the gear
+	UserID      uint          `gorm:"not null;index"`                                                           // The ID of the user who owns the workout
+	Description string        `gorm:"" json:"description" form:"description"`                                   // More information about the equipment
+	Active      bool          `gorm:"default:true" json:"active" form:"active"`                                 // Whether this equipment is active
+	DefaultFor  []WorkoutType `gorm:"serializer:json;column:default_for" json:"default_for" form:"default_for"` // Which workout types to add this equipment by default
 
-	User     User
-	Workouts []Workout `gorm:"many2many:workout_equipment"`
+	User             User               `json:"-"`
+	Workouts         []Workout          `gorm:"many2many:workout_equipment" json:",omitempty"`
+	MaintenanceItems []MaintenanceItem  `json:",omitempty"` // What has to be done to the equipment after some usage
+	Services         []EquipmentService `json:",omitempty"` // The services that were done, most recent first
 
 	db *gorm.DB
 }
@@ -33,7 +35,7 @@ type WorkoutEquipment struct {
 func GetEquipment(db *gorm.DB, id int) (*Equipment, error) {
 	var e Equipment
 
-	if err := db.Preload("User").Preload("Workouts").Preload("Workouts.Data").First(&e, id).Error; err != nil {
+	if err := preloadMaintenance(db.Preload("User")).First(&e, id).Error; err != nil {
 		return nil, err
 	}
 
@@ -51,7 +53,7 @@ func (e *Equipment) Delete(db *gorm.DB) error {
 		return err
 	}
 
-	return db.Unscoped().Select("workout_equipment").Delete(e).Error
+	return db.Unscoped().Select("workout_equipment", "MaintenanceItems", "Services").Delete(e).Error
 }
 
 func (e *Equipment) Save(db *gorm.DB) error {
@@ -76,28 +78,33 @@ func (e *Equipment) SetDB(db *gorm.DB) {
 	e.db = db
 }
 
+// GetTotals returns the usage of the equipment in all its workouts, except
+// those left out of the statistics
 func (e *Equipment) GetTotals() (WorkoutTotals, error) {
-	rs := WorkoutTotals{}
-
-	for _, w := range e.Workouts {
-		if w.Type.IsDistance() {
-			rs.Distance += w.Distance()
-		}
-
-		if w.Type.IsDuration() {
-			rs.Duration += w.Duration()
-		}
-
-		if w.Type.IsRepetition() {
-			rs.Repetitions += w.Repetitions()
-		}
-	}
-
-	return rs, nil
+	return e.GetTotalsSince(nil), nil
 }
 
 type WorkoutTotals struct {
 	Distance    float64
 	Duration    time.Duration
 	Repetitions int
+	Workouts    int
+}
+
+// Add counts the workout towards the totals, for the metrics that apply to
+// its type
+func (t *WorkoutTotals) Add(w *Workout) {
+	t.Workouts++
+
+	if w.Type.IsDistance() {
+		t.Distance += w.Distance()
+	}
+
+	if w.Type.IsDuration() {
+		t.Duration += w.Duration()
+	}
+
+	if w.Type.IsRepetition() {
+		t.Repetitions += w.Repetitions()
+	}
 }
@@ -16,6 +16,17 @@ func (em ExtraMetrics) Get(key string) float64 {
 	return em[key]
 }
 
+// GetPtr returns a pointer to the value of the metric, or nil if the metric is
+// not set
+func (em ExtraMetrics) GetPtr(key string) *float64 {
+	v, ok := em[key]
+	if !ok {
+		return nil
+	}
+
+	return &v
+}
+
 func (em ExtraMetrics) ParseGPXExtensions(extension gpx.Extension) {
 	for _, n := range extension.Nodes {
 		if key, value := getGPXExtensionKeyValue(&n); key != "" {
@@ -52,6 +63,8 @@ func standardExtensionName(name string) string {
 		return "heart-rate"
 	case "ns3:cad", "cad":
 		return "cadence"
+	case "ns3:power", "PowerInWatts", "watts":
+		return "power"
 	default:
 		return name
 	}
@@ -0,0 +1,236 @@
+package database
+
+import (
+	"errors"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var ErrInvalidGoal = errors.New("invalid goal")
+
+// GoalMetric is what is counted towards a goal
+type GoalMetric string
+
+const (
+	GoalMetricDistance    GoalMetric = "distance"    // The total distance, in meters
+	GoalMetricDuration    GoalMetric = "duration"    // The total duration, in seconds
+	GoalMetricRepetitions GoalMetric = "repetitions" // The total number of repetitions
+	GoalMetricWorkouts    GoalMetric = "workouts"    // The number of workouts
+)
+
+func GoalMetrics() []GoalMetric {
+	return []GoalMetric{GoalMetricDistance, GoalMetricDuration, GoalMetricRepetitions, GoalMetricWorkouts}
+}
+
+func (m GoalMetric) String() string {
+	return string(m)
+}
+
+func (m GoalMetric) IsValid() bool {
+	return slices.Contains(GoalMetrics(), m)
+}
+
+// WorkoutTypes returns the workout types that count towards the metric
+func (m GoalMetric) WorkoutTypes() []WorkoutType {
+	switch m {
+	case GoalMetricDistance:
+		return DistanceWorkoutTypes()
+	case GoalMetricRepetitions:
+		return RepetitionWorkoutTypes()
+	default:
+		return DurationWorkoutTypes()
+	}
+}
+
+// GoalPeriod is the calendar period over which progress towards a goal is
+// counted; progress starts over every period
+type GoalPeriod string
+
+const (
+	GoalPeriodWeek  GoalPeriod = "week"
+	GoalPeriodMonth GoalPeriod = "month"
+	GoalPeriodYear  GoalPeriod = "year"
+)
+
+func GoalPeriods() []GoalPeriod {
+	return []GoalPeriod{GoalPeriodWeek, GoalPeriodMonth, GoalPeriodYear}
+}
+
+func (p GoalPeriod) String() string {
+	return string(p)
+}
+
+func (p GoalPeriod) IsValid() bool {
+	return slices.Contains(GoalPeriods(), p)
+}
+
+// Bounds returns the start and end of the period that contains t, in the
+// location of t; weeks start on Monday
+func (p GoalPeriod) Bounds(t time.Time) (time.Time, time.Time) {
+	switch p {
+	case GoalPeriodWeek:
+		offset := (int(t.Weekday()) + 6) % 7
+		start := time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, t.Location())
+
+		return start, start.AddDate(0, 0, 7)
+	case GoalPeriodMonth:
+		start := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+
+		return start, start.AddDate(0, 1, 0)
+	default:
+		start := time.Date(t.Year(), 1, 1, 0, 0, 0, 0, t.Location())
+
+		return start, start.AddDate(1, 0, 0)
+	}
+}
+
+// Goal is a target the user wants to reach every period, eg. 1000 km running
+// per year or 3 workouts per week
+type Goal struct {
+	gorm.Model
+	UserID uint        `gorm:"not null;index"`       // The ID of the user who owns the goal
+	Name   string      `form:"name" json:"name"`     // The name of the goal
+	Type   WorkoutType `form:"type" json:"type"`     // The workout type that counts towards the goal; empty for all types that support the metric
+	Metric GoalMetric  `form:"metric" json:"metric"` // What is counted towards the goal
+	Period GoalPeriod  `form:"period" json:"period"` // The period over which progress is counted
+	Target float64     `form:"target" json:"target"` // The target; in meters for distance, in seconds for duration
+
+	User *User `json:"-"` // The user who owns the goal
+}
+
+func (g *Goal) Validate() error {
+	if !g.Metric.IsValid() || !g.Period.IsValid() || g.Target <= 0 {
+		return ErrInvalidGoal
+	}
+
+	if g.Type != "" && !slices.Contains(g.Metric.WorkoutTypes(), g.Type) {
+		return ErrInvalidGoal
+	}
+
+	return nil
+}
+
+// WorkoutTypes returns the workout types that count towards the goal; a goal
+// for all types leaves out multisport workouts, since their legs are counted
+func (g *Goal) WorkoutTypes() []WorkoutType {
+	if g.Type != "" {
+		return []WorkoutType{g.Type}
+	}
+
+	return slices.DeleteFunc(slices.Clone(g.Metric.WorkoutTypes()), WorkoutType.IsMultisport)
+}
+
+// TargetDuration returns the target of a duration goal
+func (g *Goal) TargetDuration() time.Duration {
+	return time.Duration(g.Target * float64(time.Second))
+}
+
+func (g *Goal) Save(db *gorm.DB) error {
+	if err := g.Validate(); err != nil {
+		return err
+	}
+
+	return db.Save(g).Error
+}
+
+func (g *Goal) Delete(db *gorm.DB) error {
+	return db.Unscoped().Delete(g).Error
+}
+
+func (u *User) GetGoals(db *gorm.DB) ([]Goal, error) {
+	var g []Goal
+
+	if err := db.Where(&Goal{UserID: u.ID}).Order("id").Find(&g).Error; err != nil {
+		return nil, err
+	}
+
+	return g, nil
+}
+
+func (u *User) GetGoal(db *gorm.DB, id int) (*Goal, error) {
+	var g Goal
+
+	if err := db.Where(&Goal{UserID: u.ID}).First(&g, id).Error; err != nil {
+		return nil, err
+	}
+
+	return &g, nil
+}
+
+// GoalProgress is the progress towards a goal in the current period
+type GoalProgress struct {
+	Goal       Goal      // The goal
+	Start      time.Time // The start of the current period
+	End        time.Time // The end of the current period
+	Value      float64   // The progress; in meters for distance, in seconds for duration
+	Percentage float64   // The progress, as a percentage of the target
+}
+
+// Completed returns whether the target has been reached
+func (p *GoalProgress) Completed() bool {
+	return p.Value >= p.Goal.Target
+}
+
+// ValueDuration returns the progress of a duration goal
+func (p *GoalProgress) ValueDuration() time.Duration {
+	return time.Duration(p.Value * float64(time.Second))
+}
+
+// GetGoalProgress returns the progress towards the goal in the period that
+// contains now
+func (u *User) GetGoalProgress(g *Goal, now time.Time) (*GoalProgress, error) {
+	start, end := g.Period.Bounds(now.In(u.Timezone()))
+
+	var buckets []Bucket
+
+	err := u.aggregateWorkouts("'goal' as bucket").
+		Where("workouts.date >= ? AND workouts.date < ?", start.UTC(), end.UTC()).
+		Where("type IN ?", g.WorkoutTypes()).
+		Group("workout_type").
+		Scan(&buckets).Error
+	if err != nil {
+		return nil, err
+	}
+
+	p := &GoalProgress{Goal: *g, Start: start, End: end}
+
+	for _, b := range buckets {
+		switch g.Metric {
+		case GoalMetricDistance:
+			p.Value += b.Distance
+		case GoalMetricDuration:
+			p.Value += b.Duration.Seconds()
+		case GoalMetricRepetitions:
+			p.Value += float64(b.Repetitions)
+		case GoalMetricWorkouts:
+			p.Value += float64(b.Workouts)
+		}
+	}
+
+	p.Percentage = 100 * p.Value / g.Target
+
+	return p, nil
+}
+
+// GetGoalsProgress returns the progress towards all goals of the user
+func (u *User) GetGoalsProgress(now time.Time) ([]GoalProgress, error) {
+	goals, err := u.GetGoals(u.db)
+	if err != nil {
+		return nil, err
+	}
+
+	r := make([]GoalProgress, 0, len(goals))
+
+	for i := range goals {
+		p, err := u.GetGoalProgress(&goals[i], now)
+		if err != nil {
+			return nil, err
+		}
+
+		r = append(r, *p)
+	}
+
+	return r, nil
+}
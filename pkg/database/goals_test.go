@@ -0,0 +1,97 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGoalPeriod_Bounds(t *testing.T) {
+	now := time.Date(2024, 3, 13, 15, 0, 0, 0, time.UTC) // a Wednesday
+
+	start, end := GoalPeriodWeek.Bounds(now)
+	assert.Equal(t, time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC), start)
+	assert.Equal(t, time.Date(2024, 3, 18, 0, 0, 0, 0, time.UTC), end)
+
+	start, end = GoalPeriodMonth.Bounds(now)
+	assert.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), start)
+	assert.Equal(t, time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), end)
+
+	start, end = GoalPeriodYear.Bounds(now)
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), start)
+	assert.Equal(t, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), end)
+}
+
+func TestGoal_Validate(t *testing.T) {
+	for name, g := range map[string]Goal{
+		"no metric":         {Period: GoalPeriodWeek, Target: 3},
+		"no period":         {Metric: GoalMetricWorkouts, Target: 3},
+		"no target":         {Metric: GoalMetricWorkouts, Period: GoalPeriodWeek},
+		"type without data": {Type: WorkoutTypePushups, Metric: GoalMetricDistance, Period: GoalPeriodWeek, Target: 3},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.ErrorIs(t, g.Validate(), ErrInvalidGoal)
+		})
+	}
+
+	require.NoError(t, (&Goal{Metric: GoalMetricWorkouts, Period: GoalPeriodWeek, Target: 3}).Validate())
+	require.NoError(t, (&Goal{Type: WorkoutTypeRunning, Metric: GoalMetricDistance, Period: GoalPeriodYear, Target: 1e6}).Validate())
+}
+
+func TestUser_GetGoalsProgress(t *testing.T) {
+	db := createMemoryDB(t)
+
+	u := defaultUser()
+	require.NoError(t, u.Create(db))
+
+	u, err := GetUserByID(db, int(u.ID))
+	require.NoError(t, err)
+
+	now := time.Date(2024, 3, 13, 15, 0, 0, 0, time.UTC)
+
+	for i, w := range []struct {
+		date time.Time
+		t    WorkoutType
+		data MapData
+	}{
+		{time.Date(2024, 3, 12, 10, 0, 0, 0, time.UTC), WorkoutTypeRunning, MapData{TotalDistance: 10000, TotalDuration: time.Hour}},
+		{time.Date(2024, 3, 4, 10, 0, 0, 0, time.UTC), WorkoutTypeCycling, MapData{TotalDistance: 40000, TotalDuration: 2 * time.Hour}},
+		{time.Date(2024, 1, 10, 10, 0, 0, 0, time.UTC), WorkoutTypeRunning, MapData{TotalDistance: 5000, TotalDuration: 30 * time.Minute}},
+		{time.Date(2023, 12, 30, 10, 0, 0, 0, time.UTC), WorkoutTypeRunning, MapData{TotalDistance: 20000, TotalDuration: 2 * time.Hour}},
+		{time.Date(2024, 3, 13, 8, 0, 0, 0, time.UTC), WorkoutTypePushups, MapData{TotalRepetitions: 50, TotalDuration: 5 * time.Minute}},
+		// Only counted through its legs
+		{time.Date(2024, 3, 11, 8, 0, 0, 0, time.UTC), WorkoutTypeMultisport, MapData{TotalDistance: 5000, TotalDuration: 30 * time.Minute}},
+	} {
+		wo := &Workout{UserID: u.ID, Name: "goal", Type: w.t, Date: &w.date, Data: &w.data}
+		require.NoError(t, wo.Create(db), i)
+	}
+
+	goals := []Goal{
+		{UserID: u.ID, Name: "running", Type: WorkoutTypeRunning, Metric: GoalMetricDistance, Period: GoalPeriodYear, Target: 1e6},
+		{UserID: u.ID, Name: "often", Metric: GoalMetricWorkouts, Period: GoalPeriodWeek, Target: 3},
+		{UserID: u.ID, Name: "long", Metric: GoalMetricDuration, Period: GoalPeriodMonth, Target: 10 * 3600},
+		{UserID: u.ID, Name: "strong", Metric: GoalMetricRepetitions, Period: GoalPeriodWeek, Target: 50},
+	}
+
+	for i := range goals {
+		require.NoError(t, goals[i].Save(db))
+	}
+
+	p, err := u.GetGoalsProgress(now)
+	require.NoError(t, err)
+	require.Len(t, p, 4)
+
+	assert.InDelta(t, 15000, p[0].Value, 0.1)
+	assert.InDelta(t, 1.5, p[0].Percentage, 0.01)
+
+	assert.InDelta(t, 2, p[1].Value, 0.1)
+	assert.False(t, p[1].Completed())
+
+	assert.Equal(t, 3*time.Hour+5*time.Minute, p[2].ValueDuration())
+	assert.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), p[2].Start)
+
+	assert.InDelta(t, 50, p[3].Value, 0.1)
+	assert.True(t, p[3].Completed())
+}
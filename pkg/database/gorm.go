@@ -51,6 +51,12 @@ func Connect(driver, dsn string, debug bool, logger *slog.Logger) (*gorm.DB, err
 	if err := db.AutoMigrate(
 		&User{}, &Profile{}, &Config{}, &Equipment{}, &WorkoutEquipment{},
 		&Workout{}, &GPXData{}, &MapData{}, &MapDataDetails{},
+		&PrivacyZone{}, &Lap{}, &BestEffort{}, &SwimLength{}, &Climb{},
+		&Segment{}, &SegmentEffort{}, &Goal{},
+		&CustomWorkoutType{}, &WorkoutTypeMapping{},
+		&Exercise{}, &WorkoutExercise{}, &ExerciseSet{},
+		&MaintenanceItem{}, &EquipmentService{},
+		&Measurement{}, &MergedFile{},
 	); err != nil {
 		return nil, err
 	}
@@ -63,6 +69,10 @@ func Connect(driver, dsn string, debug bool, logger *slog.Logger) (*gorm.DB, err
 		return nil, err
 	}
 
+	if err := LoadWorkoutTypes(db); err != nil {
+		return nil, err
+	}
+
 	return db, nil
 }
 
@@ -78,11 +88,23 @@ func preMigrationActions(db *gorm.DB) error {
 		return q.Error
 	}
 
-	q = db.Unscoped().
-		Where("id < (select max(id) from workouts as w where w.date = workouts.date and w.user_id = workouts.user_id)").
-		Delete(&Workout{})
+	duplicates := "id < (select max(id) from workouts as w where w.date = workouts.date and w.user_id = workouts.user_id)"
+	if db.Migrator().HasColumn(&Workout{}, "leg") {
+		duplicates = "id < (select max(id) from workouts as w where w.date = workouts.date and w.user_id = workouts.user_id and w.leg = workouts.leg)"
+	}
 
-	return q.Error
+	q = db.Unscoped().Where(duplicates).Delete(&Workout{})
+	if q.Error != nil {
+		return q.Error
+	}
+
+	// The legs of a multisport workout share the date of the workout, so the
+	// unique index on the date and user now includes the leg
+	if db.Migrator().HasIndex(&Workout{}, "idx_start_user") {
+		return db.Migrator().DropIndex(&Workout{}, "idx_start_user")
+	}
+
+	return nil
 }
 
 func postMigrationActions(db *gorm.DB) error {
@@ -0,0 +1,195 @@
+package database
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+const (
+	DefaultMaxHeartRate     = 190 // The maximum heart rate when the user did not set one, in beats per minute
+	DefaultRestingHeartRate = 60  // The resting heart rate when the user did not set one, in beats per minute
+	HeartRateZoneCount      = 5   // The number of heart rate zones
+
+	// maxHeartRateInterval is the longest interval between two points that is
+	// counted towards the heart rate metrics; longer intervals are pauses
+	maxHeartRateInterval = time.Minute
+)
+
+var ErrInvalidHeartRate = errors.New("invalid heart rate settings")
+
+// defaultZonePercentages are the lower bounds of zones 2 to 5, as a fraction
+// of the maximum heart rate
+var defaultZonePercentages = [HeartRateZoneCount - 1]float64{0.6, 0.7, 0.8, 0.9}
+
+// HeartRateSettings are the user's heart rate parameters, used to calculate
+// time in zones and training load; zero values fall back to defaults
+type HeartRateSettings struct {
+	Max     int `form:"hr_max" json:"max"`         // The maximum heart rate, in beats per minute
+	Resting int `form:"hr_resting" json:"resting"` // The resting heart rate, in beats per minute
+	Zone2   int `form:"hr_zone_2" json:"zone2"`    // The lower bound of zone 2, in beats per minute
+	Zone3   int `form:"hr_zone_3" json:"zone3"`    // The lower bound of zone 3, in beats per minute
+	Zone4   int `form:"hr_zone_4" json:"zone4"`    // The lower bound of zone 4, in beats per minute
+	Zone5   int `form:"hr_zone_5" json:"zone5"`    // The lower bound of zone 5, in beats per minute
+}
+
+func (s HeartRateSettings) MaxOrDefault() int {
+	if s.Max <= 0 {
+		return DefaultMaxHeartRate
+	}
+
+	return s.Max
+}
+
+func (s HeartRateSettings) RestingOrDefault() int {
+	if s.Resting <= 0 {
+		return DefaultRestingHeartRate
+	}
+
+	return s.Resting
+}
+
+func (s HeartRateSettings) hasZones() bool {
+	return s.Zone2 > 0 || s.Zone3 > 0 || s.Zone4 > 0 || s.Zone5 > 0
+}
+
+// ZoneBounds returns the lower bounds of zones 2 to 5; when no zones are set,
+// they are derived from the maximum heart rate
+func (s HeartRateSettings) ZoneBounds() []int {
+	if s.hasZones() {
+		return []int{s.Zone2, s.Zone3, s.Zone4, s.Zone5}
+	}
+
+	bounds := make([]int, 0, len(defaultZonePercentages))
+	for _, p := range defaultZonePercentages {
+		bounds = append(bounds, int(math.Round(p*float64(s.MaxOrDefault()))))
+	}
+
+	return bounds
+}
+
+// Zone returns the zone (1 to 5) of the heart rate
+func (s HeartRateSettings) Zone(hr float64) int {
+	zone := 1
+
+	for i, b := range s.ZoneBounds() {
+		if hr >= float64(b) {
+			zone = i + 2
+		}
+	}
+
+	return zone
+}
+
+// Validate checks that the settings are consistent: the resting heart rate is
+// below the maximum, and the zones are either all empty or all set in
+// ascending order
+func (s HeartRateSettings) Validate() error {
+	if s.Max < 0 || s.Resting < 0 || s.Zone2 < 0 || s.Zone3 < 0 || s.Zone4 < 0 || s.Zone5 < 0 {
+		return ErrInvalidHeartRate
+	}
+
+	if s.RestingOrDefault() >= s.MaxOrDefault() {
+		return ErrInvalidHeartRate
+	}
+
+	if !s.hasZones() {
+		return nil
+	}
+
+	previous := 0
+
+	for _, b := range s.ZoneBounds() {
+		if b <= previous {
+			return ErrInvalidHeartRate
+		}
+
+		previous = b
+	}
+
+	if s.Max > 0 && previous > s.Max {
+		return ErrInvalidHeartRate
+	}
+
+	return nil
+}
+
+// trimp returns Banister's training impulse for the duration at the given heart
+// rate
+func (s HeartRateSettings) trimp(hr float64, d time.Duration) float64 {
+	rest := float64(s.RestingOrDefault())
+
+	reserve := (hr - rest) / (float64(s.MaxOrDefault()) - rest)
+	reserve = max(0, min(1, reserve))
+
+	return d.Minutes() * reserve * 0.64 * math.Exp(1.92*reserve)
+}
+
+// UpdateHeartRate calculates the heart rate metrics (average, maximum, time in
+// zones and training load) from the heart rate of the points
+func (m *MapData) UpdateHeartRate(s HeartRateSettings) {
+	m.AverageHeartRate = 0
+	m.MaxHeartRate = 0
+	m.TimeInZones = nil
+	m.TrainingLoad = 0
+
+	if m.Details == nil {
+		return
+	}
+
+	var (
+		total time.Duration
+		sum   float64
+		zones = make([]time.Duration, HeartRateZoneCount)
+	)
+
+	for _, p := range m.Details.Points {
+		hr := p.ExtraMetrics.Get("heart-rate")
+		if hr <= 0 {
+			continue
+		}
+
+		m.MaxHeartRate = max(m.MaxHeartRate, hr)
+
+		if p.Duration <= 0 || p.Duration > maxHeartRateInterval {
+			continue
+		}
+
+		total += p.Duration
+		sum += hr * p.Duration.Seconds()
+		zones[s.Zone(hr)-1] += p.Duration
+		m.TrainingLoad += s.trimp(hr, p.Duration)
+	}
+
+	if total == 0 {
+		return
+	}
+
+	m.AverageHeartRate = sum / total.Seconds()
+	m.TimeInZones = zones
+}
+
+// HasHeartRateZones returns whether time in zones was calculated
+func (m *MapData) HasHeartRateZones() bool {
+	return len(m.TimeInZones) == HeartRateZoneCount
+}
+
+// TimeInZonePercentages returns the share (0 to 100) of the time spent in each
+// heart rate zone
+func (m *MapData) TimeInZonePercentages() []float64 {
+	var total time.Duration
+	for _, d := range m.TimeInZones {
+		total += d
+	}
+
+	r := make([]float64, len(m.TimeInZones))
+	if total == 0 {
+		return r
+	}
+
+	for i, d := range m.TimeInZones {
+		r[i] = 100 * d.Seconds() / total.Seconds()
+	}
+
+	return r
+}
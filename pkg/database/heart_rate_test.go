@@ -0,0 +1,151 @@
+package database
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func heartRatePoints(rates ...float64) *MapDataDetails {
+	d := &MapDataDetails{}
+
+	for i, hr := range rates {
+		p := MapPoint{ExtraMetrics: ExtraMetrics{}}
+		if i > 0 {
+			p.Duration = 30 * time.Second
+		}
+
+		if hr > 0 {
+			p.ExtraMetrics.Set("heart-rate", hr)
+		}
+
+		d.Points = append(d.Points, p)
+	}
+
+	return d
+}
+
+func TestHeartRateSettings_Defaults(t *testing.T) {
+	s := HeartRateSettings{}
+
+	assert.Equal(t, DefaultMaxHeartRate, s.MaxOrDefault())
+	assert.Equal(t, DefaultRestingHeartRate, s.RestingOrDefault())
+	assert.Equal(t, []int{114, 133, 152, 171}, s.ZoneBounds())
+
+	assert.Equal(t, 1, s.Zone(100))
+	assert.Equal(t, 2, s.Zone(114))
+	assert.Equal(t, 3, s.Zone(140))
+	assert.Equal(t, 5, s.Zone(185))
+}
+
+func TestHeartRateSettings_CustomZones(t *testing.T) {
+	s := HeartRateSettings{Max: 200, Zone2: 120, Zone3: 140, Zone4: 160, Zone5: 180}
+
+	require.NoError(t, s.Validate())
+	assert.Equal(t, []int{120, 140, 160, 180}, s.ZoneBounds())
+	assert.Equal(t, 1, s.Zone(119))
+	assert.Equal(t, 4, s.Zone(179))
+}
+
+func TestHeartRateSettings_Validate(t *testing.T) {
+	for name, s := range map[string]HeartRateSettings{
+		"negative":         {Max: -1},
+		"resting too high": {Max: 150, Resting: 160},
+		"incomplete zones": {Zone2: 120, Zone3: 140},
+		"unordered zones":  {Zone2: 120, Zone3: 110, Zone4: 160, Zone5: 180},
+		"zones above max":  {Max: 170, Zone2: 120, Zone3: 140, Zone4: 160, Zone5: 180},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.ErrorIs(t, s.Validate(), ErrInvalidHeartRate)
+		})
+	}
+
+	assert.NoError(t, HeartRateSettings{}.Validate())
+	assert.NoError(t, HeartRateSettings{Max: 180, Resting: 45}.Validate())
+}
+
+func TestMapData_UpdateHeartRate(t *testing.T) {
+	m := &MapData{Details: heartRatePoints(100, 120, 0, 160, 180)}
+	m.Details.Points[4].Duration = 5 * time.Minute // a pause
+
+	m.UpdateHeartRate(HeartRateSettings{})
+
+	assert.InDelta(t, 180, m.MaxHeartRate, 0.1)
+	assert.InDelta(t, 140, m.AverageHeartRate, 0.1)
+	require.True(t, m.HasHeartRateZones())
+	assert.Equal(t, []time.Duration{0, 30 * time.Second, 0, 30 * time.Second, 0}, m.TimeInZones)
+	assert.Equal(t, []float64{0, 50, 0, 50, 0}, m.TimeInZonePercentages())
+
+	// Half a minute at 120 bpm and half a minute at 160 bpm, with a heart rate
+	// reserve of 60 to 190 bpm
+	r1, r2 := 60.0/130, 100.0/130
+	expected := 0.5*r1*0.64*math.Exp(1.92*r1) + 0.5*r2*0.64*math.Exp(1.92*r2)
+	assert.InDelta(t, expected, m.TrainingLoad, 0.01)
+}
+
+func TestMapData_UpdateHeartRateWithoutHeartRate(t *testing.T) {
+	m := &MapData{Details: heartRatePoints(0, 0, 0)}
+	m.TrainingLoad = 10
+
+	m.UpdateHeartRate(HeartRateSettings{})
+
+	assert.False(t, m.HasHeartRateZones())
+	assert.Zero(t, m.TrainingLoad)
+	assert.Zero(t, m.MaxHeartRate)
+}
+
+func TestUser_GetTrainingLoad(t *testing.T) {
+	db := createMemoryDB(t)
+	u := defaultUser()
+	require.NoError(t, u.Create(db))
+
+	u, err := GetUserByID(db, int(u.ID))
+	require.NoError(t, err)
+
+	now := time.Now().UTC()
+
+	for i, load := range []float64{100, 50, 50, 50} {
+		d := now.AddDate(0, 0, -7*i)
+		w := &Workout{UserID: u.ID, Name: "load", Date: &d, Data: &MapData{TrainingLoad: load}}
+		require.NoError(t, w.Create(db))
+	}
+
+	weeks, err := u.GetTrainingLoad(now.AddDate(0, 0, -7))
+	require.NoError(t, err)
+	require.Len(t, weeks, 2)
+
+	assert.Equal(t, startOfWeek(now), weeks[1].Week)
+	assert.InDelta(t, 100, weeks[1].Acute, 0.01)
+	assert.InDelta(t, 62.5, weeks[1].Chronic, 0.01)
+	assert.InDelta(t, 1.6, weeks[1].Ratio, 0.01)
+	assert.InDelta(t, 50, weeks[0].Acute, 0.01)
+
+	stats, err := u.GetStatisticsFor("3 months", "month")
+	require.NoError(t, err)
+	assert.True(t, stats.HasTrainingLoad())
+}
+
+func TestStartOfWeek(t *testing.T) {
+	sunday := time.Date(2024, 3, 10, 15, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC), startOfWeek(sunday))
+
+	monday := time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, monday, startOfWeek(monday))
+}
+
+func TestStatConfig_SinceTime(t *testing.T) {
+	now := time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)
+
+	for since, expected := range map[string]time.Time{
+		"3 months": time.Date(2023, 12, 10, 0, 0, 0, 0, time.UTC),
+		"2 year":   time.Date(2022, 3, 10, 0, 0, 0, 0, time.UTC),
+		"7 days":   time.Date(2024, 3, 3, 0, 0, 0, 0, time.UTC),
+		"garbage":  time.Date(2023, 3, 10, 0, 0, 0, 0, time.UTC),
+	} {
+		sc := StatConfig{Since: since}
+		assert.Equal(t, expected, sc.SinceTime(now), since)
+	}
+}
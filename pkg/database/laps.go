@@ -0,0 +1,83 @@
+package database
+
+import (
+	"time"
+
+	"github.com/jovandeginste/workout-tracker/pkg/converters"
+	"gorm.io/gorm"
+)
+
+// Lap is a lap or split of a workout, as recorded by the device
+type Lap struct {
+	gorm.Model
+	MapDataID        uint          `gorm:"not null;index" json:"-"` // The ID of the map data this lap belongs to
+	Number           int           // The number of the lap, starting at 1
+	Start            time.Time     // The start time of the lap
+	Stop             time.Time     // The end time of the lap
+	Distance         float64       // The distance of the lap, in meters
+	Duration         time.Duration // The duration of the lap, without pauses
+	AverageHeartRate float64       // The average heart rate, in beats per minute
+	MaxHeartRate     float64       // The maximum heart rate, in beats per minute
+	AverageCadence   float64       // The average cadence
+	MaxCadence       float64       // The maximum cadence
+}
+
+func (l *Lap) AverageSpeed() float64 {
+	if l.Duration <= 0 {
+		return 0
+	}
+
+	return l.Distance / l.Duration.Seconds()
+}
+
+// lapsFromFile returns the laps recorded in the file, numbered in order
+func lapsFromFile(filename string, content []byte) ([]Lap, error) {
+	recorded, err := converters.ParseLaps(filename, content)
+	if err != nil {
+		return nil, err
+	}
+
+	laps := make([]Lap, 0, len(recorded))
+
+	for i, l := range recorded {
+		laps = append(laps, Lap{
+			Number:           i + 1,
+			Start:            l.Start,
+			Stop:             l.Stop,
+			Distance:         l.Distance,
+			Duration:         l.Duration,
+			AverageHeartRate: l.AverageHeartRate,
+			MaxHeartRate:     l.MaxHeartRate,
+			AverageCadence:   l.AverageCadence,
+			MaxCadence:       l.MaxCadence,
+		})
+	}
+
+	return laps, nil
+}
+
+// setRecordedData sets the laps, the calories and the pool lengths that were
+// recorded by the device in the file
+func (m *MapData) setRecordedData(filename string, content []byte) error {
+	var err error
+
+	if m.Laps, err = lapsFromFile(filename, content); err != nil {
+		return err
+	}
+
+	if err := m.setDeviceCalories(filename, content); err != nil {
+		return err
+	}
+
+	return m.setSwimPool(filename, content)
+}
+
+// deleteLaps removes the stored laps of the map data, before they are
+// replaced
+func (m *MapData) deleteLaps(db *gorm.DB) error {
+	if m.ID == 0 {
+		return nil
+	}
+
+	return db.Unscoped().Where(&Lap{MapDataID: m.ID}).Delete(&Lap{}).Error
+}
@@ -0,0 +1,147 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jovandeginste/workout-tracker/pkg/converters"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const tcxTwoLaps = `<?xml version="1.0" encoding="UTF-8"?>
+<TrainingCenterDatabase xmlns="http://www.garmin.com/xmlschemas/TrainingCenterDatabase/v2">
+  <Activities>
+    <Activity Sport="Running">
+      <Id>2024-01-02T10:00:00Z</Id>
+      <Lap StartTime="2024-01-02T10:00:00Z">
+        <TotalTimeSeconds>120</TotalTimeSeconds>
+        <DistanceMeters>400</DistanceMeters>
+        <Track>
+          <Trackpoint>
+            <Time>2024-01-02T10:00:00Z</Time>
+            <Position><LatitudeDegrees>51.0</LatitudeDegrees><LongitudeDegrees>4.0</LongitudeDegrees></Position>
+            <HeartRateBpm><Value>120</Value></HeartRateBpm>
+            <Cadence>80</Cadence>
+          </Trackpoint>
+          <Trackpoint>
+            <Time>2024-01-02T10:02:00Z</Time>
+            <Position><LatitudeDegrees>51.0036</LatitudeDegrees><LongitudeDegrees>4.0</LongitudeDegrees></Position>
+            <HeartRateBpm><Value>140</Value></HeartRateBpm>
+            <Cadence>90</Cadence>
+          </Trackpoint>
+        </Track>
+      </Lap>
+      <Lap StartTime="2024-01-02T10:02:00Z">
+        <TotalTimeSeconds>60</TotalTimeSeconds>
+        <DistanceMeters>200</DistanceMeters>
+        <Track>
+          <Trackpoint>
+            <Time>2024-01-02T10:03:00Z</Time>
+            <Position><LatitudeDegrees>51.0054</LatitudeDegrees><LongitudeDegrees>4.0</LongitudeDegrees></Position>
+            <HeartRateBpm><Value>160</Value></HeartRateBpm>
+          </Trackpoint>
+        </Track>
+      </Lap>
+    </Activity>
+  </Activities>
+</TrainingCenterDatabase>`
+
+func TestNewWorkout_LapsFromTCX(t *testing.T) {
+	w, err := NewWorkout(defaultUser(), WorkoutTypeRunning, "", "laps.tcx", []byte(tcxTwoLaps))
+	require.NoError(t, err)
+	require.Len(t, w.Data.Laps, 2)
+
+	l := w.Data.Laps[0]
+	assert.Equal(t, 1, l.Number)
+	assert.Equal(t, time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC), l.Start)
+	assert.Equal(t, time.Date(2024, 1, 2, 10, 2, 0, 0, time.UTC), l.Stop)
+	assert.InDelta(t, 400, l.Distance, 0.1)
+	assert.Equal(t, 2*time.Minute, l.Duration)
+	assert.InDelta(t, 130, l.AverageHeartRate, 0.1)
+	assert.InDelta(t, 140, l.MaxHeartRate, 0.1)
+	assert.InDelta(t, 85, l.AverageCadence, 0.1)
+	assert.InDelta(t, 90, l.MaxCadence, 0.1)
+	assert.InDelta(t, 400.0/120, l.AverageSpeed(), 0.01)
+
+	l = w.Data.Laps[1]
+	assert.Equal(t, 2, l.Number)
+	assert.InDelta(t, 160, l.MaxHeartRate, 0.1)
+	assert.Zero(t, l.MaxCadence)
+}
+
+func TestNewWorkout_LapsFromFIT(t *testing.T) {
+	d := time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC)
+	content, err := converters.ExportFIT(&converters.Activity{
+		Type:          "running",
+		Start:         d,
+		TotalDistance: 1000,
+		TotalDuration: 5 * time.Minute,
+		Points: []converters.ActivityPoint{
+			{Time: d, Lat: 51, Lng: 4},
+			{Time: d.Add(5 * time.Minute), Lat: 51.009, Lng: 4, TotalDistance: 1000},
+		},
+	})
+	require.NoError(t, err)
+
+	w, err := NewWorkout(defaultUser(), WorkoutTypeRunning, "", "laps.fit", content)
+	require.NoError(t, err)
+	require.Len(t, w.Data.Laps, 1)
+	assert.InDelta(t, 1000, w.Data.Laps[0].Distance, 0.1)
+	assert.Equal(t, 5*time.Minute, w.Data.Laps[0].Duration)
+	assert.Zero(t, w.Data.Laps[0].MaxHeartRate)
+}
+
+func TestWorkout_UpdateDataReplacesLaps(t *testing.T) {
+	db := createMemoryDB(t)
+
+	u := defaultUser()
+	require.NoError(t, u.Create(db))
+
+	w, err := u.AddWorkout(db, WorkoutTypeRunning, "", "laps.tcx", []byte(tcxTwoLaps))
+	require.NoError(t, err)
+
+	require.NoError(t, w.UpdateData(db))
+	require.NoError(t, w.UpdateData(db))
+
+	var count int64
+	require.NoError(t, db.Model(&Lap{}).Count(&count).Error)
+	assert.Equal(t, int64(2), count)
+
+	w, err = GetWorkout(db.Preload("Data.Laps"), int(w.ID))
+	require.NoError(t, err)
+	assert.Len(t, w.Data.Laps, 2)
+}
+
+func TestWorkout_DeleteRemovesDataChildren(t *testing.T) {
+	db := createMemoryDB(t)
+
+	u := defaultUser()
+	require.NoError(t, u.Create(db))
+
+	laps, err := u.AddWorkout(db, WorkoutTypeRunning, "", "laps.tcx", []byte(tcxTwoLaps))
+	require.NoError(t, err)
+
+	hill := addHill(t, db, u, 6, 0)
+
+	count := func(model any) int64 {
+		var c int64
+		require.NoError(t, db.Model(model).Count(&c).Error)
+
+		return c
+	}
+
+	require.Equal(t, int64(2), count(&Lap{}))
+	require.Positive(t, count(&BestEffort{}))
+	require.Equal(t, int64(1), count(&Climb{}))
+
+	// The workouts are deleted without their map data loaded
+	laps.Data = nil
+
+	require.NoError(t, laps.Delete(db))
+	require.NoError(t, hill.Delete(db))
+
+	assert.Zero(t, count(&Lap{}))
+	assert.Zero(t, count(&BestEffort{}))
+	assert.Zero(t, count(&Climb{}))
+}
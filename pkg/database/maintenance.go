@@ -0,0 +1,267 @@
+package database
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var ErrInvalidMaintenanceItem = errors.New("invalid maintenance item")
+
+// MaintenanceMetric is what the usage of equipment is counted in, towards
+// the interval of a maintenance item
+type MaintenanceMetric string
+
+const (
+	MaintenanceMetricDistance    MaintenanceMetric = "distance"    // The distance, in meters
+	MaintenanceMetricDuration    MaintenanceMetric = "duration"    // The duration, in seconds
+	MaintenanceMetricRepetitions MaintenanceMetric = "repetitions" // The number of repetitions
+	MaintenanceMetricWorkouts    MaintenanceMetric = "workouts"    // The number of workouts
+)
+
+func MaintenanceMetrics() []MaintenanceMetric {
+	return []MaintenanceMetric{
+		MaintenanceMetricDistance, MaintenanceMetricDuration,
+		MaintenanceMetricRepetitions, MaintenanceMetricWorkouts,
+	}
+}
+
+func (m MaintenanceMetric) String() string {
+	return string(m)
+}
+
+func (m MaintenanceMetric) IsValid() bool {
+	return slices.Contains(MaintenanceMetrics(), m)
+}
+
+// Value returns the usage in the metric
+func (m MaintenanceMetric) Value(t WorkoutTotals) float64 {
+	switch m {
+	case MaintenanceMetricDistance:
+		return t.Distance
+	case MaintenanceMetricDuration:
+		return t.Duration.Seconds()
+	case MaintenanceMetricRepetitions:
+		return float64(t.Repetitions)
+	default:
+		return float64(t.Workouts)
+	}
+}
+
+// MaintenanceItem is something that has to be done to equipment after some
+// usage, eg. "replace the chain every 3000 km" or "retire the shoes at 800 km"
+type MaintenanceItem struct {
+	gorm.Model
+	EquipmentID uint              `gorm:"not null;index" json:"-"`  // The ID of the equipment
+	Name        string            `form:"name" json:"name"`         // What has to be done
+	Metric      MaintenanceMetric `form:"metric" json:"metric"`     // What the usage is counted in
+	Interval    float64           `form:"interval" json:"interval"` // The usage after which it has to be done; in meters for distance, in seconds for duration
+	Retire      bool              `form:"retire" json:"retire"`     // Whether the equipment has to be retired, rather than serviced
+
+	Equipment *Equipment `json:"-"` // The equipment
+}
+
+// EquipmentService is a completed service of equipment
+type EquipmentService struct {
+	gorm.Model
+	EquipmentID       uint      `gorm:"not null;index" json:"-"`                      // The ID of the equipment
+	MaintenanceItemID *uint     `gorm:"index" form:"item" json:"maintenance_item_id"` // The ID of the maintenance item that was done; empty for other services
+	Date              time.Time `json:"date"`                                         // When the service was done
+	Notes             string    `form:"notes" json:"notes"`                           // Notes about the service
+
+	Equipment       *Equipment       `json:"-"`                          // The equipment
+	MaintenanceItem *MaintenanceItem `json:"maintenance_item,omitempty"` // The maintenance item that was done
+}
+
+func (i *MaintenanceItem) Validate() error {
+	i.Name = strings.TrimSpace(i.Name)
+
+	if i.Name == "" || !i.Metric.IsValid() || i.Interval <= 0 {
+		return ErrInvalidMaintenanceItem
+	}
+
+	return nil
+}
+
+// IntervalDuration returns the interval of a duration item
+func (i *MaintenanceItem) IntervalDuration() time.Duration {
+	return time.Duration(i.Interval * float64(time.Second))
+}
+
+func (i *MaintenanceItem) Save(db *gorm.DB) error {
+	if err := i.Validate(); err != nil {
+		return err
+	}
+
+	return db.Save(i).Error
+}
+
+// Delete removes the item; services of the item are kept in the log
+func (i *MaintenanceItem) Delete(db *gorm.DB) error {
+	if err := db.Model(&EquipmentService{}).
+		Where(&EquipmentService{MaintenanceItemID: &i.ID}).
+		Update("maintenance_item_id", nil).Error; err != nil {
+		return err
+	}
+
+	return db.Unscoped().Delete(i).Error
+}
+
+func (s *EquipmentService) Save(db *gorm.DB) error {
+	if s.Date.IsZero() {
+		s.Date = time.Now()
+	}
+
+	return db.Save(s).Error
+}
+
+func (s *EquipmentService) Delete(db *gorm.DB) error {
+	return db.Unscoped().Delete(s).Error
+}
+
+// MaintenanceStatus is the usage of equipment towards a maintenance item,
+// since it was last done
+type MaintenanceStatus struct {
+	Item        MaintenanceItem // The maintenance item
+	EquipmentID uint            // The ID of the equipment
+	Equipment   *Equipment      `json:"-"` // The equipment
+	LastService *time.Time      // When the item was last done; empty if it was never done
+	Usage       float64         // The usage since the item was last done; in meters for distance, in seconds for duration
+	Percentage  float64         // The usage, as a percentage of the interval
+}
+
+// Due returns whether the interval has been reached
+func (s *MaintenanceStatus) Due() bool {
+	return s.Usage >= s.Item.Interval
+}
+
+// UsageDuration returns the usage of a duration item
+func (s *MaintenanceStatus) UsageDuration() time.Duration {
+	return time.Duration(s.Usage * float64(time.Second))
+}
+
+// LastService returns when the maintenance item was last done, or nil
+func (e *Equipment) LastService(item *MaintenanceItem) *time.Time {
+	var last *time.Time
+
+	for _, s := range e.Services {
+		if s.MaintenanceItemID == nil || *s.MaintenanceItemID != item.ID {
+			continue
+		}
+
+		if last == nil || s.Date.After(*last) {
+			last = &s.Date
+		}
+	}
+
+	return last
+}
+
+// GetTotalsSince returns the usage of the equipment in the workouts after the
+// time; all workouts are counted if the time is nil, except those left out of
+// the statistics
+func (e *Equipment) GetTotalsSince(since *time.Time) WorkoutTotals {
+	rs := WorkoutTotals{}
+
+	for _, w := range e.Workouts {
+		if w.ExcludeFromStatistics {
+			continue
+		}
+
+		if since != nil && (w.Date == nil || !w.Date.After(*since)) {
+			continue
+		}
+
+		rs.Add(&w)
+	}
+
+	return rs
+}
+
+// MaintenanceStatus returns the status of all maintenance items of the
+// equipment; the workouts (with their data), maintenance items and services
+// must be loaded
+func (e *Equipment) MaintenanceStatus() []MaintenanceStatus {
+	r := make([]MaintenanceStatus, 0, len(e.MaintenanceItems))
+
+	for _, item := range e.MaintenanceItems {
+		last := e.LastService(&item)
+		s := MaintenanceStatus{
+			Item:        item,
+			EquipmentID: e.ID,
+			Equipment:   e,
+			LastService: last,
+			Usage:       item.Metric.Value(e.GetTotalsSince(last)),
+		}
+
+		s.Percentage = 100 * s.Usage / item.Interval
+
+		r = append(r, s)
+	}
+
+	return r
+}
+
+// MaintenanceAlerts returns the maintenance items of the equipment that are
+// due; inactive equipment has no alerts
+func (e *Equipment) MaintenanceAlerts() []MaintenanceStatus {
+	if !e.Active {
+		return nil
+	}
+
+	return slices.DeleteFunc(e.MaintenanceStatus(), func(s MaintenanceStatus) bool {
+		return !s.Due()
+	})
+}
+
+// preloadMaintenance loads everything needed for the maintenance status
+func preloadMaintenance(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("Workouts.Data").
+		Preload("MaintenanceItems").
+		Preload("Services", func(db *gorm.DB) *gorm.DB { return db.Order("date DESC") }).
+		Preload("Services.MaintenanceItem")
+}
+
+// GetMaintenanceAlerts returns the maintenance items that are due for all
+// active equipment of the user
+func (u *User) GetMaintenanceAlerts(db *gorm.DB) ([]MaintenanceStatus, error) {
+	var equipment []*Equipment
+
+	if err := preloadMaintenance(db).
+		Where(&Equipment{UserID: u.ID, Active: true}).
+		Order("name").
+		Find(&equipment).Error; err != nil {
+		return nil, err
+	}
+
+	r := []MaintenanceStatus{}
+	for _, e := range equipment {
+		r = append(r, e.MaintenanceAlerts()...)
+	}
+
+	return r, nil
+}
+
+func (e *Equipment) GetMaintenanceItem(db *gorm.DB, id int) (*MaintenanceItem, error) {
+	var i MaintenanceItem
+
+	if err := db.Where(&MaintenanceItem{EquipmentID: e.ID}).First(&i, id).Error; err != nil {
+		return nil, err
+	}
+
+	return &i, nil
+}
+
+func (e *Equipment) GetService(db *gorm.DB, id int) (*EquipmentService, error) {
+	var s EquipmentService
+
+	if err := db.Where(&EquipmentService{EquipmentID: e.ID}).First(&s, id).Error; err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
@@ -0,0 +1,109 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaintenanceItem_Validate(t *testing.T) {
+	assert.NoError(t, (&MaintenanceItem{Name: "chain", Metric: MaintenanceMetricDistance, Interval: 3000000}).Validate())
+	assert.ErrorIs(t, (&MaintenanceItem{Name: " ", Metric: MaintenanceMetricDistance, Interval: 1}).Validate(), ErrInvalidMaintenanceItem)
+	assert.ErrorIs(t, (&MaintenanceItem{Name: "chain", Metric: "kilograms", Interval: 1}).Validate(), ErrInvalidMaintenanceItem)
+	assert.ErrorIs(t, (&MaintenanceItem{Name: "chain", Metric: MaintenanceMetricWorkouts}).Validate(), ErrInvalidMaintenanceItem)
+}
+
+func TestEquipment_MaintenanceStatus(t *testing.T) {
+	db := createMemoryDB(t)
+
+	u := defaultUser()
+	require.NoError(t, u.Create(db))
+
+	e := &Equipment{Name: "bike", UserID: u.ID, Active: true}
+	require.NoError(t, e.Save(db))
+
+	for i := range 4 {
+		d := time.Date(2024, 1, 1+i, 10, 0, 0, 0, time.UTC)
+		w := &Workout{
+			UserID: u.ID, Name: "ride", Type: WorkoutTypeCycling, Date: &d,
+			Data: &MapData{TotalDistance: 100000, TotalDuration: time.Hour},
+		}
+		require.NoError(t, w.Create(db))
+		require.NoError(t, db.Model(w).Association("Equipment").Append(e))
+	}
+
+	chain := &MaintenanceItem{EquipmentID: e.ID, Name: "chain", Metric: MaintenanceMetricDistance, Interval: 300000}
+	require.NoError(t, chain.Save(db))
+
+	retire := &MaintenanceItem{EquipmentID: e.ID, Name: "bike", Metric: MaintenanceMetricWorkouts, Interval: 10, Retire: true}
+	require.NoError(t, retire.Save(db))
+
+	alerts, err := u.GetMaintenanceAlerts(db)
+	require.NoError(t, err)
+	require.Len(t, alerts, 1)
+	assert.Equal(t, "chain", alerts[0].Item.Name)
+	assert.Equal(t, e.ID, alerts[0].EquipmentID)
+	assert.InDelta(t, 400000, alerts[0].Usage, 0.1)
+
+	// A service after the second workout resets the usage of the item
+	s := &EquipmentService{EquipmentID: e.ID, MaintenanceItemID: &chain.ID, Date: time.Date(2024, 1, 2, 12, 0, 0, 0, time.UTC)}
+	require.NoError(t, s.Save(db))
+	require.NoError(t, (&EquipmentService{EquipmentID: e.ID, Notes: "cleaned"}).Save(db))
+
+	e, err = GetEquipment(db, int(e.ID))
+	require.NoError(t, err)
+	require.Len(t, e.Services, 2)
+
+	status := e.MaintenanceStatus()
+	require.Len(t, status, 2)
+	assert.InDelta(t, 200000, status[0].Usage, 0.1)
+	assert.InDelta(t, 66.67, status[0].Percentage, 0.01)
+	assert.False(t, status[0].Due())
+	require.NotNil(t, status[0].LastService)
+	assert.InDelta(t, 4, status[1].Usage, 0.1)
+	assert.Nil(t, status[1].LastService)
+
+	alerts, err = u.GetMaintenanceAlerts(db)
+	require.NoError(t, err)
+	assert.Empty(t, alerts)
+
+	// Workouts left out of the statistics don't count towards the usage
+	for i := range e.Workouts {
+		e.Workouts[i].ExcludeFromStatistics = e.Workouts[i].Date.Day() == 4
+	}
+
+	status = e.MaintenanceStatus()
+	assert.InDelta(t, 100000, status[0].Usage, 0.1)
+	assert.InDelta(t, 3, status[1].Usage, 0.1)
+
+	totals, err := e.GetTotals()
+	require.NoError(t, err)
+	assert.Equal(t, 3, totals.Workouts)
+	assert.InDelta(t, 300000, totals.Distance, 0.1)
+
+	// Inactive equipment has no alerts
+	e.Active = false
+	require.NoError(t, e.Save(db))
+	require.NoError(t, s.Delete(db))
+
+	alerts, err = u.GetMaintenanceAlerts(db)
+	require.NoError(t, err)
+	assert.Empty(t, alerts)
+
+	// Deleting an item keeps its services in the log
+	require.NoError(t, (&EquipmentService{EquipmentID: e.ID, MaintenanceItemID: &chain.ID}).Save(db))
+	require.NoError(t, chain.Delete(db))
+
+	e, err = GetEquipment(db, int(e.ID))
+	require.NoError(t, err)
+	assert.Len(t, e.MaintenanceItems, 1)
+	assert.Len(t, e.Services, 2)
+
+	require.NoError(t, e.Delete(db))
+
+	var count int64
+	require.NoError(t, db.Model(&EquipmentService{}).Count(&count).Error)
+	assert.Zero(t, count)
+}
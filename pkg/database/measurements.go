@@ -0,0 +1,398 @@
+package database
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidMeasurement    = errors.New("invalid measurement")
+	ErrInvalidMeasurementCSV = errors.New("invalid measurement CSV")
+)
+
+// measurementDateFormats are the date formats that are accepted in CSV files
+var measurementDateFormats = []string{
+	time.RFC3339,
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// Measurement is a body measurement of a user at a point in time; values that
+// were not measured are empty
+type Measurement struct {
+	gorm.Model
+	UserID           uint      `gorm:"not null;index" json:"-"`      // The ID of the user who owns the measurement
+	Date             time.Time `gorm:"not null;index" json:"date"`   // When the measurement was taken
+	Weight           *float64  `json:"weight,omitempty"`             // The body weight, in kilograms
+	BodyFat          *float64  `json:"body_fat,omitempty"`           // The body fat, in percent
+	RestingHeartRate *float64  `json:"resting_heart_rate,omitempty"` // The resting heart rate, in beats per minute
+	HRV              *float64  `json:"hrv,omitempty"`                // The heart rate variability (RMSSD), in milliseconds
+	Waist            *float64  `json:"waist,omitempty"`              // The waist circumference, in centimeters
+	Notes            string    `json:"notes"`                        // Notes about the measurement
+	User             *User     `json:"-"`                            // The user who owns the measurement
+}
+
+// HasValues returns whether anything was measured
+func (m *Measurement) HasValues() bool {
+	return m.Weight != nil || m.BodyFat != nil || m.RestingHeartRate != nil || m.HRV != nil || m.Waist != nil
+}
+
+func (m *Measurement) Validate() error {
+	if m.Date.IsZero() || !m.HasValues() {
+		return ErrInvalidMeasurement
+	}
+
+	for _, v := range []*float64{m.Weight, m.RestingHeartRate, m.HRV, m.Waist} {
+		if v != nil && *v <= 0 {
+			return ErrInvalidMeasurement
+		}
+	}
+
+	if m.BodyFat != nil && (*m.BodyFat <= 0 || *m.BodyFat >= 100) {
+		return ErrInvalidMeasurement
+	}
+
+	return nil
+}
+
+// Save stores the measurement; when it has a weight, or an existing
+// measurement may have lost its weight, the calories of the user's workouts
+// are recalculated
+func (m *Measurement) Save(db *gorm.DB) error {
+	affectsWorkouts := m.Weight != nil || m.ID != 0
+
+	if err := m.save(db); err != nil {
+		return err
+	}
+
+	if !affectsWorkouts {
+		return nil
+	}
+
+	return m.markWorkoutsDirty(db)
+}
+
+func (m *Measurement) save(db *gorm.DB) error {
+	if err := m.Validate(); err != nil {
+		return err
+	}
+
+	return db.Save(m).Error
+}
+
+func (m *Measurement) Delete(db *gorm.DB) error {
+	if err := db.Unscoped().Delete(m).Error; err != nil {
+		return err
+	}
+
+	if m.Weight == nil {
+		return nil
+	}
+
+	return m.markWorkoutsDirty(db)
+}
+
+// markWorkoutsDirty marks all the user's workouts for recalculation, since
+// their calories depend on the body weight at their date; workouts before the
+// first weight measurement use that first weight, so no workout is safe from
+// a change
+func (m *Measurement) markWorkoutsDirty(db *gorm.DB) error {
+	return db.Model(&Workout{}).Where(&Workout{UserID: m.UserID}).Update("dirty", true).Error
+}
+
+// merge copies the values that were measured in other
+func (m *Measurement) merge(other *Measurement) {
+	for _, f := range []struct{ dst, src **float64 }{
+		{&m.Weight, &other.Weight},
+		{&m.BodyFat, &other.BodyFat},
+		{&m.RestingHeartRate, &other.RestingHeartRate},
+		{&m.HRV, &other.HRV},
+		{&m.Waist, &other.Waist},
+	} {
+		if *f.src != nil {
+			*f.dst = *f.src
+		}
+	}
+
+	if other.Notes != "" {
+		m.Notes = other.Notes
+	}
+}
+
+// GetMeasurements returns the user's measurements, most recent first
+func (u *User) GetMeasurements(db *gorm.DB) ([]Measurement, error) {
+	var m []Measurement
+
+	if err := db.Where(&Measurement{UserID: u.ID}).Order("date DESC").Find(&m).Error; err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// GetMeasurementsSince returns the user's measurements after the time, oldest
+// first
+func (u *User) GetMeasurementsSince(db *gorm.DB, since time.Time) ([]Measurement, error) {
+	var m []Measurement
+
+	if err := db.Where(&Measurement{UserID: u.ID}).
+		Where("date >= ?", since).
+		Order("date").
+		Find(&m).Error; err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+func (u *User) GetMeasurement(db *gorm.DB, id int) (*Measurement, error) {
+	var m Measurement
+
+	if err := db.Where(&Measurement{UserID: u.ID}).First(&m, id).Error; err != nil {
+		return nil, err
+	}
+
+	return &m, nil
+}
+
+// WeightAt returns the user's body weight at the time, in kilograms: the most
+// recent weight measured before it, or else the first weight measured after
+// it; it returns 0 if the user never measured their weight
+func (u *User) WeightAt(db *gorm.DB, t time.Time) (float64, error) {
+	var m Measurement
+
+	q := db.Where(&Measurement{UserID: u.ID}).Where("weight IS NOT NULL")
+
+	err := q.Session(&gorm.Session{}).Where("date <= ?", t).Order("date DESC").First(&m).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = q.Session(&gorm.Session{}).Order("date").First(&m).Error
+	}
+
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return 0, nil
+	case err != nil:
+		return 0, err
+	}
+
+	return *m.Weight, nil
+}
+
+// LatestWeight returns the user's most recent body weight, in kilograms; it
+// returns 0 if the user never measured their weight
+func (u *User) LatestWeight(db *gorm.DB) (float64, error) {
+	return u.WeightAt(db, time.Now())
+}
+
+// ImportMeasurements stores the measurements for the user; a measurement at
+// the same time as an existing one updates the values of the existing one.
+// When a weight was imported, the user's workouts are recalculated.
+func (u *User) ImportMeasurements(db *gorm.DB, measurements []Measurement) (int, error) {
+	err := db.Transaction(func(tx *gorm.DB) error {
+		weights := false
+
+		for _, m := range measurements {
+			weights = weights || m.Weight != nil
+			existing := Measurement{}
+
+			err := tx.Where(&Measurement{UserID: u.ID}).Where("date = ?", m.Date).First(&existing).Error
+			switch {
+			case err == nil:
+				existing.merge(&m)
+				m = existing
+			case !errors.Is(err, gorm.ErrRecordNotFound):
+				return err
+			default:
+				m.Model = gorm.Model{}
+				m.UserID = u.ID
+			}
+
+			if err := m.save(tx); err != nil {
+				return err
+			}
+		}
+
+		if !weights {
+			return nil
+		}
+
+		return u.MarkWorkoutsDirty(tx)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return len(measurements), nil
+}
+
+// ParseMeasurementsCSV reads measurements from a CSV file with a header row;
+// the columns are "date", "weight" (in the preferred unit), "body_fat",
+// "resting_heart_rate", "hrv", "waist" and "notes". Only the date is
+// required, other columns are ignored. Dates without a timezone are in loc.
+func ParseMeasurementsCSV(r io.Reader, units UserPreferredUnits, loc *time.Location) ([]Measurement, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidMeasurementCSV, err)
+	}
+
+	columns := map[string]int{}
+
+	for i, h := range header {
+		columns[strings.ReplaceAll(strings.ToLower(strings.TrimSpace(h)), " ", "_")] = i
+	}
+
+	if _, ok := columns["date"]; !ok {
+		return nil, fmt.Errorf("%w: no date column", ErrInvalidMeasurementCSV)
+	}
+
+	var measurements []Measurement
+
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidMeasurementCSV, err)
+		}
+
+		m, err := parseMeasurementRecord(record, columns, units, loc)
+		if err != nil {
+			line, _ := cr.FieldPos(0)
+			return nil, fmt.Errorf("%w: line %d: %w", ErrInvalidMeasurementCSV, line, err)
+		}
+
+		measurements = append(measurements, *m)
+	}
+
+	return measurements, nil
+}
+
+func parseMeasurementRecord(record []string, columns map[string]int, units UserPreferredUnits, loc *time.Location) (*Measurement, error) {
+	field := func(name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+
+		return strings.TrimSpace(record[i])
+	}
+
+	d, err := parseMeasurementDate(field("date"), loc)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Measurement{Date: d, Notes: field("notes")}
+
+	for _, f := range []struct {
+		name string
+		dst  **float64
+	}{
+		{"weight", &m.Weight},
+		{"body_fat", &m.BodyFat},
+		{"resting_heart_rate", &m.RestingHeartRate},
+		{"hrv", &m.HRV},
+		{"waist", &m.Waist},
+	} {
+		if *f.dst, err = ParseMeasurementValue(field(f.name)); err != nil {
+			return nil, err
+		}
+	}
+
+	if m.Weight != nil {
+		w := units.WeightToDatabase(*m.Weight)
+		m.Weight = &w
+	}
+
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+func parseMeasurementDate(s string, loc *time.Location) (time.Time, error) {
+	for _, f := range measurementDateFormats {
+		if d, err := time.ParseInLocation(f, s, loc); err == nil {
+			return d, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("%w: invalid date: %q", ErrInvalidMeasurement, s)
+}
+
+// ParseMeasurementValue parses an optional value; an empty string is no value
+func ParseMeasurementValue(s string) (*float64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil //nolint:nilnil
+	}
+
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidMeasurement, err)
+	}
+
+	return &v, nil
+}
+
+// MeasurementPoint is a single value of a measurement trend
+type MeasurementPoint struct {
+	Date  time.Time // When the value was measured
+	Value float64   // The value
+}
+
+// MeasurementTrends are the values of the measurements over time, per kind
+// of measurement, oldest first
+type MeasurementTrends struct {
+	Weight           []MeasurementPoint `json:",omitempty"` // The body weight, in kilograms
+	BodyFat          []MeasurementPoint `json:",omitempty"` // The body fat, in percent
+	RestingHeartRate []MeasurementPoint `json:",omitempty"` // The resting heart rate, in beats per minute
+	HRV              []MeasurementPoint `json:",omitempty"` // The heart rate variability, in milliseconds
+	Waist            []MeasurementPoint `json:",omitempty"` // The waist circumference, in centimeters
+}
+
+// NewMeasurementTrends splits the measurements, ordered by date, into trends
+func NewMeasurementTrends(measurements []Measurement) MeasurementTrends {
+	t := MeasurementTrends{}
+
+	for _, m := range measurements {
+		for _, f := range []struct {
+			v   *float64
+			dst *[]MeasurementPoint
+		}{
+			{m.Weight, &t.Weight},
+			{m.BodyFat, &t.BodyFat},
+			{m.RestingHeartRate, &t.RestingHeartRate},
+			{m.HRV, &t.HRV},
+			{m.Waist, &t.Waist},
+		} {
+			if f.v != nil {
+				*f.dst = append(*f.dst, MeasurementPoint{Date: m.Date, Value: *f.v})
+			}
+		}
+	}
+
+	return t
+}
+
+// IsEmpty returns whether there are no measurements in any trend
+func (t MeasurementTrends) IsEmpty() bool {
+	return len(t.Weight) == 0 && len(t.BodyFat) == 0 && len(t.RestingHeartRate) == 0 &&
+		len(t.HRV) == 0 && len(t.Waist) == 0
+}
@@ -0,0 +1,189 @@
+package database
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ptr(v float64) *float64 {
+	return &v
+}
+
+func TestMeasurement_Validate(t *testing.T) {
+	d := time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)
+
+	assert.NoError(t, (&Measurement{Date: d, Weight: ptr(80)}).Validate())
+	assert.NoError(t, (&Measurement{Date: d, BodyFat: ptr(20), HRV: ptr(55)}).Validate())
+	assert.ErrorIs(t, (&Measurement{Date: d}).Validate(), ErrInvalidMeasurement)
+	assert.ErrorIs(t, (&Measurement{Weight: ptr(80)}).Validate(), ErrInvalidMeasurement)
+	assert.ErrorIs(t, (&Measurement{Date: d, Weight: ptr(-1)}).Validate(), ErrInvalidMeasurement)
+	assert.ErrorIs(t, (&Measurement{Date: d, BodyFat: ptr(100)}).Validate(), ErrInvalidMeasurement)
+}
+
+func TestUser_WeightAt(t *testing.T) {
+	db := createMemoryDB(t)
+
+	u := defaultUser()
+	require.NoError(t, u.Create(db))
+
+	w, err := u.LatestWeight(db)
+	require.NoError(t, err)
+	assert.Zero(t, w)
+
+	for i, v := range []float64{82, 81, 80} {
+		m := &Measurement{UserID: u.ID, Date: time.Date(2024, 1, 10*(i+1), 8, 0, 0, 0, time.UTC), Weight: ptr(v)}
+		require.NoError(t, m.Save(db))
+	}
+
+	require.NoError(t, (&Measurement{UserID: u.ID, Date: time.Date(2024, 2, 1, 8, 0, 0, 0, time.UTC), HRV: ptr(60)}).Save(db))
+
+	w, err = u.LatestWeight(db)
+	require.NoError(t, err)
+	assert.InDelta(t, 80, w, 0.01)
+
+	w, err = u.WeightAt(db, time.Date(2024, 1, 25, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.InDelta(t, 81, w, 0.01)
+
+	// Before the first measurement, the first weight is used
+	w, err = u.WeightAt(db, time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.InDelta(t, 82, w, 0.01)
+
+	ms, err := u.GetMeasurementsSince(db, time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+
+	trends := NewMeasurementTrends(ms)
+	assert.Len(t, trends.Weight, 2)
+	assert.Len(t, trends.HRV, 1)
+	assert.Empty(t, trends.Waist)
+	assert.False(t, trends.IsEmpty())
+}
+
+func TestParseMeasurementsCSV(t *testing.T) {
+	content := `Date,Weight,Body fat,Resting heart rate,Waist,Source
+2024-01-01,176.4,20.5,,,scale
+2024-01-02 07:30,,,52,81,watch
+`
+
+	ms, err := ParseMeasurementsCSV(strings.NewReader(content), UserPreferredUnits{WeightRaw: "lbs"}, time.UTC)
+	require.NoError(t, err)
+	require.Len(t, ms, 2)
+
+	require.NotNil(t, ms[0].Weight)
+	assert.InDelta(t, 80, *ms[0].Weight, 0.05)
+	assert.InDelta(t, 20.5, *ms[0].BodyFat, 0.01)
+	assert.Nil(t, ms[0].RestingHeartRate)
+	assert.Equal(t, time.Date(2024, 1, 2, 7, 30, 0, 0, time.UTC), ms[1].Date)
+	assert.InDelta(t, 52, *ms[1].RestingHeartRate, 0.01)
+	assert.Nil(t, ms[1].Weight)
+
+	_, err = ParseMeasurementsCSV(strings.NewReader("weight\n80\n"), UserPreferredUnits{}, time.UTC)
+	require.ErrorIs(t, err, ErrInvalidMeasurementCSV)
+
+	_, err = ParseMeasurementsCSV(strings.NewReader("date,weight\n2024-01-01,80\nyesterday,81\n"), UserPreferredUnits{}, time.UTC)
+	require.ErrorIs(t, err, ErrInvalidMeasurementCSV)
+	assert.Contains(t, err.Error(), "line 3")
+}
+
+func TestUser_ImportMeasurements(t *testing.T) {
+	db := createMemoryDB(t)
+
+	u := defaultUser()
+	require.NoError(t, u.Create(db))
+
+	d := time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)
+	require.NoError(t, (&Measurement{UserID: u.ID, Date: d, Weight: ptr(80), Notes: "manual"}).Save(db))
+
+	n, err := u.ImportMeasurements(db, []Measurement{
+		{Date: d, BodyFat: ptr(20)},
+		{Date: d.AddDate(0, 0, 1), Weight: ptr(79)},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	ms, err := u.GetMeasurements(db)
+	require.NoError(t, err)
+	require.Len(t, ms, 2)
+
+	assert.InDelta(t, 79, *ms[0].Weight, 0.01)
+	assert.InDelta(t, 80, *ms[1].Weight, 0.01)
+	assert.InDelta(t, 20, *ms[1].BodyFat, 0.01)
+	assert.Equal(t, "manual", ms[1].Notes)
+}
+
+func TestMeasurement_MarksWorkoutsDirty(t *testing.T) {
+	db := createMemoryDB(t)
+
+	u := defaultUser()
+	require.NoError(t, u.Create(db))
+
+	_, err := u.AddWorkout(db, WorkoutTypeRunning, "", "sample.gpx", []byte(GpxSample1))
+	require.NoError(t, err)
+
+	dirty := func() bool {
+		t.Helper()
+
+		var count int64
+
+		require.NoError(t, db.Model(&Workout{}).Where(&Workout{UserID: u.ID, Dirty: true}).Count(&count).Error)
+		require.NoError(t, db.Model(&Workout{}).Where(&Workout{UserID: u.ID}).Update("dirty", false).Error)
+
+		return count > 0
+	}
+
+	d := time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)
+
+	require.NoError(t, (&Measurement{UserID: u.ID, Date: d, HRV: ptr(60)}).Save(db))
+	assert.False(t, dirty())
+
+	m := &Measurement{UserID: u.ID, Date: d.AddDate(0, 0, 1), Weight: ptr(80)}
+	require.NoError(t, m.Save(db))
+	assert.True(t, dirty())
+
+	_, err = u.ImportMeasurements(db, []Measurement{{Date: d, BodyFat: ptr(20)}})
+	require.NoError(t, err)
+	assert.False(t, dirty())
+
+	_, err = u.ImportMeasurements(db, []Measurement{{Date: d, Weight: ptr(81)}})
+	require.NoError(t, err)
+	assert.True(t, dirty())
+
+	require.NoError(t, m.Delete(db))
+	assert.True(t, dirty())
+}
+
+func TestMeasurement_UpdatesManualWorkouts(t *testing.T) {
+	db := createMemoryDB(t)
+
+	u := defaultUser()
+	require.NoError(t, u.Create(db))
+
+	d := time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC)
+	w := &Workout{
+		UserID: u.ID, Name: "walk", Type: WorkoutTypeWalking, Date: &d,
+		Data: &MapData{TotalDistance: 5000, TotalDuration: time.Hour},
+	}
+	require.NoError(t, w.Create(db))
+
+	before := w.Data.Calories
+	require.Positive(t, before)
+
+	require.NoError(t, (&Measurement{UserID: u.ID, Date: d, Weight: ptr(140)}).Save(db))
+
+	w, err := GetWorkoutWithGPX(db, int(w.ID))
+	require.NoError(t, err)
+	require.True(t, w.Dirty)
+
+	require.NoError(t, w.UpdateData(db))
+
+	w, err = GetWorkoutWithGPX(db, int(w.ID))
+	require.NoError(t, err)
+	assert.False(t, w.Dirty)
+	assert.Greater(t, w.Data.Calories, before)
+	assert.Equal(t, CaloriesSourceMET, w.Data.CaloriesSource)
+}
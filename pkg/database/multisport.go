@@ -0,0 +1,149 @@
+package database
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jovandeginste/workout-tracker/pkg/converters"
+	"gorm.io/gorm"
+)
+
+// IsLeg returns whether the workout is a leg of a multisport workout
+func (w *Workout) IsLeg() bool {
+	return w.ParentID != nil
+}
+
+// HasLegs returns whether the workout is a multisport workout with legs
+func (w *Workout) HasLegs() bool {
+	return len(w.Legs) > 0
+}
+
+// LegsDuration returns the total duration of the legs
+func (w *Workout) LegsDuration() time.Duration {
+	var d time.Duration
+
+	for i := range w.Legs {
+		d += w.Legs[i].Duration()
+	}
+
+	return d
+}
+
+// LegShare returns the share of the leg in the duration of all legs, as a
+// percentage; it is used to draw the timeline of the legs
+func (w *Workout) LegShare(leg *Workout) float64 {
+	total := w.LegsDuration()
+	if total <= 0 {
+		return 0
+	}
+
+	return 100 * float64(leg.Duration()) / float64(total)
+}
+
+// legName returns the name of a leg: transitions are numbered (T1, T2, ...),
+// other legs are named after their type
+func legName(parent string, wt WorkoutType, transitions int) string {
+	if wt == WorkoutTypeTransition {
+		return fmt.Sprintf("%s - T%d", parent, transitions)
+	}
+
+	return fmt.Sprintf("%s - %s", parent, wt)
+}
+
+// createLegs imports the sessions of a multi-session file (eg. the swim,
+// transitions, bike and run of a triathlon) as legs of the workout; each leg
+// is a workout of its own type, with a FIT file of its session, so it keeps
+// the calories, laps and lengths recorded for that session
+func (w *Workout) createLegs(db *gorm.DB) error {
+	if !w.HasFile() || w.User == nil {
+		return nil
+	}
+
+	sessions, err := converters.SplitSessions(w.GPX.Filename, w.GPX.Content)
+	if err != nil || len(sessions) == 0 {
+		return err
+	}
+
+	base := strings.TrimSuffix(w.GPX.Filename, filepath.Ext(w.GPX.Filename))
+	transitions := 0
+
+	for i, content := range sessions {
+		leg, err := NewWorkout(w.User, WorkoutTypeAutoDetect, "", fmt.Sprintf("%s-%d.fit", base, i+1), content)
+		if err != nil {
+			return err
+		}
+
+		if leg.Type == WorkoutTypeTransition {
+			transitions++
+		}
+
+		leg.ParentID = &w.ID
+		leg.Leg = i + 1
+		leg.Name = legName(w.Name, leg.Type, transitions)
+		leg.Visibility = w.Visibility
+
+		if err := leg.Create(db); err != nil {
+			return err
+		}
+
+		if err := leg.MatchSegments(db); err != nil {
+			return err
+		}
+
+		if err := w.User.setDefaultEquipment(db, leg); err != nil {
+			return err
+		}
+
+		w.Legs = append(w.Legs, *leg)
+	}
+
+	return nil
+}
+
+// deleteLegs deletes the legs of a multisport workout
+func (w *Workout) deleteLegs(db *gorm.DB) error {
+	if w.ID == 0 {
+		return nil
+	}
+
+	var legs []*Workout
+	if err := db.Where("parent_id = ?", w.ID).Find(&legs).Error; err != nil {
+		return err
+	}
+
+	for _, l := range legs {
+		if err := l.Delete(db); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// updateLegs gives the legs of a multisport workout the visibility of the
+// workout, so they are not shown to users who can not see the workout
+func (w *Workout) updateLegs(db *gorm.DB) error {
+	if w.ID == 0 || w.IsLeg() {
+		return nil
+	}
+
+	if err := db.Model(&Workout{}).Where("parent_id = ?", w.ID).Update("visibility", w.Visibility).Error; err != nil {
+		return err
+	}
+
+	for i := range w.Legs {
+		w.Legs[i].Visibility = w.Visibility
+	}
+
+	return nil
+}
+
+// preloadLegs preloads the legs of the workouts with their map data, in order
+func preloadLegs(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("Legs", func(db *gorm.DB) *gorm.DB { return db.Order("leg") }).
+		Preload("Legs.Data").
+		Preload("Parent")
+}
@@ -0,0 +1,212 @@
+package database
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/jovandeginste/workout-tracker/pkg/converters"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tormoder/fit"
+)
+
+// triathlonFIT returns a FIT file with three sessions along a straight line: a
+// swim of 10 minutes, a transition of 2 minutes and a run of 20 minutes; every
+// session has its calories and a lap, and the swim has a pool length
+func triathlonFIT(t *testing.T) []byte {
+	t.Helper()
+
+	start := time.Date(2024, 6, 2, 8, 0, 0, 0, time.UTC)
+	a := &converters.Activity{Name: "Triathlon", Type: "running", Start: start, TotalDuration: 32 * time.Minute}
+
+	for i := range 32*6 + 1 {
+		a.Points = append(a.Points, converters.ActivityPoint{
+			Time: start.Add(time.Duration(i) * 10 * time.Second),
+			Lat:  51 + float64(i)*0.0002,
+			Lng:  4,
+		})
+	}
+
+	content, err := converters.ExportFIT(a)
+	require.NoError(t, err)
+
+	f, err := fit.Decode(bytes.NewReader(content))
+	require.NoError(t, err)
+
+	act, err := f.Activity()
+	require.NoError(t, err)
+
+	act.Sessions, act.Laps = nil, nil
+	offset := time.Duration(0)
+
+	for i, s := range []struct {
+		sport    fit.Sport
+		duration time.Duration
+		calories uint16
+	}{
+		{fit.SportSwimming, 10 * time.Minute, 120},
+		{fit.SportTransition, 2 * time.Minute, 15},
+		{fit.SportRunning, 20 * time.Minute, 250},
+	} {
+		lap := fit.NewLapMsg()
+		lap.MessageIndex = fit.MessageIndex(i)
+		lap.StartTime = start.Add(offset)
+		lap.Timestamp = start.Add(offset + s.duration)
+		lap.TotalTimerTime = uint32(s.duration.Milliseconds())
+		act.Laps = append(act.Laps, lap)
+
+		session := fit.NewSessionMsg()
+		session.MessageIndex = fit.MessageIndex(i)
+		session.StartTime = start.Add(offset)
+		session.Timestamp = start.Add(offset + s.duration)
+		session.Sport = s.sport
+		session.TotalElapsedTime = uint32(s.duration.Milliseconds())
+		session.TotalCalories = s.calories
+		session.FirstLapIndex = uint16(i)
+		session.NumLaps = 1
+
+		if s.sport == fit.SportSwimming {
+			session.PoolLength = 2500
+
+			length := fit.NewLengthMsg()
+			length.StartTime = start.Add(time.Minute)
+			length.TotalTimerTime = 30000
+			length.LengthType = fit.LengthTypeActive
+			act.Lengths = append(act.Lengths, length)
+		}
+
+		act.Sessions = append(act.Sessions, session)
+
+		offset += s.duration
+	}
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, fit.Encode(buf, f, binary.LittleEndian))
+
+	return buf.Bytes()
+}
+
+func TestWorkout_ParseMultisport(t *testing.T) {
+	w, err := NewWorkout(defaultUser(), WorkoutTypeAutoDetect, "", "triathlon.fit", triathlonFIT(t))
+	require.NoError(t, err)
+
+	assert.Equal(t, WorkoutTypeMultisport, w.Type)
+	assert.Equal(t, 32*time.Minute, w.Data.TotalDuration)
+}
+
+func TestUser_AddMultisportWorkout(t *testing.T) {
+	db := createMemoryDB(t)
+	u := defaultUser()
+	require.NoError(t, u.Create(db))
+
+	w, err := u.AddWorkout(db, WorkoutTypeAutoDetect, "", "triathlon.fit", triathlonFIT(t))
+	require.NoError(t, err)
+
+	w, err = GetWorkout(db, int(w.ID))
+	require.NoError(t, err)
+
+	require.True(t, w.HasLegs())
+	require.Len(t, w.Legs, 3)
+
+	swim, transition, run := w.Legs[0], w.Legs[1], w.Legs[2]
+
+	assert.Equal(t, WorkoutTypeSwimming, swim.Type)
+	assert.Equal(t, WorkoutTypeTransition, transition.Type)
+	assert.Equal(t, WorkoutTypeRunning, run.Type)
+	assert.Equal(t, w.Name+" - T1", transition.Name)
+	assert.Equal(t, w.Name+" - running", run.Name)
+
+	assert.Equal(t, 1, swim.Leg)
+	assert.Equal(t, w.Date.Unix(), swim.Date.Unix())
+	assert.Equal(t, 10*time.Minute-10*time.Second, swim.Duration())
+	assert.Equal(t, 20*time.Minute, run.Duration())
+	assert.InDelta(t, w.LegsDuration().Minutes()/20, 100/w.LegShare(&run), 0.01)
+
+	// The legs keep what the device recorded for their session
+	for _, l := range []struct {
+		leg      Workout
+		calories float64
+		lengths  int
+	}{
+		{swim, 120, 1},
+		{transition, 15, 0},
+		{run, 250, 0},
+	} {
+		leg, err := GetWorkoutDetails(db.Preload("Data.Laps").Preload("Data.Lengths"), int(l.leg.ID))
+		require.NoError(t, err)
+
+		assert.InDelta(t, l.calories, leg.Data.Calories, 0.01, l.leg.Name)
+		assert.Equal(t, CaloriesSourceDevice, leg.Data.CaloriesSource, l.leg.Name)
+		assert.Len(t, leg.Data.Laps, 1, l.leg.Name)
+		assert.Len(t, leg.Data.Lengths, l.lengths, l.leg.Name)
+	}
+
+	leg, err := GetWorkout(db, int(run.ID))
+	require.NoError(t, err)
+	assert.True(t, leg.IsLeg())
+	require.NotNil(t, leg.Parent)
+	assert.Equal(t, w.ID, leg.Parent.ID)
+
+	// The legs count towards the totals of their own type
+	u, err = GetUserByID(db, int(u.ID))
+	require.NoError(t, err)
+
+	totals, err := u.GetTotals(WorkoutTypeRunning)
+	require.NoError(t, err)
+	assert.Equal(t, 1, totals.Workouts)
+	assert.InDelta(t, run.Distance(), totals.Distance, 0.01)
+
+	// The legs are only listed when asking for their type
+	p, err := u.GetWorkoutsPage(db, WorkoutQuery{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), p.Total)
+
+	p, err = u.GetWorkoutsPage(db, WorkoutQuery{Type: WorkoutTypeRunning})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), p.Total)
+
+	require.NoError(t, w.Delete(db))
+
+	var count int64
+	require.NoError(t, db.Model(&Workout{}).Count(&count).Error)
+	assert.Zero(t, count)
+}
+
+func TestWorkout_LegsFollowParent(t *testing.T) {
+	db := createMemoryDB(t)
+	u := defaultUser()
+	require.NoError(t, u.Create(db))
+
+	w, err := u.AddWorkout(db, WorkoutTypeAutoDetect, "", "triathlon.fit", triathlonFIT(t))
+	require.NoError(t, err)
+
+	w, err = GetWorkout(db, int(w.ID))
+	require.NoError(t, err)
+	require.Len(t, w.Legs, 3)
+
+	other := &User{Username: "other", Password: "pwd", Name: "other", Active: true}
+	require.NoError(t, other.Create(db))
+	assert.True(t, w.Legs[0].IsVisibleTo(other))
+
+	w.Visibility = WorkoutVisibilityPrivate
+	require.NoError(t, w.Save(db))
+
+	var visible int64
+	require.NoError(t, db.Model(&Workout{}).Scopes(WorkoutsVisibleTo(other)).Count(&visible).Error)
+	assert.Zero(t, visible)
+
+	for _, l := range w.Legs {
+		leg, err := GetWorkout(db, int(l.ID))
+		require.NoError(t, err)
+		assert.Equal(t, WorkoutVisibilityPrivate, leg.Visibility)
+		assert.False(t, leg.IsVisibleTo(other))
+	}
+
+	require.NoError(t, w.Delete(db))
+
+	var count int64
+	require.NoError(t, db.Model(&Workout{}).Where("parent_id = ?", w.ID).Count(&count).Error)
+	assert.Zero(t, count)
+}
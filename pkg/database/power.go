@@ -0,0 +1,204 @@
+package database
+
+import (
+	"encoding/json"
+	"math"
+	"time"
+)
+
+const (
+	// maxPowerInterval is the longest interval between two points that is
+	// counted towards the power metrics; longer intervals are pauses
+	maxPowerInterval = time.Minute
+
+	// normalizedPowerWindow is the rolling window of the normalized power
+	normalizedPowerWindow = 30 * time.Second
+)
+
+// PowerCurveDurations are the durations for which the best average power of a
+// workout is tracked
+func PowerCurveDurations() []time.Duration {
+	return []time.Duration{
+		5 * time.Second, 15 * time.Second, 30 * time.Second,
+		time.Minute, 2 * time.Minute, 5 * time.Minute, 10 * time.Minute,
+		20 * time.Minute, 30 * time.Minute, time.Hour,
+	}
+}
+
+// PowerCurvePoint is the best average power of a workout over a duration
+type PowerCurvePoint struct {
+	Duration time.Duration // The duration of the effort
+	Power    float64       // The average power over the duration, in watts
+}
+
+// powerSeries returns the power of the points resampled to one value per
+// second; pauses are left out
+func powerSeries(points []MapPoint) []float64 {
+	var series []float64
+
+	for _, p := range points {
+		w, ok := p.ExtraMetrics["power"]
+		if !ok || p.Duration <= 0 || p.Duration > maxPowerInterval {
+			continue
+		}
+
+		for range int(math.Round(p.Duration.Seconds())) {
+			series = append(series, max(0, w))
+		}
+	}
+
+	return series
+}
+
+// bestAveragePower returns the highest average of the series over the window,
+// in seconds; it is 0 if the series is shorter than the window
+func bestAveragePower(series []float64, window int) float64 {
+	if window <= 0 || len(series) < window {
+		return 0
+	}
+
+	sum := 0.0
+	for _, w := range series[:window] {
+		sum += w
+	}
+
+	best := sum
+
+	for i := window; i < len(series); i++ {
+		sum += series[i] - series[i-window]
+		best = max(best, sum)
+	}
+
+	return best / float64(window)
+}
+
+// normalizedPower returns the fourth root of the mean of the fourth power of
+// the 30 seconds rolling average
+func normalizedPower(series []float64) float64 {
+	window := int(normalizedPowerWindow.Seconds())
+	if len(series) < window {
+		return 0
+	}
+
+	sum, total := 0.0, 0.0
+	for _, w := range series[:window-1] {
+		sum += w
+	}
+
+	for i := window - 1; i < len(series); i++ {
+		sum += series[i]
+		total += math.Pow(sum/float64(window), 4)
+		sum -= series[i-window+1]
+	}
+
+	return math.Pow(total/float64(len(series)-window+1), 0.25)
+}
+
+// UpdatePower calculates the power metrics (average, normalized and maximum
+// power, the power curve, and with a functional threshold power the intensity
+// factor and training stress score) from the power of the points
+func (m *MapData) UpdatePower(ftp int) {
+	m.AveragePower = 0
+	m.NormalizedPower = 0
+	m.MaxPower = 0
+	m.PowerCurve = nil
+	m.IntensityFactor = 0
+	m.TrainingStressScore = 0
+
+	if m.Details == nil {
+		return
+	}
+
+	for _, p := range m.Details.Points {
+		m.MaxPower = max(m.MaxPower, p.ExtraMetrics.Get("power"))
+	}
+
+	series := powerSeries(m.Details.Points)
+	if len(series) == 0 {
+		return
+	}
+
+	sum := 0.0
+	for _, w := range series {
+		sum += w
+	}
+
+	m.AveragePower = sum / float64(len(series))
+	m.NormalizedPower = normalizedPower(series)
+
+	for _, d := range PowerCurveDurations() {
+		if w := bestAveragePower(series, int(d.Seconds())); w > 0 {
+			m.PowerCurve = append(m.PowerCurve, PowerCurvePoint{Duration: d, Power: w})
+		}
+	}
+
+	if ftp <= 0 || m.NormalizedPower == 0 {
+		return
+	}
+
+	m.IntensityFactor = m.NormalizedPower / float64(ftp)
+	m.TrainingStressScore = 100 * float64(len(series)) * m.NormalizedPower * m.IntensityFactor / (float64(ftp) * 3600)
+}
+
+// HasPower returns whether the power metrics were calculated
+func (m *MapData) HasPower() bool {
+	return m.MaxPower > 0
+}
+
+// PowerCurveRecord is the best average power over a duration over a number of
+// workouts
+type PowerCurveRecord struct {
+	Duration time.Duration // The duration of the effort
+	Power    float64       // The average power over the duration, in watts
+	Date     time.Time     // The timestamp of the workout
+	ID       uint          // The workout ID of the record
+}
+
+// getPowerCurve returns the best average power per duration over all
+// workouts of the type
+func (u *User) getPowerCurve(t WorkoutType) ([]PowerCurveRecord, error) {
+	var rows []struct {
+		ID         uint
+		Date       time.Time
+		PowerCurve string
+	}
+
+	err := u.db.
+		Table("workouts").
+		Joins("join map_data on workouts.id = map_data.workout_id").
+		Where("workouts.user_id = ?", u.ID).
+		Where("workouts.type = ?", t).
+		Where("map_data.max_power > 0").
+		Where("map_data.deleted_at IS NULL AND workouts.deleted_at IS NULL").
+		Scopes(countedInStatistics).
+		Select("workouts.id as id", "workouts.date as date", "map_data.power_curve as power_curve").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	best := map[time.Duration]PowerCurveRecord{}
+
+	for _, r := range rows {
+		var curve []PowerCurvePoint
+		if err := json.Unmarshal([]byte(r.PowerCurve), &curve); err != nil {
+			continue
+		}
+
+		for _, p := range curve {
+			if current, ok := best[p.Duration]; !ok || p.Power > current.Power {
+				best[p.Duration] = PowerCurveRecord{Duration: p.Duration, Power: p.Power, Date: r.Date, ID: r.ID}
+			}
+		}
+	}
+
+	records := []PowerCurveRecord{}
+
+	for _, d := range PowerCurveDurations() {
+		if r, ok := best[d]; ok {
+			records = append(records, r)
+		}
+	}
+
+	return records, nil
+}
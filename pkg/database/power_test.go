@@ -0,0 +1,114 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func powerPoints(interval time.Duration, watts ...float64) *MapDataDetails {
+	d := &MapDataDetails{}
+
+	for i, w := range watts {
+		p := MapPoint{ExtraMetrics: ExtraMetrics{}}
+		if i > 0 {
+			p.Duration = interval
+		}
+
+		p.ExtraMetrics.Set("power", w)
+
+		d.Points = append(d.Points, p)
+	}
+
+	return d
+}
+
+func constantPower(n int, w float64) []float64 {
+	r := make([]float64, n)
+	for i := range r {
+		r[i] = w
+	}
+
+	return r
+}
+
+func TestMapData_UpdatePower(t *testing.T) {
+	m := &MapData{Details: powerPoints(time.Second, constantPower(20*60+1, 200)...)}
+	m.UpdatePower(250)
+
+	assert.True(t, m.HasPower())
+	assert.InDelta(t, 200, m.AveragePower, 0.01)
+	assert.InDelta(t, 200, m.NormalizedPower, 0.01)
+	assert.InDelta(t, 200, m.MaxPower, 0.01)
+	assert.InDelta(t, 0.8, m.IntensityFactor, 0.001)
+	assert.InDelta(t, 21.33, m.TrainingStressScore, 0.01)
+
+	require.Len(t, m.PowerCurve, 8)
+	assert.Equal(t, 20*time.Minute, m.PowerCurve[7].Duration)
+	assert.InDelta(t, 200, m.PowerCurve[7].Power, 0.01)
+
+	// Without FTP, there is no intensity factor
+	m.UpdatePower(0)
+	assert.InDelta(t, 200, m.NormalizedPower, 0.01)
+	assert.Zero(t, m.IntensityFactor)
+	assert.Zero(t, m.TrainingStressScore)
+}
+
+func TestMapData_UpdatePowerVariable(t *testing.T) {
+	// Alternating a minute at 300W and a minute at 100W, ending with a 10
+	// second sprint
+	watts := []float64{0}
+	for range 10 {
+		watts = append(watts, constantPower(60, 300)...)
+		watts = append(watts, constantPower(60, 100)...)
+	}
+
+	watts = append(watts, constantPower(10, 800)...)
+
+	m := &MapData{Details: powerPoints(time.Second, watts...)}
+	m.UpdatePower(250)
+
+	assert.InDelta(t, 800, m.MaxPower, 0.01)
+	assert.Greater(t, m.NormalizedPower, m.AveragePower)
+	assert.InDelta(t, 800, m.PowerCurve[0].Power, 0.01)
+	assert.InDelta(t, (10*800+5*100)/15.0, m.PowerCurve[1].Power, 0.01)
+}
+
+func TestMapData_UpdatePowerWithoutPower(t *testing.T) {
+	m := &MapData{Details: heartRatePoints(120, 130, 140)}
+	m.UpdatePower(250)
+
+	assert.False(t, m.HasPower())
+	assert.Zero(t, m.AveragePower)
+	assert.Empty(t, m.PowerCurve)
+}
+
+func TestUser_GetRecordsPower(t *testing.T) {
+	db := createMemoryDB(t)
+	u := defaultUser()
+	require.NoError(t, u.Create(db))
+
+	u, err := GetUserByID(db, int(u.ID))
+	require.NoError(t, err)
+
+	for i, w := range []float64{200, 250} {
+		d := time.Date(2024, 5, i+1, 8, 0, 0, 0, time.UTC)
+		data := &MapData{TotalDistance: 20000, Details: powerPoints(time.Second, constantPower(6*60, w)...)}
+		data.UpdatePower(0)
+
+		wo := &Workout{UserID: u.ID, Name: "ride", Type: WorkoutTypeCycling, Date: &d, Data: data}
+		require.NoError(t, wo.Create(db))
+	}
+
+	r, err := u.GetRecords(WorkoutTypeCycling)
+	require.NoError(t, err)
+
+	assert.InDelta(t, 250, r.MaxPower.Value, 0.01)
+	assert.InDelta(t, 250, r.NormalizedPower.Value, 0.01)
+	require.Len(t, r.PowerCurve, 6)
+	assert.Equal(t, 5*time.Minute, r.PowerCurve[5].Duration)
+	assert.InDelta(t, 250, r.PowerCurve[5].Power, 0.01)
+	assert.Equal(t, r.MaxPower.ID, r.PowerCurve[5].ID)
+}
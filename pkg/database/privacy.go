@@ -0,0 +1,241 @@
+package database
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"slices"
+
+	"github.com/tkrajina/gpxgo/gpx"
+	"gorm.io/gorm"
+)
+
+// shareTokenBytes is the number of random bytes of a share token; 160 bits,
+// encoded as 32 characters
+const shareTokenBytes = 20
+
+// shareTokenEncoding encodes share tokens, so they can be used in a URL
+var shareTokenEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+var ErrNoShareToken = errors.New("no share token given")
+
+// PrivacyZone is a circle in which no points of the user's workouts are shown
+// to people who follow a share link
+type PrivacyZone struct {
+	gorm.Model
+	UserID uint    `gorm:"not null;index"`       // The ID of the user who owns the zone
+	Name   string  `form:"name" json:"name"`     // The name of the zone, eg. "home"
+	Lat    float64 `form:"lat" json:"lat"`       // The latitude of the center of the zone
+	Lng    float64 `form:"lng" json:"lng"`       // The longitude of the center of the zone
+	Radius float64 `form:"radius" json:"radius"` // The radius of the zone, in meters
+
+	User *User `json:"-"` // The user who owns the zone
+}
+
+func (z *PrivacyZone) Contains(p *MapPoint) bool {
+	return gpx.HaversineDistance(z.Lat, z.Lng, p.Lat, p.Lng) <= z.Radius
+}
+
+func (z *PrivacyZone) Save(db *gorm.DB) error {
+	return db.Save(z).Error
+}
+
+func (z *PrivacyZone) Delete(db *gorm.DB) error {
+	return db.Unscoped().Delete(z).Error
+}
+
+func (u *User) GetPrivacyZones(db *gorm.DB) ([]PrivacyZone, error) {
+	var z []PrivacyZone
+
+	if err := db.Where(&PrivacyZone{UserID: u.ID}).Order("name").Find(&z).Error; err != nil {
+		return nil, err
+	}
+
+	return z, nil
+}
+
+func (u *User) GetPrivacyZone(db *gorm.DB, id int) (*PrivacyZone, error) {
+	var z PrivacyZone
+
+	if err := db.Where(&PrivacyZone{UserID: u.ID}).First(&z, id).Error; err != nil {
+		return nil, err
+	}
+
+	return &z, nil
+}
+
+// HasShareToken returns whether the workout can be seen through a share link
+func (w *Workout) HasShareToken() bool {
+	return w.ShareToken != nil && *w.ShareToken != ""
+}
+
+// GenerateShareToken creates a new share token, invalidating the previous one
+func (w *Workout) GenerateShareToken(db *gorm.DB) error {
+	b := make([]byte, shareTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return err
+	}
+
+	t := shareTokenEncoding.EncodeToString(b)
+
+	if err := db.Model(w).Update("share_token", t).Error; err != nil {
+		return err
+	}
+
+	w.ShareToken = &t
+
+	return nil
+}
+
+// RevokeShareToken removes the share token, so the share link no longer works
+func (w *Workout) RevokeShareToken(db *gorm.DB) error {
+	if err := db.Model(w).Update("share_token", nil).Error; err != nil {
+		return err
+	}
+
+	w.ShareToken = nil
+
+	return nil
+}
+
+func GetWorkoutByShareToken(db *gorm.DB, token string) (*Workout, error) {
+	if token == "" {
+		return nil, ErrNoShareToken
+	}
+
+	var w Workout
+
+	if err := db.
+		Preload("Data").Preload("Data.Details").Preload("Data.Laps").Preload("Data.Lengths").Preload("Data.Climbs").
+		Preload("User").Preload("User.Profile").Preload("User.PrivacyZones").
+		Where("share_token = ?", token).
+		First(&w).Error; err != nil {
+		return nil, err
+	}
+
+	return &w, nil
+}
+
+// HidePrivateLocations removes the points that should not be shown to others
+// from the workout's details: the start and end of the track, as far as
+// configured in the owner's profile, and every point in one of the owner's
+// privacy zones. Since the address is derived from the start of the track, it
+// is removed as well, and so are the climbs that are partly hidden. The result
+// should never be saved.
+func (w *Workout) HidePrivateLocations() {
+	if w.User == nil || w.Data == nil {
+		return
+	}
+
+	hideDistance := w.User.Profile.ShareHideDistance
+	if hideDistance <= 0 && len(w.User.PrivacyZones) == 0 {
+		return
+	}
+
+	w.Data.Address = nil
+	w.Data.AddressString = ""
+
+	total := w.Data.TotalDistance
+
+	if w.Data.Details != nil {
+		if points := w.Data.Details.Points; len(points) > 0 {
+			total = points[len(points)-1].TotalDistance
+		}
+
+		w.Data.Details.Points = hidePrivatePoints(w.Data.Details.Points, hideDistance, w.User.PrivacyZones)
+	}
+
+	if w.Data.Details == nil || len(w.Data.Details.Points) == 0 {
+		w.Data.Details = nil
+		w.Data.Center = MapCenter{}
+		w.Data.Climbs = nil
+
+		return
+	}
+
+	w.Data.Climbs = hidePrivateClimbs(w.Data.Climbs, hideDistance, total, w.User.PrivacyZones)
+
+	w.Data.Center = pointsCenter(w.Data.Details.Points)
+}
+
+func hidePrivatePoints(points []MapPoint, hideDistance float64, zones []PrivacyZone) []MapPoint {
+	if len(points) == 0 {
+		return points
+	}
+
+	total := points[len(points)-1].TotalDistance
+	result := []MapPoint{}
+
+	for i := range points {
+		p := &points[i]
+
+		if hideDistance > 0 &&
+			(p.TotalDistance < hideDistance || total-p.TotalDistance < hideDistance) {
+			continue
+		}
+
+		if inPrivacyZone(p, zones) {
+			continue
+		}
+
+		result = append(result, *p)
+	}
+
+	return result
+}
+
+// hidePrivateClimbs removes the climbs that start or end within the hidden
+// distance from the start or the end of the track, or that pass through a
+// privacy zone
+func hidePrivateClimbs(climbs []Climb, hideDistance, total float64, zones []PrivacyZone) []Climb {
+	result := []Climb{}
+
+	for _, c := range climbs {
+		if hideDistance > 0 &&
+			(c.StartDistance < hideDistance || total-(c.StartDistance+c.Length) < hideDistance) {
+			continue
+		}
+
+		hidden := slices.ContainsFunc(c.Points, func(p MapCenter) bool {
+			return inPrivacyZone(&MapPoint{Lat: p.Lat, Lng: p.Lng}, zones)
+		})
+		if hidden {
+			continue
+		}
+
+		result = append(result, c)
+	}
+
+	return result
+}
+
+func inPrivacyZone(p *MapPoint, zones []PrivacyZone) bool {
+	for i := range zones {
+		if zones[i].Contains(p) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// pointsCenter returns the center point (lat, lng) of map points
+func pointsCenter(points []MapPoint) MapCenter {
+	if len(points) == 0 {
+		return MapCenter{}
+	}
+
+	lat, lng := 0.0, 0.0
+
+	for _, pt := range points {
+		lat += pt.Lat
+		lng += pt.Lng
+	}
+
+	size := float64(len(points))
+
+	return MapCenter{
+		Lat: lat / size,
+		Lng: lng / size,
+	}
+}
@@ -0,0 +1,154 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestPrivacyZone_Contains(t *testing.T) {
+	z := &PrivacyZone{Lat: 51.0, Lng: 4.0, Radius: 100}
+
+	assert.True(t, z.Contains(&MapPoint{Lat: 51.0, Lng: 4.0}))
+	assert.True(t, z.Contains(&MapPoint{Lat: 51.0005, Lng: 4.0}))
+	assert.False(t, z.Contains(&MapPoint{Lat: 51.01, Lng: 4.0}))
+}
+
+func TestWorkout_ShareToken(t *testing.T) {
+	populateGPXFS()
+
+	db := createMemoryDB(t)
+
+	u := defaultUser()
+	require.NoError(t, u.Create(db))
+
+	w := defaultWorkout(t)
+	w.User = u
+	w.UserID = u.ID
+	require.NoError(t, w.Save(db))
+
+	assert.False(t, w.HasShareToken())
+
+	_, err := GetWorkoutByShareToken(db, "")
+	require.ErrorIs(t, err, ErrNoShareToken)
+
+	require.NoError(t, w.GenerateShareToken(db))
+	assert.True(t, w.HasShareToken())
+	assert.Len(t, *w.ShareToken, shareTokenEncoding.EncodedLen(shareTokenBytes))
+
+	token := *w.ShareToken
+
+	sw, err := GetWorkoutByShareToken(db, token)
+	require.NoError(t, err)
+	assert.Equal(t, w.ID, sw.ID)
+
+	require.NoError(t, w.GenerateShareToken(db))
+	assert.NotEqual(t, token, *w.ShareToken)
+
+	_, err = GetWorkoutByShareToken(db, token)
+	require.Error(t, err)
+
+	token = *w.ShareToken
+
+	require.NoError(t, w.RevokeShareToken(db))
+	assert.False(t, w.HasShareToken())
+
+	_, err = GetWorkoutByShareToken(db, token)
+	require.Error(t, err)
+}
+
+func TestWorkout_HidePrivateLocations(t *testing.T) {
+	populateGPXFS()
+
+	w := defaultWorkout(t)
+	w.User = defaultUser()
+
+	points := w.Data.Details.Points
+	require.NotEmpty(t, points)
+
+	total := points[len(points)-1].TotalDistance
+	center := w.Data.Center
+
+	// A climb near the start, and one halfway
+	climb := func(from, to int) Climb {
+		c := Climb{StartDistance: points[from].TotalDistance, Length: points[to].TotalDistance - points[from].TotalDistance}
+		for _, p := range points[from : to+1] {
+			c.Points = append(c.Points, MapCenter{Lat: p.Lat, Lng: p.Lng})
+		}
+
+		return c
+	}
+
+	half := len(points) / 2
+	w.Data.Climbs = []Climb{climb(0, 5), climb(half, half+5)}
+
+	w.HidePrivateLocations()
+	assert.Len(t, w.Data.Details.Points, len(points))
+	assert.Equal(t, center, w.Data.Center)
+	assert.Len(t, w.Data.Climbs, 2)
+
+	w.User.Profile.ShareHideDistance = total / 4
+	w.HidePrivateLocations()
+
+	require.Len(t, w.Data.Climbs, 1)
+	assert.Equal(t, points[half].TotalDistance, w.Data.Climbs[0].StartDistance)
+	assert.Less(t, len(w.Data.Details.Points), len(points))
+	assert.NotEmpty(t, w.Data.Details.Points)
+	assert.Nil(t, w.Data.Address)
+	assert.Empty(t, w.Data.AddressString)
+
+	for _, p := range w.Data.Details.Points {
+		assert.GreaterOrEqual(t, p.TotalDistance, total/4)
+		assert.GreaterOrEqual(t, total-p.TotalDistance, total/4)
+	}
+
+	// A climb through a privacy zone is hidden
+	top := w.Data.Climbs[0].Points[3]
+	w.User.Profile.ShareHideDistance = 0
+	w.User.PrivacyZones = []PrivacyZone{{Lat: top.Lat, Lng: top.Lng, Radius: 1}}
+	w.HidePrivateLocations()
+
+	assert.Empty(t, w.Data.Climbs)
+
+	first := w.Data.Details.Points[0]
+	w.User.PrivacyZones = []PrivacyZone{{Lat: first.Lat, Lng: first.Lng, Radius: 10}}
+	w.HidePrivateLocations()
+
+	for _, p := range w.Data.Details.Points {
+		assert.False(t, w.User.PrivacyZones[0].Contains(&p))
+	}
+
+	w.User.PrivacyZones = []PrivacyZone{{Lat: first.Lat, Lng: first.Lng, Radius: 100000}}
+	w.HidePrivateLocations()
+
+	assert.Nil(t, w.Data.Details)
+	assert.Nil(t, w.Data.Climbs)
+	assert.False(t, w.HasTracks())
+}
+
+func TestUser_PrivacyZones(t *testing.T) {
+	db := createMemoryDB(t)
+
+	u := defaultUser()
+	require.NoError(t, u.Create(db))
+
+	z := &PrivacyZone{UserID: u.ID, Name: "home", Lat: 51, Lng: 4, Radius: 200}
+	require.NoError(t, z.Save(db))
+
+	zones, err := u.GetPrivacyZones(db)
+	require.NoError(t, err)
+	assert.Len(t, zones, 1)
+
+	other := &User{Model: gorm.Model{ID: u.ID + 1}}
+
+	_, err = other.GetPrivacyZone(db, int(z.ID))
+	require.Error(t, err)
+
+	require.NoError(t, z.Delete(db))
+
+	zones, err = u.GetPrivacyZones(db)
+	require.NoError(t, err)
+	assert.Empty(t, zones)
+}
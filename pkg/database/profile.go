@@ -3,6 +3,7 @@ package database
 import (
 	"fmt"
 	"os"
+	"slices"
 
 	"github.com/jovandeginste/workout-tracker/pkg/templatehelpers"
 	"gorm.io/gorm"
@@ -10,16 +11,22 @@ import (
 
 type Profile struct {
 	gorm.Model
-	UserID              uint        // The ID of the user who owns this profile
-	APIActive           bool        `form:"api_active"`            // Whether the user's API key is active
-	Language            string      `form:"language"`              // The user's preferred language
-	TotalsShow          WorkoutType `form:"totals_show"`           // What workout type of totals to show
-	Timezone            string      `form:"timezone"`              // The user's preferred timezone
-	AutoImportDirectory string      `form:"auto_import_directory"` // The user's preferred directory for auto-import
-	SocialsDisabled     bool        `form:"socials_disabled"`      // Whether social sharing buttons are disabled when viewing a workout
-	PreferFullDate      bool        `form:"prefer_full_date"`      // Whether to show full dates in the workout details
-
-	PreferredUnits UserPreferredUnits `gorm:"serializer:json"` // The user's preferred units
+	UserID              uint              // The ID of the user who owns this profile
+	APIActive           bool              `form:"api_active"`            // Whether the user's API key is active
+	Language            string            `form:"language"`              // The user's preferred language
+	TotalsShow          WorkoutType       `form:"totals_show"`           // What workout type of totals to show
+	Timezone            string            `form:"timezone"`              // The user's preferred timezone
+	AutoImportDirectory string            `form:"auto_import_directory"` // The user's preferred directory for auto-import
+	SocialsDisabled     bool              `form:"socials_disabled"`      // Whether social sharing buttons are disabled when viewing a workout
+	PreferFullDate      bool              `form:"prefer_full_date"`      // Whether to show full dates in the workout details
+	DefaultVisibility   WorkoutVisibility `form:"default_visibility"`    // The default visibility of new workouts
+	ShareHideDistance   float64           `form:"share_hide_distance"`   // The distance (in meters) at the start and end of shared workouts that is hidden
+	FTP                 int               `form:"ftp"`                   // The user's functional threshold power, in watts
+
+	PreferredUnits UserPreferredUnits    `gorm:"serializer:json"` // The user's preferred units
+	HeartRate      HeartRateSettings     `gorm:"serializer:json"` // The user's heart rate settings
+	TrackCleaning  TrackCleaningSettings `gorm:"serializer:json"` // The user's GPS track cleaning settings
+	Climbs         ClimbSettings         `gorm:"serializer:json"` // The user's climb categorisation settings
 
 	User *User `gorm:"foreignKey:UserID" json:"-"` // The user who owns this profile
 }
@@ -31,10 +38,29 @@ type UserPreferredUnits struct {
 	WeightRaw    string `form:"weight" json:"weight"`       // The user's preferred weight unit
 }
 
+// IsValid returns whether all units are known; empty units fall back to the
+// default unit
+func (u UserPreferredUnits) IsValid() bool {
+	return slices.Contains([]string{"", "kph", "mph"}, u.SpeedRaw) &&
+		slices.Contains([]string{"", "km", "mi"}, u.DistanceRaw) &&
+		slices.Contains([]string{"", "m", "ft"}, u.ElevationRaw) &&
+		slices.Contains([]string{"", "kg", "lbs"}, u.WeightRaw)
+}
+
 func (u UserPreferredUnits) Tempo() string {
 	return "min/" + u.Distance()
 }
 
+// SwimTempo is the unit of the swim tempo: per 100 yards for miles, else per
+// 100 meters
+func (u UserPreferredUnits) SwimTempo() string {
+	if u.Distance() == "mi" {
+		return "min/100yd"
+	}
+
+	return "min/100m"
+}
+
 func (u UserPreferredUnits) HeartRate() string {
 	return "bpm"
 }
@@ -43,6 +69,10 @@ func (u UserPreferredUnits) Cadence() string {
 	return "spm"
 }
 
+func (u UserPreferredUnits) Power() string {
+	return "W"
+}
+
 func (u UserPreferredUnits) Elevation() string {
 	switch u.ElevationRaw {
 	case "ft":
@@ -61,6 +91,16 @@ func (u UserPreferredUnits) Weight() string {
 	}
 }
 
+// WeightToDatabase converts a weight in the preferred unit to kilograms
+func (u UserPreferredUnits) WeightToDatabase(w float64) float64 {
+	switch u.Weight() {
+	case "lbs":
+		return w / templatehelpers.PoundsPerKG
+	default:
+		return w
+	}
+}
+
 func (u UserPreferredUnits) Distance() string {
 	switch u.DistanceRaw {
 	case "mi":
@@ -92,6 +132,17 @@ func (p *Profile) ResetBools() {
 	p.PreferFullDate = false
 	p.APIActive = false
 	p.SocialsDisabled = false
+	p.TrackCleaning.Enabled = false
+}
+
+// AffectsWorkoutData returns whether the profile changed settings that the
+// data of the workouts is calculated with, compared to the old profile: time
+// in zones, training load, intensity factor and training stress score depend
+// on the heart rate settings and FTP, the totals on the track cleaning, and
+// the climb categories on the climb settings
+func (p *Profile) AffectsWorkoutData(old Profile) bool {
+	return p.HeartRate != old.HeartRate || p.FTP != old.FTP ||
+		p.TrackCleaning != old.TrackCleaning || p.Climbs != old.Climbs
 }
 
 func (p *Profile) Save(db *gorm.DB) error {
@@ -0,0 +1,381 @@
+package database
+
+import (
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/tkrajina/gpxgo/gpx"
+	"gorm.io/gorm"
+)
+
+const (
+	SegmentGateRadius     = 30.0  // How close (in meters) a track must pass the start and end of a segment
+	SegmentTrackTolerance = 50.0  // How far (in meters) a track may stray from the segment
+	SegmentMinDistance    = 100.0 // The minimum length of a segment, in meters
+
+	// segmentSamples is the number of points of a segment that are checked
+	// against a track
+	segmentSamples = 50
+	// segmentMinRatio and segmentMaxRatio limit the distance of an effort,
+	// relative to the length of the segment
+	segmentMinRatio = 0.8
+	segmentMaxRatio = 1.25
+)
+
+var (
+	ErrSegmentTooShort = errors.New("segment is too short")
+	ErrSegmentNoTrack  = errors.New("workout has no track")
+	ErrSegmentPrivate  = errors.New("segments can not be created from private workouts")
+	ErrSegmentInZone   = errors.New("segment passes through a privacy zone")
+)
+
+// Segment is a stretch of road or trail, defined from the track of a workout;
+// workouts of the same type that pass through it are matched as efforts
+type Segment struct {
+	gorm.Model
+	UserID   uint            `gorm:"not null;index"`  // The ID of the user who created the segment
+	Name     string          `form:"name"`            // The name of the segment
+	Notes    string          `form:"notes"`           // Notes about the segment
+	Type     WorkoutType     `gorm:"index"`           // The workout type that is matched against the segment
+	Points   []MapCenter     `gorm:"serializer:json"` // The polyline of the segment; the first and last points are the start and end gates
+	Center   MapCenter       `gorm:"serializer:json"` // The center of the segment
+	Distance float64         // The length of the segment, in meters
+	Efforts  []SegmentEffort `json:"-"` // The efforts of all users on this segment
+
+	User *User `json:"-"` // The user who created the segment
+}
+
+// SegmentEffort is a single pass of a workout through a segment
+type SegmentEffort struct {
+	gorm.Model
+	SegmentID uint          `gorm:"not null;index"` // The ID of the segment
+	WorkoutID uint          `gorm:"not null;index"` // The ID of the workout
+	UserID    uint          `gorm:"not null;index"` // The ID of the user who owns the workout
+	Start     time.Time     // The time the effort started
+	Duration  time.Duration // The elapsed time of the effort
+	Distance  float64       // The distance of the effort, in meters
+
+	Segment *Segment `json:"-"` // The segment
+	Workout *Workout `json:"-"` // The workout
+	User    *User    `json:"-"` // The user who owns the workout
+}
+
+func (e *SegmentEffort) AverageSpeed() float64 {
+	if e.Duration <= 0 {
+		return 0
+	}
+
+	return e.Distance / e.Duration.Seconds()
+}
+
+// NewSegment creates a segment from the part of the workout's track between
+// the two distances (in meters) from the start; since segments are shown to
+// all users, the workout must be shared, and the segment may not pass through
+// one of the owner's privacy zones
+func NewSegment(w *Workout, zones []PrivacyZone, name string, from, to float64) (*Segment, error) {
+	if w.Data == nil || w.Data.Details == nil || !w.Type.IsLocation() {
+		return nil, ErrSegmentNoTrack
+	}
+
+	if w.Visibility.OrDefault() == WorkoutVisibilityPrivate {
+		return nil, ErrSegmentPrivate
+	}
+
+	s := &Segment{
+		UserID: w.UserID,
+		Name:   name,
+		Type:   w.Type,
+	}
+
+	var first, last *MapPoint
+
+	for i := range w.Data.Details.Points {
+		p := &w.Data.Details.Points[i]
+		if p.TotalDistance < from || p.TotalDistance > to {
+			continue
+		}
+
+		for _, z := range zones {
+			if z.Contains(p) {
+				return nil, ErrSegmentInZone
+			}
+		}
+
+		if first == nil {
+			first = p
+		}
+
+		last = p
+		s.Points = append(s.Points, MapCenter{Lat: p.Lat, Lng: p.Lng})
+	}
+
+	if len(s.Points) < 2 || last.TotalDistance-first.TotalDistance < SegmentMinDistance {
+		return nil, ErrSegmentTooShort
+	}
+
+	s.Distance = last.TotalDistance - first.TotalDistance
+	s.Center = s.Points[len(s.Points)/2]
+
+	if s.Name == "" {
+		s.Name = w.Name
+	}
+
+	return s, nil
+}
+
+func (s *Segment) Start() MapCenter {
+	return s.Points[0]
+}
+
+func (s *Segment) End() MapCenter {
+	return s.Points[len(s.Points)-1]
+}
+
+func (s *Segment) Save(db *gorm.DB) error {
+	if len(s.Points) < 2 {
+		return ErrSegmentTooShort
+	}
+
+	return db.Save(s).Error
+}
+
+func (s *Segment) Delete(db *gorm.DB) error {
+	return db.Unscoped().Select("Efforts").Delete(s).Error
+}
+
+func GetSegments(db *gorm.DB) ([]*Segment, error) {
+	var s []*Segment
+
+	if err := db.Preload("User").Order("name").Find(&s).Error; err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func GetSegment(db *gorm.DB, id int) (*Segment, error) {
+	var s Segment
+
+	if err := db.Preload("User").First(&s, id).Error; err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+func (u *User) GetSegment(db *gorm.DB, id int) (*Segment, error) {
+	var s Segment
+
+	if err := db.Where(&Segment{UserID: u.ID}).First(&s, id).Error; err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+func distanceTo(p *MapPoint, c MapCenter) float64 {
+	return gpx.HaversineDistance(p.Lat, p.Lng, c.Lat, c.Lng)
+}
+
+// MatchPoints returns all efforts on the segment in the track; the points must
+// be in chronological order
+func (s *Segment) MatchPoints(points []MapPoint) []SegmentEffort {
+	if len(s.Points) < 2 {
+		return nil
+	}
+
+	var efforts []SegmentEffort
+
+	for i := 0; i < len(points); i++ {
+		if distanceTo(&points[i], s.Start()) > SegmentGateRadius {
+			continue
+		}
+
+		// Start at the point closest to the start gate
+		for i+1 < len(points) && distanceTo(&points[i+1], s.Start()) <= distanceTo(&points[i], s.Start()) {
+			i++
+		}
+
+		j := s.findEnd(points, i)
+		if j < 0 || !s.followedBy(points[i:j+1]) {
+			continue
+		}
+
+		efforts = append(efforts, SegmentEffort{
+			SegmentID: s.ID,
+			Start:     points[i].Time,
+			Duration:  points[j].TotalDuration - points[i].TotalDuration,
+			Distance:  points[j].TotalDistance - points[i].TotalDistance,
+		})
+
+		i = j
+	}
+
+	return efforts
+}
+
+// findEnd returns the index of the point closest to the end gate, after the
+// start point, or -1 if the track does not reach the end gate within a
+// reasonable distance
+func (s *Segment) findEnd(points []MapPoint, start int) int {
+	for j := start + 1; j < len(points); j++ {
+		covered := points[j].TotalDistance - points[start].TotalDistance
+		if covered > segmentMaxRatio*s.Distance {
+			return -1
+		}
+
+		if covered < segmentMinRatio*s.Distance || distanceTo(&points[j], s.End()) > SegmentGateRadius {
+			continue
+		}
+
+		for j+1 < len(points) && distanceTo(&points[j+1], s.End()) <= distanceTo(&points[j], s.End()) {
+			j++
+		}
+
+		return j
+	}
+
+	return -1
+}
+
+// followedBy returns whether the track passes close to all (sampled) points
+// of the segment
+func (s *Segment) followedBy(track []MapPoint) bool {
+	step := max(1, len(s.Points)/segmentSamples)
+
+	for i := 0; i < len(s.Points); i += step {
+		if !slices.ContainsFunc(track, func(p MapPoint) bool {
+			return distanceTo(&p, s.Points[i]) <= SegmentTrackTolerance
+		}) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// MatchWorkout returns the efforts on the segment in the workout
+func (s *Segment) MatchWorkout(w *Workout) []SegmentEffort {
+	if w.Type != s.Type || w.Data == nil || w.Data.Details == nil {
+		return nil
+	}
+
+	efforts := s.MatchPoints(w.Data.Details.Points)
+	for i := range efforts {
+		efforts[i].WorkoutID = w.ID
+		efforts[i].UserID = w.UserID
+	}
+
+	return efforts
+}
+
+// MatchWorkouts replaces the efforts of the segment by matching it against
+// all workouts of the same type
+func (s *Segment) MatchWorkouts(db *gorm.DB) error {
+	if s.ID == 0 {
+		return ErrInvalidData
+	}
+
+	if err := db.Unscoped().Where(&SegmentEffort{SegmentID: s.ID}).Delete(&SegmentEffort{}).Error; err != nil {
+		return err
+	}
+
+	var workouts []*Workout
+
+	return db.
+		Preload("Data.Details").
+		Where(&Workout{Type: s.Type}).
+		FindInBatches(&workouts, 50, func(tx *gorm.DB, _ int) error {
+			for _, w := range workouts {
+				efforts := s.MatchWorkout(w)
+				if len(efforts) == 0 {
+					continue
+				}
+
+				if err := db.Create(&efforts).Error; err != nil {
+					return err
+				}
+			}
+
+			return nil
+		}).Error
+}
+
+// MatchSegments replaces the segment efforts of the workout by matching it
+// against all segments of the same type
+func (w *Workout) MatchSegments(db *gorm.DB) error {
+	if w.ID == 0 {
+		return ErrInvalidData
+	}
+
+	if err := db.Unscoped().Where(&SegmentEffort{WorkoutID: w.ID}).Delete(&SegmentEffort{}).Error; err != nil {
+		return err
+	}
+
+	if !w.Type.IsLocation() || w.Data == nil || w.Data.Details == nil {
+		return nil
+	}
+
+	var segments []*Segment
+	if err := db.Where(&Segment{Type: w.Type}).Find(&segments).Error; err != nil {
+		return err
+	}
+
+	for _, s := range segments {
+		efforts := s.MatchWorkout(w)
+		if len(efforts) == 0 {
+			continue
+		}
+
+		if err := db.Create(&efforts).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetEffortsFor returns the user's efforts on the segment, most recent first
+func (s *Segment) GetEffortsFor(db *gorm.DB, u *User) ([]SegmentEffort, error) {
+	var e []SegmentEffort
+
+	if err := db.Preload("Workout").
+		Where(&SegmentEffort{SegmentID: s.ID, UserID: u.ID}).
+		Order("start DESC").
+		Find(&e).Error; err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+// GetLeaderboard returns the best effort of every user on the segment, fastest
+// first; only efforts of workouts the viewer can see are ranked
+func (s *Segment) GetLeaderboard(db *gorm.DB, viewer *User) ([]SegmentEffort, error) {
+	var all []SegmentEffort
+
+	if err := db.Preload("User").Preload("Workout").
+		Joins("join workouts on workouts.id = segment_efforts.workout_id").
+		Where("workouts.deleted_at IS NULL").
+		Scopes(WorkoutsVisibleTo(viewer)).
+		Where(&SegmentEffort{SegmentID: s.ID}).
+		Order("segment_efforts.duration ASC").
+		Find(&all).Error; err != nil {
+		return nil, err
+	}
+
+	seen := map[uint]bool{}
+	board := []SegmentEffort{}
+
+	for _, e := range all {
+		if seen[e.UserID] {
+			continue
+		}
+
+		seen[e.UserID] = true
+		board = append(board, e)
+	}
+
+	return board, nil
+}
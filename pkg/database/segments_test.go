@@ -0,0 +1,217 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jovandeginste/workout-tracker/pkg/converters"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tkrajina/gpxgo/gpx"
+)
+
+// segmentTrack returns a point every 10 seconds at the given coordinates,
+// with the distances calculated between them
+func segmentTrack(coords ...[2]float64) []MapPoint {
+	start := time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC)
+	points := []MapPoint{}
+
+	for i, c := range coords {
+		p := MapPoint{Lat: c[0], Lng: c[1], Time: start.Add(time.Duration(i) * 10 * time.Second)}
+
+		if i > 0 {
+			prev := points[i-1]
+			p.Distance = gpx.HaversineDistance(prev.Lat, prev.Lng, p.Lat, p.Lng)
+			p.Duration = 10 * time.Second
+			p.TotalDistance = prev.TotalDistance + p.Distance
+			p.TotalDuration = prev.TotalDuration + p.Duration
+		}
+
+		points = append(points, p)
+	}
+
+	return points
+}
+
+// northwards returns n coordinates, going north from (lat, lng) in steps of
+// about 33 meters
+func northwards(lat, lng float64, n int) [][2]float64 {
+	c := make([][2]float64, n)
+	for i := range c {
+		c[i] = [2]float64{lat + float64(i)*0.0003, lng}
+	}
+
+	return c
+}
+
+func segmentWorkout(t WorkoutType, coords ...[2]float64) *Workout {
+	return &Workout{
+		Name: "segment",
+		Type: t,
+		Data: &MapData{Details: &MapDataDetails{Points: segmentTrack(coords...)}},
+	}
+}
+
+func TestNewSegment(t *testing.T) {
+	w := segmentWorkout(WorkoutTypeRunning, northwards(51, 4, 30)...)
+
+	s, err := NewSegment(w, nil, "", 300, 700)
+	require.NoError(t, err)
+
+	assert.Equal(t, "segment", s.Name)
+	assert.Equal(t, WorkoutTypeRunning, s.Type)
+	assert.Len(t, s.Points, 12)
+	assert.InDelta(t, 367, s.Distance, 1)
+
+	_, err = NewSegment(w, nil, "short", 300, 350)
+	require.ErrorIs(t, err, ErrSegmentTooShort)
+
+	_, err = NewSegment(&Workout{Type: WorkoutTypeRunning}, nil, "none", 0, 1000)
+	require.ErrorIs(t, err, ErrSegmentNoTrack)
+
+	zones := []PrivacyZone{{Lat: 51.0045, Lng: 4, Radius: 50}}
+
+	_, err = NewSegment(w, zones, "home", 300, 700)
+	require.ErrorIs(t, err, ErrSegmentInZone)
+
+	_, err = NewSegment(w, zones, "away", 0, 400)
+	require.NoError(t, err)
+
+	w.Visibility = WorkoutVisibilityPrivate
+
+	_, err = NewSegment(w, nil, "private", 300, 700)
+	require.ErrorIs(t, err, ErrSegmentPrivate)
+}
+
+func TestSegment_MatchPoints(t *testing.T) {
+	s, err := NewSegment(segmentWorkout(WorkoutTypeRunning, northwards(51, 4, 30)...), nil, "hill", 300, 700)
+	require.NoError(t, err)
+
+	// Twice up the same road
+	twice := append(northwards(51, 4, 30), northwards(51, 4, 30)...)
+	efforts := s.MatchPoints(segmentTrack(twice...))
+	require.Len(t, efforts, 2)
+	assert.Equal(t, 110*time.Second, efforts[0].Duration)
+	assert.InDelta(t, s.Distance, efforts[0].Distance, 1)
+	assert.True(t, efforts[1].Start.After(efforts[0].Start))
+
+	// A parallel road, more than the tolerance away
+	assert.Empty(t, s.MatchPoints(segmentTrack(northwards(51, 4.001, 30)...)))
+
+	// A detour between the gates
+	detour := northwards(51, 4, 30)
+	for i := 14; i < 18; i++ {
+		detour[i][1] += 0.002
+	}
+
+	assert.Empty(t, s.MatchPoints(segmentTrack(detour...)))
+
+	// The same road, but in the other direction
+	reversed := northwards(51, 4, 30)
+	for i, j := 0, len(reversed)-1; i < j; i, j = i+1, j-1 {
+		reversed[i], reversed[j] = reversed[j], reversed[i]
+	}
+
+	assert.Empty(t, s.MatchPoints(segmentTrack(reversed...)))
+}
+
+func TestSegment_MatchWorkoutType(t *testing.T) {
+	s, err := NewSegment(segmentWorkout(WorkoutTypeRunning, northwards(51, 4, 30)...), nil, "hill", 300, 700)
+	require.NoError(t, err)
+
+	assert.Len(t, s.MatchWorkout(segmentWorkout(WorkoutTypeRunning, northwards(51, 4, 30)...)), 1)
+	assert.Empty(t, s.MatchWorkout(segmentWorkout(WorkoutTypeCycling, northwards(51, 4, 30)...)))
+}
+
+func segmentFIT(t *testing.T, start time.Time, step time.Duration) []byte {
+	t.Helper()
+
+	a := &converters.Activity{Type: "running", Start: start}
+
+	for i, c := range northwards(51, 4, 30) {
+		a.Points = append(a.Points, converters.ActivityPoint{
+			Time: start.Add(time.Duration(i) * step),
+			Lat:  c[0],
+			Lng:  c[1],
+		})
+	}
+
+	content, err := converters.ExportFIT(a)
+	require.NoError(t, err)
+
+	return content
+}
+
+func TestSegment_Leaderboard(t *testing.T) {
+	db := createMemoryDB(t)
+
+	u1 := defaultUser()
+	require.NoError(t, u1.Create(db))
+
+	u2 := defaultUser()
+	u2.Username = "other-user"
+	u2.APIKey = "other-key"
+	require.NoError(t, u2.Create(db))
+
+	u1, err := GetUserByID(db, int(u1.ID))
+	require.NoError(t, err)
+
+	u2, err = GetUserByID(db, int(u2.ID))
+	require.NoError(t, err)
+
+	first, err := u1.AddWorkout(db, WorkoutTypeRunning, "", "first.fit", segmentFIT(t, time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC), 10*time.Second))
+	require.NoError(t, err)
+
+	s, err := NewSegment(first, nil, "hill", 300, 700)
+	require.NoError(t, err)
+	require.NoError(t, s.Save(db))
+	require.NoError(t, s.MatchWorkouts(db))
+
+	// New workouts are matched against existing segments
+	_, err = u1.AddWorkout(db, WorkoutTypeRunning, "", "second.fit", segmentFIT(t, time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC), 8*time.Second))
+	require.NoError(t, err)
+
+	third, err := u2.AddWorkout(db, WorkoutTypeRunning, "", "third.fit", segmentFIT(t, time.Date(2024, 1, 3, 10, 0, 0, 0, time.UTC), 9*time.Second))
+	require.NoError(t, err)
+
+	efforts, err := s.GetEffortsFor(db, u1)
+	require.NoError(t, err)
+	require.Len(t, efforts, 2)
+	assert.Equal(t, 2, efforts[0].Start.Day())
+
+	board, err := s.GetLeaderboard(db, u1)
+	require.NoError(t, err)
+	require.Len(t, board, 2)
+	assert.Equal(t, u1.ID, board[0].UserID)
+	assert.Equal(t, 88*time.Second, board[0].Duration)
+	assert.Equal(t, u2.ID, board[1].UserID)
+
+	// Efforts of private workouts are only ranked for their owner
+	require.NoError(t, db.Model(third).Update("visibility", WorkoutVisibilityPrivate).Error)
+
+	board, err = s.GetLeaderboard(db, u1)
+	require.NoError(t, err)
+	require.Len(t, board, 1)
+	assert.Equal(t, u1.ID, board[0].UserID)
+
+	board, err = s.GetLeaderboard(db, u2)
+	require.NoError(t, err)
+	assert.Len(t, board, 2)
+
+	// Refreshing the segment does not duplicate efforts
+	require.NoError(t, s.MatchWorkouts(db))
+
+	var count int64
+	require.NoError(t, db.Model(&SegmentEffort{}).Count(&count).Error)
+	assert.Equal(t, int64(3), count)
+
+	// Deleting a workout removes its efforts
+	require.NoError(t, first.Delete(db))
+	require.NoError(t, db.Model(&SegmentEffort{}).Count(&count).Error)
+	assert.Equal(t, int64(2), count)
+
+	// Deleting the segment removes all efforts
+	require.NoError(t, s.Delete(db))
+	require.NoError(t, db.Model(&SegmentEffort{}).Count(&count).Error)
+	assert.Zero(t, count)
+}
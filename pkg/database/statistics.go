@@ -3,6 +3,8 @@ package database
 import (
 	"fmt"
 	"time"
+
+	"gorm.io/gorm"
 )
 
 const postgresDialect = "postgres"
@@ -76,16 +78,10 @@ func (u *User) GetStatisticsFor(since, per string) (*Statistics, error) {
 	})
 }
 
-func (u *User) GetStatistics(statConfig StatConfig) (*Statistics, error) {
-	sqlDialect := u.db.Dialector.Name()
-
-	r := &Statistics{
-		UserID:       u.ID,
-		BucketFormat: statConfig.GetBucketString(sqlDialect),
-		Buckets:      map[WorkoutType]map[string]Bucket{},
-	}
-
-	rows, err := u.db.
+// aggregateWorkouts returns a query that sums up the user's workouts into a
+// Bucket per workout type; the bucket expression names the bucket
+func (u *User) aggregateWorkouts(bucket string) *gorm.DB {
+	return u.db.
 		Table("workouts").
 		Select(
 			"count(*) as workouts",
@@ -93,13 +89,29 @@ func (u *User) GetStatistics(statConfig StatConfig) (*Statistics, error) {
 			"sum(total_duration) as duration",
 			"sum(total_distance) as distance",
 			"sum(total_up) as up",
+			"sum(total_repetitions) as repetitions",
 			"max(max_speed) as max_speed",
+			"sum(training_load) as training_load",
+			"sum(calories) as calories",
 			fmt.Sprintf("avg(total_distance / (total_duration / %d)) as average_speed", time.Second),
 			fmt.Sprintf("avg(total_distance / ((total_duration - pause_duration) / %d)) as average_speed_no_pause", time.Second),
-			statConfig.GetBucketFormatExpression(sqlDialect),
+			bucket,
 		).
 		Joins("join map_data on workouts.id = map_data.workout_id").
 		Where("user_id = ?", u.ID).
+		Scopes(countedInStatistics)
+}
+
+func (u *User) GetStatistics(statConfig StatConfig) (*Statistics, error) {
+	sqlDialect := u.db.Dialector.Name()
+
+	r := &Statistics{
+		UserID:       u.ID,
+		BucketFormat: statConfig.GetBucketString(sqlDialect),
+		Buckets:      map[WorkoutType]map[string]Bucket{},
+	}
+
+	rows, err := u.aggregateWorkouts(statConfig.GetBucketFormatExpression(sqlDialect)).
 		Where(statConfig.GetDateLimitExpression(sqlDialect), "-"+statConfig.GetSince()).
 		Group("bucket, workout_type").Rows()
 	if err != nil {
@@ -122,6 +134,17 @@ func (u *User) GetStatistics(statConfig StatConfig) (*Statistics, error) {
 		r.Buckets[result.WorkoutType][result.Bucket] = result
 	}
 
+	if r.TrainingLoad, err = u.GetTrainingLoad(statConfig.SinceTime(time.Now())); err != nil {
+		return nil, err
+	}
+
+	measurements, err := u.GetMeasurementsSince(u.db, statConfig.SinceTime(time.Now()))
+	if err != nil {
+		return nil, err
+	}
+
+	r.Measurements = NewMeasurementTrends(measurements)
+
 	return r, nil
 }
 
@@ -179,6 +202,7 @@ func (u *User) GetTotals(t WorkoutType) (*Bucket, error) {
 		Joins("join map_data on workouts.id = map_data.workout_id").
 		Where("user_id = ?", u.ID).
 		Where("type = ?", t).
+		Scopes(countedInStatistics).
 		Scan(r).Error
 	if err != nil {
 		return nil, err
@@ -217,6 +241,9 @@ func (u *User) GetRecords(t WorkoutType) (*WorkoutRecord, error) {
 		&r.TotalUp:             "max(total_up)",
 		&r.AverageSpeed:        fmt.Sprintf("max(total_distance / (total_duration / %d))", time.Second),
 		&r.AverageSpeedNoPause: fmt.Sprintf("max(total_distance / ((total_duration - pause_duration) / %d))", time.Second),
+		&r.AveragePower:        "max(average_power)",
+		&r.NormalizedPower:     "max(normalized_power)",
+		&r.MaxPower:            "max(max_power)",
 	}
 
 	for k, v := range mapping {
@@ -225,6 +252,7 @@ func (u *User) GetRecords(t WorkoutType) (*WorkoutRecord, error) {
 			Joins("join map_data on workouts.id = map_data.workout_id").
 			Where("user_id = ?", u.ID).
 			Where("type = ?", t).
+			Scopes(countedInStatistics).
 			Select("workouts.id as id", v+" as value", "workouts.date as date").
 			Order(v + " DESC").
 			Group("workouts.id").
@@ -240,6 +268,7 @@ func (u *User) GetRecords(t WorkoutType) (*WorkoutRecord, error) {
 		Joins("join map_data on workouts.id = map_data.workout_id").
 		Where("user_id = ?", u.ID).
 		Where("type = ?", t).
+		Scopes(countedInStatistics).
 		Select("workouts.id as id", "max(total_duration) as value", "workouts.date as date").
 		Order("max(total_duration) DESC").
 		Group("workouts.id").
@@ -249,6 +278,14 @@ func (u *User) GetRecords(t WorkoutType) (*WorkoutRecord, error) {
 		return nil, err
 	}
 
+	if r.BestEfforts, r.BestEffortsPerYear, err = u.getBestEfforts(t); err != nil {
+		return nil, err
+	}
+
+	if r.PowerCurve, err = u.getPowerCurve(t); err != nil {
+		return nil, err
+	}
+
 	r.Active = r.Distance.Value > 0
 
 	return r, nil
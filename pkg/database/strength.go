@@ -0,0 +1,404 @@
+package database
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidExercise     = errors.New("invalid exercise")
+	ErrExerciseExists      = errors.New("exercise already exists")
+	ErrExerciseInUse       = errors.New("exercise is still used by workouts")
+	ErrNoRepetitionWorkout = errors.New("workout type has no repetitions")
+)
+
+// Exercise is an exercise in the user's catalog, eg. "Bench press"
+type Exercise struct {
+	gorm.Model
+	UserID uint   `gorm:"not null;index" json:"-"`          // The ID of the user who owns the exercise
+	Name   string `gorm:"not null" form:"name" json:"name"` // The name of the exercise
+	Notes  string `form:"notes" json:"notes"`               // Notes about the exercise, eg. the setup
+	User   *User  `json:"-"`                                // The user who owns the exercise
+}
+
+// WorkoutExercise is an exercise performed during a workout, with its sets
+type WorkoutExercise struct {
+	gorm.Model
+	WorkoutID  uint          `gorm:"not null;index" json:"-"` // The ID of the workout
+	ExerciseID uint          `gorm:"not null;index"`          // The ID of the exercise
+	Position   int           // The position of the exercise in the workout, starting at 1
+	Exercise   *Exercise     `json:",omitempty"` // The exercise
+	Sets       []ExerciseSet `json:",omitempty"` // The sets, in order
+	Workout    *Workout      `json:"-"`          // The workout
+}
+
+// ExerciseSet is a single set of an exercise
+type ExerciseSet struct {
+	gorm.Model
+	WorkoutExerciseID uint             `gorm:"not null;index" json:"-"`        // The ID of the exercise in the workout
+	Number            int              `json:"number"`                         // The number of the set, starting at 1
+	Repetitions       int              `form:"repetitions" json:"repetitions"` // The number of repetitions
+	Weight            float64          `form:"weight" json:"weight"`           // The weight, in the user's preferred unit; 0 for body weight exercises
+	RPE               float64          `form:"rpe" json:"rpe,omitempty"`       // The rate of perceived exertion (1-10); 0 if not given
+	Rest              time.Duration    `json:"rest,omitempty"`                 // The rest after the set
+	WorkoutExercise   *WorkoutExercise `json:"-"`                              // The exercise in the workout
+}
+
+// Volume returns the weight moved in the set: repetitions x weight
+func (s *ExerciseSet) Volume() float64 {
+	return float64(s.Repetitions) * s.Weight
+}
+
+// EstimatedOneRepMax returns the estimated one-rep max for the set, using
+// the Epley formula
+func (s *ExerciseSet) EstimatedOneRepMax() float64 {
+	switch {
+	case s.Repetitions <= 0:
+		return 0
+	case s.Repetitions == 1:
+		return s.Weight
+	default:
+		return s.Weight * (1 + float64(s.Repetitions)/30)
+	}
+}
+
+func (s *ExerciseSet) Validate() error {
+	if s.Repetitions <= 0 || s.Weight < 0 || s.RPE < 0 || s.RPE > 10 || s.Rest < 0 {
+		return ErrInvalidExercise
+	}
+
+	return nil
+}
+
+func (e *WorkoutExercise) Repetitions() int {
+	r := 0
+	for _, s := range e.Sets {
+		r += s.Repetitions
+	}
+
+	return r
+}
+
+func (e *WorkoutExercise) Volume() float64 {
+	v := 0.0
+	for _, s := range e.Sets {
+		v += s.Volume()
+	}
+
+	return v
+}
+
+func (e *WorkoutExercise) MaxWeight() float64 {
+	m := 0.0
+	for _, s := range e.Sets {
+		m = max(m, s.Weight)
+	}
+
+	return m
+}
+
+// EstimatedOneRepMax returns the best estimated one-rep max of all sets
+func (e *WorkoutExercise) EstimatedOneRepMax() float64 {
+	m := 0.0
+	for _, s := range e.Sets {
+		m = max(m, s.EstimatedOneRepMax())
+	}
+
+	return m
+}
+
+func (e *Exercise) Validate() error {
+	e.Name = strings.TrimSpace(e.Name)
+	if e.Name == "" {
+		return ErrInvalidExercise
+	}
+
+	return nil
+}
+
+// Save stores the exercise; names are unique per user, ignoring case
+func (e *Exercise) Save(db *gorm.DB) error {
+	if err := e.Validate(); err != nil {
+		return err
+	}
+
+	var count int64
+
+	if err := db.Model(&Exercise{}).
+		Where("user_id = ? AND LOWER(name) = LOWER(?) AND id <> ?", e.UserID, e.Name, e.ID).
+		Count(&count).Error; err != nil {
+		return err
+	}
+
+	if count > 0 {
+		return ErrExerciseExists
+	}
+
+	return db.Save(e).Error
+}
+
+// Delete removes the exercise, unless workouts still use it
+func (e *Exercise) Delete(db *gorm.DB) error {
+	var count int64
+
+	if err := db.Model(&WorkoutExercise{}).Where(&WorkoutExercise{ExerciseID: e.ID}).Count(&count).Error; err != nil {
+		return err
+	}
+
+	if count > 0 {
+		return ErrExerciseInUse
+	}
+
+	return db.Unscoped().Delete(e).Error
+}
+
+func (u *User) GetExercises(db *gorm.DB) ([]Exercise, error) {
+	var e []Exercise
+
+	if err := db.Where(&Exercise{UserID: u.ID}).Order("name").Find(&e).Error; err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+func (u *User) GetExercise(db *gorm.DB, id int) (*Exercise, error) {
+	var e Exercise
+
+	if err := db.Where(&Exercise{UserID: u.ID}).First(&e, id).Error; err != nil {
+		return nil, err
+	}
+
+	return &e, nil
+}
+
+// GetOrCreateExercise returns the exercise with the name from the user's
+// catalog, adding it if it is not there yet
+func (u *User) GetOrCreateExercise(db *gorm.DB, name string) (*Exercise, error) {
+	e := &Exercise{UserID: u.ID, Name: name}
+	if err := e.Validate(); err != nil {
+		return nil, err
+	}
+
+	err := db.Where("user_id = ? AND LOWER(name) = LOWER(?)", u.ID, e.Name).First(e).Error
+	if err == nil {
+		return e, nil
+	}
+
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	if err := e.Save(db); err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+// LoadExercises reads the exercises of the workout and their sets, in order
+func (w *Workout) LoadExercises(db *gorm.DB) error {
+	return db.
+		Preload("Exercise").
+		Preload("Sets", func(db *gorm.DB) *gorm.DB { return db.Order("number") }).
+		Where(&WorkoutExercise{WorkoutID: w.ID}).
+		Order("position").
+		Find(&w.Exercises).Error
+}
+
+// UpdateStrengthTotals rolls the sets of the exercises up into the total
+// repetitions and the total weight (the volume) of the workout
+func (w *Workout) UpdateStrengthTotals() {
+	if len(w.Exercises) == 0 || w.Data == nil {
+		return
+	}
+
+	w.Data.TotalRepetitions = 0
+	w.Data.TotalWeight = 0
+
+	for _, e := range w.Exercises {
+		w.Data.TotalRepetitions += e.Repetitions()
+		w.Data.TotalWeight += e.Volume()
+	}
+}
+
+// AddSet adds a set of the exercise to the workout; a new exercise is added
+// after the exercises already in the workout
+func (w *Workout) AddSet(db *gorm.DB, e *Exercise, s *ExerciseSet) error {
+	if !w.Type.IsRepetition() {
+		return ErrNoRepetitionWorkout
+	}
+
+	if e.UserID != w.UserID {
+		return ErrInvalidExercise
+	}
+
+	if err := s.Validate(); err != nil {
+		return err
+	}
+
+	if err := w.LoadExercises(db); err != nil {
+		return err
+	}
+
+	var we *WorkoutExercise
+
+	for i := range w.Exercises {
+		if w.Exercises[i].ExerciseID == e.ID {
+			we = &w.Exercises[i]
+		}
+	}
+
+	if we == nil {
+		we = &WorkoutExercise{WorkoutID: w.ID, ExerciseID: e.ID, Position: len(w.Exercises) + 1}
+		if err := db.Create(we).Error; err != nil {
+			return err
+		}
+	}
+
+	s.WorkoutExerciseID = we.ID
+	s.Number = len(we.Sets) + 1
+
+	if err := db.Create(s).Error; err != nil {
+		return err
+	}
+
+	return w.saveStrengthTotals(db)
+}
+
+// DeleteSet removes a set from the workout, and the exercise if it was its
+// last set
+func (w *Workout) DeleteSet(db *gorm.DB, id uint) error {
+	if err := w.LoadExercises(db); err != nil {
+		return err
+	}
+
+	for _, we := range w.Exercises {
+		for _, s := range we.Sets {
+			if s.ID != id {
+				continue
+			}
+
+			if err := db.Unscoped().Delete(&s).Error; err != nil {
+				return err
+			}
+
+			if err := db.Model(&ExerciseSet{}).
+				Where("workout_exercise_id = ? AND number > ?", we.ID, s.Number).
+				Update("number", gorm.Expr("number - 1")).Error; err != nil {
+				return err
+			}
+
+			if len(we.Sets) == 1 {
+				if err := w.deleteExercise(db, &we); err != nil {
+					return err
+				}
+			}
+
+			return w.saveStrengthTotals(db)
+		}
+	}
+
+	return gorm.ErrRecordNotFound
+}
+
+// deleteExercise removes an exercise without sets from the workout, and moves
+// up the exercises after it
+func (w *Workout) deleteExercise(db *gorm.DB, we *WorkoutExercise) error {
+	if err := db.Unscoped().Delete(we).Error; err != nil {
+		return err
+	}
+
+	return db.Model(&WorkoutExercise{}).
+		Where("workout_id = ? AND position > ?", w.ID, we.Position).
+		Update("position", gorm.Expr("position - 1")).Error
+}
+
+// saveStrengthTotals re-reads the exercises and stores the new totals
+func (w *Workout) saveStrengthTotals(db *gorm.DB) error {
+	if err := w.LoadExercises(db); err != nil {
+		return err
+	}
+
+	if w.Data == nil {
+		return nil
+	}
+
+	if len(w.Exercises) == 0 {
+		w.Data.TotalRepetitions = 0
+		w.Data.TotalWeight = 0
+	}
+
+	w.UpdateStrengthTotals()
+
+	return db.Model(w.Data).
+		Select("TotalRepetitions", "TotalWeight").
+		Updates(w.Data).Error
+}
+
+// deleteExercises removes the exercises and sets of the workout
+func (w *Workout) deleteExercises(db *gorm.DB) error {
+	if w.ID == 0 {
+		return nil
+	}
+
+	ids := db.Model(&WorkoutExercise{}).Select("id").Where(&WorkoutExercise{WorkoutID: w.ID})
+
+	if err := db.Unscoped().Where("workout_exercise_id IN (?)", ids).Delete(&ExerciseSet{}).Error; err != nil {
+		return err
+	}
+
+	return db.Unscoped().Where(&WorkoutExercise{WorkoutID: w.ID}).Delete(&WorkoutExercise{}).Error
+}
+
+// ExerciseHistoryItem is the performance on an exercise in a single workout
+type ExerciseHistoryItem struct {
+	WorkoutID          uint      // The ID of the workout
+	WorkoutName        string    // The name of the workout
+	Date               time.Time // The date of the workout
+	Sets               int       // The number of sets
+	Repetitions        int       // The total number of repetitions
+	Volume             float64   // The total weight moved
+	MaxWeight          float64   // The heaviest weight
+	EstimatedOneRepMax float64   // The best estimated one-rep max
+}
+
+// History returns the performance on the exercise in every workout, oldest
+// first
+func (e *Exercise) History(db *gorm.DB) ([]ExerciseHistoryItem, error) {
+	var exercises []WorkoutExercise
+
+	if err := db.
+		Preload("Workout").
+		Preload("Sets").
+		Joins("JOIN workouts ON workouts.id = workout_exercises.workout_id").
+		Where(&WorkoutExercise{ExerciseID: e.ID}).
+		Order("workouts.date").
+		Find(&exercises).Error; err != nil {
+		return nil, err
+	}
+
+	r := make([]ExerciseHistoryItem, 0, len(exercises))
+
+	for _, we := range exercises {
+		if we.Workout == nil || we.Workout.Date == nil {
+			continue
+		}
+
+		r = append(r, ExerciseHistoryItem{
+			WorkoutID:          we.WorkoutID,
+			WorkoutName:        we.Workout.Name,
+			Date:               *we.Workout.Date,
+			Sets:               len(we.Sets),
+			Repetitions:        we.Repetitions(),
+			Volume:             we.Volume(),
+			MaxWeight:          we.MaxWeight(),
+			EstimatedOneRepMax: we.EstimatedOneRepMax(),
+		})
+	}
+
+	return r, nil
+}
@@ -0,0 +1,134 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExerciseSet_EstimatedOneRepMax(t *testing.T) {
+	assert.InDelta(t, 0, (&ExerciseSet{Weight: 100}).EstimatedOneRepMax(), 0.01)
+	assert.InDelta(t, 100, (&ExerciseSet{Repetitions: 1, Weight: 100}).EstimatedOneRepMax(), 0.01)
+	assert.InDelta(t, 116.67, (&ExerciseSet{Repetitions: 5, Weight: 100}).EstimatedOneRepMax(), 0.01)
+	assert.InDelta(t, 500, (&ExerciseSet{Repetitions: 5, Weight: 100}).Volume(), 0.01)
+}
+
+func strengthWorkout(t *testing.T, u *User, d time.Time) *Workout {
+	t.Helper()
+
+	w := &Workout{UserID: u.ID, Name: "lifting", Type: WorkoutTypeWeightLifting, Date: &d, Data: &MapData{}}
+	require.NoError(t, w.Create(u.db))
+
+	return w
+}
+
+func TestWorkout_AddSet(t *testing.T) {
+	db := createMemoryDB(t)
+
+	u := defaultUser()
+	require.NoError(t, u.Create(db))
+
+	u, err := GetUserByID(db, int(u.ID))
+	require.NoError(t, err)
+
+	w := strengthWorkout(t, u, time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC))
+
+	bench, err := u.GetOrCreateExercise(db, "Bench press")
+	require.NoError(t, err)
+
+	squat, err := u.GetOrCreateExercise(db, "Squat")
+	require.NoError(t, err)
+
+	again, err := u.GetOrCreateExercise(db, " bench PRESS ")
+	require.NoError(t, err)
+	assert.Equal(t, bench.ID, again.ID)
+
+	require.NoError(t, w.AddSet(db, bench, &ExerciseSet{Repetitions: 5, Weight: 80}))
+	require.NoError(t, w.AddSet(db, squat, &ExerciseSet{Repetitions: 5, Weight: 100}))
+	require.NoError(t, w.AddSet(db, bench, &ExerciseSet{Repetitions: 3, Weight: 90, RPE: 9}))
+
+	assert.ErrorIs(t, w.AddSet(db, bench, &ExerciseSet{Repetitions: 0, Weight: 90}), ErrInvalidExercise)
+
+	w, err = u.GetWorkout(db, int(w.ID))
+	require.NoError(t, err)
+
+	require.Len(t, w.Exercises, 2)
+	assert.Equal(t, "Bench press", w.Exercises[0].Exercise.Name)
+	assert.Equal(t, 1, w.Exercises[0].Position)
+	require.Len(t, w.Exercises[0].Sets, 2)
+	assert.Equal(t, 2, w.Exercises[0].Sets[1].Number)
+	assert.Equal(t, "Squat", w.Exercises[1].Exercise.Name)
+
+	assert.Equal(t, 13, w.Data.TotalRepetitions)
+	assert.InDelta(t, 5*80+5*100+3*90, w.Data.TotalWeight, 0.01)
+
+	// Removing the only set of an exercise removes the exercise
+	require.NoError(t, w.DeleteSet(db, w.Exercises[1].Sets[0].ID))
+
+	w, err = u.GetWorkout(db, int(w.ID))
+	require.NoError(t, err)
+
+	require.Len(t, w.Exercises, 1)
+	assert.Equal(t, 8, w.Data.TotalRepetitions)
+	require.NoError(t, squat.Delete(db))
+	assert.ErrorIs(t, bench.Delete(db), ErrExerciseInUse)
+
+	require.NoError(t, w.Delete(db))
+
+	var count int64
+	require.NoError(t, db.Model(&ExerciseSet{}).Count(&count).Error)
+	assert.Zero(t, count)
+}
+
+func TestWorkout_AddSetNoRepetitions(t *testing.T) {
+	db := createMemoryDB(t)
+
+	u := defaultUser()
+	require.NoError(t, u.Create(db))
+
+	d := time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC)
+	w := &Workout{UserID: u.ID, Name: "run", Type: WorkoutTypeRunning, Date: &d, Data: &MapData{}}
+	require.NoError(t, w.Create(db))
+
+	e, err := u.GetOrCreateExercise(db, "Squat")
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, w.AddSet(db, e, &ExerciseSet{Repetitions: 5}), ErrNoRepetitionWorkout)
+}
+
+func TestExercise_History(t *testing.T) {
+	db := createMemoryDB(t)
+
+	u := defaultUser()
+	require.NoError(t, u.Create(db))
+
+	u, err := GetUserByID(db, int(u.ID))
+	require.NoError(t, err)
+
+	e, err := u.GetOrCreateExercise(db, "Deadlift")
+	require.NoError(t, err)
+
+	for i, weight := range []float64{120, 100} {
+		w := strengthWorkout(t, u, time.Date(2024, time.Month(2-i), 1, 10, 0, 0, 0, time.UTC))
+		require.NoError(t, w.AddSet(db, e, &ExerciseSet{Repetitions: 3, Weight: weight}))
+		require.NoError(t, w.AddSet(db, e, &ExerciseSet{Repetitions: 1, Weight: weight + 10}))
+	}
+
+	h, err := e.History(db)
+	require.NoError(t, err)
+
+	require.Len(t, h, 2)
+	assert.Equal(t, time.January, h[0].Date.Month())
+	assert.Equal(t, 2, h[0].Sets)
+	assert.Equal(t, 4, h[0].Repetitions)
+	assert.InDelta(t, 110, h[0].MaxWeight, 0.01)
+	assert.InDelta(t, 110, h[0].EstimatedOneRepMax, 0.01)
+	assert.InDelta(t, 132, h[1].EstimatedOneRepMax, 0.01)
+	assert.InDelta(t, 3*120+130, h[1].Volume, 0.01)
+
+	_, err = u.GetOrCreateExercise(db, " ")
+	assert.ErrorIs(t, err, ErrInvalidExercise)
+	assert.ErrorIs(t, (&Exercise{UserID: u.ID, Name: "DEADLIFT"}).Save(db), ErrExerciseExists)
+}
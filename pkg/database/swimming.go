@@ -0,0 +1,176 @@
+package database
+
+import (
+	"math"
+	"time"
+
+	"github.com/jovandeginste/workout-tracker/pkg/converters"
+	"gorm.io/gorm"
+)
+
+// SwimLength is a single length of a pool swim, as recorded by the device
+type SwimLength struct {
+	gorm.Model
+	MapDataID uint          `gorm:"not null;index" json:"-"` // The ID of the map data this length belongs to
+	Number    int           // The number of the length, starting at 1
+	Start     time.Time     // The start time of the length
+	Duration  time.Duration // The duration of the length
+	Stroke    string        // The stroke type, eg. "freestyle"; empty if unknown
+	Strokes   int           // The number of strokes
+	Active    bool          // Whether the length was swum; idle lengths are rests
+	Distance  float64       // The distance of the length (the pool length), in meters; 0 for rests
+}
+
+// SWOLF returns the swim golf score of the length: the number of strokes plus
+// the number of seconds; it is 0 for rests
+func (l *SwimLength) SWOLF() int {
+	if !l.Active {
+		return 0
+	}
+
+	return l.Strokes + int(math.Round(l.Duration.Seconds()))
+}
+
+// AverageSpeed returns the average speed of the length, in meters per second
+func (l *SwimLength) AverageSpeed() float64 {
+	if l.Duration <= 0 {
+		return 0
+	}
+
+	return l.Distance / l.Duration.Seconds()
+}
+
+// setSwimPool stores the pool length and lengths recorded in the file, if it
+// is a pool swim
+func (m *MapData) setSwimPool(filename string, content []byte) error {
+	pool, err := converters.ParseSwimPool(filename, content)
+	if err != nil || pool == nil {
+		return err
+	}
+
+	m.PoolLength = pool.Length
+	m.PoolLengthYards = pool.Yards
+	m.Lengths = make([]SwimLength, 0, len(pool.Lengths))
+
+	for i, l := range pool.Lengths {
+		length := SwimLength{
+			Number:   i + 1,
+			Start:    l.Start,
+			Duration: l.Duration,
+			Stroke:   l.Stroke,
+			Strokes:  l.Strokes,
+			Active:   l.Active,
+		}
+
+		if l.Active {
+			length.Distance = pool.Length
+		}
+
+		m.Lengths = append(m.Lengths, length)
+	}
+
+	return nil
+}
+
+// ActiveLengths returns the lengths that were swum, without the rests
+func (m *MapData) ActiveLengths() []SwimLength {
+	var r []SwimLength
+
+	for _, l := range m.Lengths {
+		if l.Active {
+			r = append(r, l)
+		}
+	}
+
+	return r
+}
+
+// IsPoolSwim returns whether the workout was swum in a pool of known length
+func (m *MapData) IsPoolSwim() bool {
+	return m.PoolLength > 0 && len(m.Lengths) > 0
+}
+
+// AverageSWOLF returns the average SWOLF of the lengths that were swum
+func (m *MapData) AverageSWOLF() float64 {
+	active := m.ActiveLengths()
+	if len(active) == 0 {
+		return 0
+	}
+
+	total := 0
+
+	for _, l := range active {
+		total += l.SWOLF()
+	}
+
+	return float64(total) / float64(len(active))
+}
+
+// UpdateSwimming calculates the swimming metrics: pool swims without a position
+// get their distance from the pool length and the number of lengths, and the
+// stroke rate comes from the lengths, or from the cadence of the points for
+// open-water swims
+func (m *MapData) UpdateSwimming(wt WorkoutType) {
+	m.AverageStrokeRate = 0
+
+	if !wt.IsSwimming() {
+		return
+	}
+
+	if m.IsPoolSwim() {
+		var (
+			distance float64
+			strokes  int
+			duration time.Duration
+		)
+
+		for _, l := range m.ActiveLengths() {
+			distance += l.Distance
+			strokes += l.Strokes
+			duration += l.Duration
+		}
+
+		if m.Center.IsZero() {
+			m.TotalDistance = distance
+		}
+
+		if duration > 0 {
+			m.AverageStrokeRate = float64(strokes) / duration.Minutes()
+		}
+
+		return
+	}
+
+	if m.Details == nil {
+		return
+	}
+
+	var (
+		total time.Duration
+		sum   float64
+	)
+
+	for _, p := range m.Details.Points {
+		rate := p.ExtraMetrics.Get("cadence")
+		if rate <= 0 || p.Duration <= 0 || p.Duration > maxHeartRateInterval {
+			continue
+		}
+
+		total += p.Duration
+		sum += rate * p.Duration.Seconds()
+	}
+
+	if total > 0 {
+		m.AverageStrokeRate = sum / total.Seconds()
+	}
+}
+
+// deleteSwimLengths removes the stored lengths of the map data, before they
+// are replaced
+func (m *MapData) deleteSwimLengths(db *gorm.DB) error {
+	if m.ID == 0 {
+		return nil
+	}
+
+	return db.Unscoped().Where(&SwimLength{MapDataID: m.ID}).Delete(&SwimLength{}).Error
+}
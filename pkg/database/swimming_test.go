@@ -0,0 +1,119 @@
+package database
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/jovandeginste/workout-tracker/pkg/converters"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tormoder/fit"
+)
+
+// poolSwimFIT returns a FIT file of a pool swim without positions: four
+// lengths of 30 seconds and 15 strokes in a 25m pool, with a rest after the
+// second length
+func poolSwimFIT(t *testing.T) []byte {
+	t.Helper()
+
+	start := time.Date(2024, 3, 4, 7, 0, 0, 0, time.UTC)
+	a := &converters.Activity{Name: "Pool", Type: "swimming", Start: start, TotalDuration: 150 * time.Second}
+
+	for i := range 16 {
+		a.Points = append(a.Points, converters.ActivityPoint{Time: start.Add(time.Duration(i) * 10 * time.Second)})
+	}
+
+	content, err := converters.ExportFIT(a)
+	require.NoError(t, err)
+
+	f, err := fit.Decode(bytes.NewReader(content))
+	require.NoError(t, err)
+
+	act, err := f.Activity()
+	require.NoError(t, err)
+
+	act.Sessions[0].PoolLength = 2500
+	act.Sessions[0].PoolLengthUnit = fit.DisplayMeasureMetric
+
+	ts := start
+
+	for i := range 5 {
+		l := fit.NewLengthMsg()
+		l.MessageIndex = fit.MessageIndex(i)
+		l.StartTime = ts
+		l.TotalTimerTime = 30000
+		l.TotalElapsedTime = 30000
+		l.LengthType = fit.LengthTypeActive
+		l.SwimStroke = fit.SwimStrokeFreestyle
+		l.TotalStrokes = 15
+
+		if i == 2 {
+			l.LengthType = fit.LengthTypeIdle
+			l.TotalStrokes = 0xFFFF
+		}
+
+		ts = ts.Add(30 * time.Second)
+		l.Timestamp = ts
+		act.Lengths = append(act.Lengths, l)
+	}
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, fit.Encode(buf, f, binary.LittleEndian))
+
+	return buf.Bytes()
+}
+
+func TestWorkout_ParsePoolSwim(t *testing.T) {
+	w, err := NewWorkout(defaultUser(), WorkoutTypeSwimming, "", "pool.fit", poolSwimFIT(t))
+	require.NoError(t, err)
+
+	assert.True(t, w.Data.IsPoolSwim())
+	assert.InDelta(t, 25, w.Data.PoolLength, 0.01)
+	assert.False(t, w.Data.PoolLengthYards)
+
+	require.Len(t, w.Data.Lengths, 5)
+	assert.Len(t, w.Data.ActiveLengths(), 4)
+	assert.Equal(t, "freestyle", w.Data.Lengths[0].Stroke)
+	assert.Equal(t, 45, w.Data.Lengths[0].SWOLF())
+	assert.False(t, w.Data.Lengths[2].Active)
+	assert.Zero(t, w.Data.Lengths[2].SWOLF())
+
+	assert.InDelta(t, 100, w.Data.TotalDistance, 0.01)
+	assert.InDelta(t, 45, w.Data.AverageSWOLF(), 0.01)
+	assert.InDelta(t, 30, w.Data.AverageStrokeRate, 0.01)
+	assert.InDelta(t, 25.0/30, w.Data.Lengths[0].AverageSpeed(), 0.001)
+}
+
+func TestWorkout_SavePoolSwim(t *testing.T) {
+	db := createMemoryDB(t)
+	u := defaultUser()
+	require.NoError(t, u.Create(db))
+
+	w, err := NewWorkout(u, WorkoutTypeSwimming, "", "pool.fit", poolSwimFIT(t))
+	require.NoError(t, err)
+	require.NoError(t, w.Create(db))
+
+	require.NoError(t, w.UpdateData(db))
+
+	var count int64
+	require.NoError(t, db.Model(&SwimLength{}).Where(&SwimLength{MapDataID: w.Data.ID}).Count(&count).Error)
+	assert.Equal(t, int64(5), count)
+}
+
+func TestMapData_UpdateSwimmingOpenWater(t *testing.T) {
+	m := &MapData{TotalDistance: 1500, Details: heartRatePoints(120, 130, 140)}
+	for i := range m.Details.Points {
+		m.Details.Points[i].ExtraMetrics.Set("cadence", float64(20+10*i))
+	}
+
+	m.UpdateSwimming(WorkoutTypeSwimming)
+
+	assert.False(t, m.IsPoolSwim())
+	assert.InDelta(t, 1500, m.TotalDistance, 0.01)
+	assert.InDelta(t, 35, m.AverageStrokeRate, 0.01)
+
+	m.UpdateSwimming(WorkoutTypeRunning)
+	assert.Zero(t, m.AverageStrokeRate)
+}
@@ -0,0 +1,306 @@
+package database
+
+import (
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/tkrajina/gpxgo/gpx"
+)
+
+const (
+	// DefaultMaxAccuracy is the worst horizontal accuracy of a point that is
+	// kept when cleaning a track, in meters
+	DefaultMaxAccuracy = 30.0
+
+	// DefaultStationaryRadius is the radius within which points are considered
+	// to be jitter around a stationary position, in meters
+	DefaultStationaryRadius = 5.0
+
+	// defaultMaxSpeed is the maximum speed of types without a maximum, in m/s
+	defaultMaxSpeed = 50.0
+
+	// stationaryWindow is the time around a point in which the track has to
+	// stay within the stationary radius for the point to be stationary
+	stationaryWindow = 20 * time.Second
+
+	// maxConsecutiveOutliers is the number of consecutive points that are
+	// dropped for an impossible speed; the next point is kept, since the
+	// outlier is then more likely the point before them
+	maxConsecutiveOutliers = 5
+)
+
+var ErrInvalidTrackCleaning = errors.New("invalid track cleaning settings")
+
+// workoutTypeMaxSpeeds are the highest plausible speeds of the built-in
+// workout types, in m/s; faster points are GPS errors
+var workoutTypeMaxSpeeds = map[WorkoutType]float64{
+	WorkoutTypeRunning:      12,
+	WorkoutTypeCycling:      28,
+	WorkoutTypeWalking:      4,
+	WorkoutTypeSkiing:       40,
+	WorkoutTypeSnowboarding: 35,
+	WorkoutTypeSwimming:     3,
+	WorkoutTypeKayaking:     8,
+	WorkoutTypeGolfing:      10,
+	WorkoutTypeHiking:       4,
+	WorkoutTypeTransition:   12,
+}
+
+// MaxSpeed returns the highest plausible speed of the workout type, in m/s
+func (wt WorkoutType) MaxSpeed() float64 {
+	if s, ok := workoutTypeMaxSpeeds[wt]; ok {
+		return s
+	}
+
+	return defaultMaxSpeed
+}
+
+// TrackCleaningSettings configures the cleaning of GPS tracks before the
+// totals are calculated
+type TrackCleaningSettings struct {
+	Enabled          bool    `form:"track_cleaning" json:"enabled"`                    // Whether tracks are cleaned
+	MaxAccuracy      float64 `form:"track_max_accuracy" json:"max_accuracy"`           // The worst horizontal accuracy of a point that is kept, in meters
+	StationaryRadius float64 `form:"track_stationary_radius" json:"stationary_radius"` // The radius of the jitter around a stationary position, in meters
+}
+
+func (s TrackCleaningSettings) MaxAccuracyOrDefault() float64 {
+	if s.MaxAccuracy <= 0 {
+		return DefaultMaxAccuracy
+	}
+
+	return s.MaxAccuracy
+}
+
+func (s TrackCleaningSettings) StationaryRadiusOrDefault() float64 {
+	if s.StationaryRadius <= 0 {
+		return DefaultStationaryRadius
+	}
+
+	return s.StationaryRadius
+}
+
+// Validate checks that the settings are not negative
+func (s TrackCleaningSettings) Validate() error {
+	if s.MaxAccuracy < 0 || s.StationaryRadius < 0 {
+		return ErrInvalidTrackCleaning
+	}
+
+	return nil
+}
+
+// RawTrackStats are the totals of a track before it was cleaned
+type RawTrackStats struct {
+	TotalDistance  float64       // The total distance of the raw track
+	MaxSpeed       float64       // The maximum speed of the raw track
+	PauseDuration  time.Duration // The total pause duration of the raw track
+	RemovedPoints  int           // The number of points removed by the cleaning
+	SmoothedPoints int           // The number of points moved to a stationary position by the cleaning
+}
+
+// IsCleaned returns whether the track was changed by the cleaning
+func (m *MapData) IsCleaned() bool {
+	return m.RawStats != nil
+}
+
+// Raw returns a copy of the map data with the totals of the raw track
+func (m *MapData) Raw() *MapData {
+	if !m.IsCleaned() {
+		return m
+	}
+
+	raw := *m
+	raw.TotalDistance = m.RawStats.TotalDistance
+	raw.MaxSpeed = m.RawStats.MaxSpeed
+	raw.PauseDuration = m.RawStats.PauseDuration
+	raw.RawStats = nil
+
+	return &raw
+}
+
+// Raw returns a copy of the workout with the totals of the raw track
+func (w *Workout) Raw() *Workout {
+	if w.Data == nil || !w.Data.IsCleaned() {
+		return w
+	}
+
+	raw := *w
+	raw.Data = w.Data.Raw()
+
+	return &raw
+}
+
+// CleanTrack removes the points with a bad horizontal accuracy and the points
+// that imply an impossible speed for the workout type, and moves jitter around
+// stationary positions to that position; the totals are then calculated from
+// the remaining points, and the totals of the raw track are kept
+func (m *MapData) CleanTrack(wt WorkoutType, s TrackCleaningSettings) {
+	m.RawStats = nil
+
+	if !s.Enabled || m.Details == nil || len(m.Details.Points) < 2 {
+		return
+	}
+
+	raw := &RawTrackStats{
+		TotalDistance: m.TotalDistance,
+		MaxSpeed:      m.MaxSpeed,
+		PauseDuration: m.PauseDuration,
+	}
+
+	points := removeOutliers(m.Details.Points, wt.MaxSpeed(), s, raw)
+	smoothStationary(points, s.StationaryRadiusOrDefault(), raw)
+
+	if raw.RemovedPoints == 0 && raw.SmoothedPoints == 0 {
+		return
+	}
+
+	totalDistance, totalDuration := 0.0, time.Duration(0)
+
+	for i := range points {
+		switch {
+		case i == 0:
+			points[i].Distance, points[i].Duration = 0, 0
+		case points[i].HasPosition() && points[i-1].HasPosition():
+			points[i].Distance = mapPointDistance(&points[i-1], &points[i])
+		}
+
+		totalDistance += points[i].Distance
+		totalDuration += points[i].Duration
+		points[i].TotalDistance = totalDistance
+		points[i].TotalDuration = totalDuration
+	}
+
+	m.Details.Points = points
+	m.updateFromPoints()
+	m.RawStats = raw
+}
+
+// removeOutliers returns the points without the points with a bad horizontal
+// accuracy or an impossible speed; the duration of a removed point is added to
+// the next point
+func removeOutliers(points []MapPoint, maxSpeed float64, s TrackCleaningSettings, raw *RawTrackStats) []MapPoint {
+	var (
+		result          []MapPoint
+		last            *MapPoint
+		outliers        int
+		droppedDuration time.Duration
+	)
+
+	maxAccuracy := s.MaxAccuracyOrDefault()
+	radius := s.StationaryRadiusOrDefault()
+
+	for _, p := range points {
+		p.Duration += droppedDuration
+		droppedDuration = 0
+
+		if !p.HasPosition() {
+			// Points without a position have the distance recorded by the
+			// device, and are kept as is
+			result = append(result, p)
+			continue
+		}
+
+		if isInaccurate(p, maxAccuracy) ||
+			(last != nil && outliers < maxConsecutiveOutliers && isTooFast(last, &p, maxSpeed, radius)) {
+			raw.RemovedPoints++
+			outliers++
+			droppedDuration = p.Duration
+
+			continue
+		}
+
+		outliers = 0
+
+		result = append(result, p)
+		last = &result[len(result)-1]
+	}
+
+	return result
+}
+
+// smoothStationary moves the points that are stationary to the position where
+// the stop started; a point is stationary when all points around it, within
+// the stationary window, are within the radius of its recorded position
+func smoothStationary(points []MapPoint, radius float64, raw *RawTrackStats) {
+	// The window is checked against the recorded positions, not the positions
+	// of points that were already moved
+	recorded := slices.Clone(points)
+
+	var anchor *MapPoint
+
+	lo, hi := 0, 0
+
+	for i := range points {
+		p := &points[i]
+		if !p.HasPosition() {
+			anchor = nil
+			continue
+		}
+
+		for lo < i && p.Time.Sub(points[lo].Time) > stationaryWindow/2 {
+			lo++
+		}
+
+		for hi+1 < len(points) && points[hi+1].Time.Sub(p.Time) <= stationaryWindow/2 {
+			hi++
+		}
+
+		if lo == hi || !withinRadius(recorded[lo:hi+1], &recorded[i], radius) {
+			anchor = nil
+			continue
+		}
+
+		if anchor == nil {
+			a := *p
+			anchor = &a
+
+			continue
+		}
+
+		if p.Lat != anchor.Lat || p.Lng != anchor.Lng {
+			raw.SmoothedPoints++
+			p.Lat, p.Lng = anchor.Lat, anchor.Lng
+		}
+	}
+}
+
+// withinRadius returns whether all points have a position within the radius
+// of the center
+func withinRadius(points []MapPoint, center *MapPoint, radius float64) bool {
+	for i := range points {
+		if !points[i].HasPosition() || mapPointDistance(&points[i], center) >= radius {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isInaccurate returns whether the horizontal accuracy of the point, in
+// millimeters, is worse than the maximum, in meters
+func isInaccurate(p MapPoint, maxAccuracy float64) bool {
+	acc, ok := p.ExtraMetrics["horizontal-accuracy"]
+	if !ok {
+		return false
+	}
+
+	return acc/1000 > maxAccuracy
+}
+
+// isTooFast returns whether getting from the previous point to the point
+// implies a speed above the maximum; points recorded at the same time may
+// only be within the stationary radius
+func isTooFast(prev, p *MapPoint, maxSpeed, radius float64) bool {
+	d := mapPointDistance(prev, p)
+
+	seconds := p.Time.Sub(prev.Time).Seconds()
+	if seconds <= 0 {
+		return d > radius
+	}
+
+	return d/seconds > maxSpeed
+}
+
+func mapPointDistance(p1, p2 *MapPoint) float64 {
+	return gpx.HaversineDistance(p1.Lat, p1.Lng, p2.Lat, p2.Lng)
+}
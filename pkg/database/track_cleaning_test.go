@@ -0,0 +1,133 @@
+package database
+
+import (
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tkrajina/gpxgo/gpx"
+)
+
+// metersPerDegree is the length of a degree of latitude, close enough for the
+// tests
+const metersPerDegree = 111195.0
+
+// straightTrack returns a GPX going north at the speed, in m/s, with a point
+// every second; offsets, in meters to the east, move single points
+func straightTrack(n int, speed float64, offsets map[int]float64) *gpx.GPX {
+	g := &gpx.GPX{Creator: "Garmin"}
+	g.AppendTrack(&gpx.GPXTrack{})
+
+	start := time.Date(2024, 4, 1, 9, 0, 0, 0, time.UTC)
+
+	for i := range n {
+		g.AppendPoint(&gpx.GPXPoint{
+			Timestamp: start.Add(time.Duration(i) * time.Second),
+			Point: gpx.Point{
+				Latitude:  50 + float64(i)*speed/metersPerDegree,
+				Longitude: 4 + offsets[i]/metersPerDegree*1.556,
+			},
+		})
+	}
+
+	return g
+}
+
+var cleaningEnabled = TrackCleaningSettings{Enabled: true}
+
+func TestMapData_CleanTrackOutlier(t *testing.T) {
+	m := gpxAsMapData(straightTrack(101, 3, map[int]float64{50: 500}))
+	require.Greater(t, m.TotalDistance, 1200.0)
+
+	m.CleanTrack(WorkoutTypeRunning, cleaningEnabled)
+
+	require.True(t, m.IsCleaned())
+	assert.Equal(t, 1, m.RawStats.RemovedPoints)
+	assert.Greater(t, m.RawStats.TotalDistance, 1200.0)
+	assert.Len(t, m.Details.Points, 100)
+	assert.InDelta(t, 300, m.TotalDistance, 1)
+	assert.InDelta(t, 3, m.MaxSpeed, 0.1)
+	assert.Equal(t, 100*time.Second, m.Details.Points[99].TotalDuration)
+
+	raw := m.Raw()
+	assert.InDelta(t, m.RawStats.TotalDistance, raw.TotalDistance, 0.01)
+	assert.False(t, raw.IsCleaned())
+
+	// The same jump is plausible for a faster type
+	m = gpxAsMapData(straightTrack(101, 3, map[int]float64{50: 20}))
+	m.CleanTrack(WorkoutTypeCycling, cleaningEnabled)
+	assert.False(t, m.IsCleaned())
+}
+
+func TestMapData_CleanTrackAccuracy(t *testing.T) {
+	g := straightTrack(11, 3, nil)
+	p := &g.Tracks[0].Segments[0].Points[5]
+	p.Extensions.Nodes = append(p.Extensions.Nodes, gpx.ExtensionNode{
+		XMLName: xml.Name{Local: "hAcc"}, Data: "45000",
+	})
+
+	m := gpxAsMapData(g)
+	m.CleanTrack(WorkoutTypeRunning, cleaningEnabled)
+
+	require.True(t, m.IsCleaned())
+	assert.Equal(t, 1, m.RawStats.RemovedPoints)
+	assert.Len(t, m.Details.Points, 10)
+
+	// With a higher maximum, the point is kept
+	m = gpxAsMapData(g)
+	m.CleanTrack(WorkoutTypeRunning, TrackCleaningSettings{Enabled: true, MaxAccuracy: 50})
+	assert.False(t, m.IsCleaned())
+}
+
+func TestMapData_CleanTrackStationary(t *testing.T) {
+	// Standing still, with the position jumping a few meters around
+	offsets := map[int]float64{}
+	for i := range 60 {
+		offsets[i] = float64(i%3) * 1.5
+	}
+
+	m := gpxAsMapData(straightTrack(60, 0, offsets))
+	require.Greater(t, m.TotalDistance, 50.0)
+
+	m.CleanTrack(WorkoutTypeWalking, cleaningEnabled)
+
+	require.True(t, m.IsCleaned())
+	assert.Zero(t, m.RawStats.RemovedPoints)
+	assert.Positive(t, m.RawStats.SmoothedPoints)
+	assert.Zero(t, m.TotalDistance)
+	assert.Equal(t, 59*time.Second, m.PauseDuration)
+}
+
+func TestMapData_CleanTrackTurnaround(t *testing.T) {
+	// Walking 20 meters out and back; the ends of the window around the turn
+	// are at the same position, but the walk is not a stop
+	g := straightTrack(41, 0, nil)
+	for i := range g.Tracks[0].Segments[0].Points {
+		g.Tracks[0].Segments[0].Points[i].Latitude = 50 + float64(20-max(i-20, 20-i))/metersPerDegree
+	}
+
+	m := gpxAsMapData(g)
+	m.CleanTrack(WorkoutTypeWalking, cleaningEnabled)
+
+	assert.False(t, m.IsCleaned())
+	assert.InDelta(t, 40, m.TotalDistance, 1)
+}
+
+func TestMapData_CleanTrackDisabled(t *testing.T) {
+	m := gpxAsMapData(straightTrack(101, 3, map[int]float64{50: 500}))
+	d := m.TotalDistance
+
+	m.CleanTrack(WorkoutTypeRunning, TrackCleaningSettings{})
+
+	assert.False(t, m.IsCleaned())
+	assert.InDelta(t, d, m.TotalDistance, 0.01)
+	assert.Len(t, m.Details.Points, 101)
+}
+
+func TestTrackCleaningSettings_Validate(t *testing.T) {
+	require.NoError(t, TrackCleaningSettings{}.Validate())
+	require.ErrorIs(t, TrackCleaningSettings{MaxAccuracy: -1}.Validate(), ErrInvalidTrackCleaning)
+	assert.InDelta(t, DefaultStationaryRadius, TrackCleaningSettings{}.StationaryRadiusOrDefault(), 0.01)
+}
@@ -0,0 +1,815 @@
+package database
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jovandeginste/workout-tracker/pkg/converters"
+	"github.com/tkrajina/gpxgo/gpx"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrNoPointsLeft       = errors.New("the operation leaves no points")
+	ErrNoOriginal         = errors.New("the workout has no original file")
+	ErrTrackNotEditable   = errors.New("the track of this workout can not be changed")
+	ErrNothingToMerge     = errors.New("select other workouts to merge")
+	ErrMergeOtherUser     = errors.New("can not merge workouts of another user")
+	ErrInvalidTrackOffset = errors.New("invalid position in the track")
+)
+
+// mergeWindow is the time before and after a workout in which the other
+// workouts are offered to merge with it
+const mergeWindow = 24 * time.Hour
+
+// MergedFile is the file of a workout that was merged into another workout;
+// it is kept, so the workout can be created again when the original file of
+// the other workout is restored
+type MergedFile struct {
+	gorm.Model
+	GPXDataID  uint              `gorm:"not null;index" json:"-"` // The ID of the file of the workout it was merged into
+	Name       string            `json:"name"`                    // The name of the merged workout
+	Notes      string            `json:"notes"`                   // The notes of the merged workout
+	Type       WorkoutType       `json:"type"`                    // The type of the merged workout
+	Visibility WorkoutVisibility `json:"visibility"`              // The visibility of the merged workout
+	Filename   string            `json:"filename"`                // The filename of the imported file
+	Content    []byte            `gorm:"type:text" json:"-"`      // The imported file
+	Start      *time.Time        `json:"start"`                   // The start of the part of the file that belonged to the merged workout; empty for the start of the file
+	End        *time.Time        `json:"end"`                     // The end of that part, exclusive; empty for the end of the file
+}
+
+func (m *MergedFile) recordedFile() recordedFile {
+	return recordedFile{filename: m.Filename, content: m.Content, start: m.Start, end: m.End}
+}
+
+// recordedFile is an imported file, and the part of it that belongs to a
+// workout
+type recordedFile struct {
+	filename   string
+	content    []byte
+	start, end *time.Time // The part of the file, the end is exclusive; empty for the start or end of the file
+}
+
+// isWhole returns whether the whole file belongs to the workout
+func (f recordedFile) isWhole() bool {
+	return f.start == nil && f.end == nil
+}
+
+// contains returns whether the time is in the part of the file that belongs
+// to the workout
+func (f recordedFile) contains(t time.Time) bool {
+	return (f.start == nil || !t.Before(*f.start)) && (f.end == nil || t.Before(*f.end))
+}
+
+// gpx returns the part of the file that belongs to the workout
+func (f recordedFile) gpx() (*gpx.GPX, error) {
+	g, err := converters.Parse(f.filename, f.content)
+	if err != nil || f.isWhole() {
+		return g, err
+	}
+
+	points := slices.DeleteFunc(trackPoints(g), func(p trackPoint) bool { return !f.contains(p.point.Timestamp) })
+	if len(points) < 2 {
+		return nil, ErrNoPointsLeft
+	}
+
+	return derivedGPX(g, points), nil
+}
+
+// TrackOffset is a position in a track, by the time since the start and the
+// distance from the start; zero values are not used, a point is past the
+// offset when it is past all values that are set
+type TrackOffset struct {
+	Duration time.Duration // The time since the start
+	Distance float64       // The distance from the start, in meters
+}
+
+func (o TrackOffset) IsZero() bool {
+	return o.Duration <= 0 && o.Distance <= 0
+}
+
+// Validate checks that the offset is not negative
+func (o TrackOffset) Validate() error {
+	if o.Duration < 0 || o.Distance < 0 {
+		return ErrInvalidTrackOffset
+	}
+
+	return nil
+}
+
+func (o TrackOffset) reached(p trackPosition) bool {
+	return p.elapsed >= o.Duration && p.distance >= o.Distance
+}
+
+// trackPosition is the time since the start and the distance from the start of
+// a point in a track
+type trackPosition struct {
+	elapsed  time.Duration
+	distance float64
+}
+
+func (p trackPosition) sub(o trackPosition) trackPosition {
+	return trackPosition{elapsed: p.elapsed - o.elapsed, distance: p.distance - o.distance}
+}
+
+// trackPoint is a point of a track, with its segment and its position
+type trackPoint struct {
+	point    gpx.GPXPoint
+	segment  int
+	position trackPosition
+}
+
+// trackPoints returns the points of all tracks and segments of the GPX, in
+// order, with their position in the track
+func trackPoints(g *gpx.GPX) []trackPoint {
+	var (
+		points   []trackPoint
+		prev     *gpx.GPXPoint
+		distance float64
+		segment  int
+	)
+
+	for _, t := range g.Tracks {
+		for _, s := range t.Segments {
+			for _, p := range s.Points {
+				if prev != nil && pointHasDistance(*prev) && pointHasDistance(p) {
+					distance += gpx.HaversineDistance(prev.Latitude, prev.Longitude, p.Latitude, p.Longitude)
+				}
+
+				tp := trackPoint{point: p, segment: segment, position: trackPosition{distance: distance}}
+				if len(points) > 0 {
+					tp.position.elapsed = p.Timestamp.Sub(points[0].point.Timestamp)
+				}
+
+				points = append(points, tp)
+				prev = &points[len(points)-1].point
+			}
+
+			segment++
+		}
+	}
+
+	return points
+}
+
+// derivedGPX returns a GPX with the metadata and track of the original GPX, and
+// the points; the segments of the points are kept
+func derivedGPX(original *gpx.GPX, points []trackPoint) *gpx.GPX {
+	g := &gpx.GPX{
+		Creator:     original.Creator,
+		Name:        original.Name,
+		Description: original.Description,
+	}
+
+	t := gpx.GPXTrack{}
+	if len(original.Tracks) > 0 {
+		t.Name = original.Tracks[0].Name
+		t.Type = original.Tracks[0].Type
+	}
+
+	for i, p := range points {
+		if i == 0 || p.segment != points[i-1].segment {
+			t.Segments = append(t.Segments, gpx.GPXTrackSegment{})
+		}
+
+		s := &t.Segments[len(t.Segments)-1]
+		s.Points = append(s.Points, p.point)
+	}
+
+	g.Tracks = []gpx.GPXTrack{t}
+
+	if len(points) > 0 {
+		start := points[0].point.Timestamp
+		g.Time = &start
+	}
+
+	return g
+}
+
+// share returns the part of the duration of the file that is between the
+// start and the end
+func (f recordedFile) share(start, end time.Time) (float64, error) {
+	g, err := converters.Parse(f.filename, f.content)
+	if err != nil {
+		return 0, err
+	}
+
+	points := trackPoints(g)
+	if len(points) < 2 {
+		return 1, nil
+	}
+
+	first, last := points[0].point.Timestamp, points[len(points)-1].point.Timestamp
+	if !last.After(first) {
+		return 1, nil
+	}
+
+	if start.Before(first) {
+		start = first
+	}
+
+	if end.After(last) {
+		end = last
+	}
+
+	d := end.Sub(start)
+
+	return max(0, min(1, d.Seconds()/last.Sub(first).Seconds())), nil
+}
+
+// HasOriginal returns whether the file of the workout was derived from the
+// imported file, by trimming, splitting or merging
+func (w *Workout) HasOriginal() bool {
+	return w.GPX != nil && w.GPX.OriginalContent != nil
+}
+
+// CanEditTrack returns whether the track of the workout can be trimmed, split
+// or merged; multisport workouts and their legs can not
+func (w *Workout) CanEditTrack() bool {
+	return w.HasFile() && !w.IsLeg() && !w.HasLegs() && !w.Type.IsMultisport()
+}
+
+// editableGPX returns the GPX of the workout, if its track can be changed
+func (w *Workout) editableGPX() (*gpx.GPX, error) {
+	if !w.CanEditTrack() {
+		return nil, ErrTrackNotEditable
+	}
+
+	return w.AsGPX()
+}
+
+// setDerivedGPX replaces the file of the workout with the GPX; the imported
+// file is kept, so it can be restored
+func (w *Workout) setDerivedGPX(g *gpx.GPX) error {
+	content, err := g.ToXml(gpx.ToXmlParams{Version: "1.1", Indent: true})
+	if err != nil {
+		return err
+	}
+
+	if !w.HasOriginal() {
+		w.GPX.OriginalContent = w.GPX.Content
+		w.GPX.OriginalFilename = w.GPX.Filename
+	}
+
+	w.GPX.Content = content
+	w.GPX.Checksum = checksum(content)
+	w.GPX.Filename = strings.TrimSuffix(w.GPX.OriginalFilename, filepath.Ext(w.GPX.OriginalFilename)) + ".gpx"
+	w.Date = gpxDate(g)
+
+	return nil
+}
+
+// saveTrack saves the file of the workout, and recalculates the workout's data
+// from it
+func (w *Workout) saveTrack(db *gorm.DB) error {
+	if err := w.GPX.Save(db); err != nil {
+		return err
+	}
+
+	return w.UpdateData(db)
+}
+
+// original returns the imported file the workout was derived from
+func (w *Workout) original() recordedFile {
+	return recordedFile{
+		filename: w.GPX.OriginalFilename,
+		content:  w.GPX.OriginalContent,
+		start:    w.GPX.OriginalStart,
+		end:      w.GPX.OriginalEnd,
+	}
+}
+
+// recordedFiles returns the imported files the workout was derived from: its
+// own, followed by the files of the workouts that were merged into it
+func (w *Workout) recordedFiles(db *gorm.DB) ([]recordedFile, error) {
+	if !w.HasOriginal() {
+		return []recordedFile{{filename: w.GPX.Filename, content: w.GPX.Content}}, nil
+	}
+
+	merged, err := w.mergedFiles(db)
+	if err != nil {
+		return nil, err
+	}
+
+	files := []recordedFile{w.original()}
+	for i := range merged {
+		files = append(files, merged[i].recordedFile())
+	}
+
+	return files, nil
+}
+
+func (w *Workout) mergedFiles(db *gorm.DB) ([]MergedFile, error) {
+	var merged []MergedFile
+
+	if w.GPX.ID == 0 {
+		return merged, nil
+	}
+
+	if err := db.Where(&MergedFile{GPXDataID: w.GPX.ID}).Order("id").Find(&merged).Error; err != nil {
+		return nil, err
+	}
+
+	return merged, nil
+}
+
+// deleteMergedFiles removes the files of the workouts that were merged into
+// the workout
+func (w *Workout) deleteMergedFiles(db *gorm.DB) error {
+	if w.ID == 0 {
+		return nil
+	}
+
+	return db.Unscoped().
+		Where("gpx_data_id IN (?)", db.Model(&GPXData{}).Select("id").Where(&GPXData{WorkoutID: w.ID})).
+		Delete(&MergedFile{}).Error
+}
+
+// setRecordedData sets the laps, the calories and the pool lengths that were
+// recorded by the device. When the track was derived from imported files,
+// they are taken from those files, for the time that is still in the track;
+// the calories of a file are counted in proportion to that time.
+func (w *Workout) setRecordedData(db *gorm.DB, data *MapData) error {
+	if !w.HasOriginal() {
+		return data.setRecordedData(w.GPX.Filename, w.GPX.Content)
+	}
+
+	if data.Details == nil || len(data.Details.Points) == 0 {
+		return nil
+	}
+
+	files, err := w.recordedFiles(db)
+	if err != nil {
+		return err
+	}
+
+	points := data.Details.Points
+	trackStart, trackEnd := points[0].Time, points[len(points)-1].Time.Add(time.Nanosecond)
+
+	for _, f := range files {
+		recorded := &MapData{}
+		if err := recorded.setRecordedData(f.filename, f.content); err != nil {
+			return err
+		}
+
+		kept := f
+		kept.start, kept.end = &trackStart, &trackEnd
+
+		if f.start != nil && f.start.After(trackStart) {
+			kept.start = f.start
+		}
+
+		if f.end != nil && f.end.Before(trackEnd) {
+			kept.end = f.end
+		}
+
+		for _, l := range recorded.Laps {
+			if kept.contains(l.Start.Add(l.Stop.Sub(l.Start) / 2)) {
+				data.Laps = append(data.Laps, l)
+			}
+		}
+
+		for _, l := range recorded.Lengths {
+			if kept.contains(l.Start) {
+				data.Lengths = append(data.Lengths, l)
+			}
+		}
+
+		if recorded.PoolLength > 0 {
+			data.PoolLength, data.PoolLengthYards = recorded.PoolLength, recorded.PoolLengthYards
+		}
+
+		if recorded.Calories > 0 {
+			share, err := f.share(*kept.start, *kept.end)
+			if err != nil {
+				return err
+			}
+
+			data.Calories += recorded.Calories * share
+			data.CaloriesSource = CaloriesSourceDevice
+		}
+	}
+
+	for i := range data.Laps {
+		data.Laps[i].Number = i + 1
+	}
+
+	for i := range data.Lengths {
+		data.Lengths[i].Number = i + 1
+	}
+
+	return nil
+}
+
+// Trim removes the points before the start and the points after the end; the
+// end is counted back from the last point
+func (w *Workout) Trim(db *gorm.DB, start, end TrackOffset) error {
+	if err := start.Validate(); err != nil {
+		return err
+	}
+
+	if err := end.Validate(); err != nil {
+		return err
+	}
+
+	g, err := w.editableGPX()
+	if err != nil {
+		return err
+	}
+
+	points := trackPoints(g)
+	if len(points) == 0 {
+		return ErrNoPointsLeft
+	}
+
+	last := points[len(points)-1].position
+
+	var kept []trackPoint
+
+	for _, p := range points {
+		if start.reached(p.position) && end.reached(last.sub(p.position)) {
+			kept = append(kept, p)
+		}
+	}
+
+	if len(kept) < 2 {
+		return ErrNoPointsLeft
+	}
+
+	if err := w.setDerivedGPX(derivedGPX(g, kept)); err != nil {
+		return err
+	}
+
+	return w.saveTrack(db)
+}
+
+// Split splits the workout in two at the offset: the workout keeps the points
+// before it, a new workout gets the point at the offset and the points after
+// it; the new workout is returned
+func (w *Workout) Split(db *gorm.DB, at TrackOffset) (*Workout, error) {
+	if at.IsZero() {
+		return nil, ErrInvalidTrackOffset
+	}
+
+	if err := at.Validate(); err != nil {
+		return nil, err
+	}
+
+	g, err := w.editableGPX()
+	if err != nil {
+		return nil, err
+	}
+
+	points := trackPoints(g)
+
+	i := slices.IndexFunc(points, func(p trackPoint) bool { return at.reached(p.position) })
+	if i < 2 || len(points)-i < 2 {
+		return nil, ErrNoPointsLeft
+	}
+
+	var second *Workout
+
+	// Both workouts keep the imported file, for their part of it
+	splitTime := points[i].point.Timestamp
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if second, err = w.splitOff(tx, g, points[i:], splitTime); err != nil {
+			return err
+		}
+
+		if err := w.setDerivedGPX(derivedGPX(g, points[:i])); err != nil {
+			return err
+		}
+
+		w.GPX.OriginalEnd = &splitTime
+
+		return w.saveTrack(tx)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return second, nil
+}
+
+// splitOff creates a workout with the same settings as the workout, for the
+// points; its part of the imported file starts at the time
+func (w *Workout) splitOff(db *gorm.DB, g *gpx.GPX, points []trackPoint, start time.Time) (*Workout, error) {
+	part := &Workout{
+		UserID:          w.UserID,
+		Name:            w.Name + " (2)",
+		Notes:           w.Notes,
+		Type:            w.Type,
+		Visibility:      w.Visibility,
+		ElevationSource: w.ElevationSource,
+		Data:            &MapData{},
+		GPX: &GPXData{
+			OriginalContent:  w.GPX.Content,
+			OriginalFilename: w.GPX.Filename,
+		},
+	}
+
+	if w.HasOriginal() {
+		part.GPX.OriginalContent = w.GPX.OriginalContent
+		part.GPX.OriginalFilename = w.GPX.OriginalFilename
+		part.GPX.OriginalEnd = w.GPX.OriginalEnd
+	}
+
+	part.GPX.OriginalStart = &start
+
+	if err := part.setDerivedGPX(derivedGPX(g, points)); err != nil {
+		return nil, err
+	}
+
+	if err := part.Create(db); err != nil {
+		return nil, err
+	}
+
+	if err := db.Model(part).Association("Equipment").Replace(w.Equipment); err != nil {
+		return nil, err
+	}
+
+	if err := part.UpdateData(db); err != nil {
+		return nil, err
+	}
+
+	return part, nil
+}
+
+// Merge merges the other workouts of the same user into the workout: the
+// points of all workouts are combined, in order of time, and the other
+// workouts are deleted; the equipment of all workouts is kept, and so are
+// their imported files, to restore them
+func (w *Workout) Merge(db *gorm.DB, others []*Workout) error {
+	others = slices.DeleteFunc(slices.Clone(others), func(o *Workout) bool { return o.ID == w.ID })
+	if len(others) == 0 {
+		return ErrNothingToMerge
+	}
+
+	g, err := w.editableGPX()
+	if err != nil {
+		return err
+	}
+
+	tracks := []*gpx.GPX{g}
+	equipment := slices.Clone(w.Equipment)
+
+	for _, o := range others {
+		if o.UserID != w.UserID {
+			return ErrMergeOtherUser
+		}
+
+		og, err := o.editableGPX()
+		if err != nil {
+			return err
+		}
+
+		tracks = append(tracks, og)
+		equipment = append(equipment, o.Equipment...)
+	}
+
+	var points []trackPoint
+
+	for _, t := range tracks {
+		segments := 0
+		if len(points) > 0 {
+			segments = points[len(points)-1].segment + 1
+		}
+
+		for _, p := range trackPoints(t) {
+			p.segment += segments
+			points = append(points, p)
+		}
+	}
+
+	// Sort the points by time, keeping the order of points recorded at the
+	// same time
+	slices.SortStableFunc(points, func(a, b trackPoint) int {
+		return a.point.Timestamp.Compare(b.point.Timestamp)
+	})
+
+	if len(points) < 2 {
+		return ErrNoPointsLeft
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := w.setDerivedGPX(derivedGPX(g, points)); err != nil {
+			return err
+		}
+
+		for _, o := range others {
+			if err := w.keepMergedFiles(tx, o); err != nil {
+				return err
+			}
+
+			if err := o.Delete(tx); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Model(w).Association("Equipment").Replace(uniqueEquipment(equipment)); err != nil {
+			return err
+		}
+
+		return w.saveTrack(tx)
+	})
+}
+
+// keepMergedFiles keeps the imported file of the other workout, and the files
+// that were merged into it, with the workout; when both were split from the
+// same imported file, the workout's part of it grows to include the other's
+func (w *Workout) keepMergedFiles(db *gorm.DB, o *Workout) error {
+	if err := db.Model(&MergedFile{}).
+		Where(&MergedFile{GPXDataID: o.GPX.ID}).
+		Update("gpx_data_id", w.GPX.ID).Error; err != nil {
+		return err
+	}
+
+	if o.HasOriginal() && bytes.Equal(o.GPX.OriginalContent, w.GPX.OriginalContent) {
+		w.GPX.OriginalStart = earliest(w.GPX.OriginalStart, o.GPX.OriginalStart)
+		w.GPX.OriginalEnd = latest(w.GPX.OriginalEnd, o.GPX.OriginalEnd)
+
+		return nil
+	}
+
+	f := MergedFile{
+		GPXDataID:  w.GPX.ID,
+		Name:       o.Name,
+		Notes:      o.Notes,
+		Type:       o.Type,
+		Visibility: o.Visibility,
+		Filename:   o.GPX.Filename,
+		Content:    o.GPX.Content,
+	}
+
+	if o.HasOriginal() {
+		f.Filename, f.Content = o.GPX.OriginalFilename, o.GPX.OriginalContent
+		f.Start, f.End = o.GPX.OriginalStart, o.GPX.OriginalEnd
+	}
+
+	return db.Create(&f).Error
+}
+
+// earliest returns the earliest of the times, where empty is the earliest
+func earliest(a, b *time.Time) *time.Time {
+	if a == nil || b == nil {
+		return nil
+	}
+
+	if b.Before(*a) {
+		return b
+	}
+
+	return a
+}
+
+// latest returns the latest of the times, where empty is the latest
+func latest(a, b *time.Time) *time.Time {
+	if a == nil || b == nil {
+		return nil
+	}
+
+	if b.After(*a) {
+		return b
+	}
+
+	return a
+}
+
+// MergeCandidates returns the other workouts of the owner around the workout,
+// that can be merged into it
+func (w *Workout) MergeCandidates(db *gorm.DB) ([]*Workout, error) {
+	if w.Date == nil {
+		return nil, nil
+	}
+
+	var candidates []*Workout
+
+	q := db.Preload("Data").Preload("GPX").
+		Where(&Workout{UserID: w.UserID}).
+		Where("id <> ? AND parent_id IS NULL", w.ID).
+		Where("date BETWEEN ? AND ?", w.Date.Add(-mergeWindow), w.Date.Add(mergeWindow)).
+		Order("date")
+
+	if err := q.Find(&candidates).Error; err != nil {
+		return nil, err
+	}
+
+	return slices.DeleteFunc(candidates, func(c *Workout) bool { return !c.CanEditTrack() }), nil
+}
+
+func uniqueEquipment(equipment []Equipment) []Equipment {
+	var r []Equipment
+
+	for _, e := range equipment {
+		if !slices.ContainsFunc(r, func(o Equipment) bool { return o.ID == e.ID }) {
+			r = append(r, e)
+		}
+	}
+
+	return r
+}
+
+// RestoreOriginal replaces the derived file of the workout with the imported
+// file, and recalculates the workout's data; a workout that was split off
+// gets its part of the imported file back. The workouts that were merged into
+// the workout are created again.
+func (w *Workout) RestoreOriginal(db *gorm.DB) error {
+	if !w.HasOriginal() {
+		return ErrNoOriginal
+	}
+
+	merged, err := w.mergedFiles(db)
+	if err != nil {
+		return err
+	}
+
+	original := w.original()
+
+	g, err := original.gpx()
+	if err != nil {
+		return err
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := w.restoreOriginal(tx, original, g); err != nil {
+			return err
+		}
+
+		for i := range merged {
+			if err := w.restoreMergedFile(tx, &merged[i]); err != nil {
+				return err
+			}
+		}
+
+		return w.deleteMergedFiles(tx)
+	})
+}
+
+// restoreOriginal replaces the file of the workout with its part of the
+// imported file
+func (w *Workout) restoreOriginal(db *gorm.DB, original recordedFile, g *gpx.GPX) error {
+	if !original.isWhole() {
+		// The part keeps the imported file, for the data that was recorded in
+		// it
+		if err := w.setDerivedGPX(g); err != nil {
+			return err
+		}
+
+		return w.saveTrack(db)
+	}
+
+	w.GPX.Content = original.content
+	w.GPX.Filename = original.filename
+	w.GPX.Checksum = checksum(w.GPX.Content)
+	w.GPX.OriginalContent = nil
+	w.GPX.OriginalFilename = ""
+	w.Date = gpxDate(g)
+
+	return w.saveTrack(db)
+}
+
+// restoreMergedFile creates the workout that was merged into the workout again
+// from its file
+func (w *Workout) restoreMergedFile(db *gorm.DB, m *MergedFile) error {
+	f := m.recordedFile()
+
+	g, err := f.gpx()
+	if err != nil {
+		return err
+	}
+
+	part := &Workout{
+		UserID:          w.UserID,
+		Name:            m.Name,
+		Notes:           m.Notes,
+		Type:            m.Type,
+		Visibility:      m.Visibility,
+		ElevationSource: defaultElevationSource(g.Creator),
+		Date:            gpxDate(g),
+		Data:            &MapData{},
+		GPX: &GPXData{
+			Content:       m.Content,
+			Checksum:      checksum(m.Content),
+			Filename:      m.Filename,
+			OriginalStart: m.Start,
+			OriginalEnd:   m.End,
+		},
+	}
+
+	if !f.isWhole() {
+		if err := part.setDerivedGPX(g); err != nil {
+			return err
+		}
+	}
+
+	if err := part.Create(db); err != nil {
+		return err
+	}
+
+	return part.UpdateData(db)
+}
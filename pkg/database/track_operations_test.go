@@ -0,0 +1,202 @@
+package database
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tkrajina/gpxgo/gpx"
+	"gorm.io/gorm"
+)
+
+// addTrack adds a workout with a track going north at 3 m/s, with a point
+// every second, starting later by the delay
+func addTrack(t *testing.T, db *gorm.DB, u *User, n int, delay time.Duration) *Workout {
+	t.Helper()
+
+	g := straightTrack(n, 3, nil)
+	for i := range g.Tracks[0].Segments[0].Points {
+		g.Tracks[0].Segments[0].Points[i].Timestamp = g.Tracks[0].Segments[0].Points[i].Timestamp.Add(delay)
+	}
+
+	content, err := g.ToXml(gpx.ToXmlParams{Version: "1.1"})
+	require.NoError(t, err)
+
+	w, err := u.AddWorkout(db, WorkoutTypeRunning, "", "run.gpx", content)
+	require.NoError(t, err)
+
+	return w
+}
+
+func TestWorkout_TrimAndRestore(t *testing.T) {
+	db := createMemoryDB(t)
+
+	u := defaultUser()
+	require.NoError(t, u.Create(db))
+
+	w := addTrack(t, db, u, 101, 0)
+	start := *w.Date
+
+	require.NoError(t, w.Trim(db, TrackOffset{Duration: 10 * time.Second}, TrackOffset{Distance: 28.5}))
+
+	w, err := GetWorkoutDetails(db, int(w.ID))
+	require.NoError(t, err)
+
+	assert.True(t, w.HasOriginal())
+	assert.Equal(t, "run.gpx", w.GPX.Filename)
+	assert.Equal(t, start.Add(10*time.Second), w.Date.UTC())
+	assert.Len(t, w.Data.Details.Points, 81)
+	assert.Equal(t, 80*time.Second, w.Data.TotalDuration)
+	assert.InDelta(t, 240, w.Data.TotalDistance, 1)
+
+	// Trimming again keeps the imported file
+	require.NoError(t, w.Trim(db, TrackOffset{Duration: 10 * time.Second}, TrackOffset{}))
+	assert.Len(t, w.Data.Details.Points, 71)
+
+	require.NoError(t, w.RestoreOriginal(db))
+
+	w, err = GetWorkoutDetails(db, int(w.ID))
+	require.NoError(t, err)
+
+	assert.False(t, w.HasOriginal())
+	assert.Equal(t, start, w.Date.UTC())
+	assert.Len(t, w.Data.Details.Points, 101)
+	assert.InDelta(t, 300, w.Data.TotalDistance, 1)
+
+	require.ErrorIs(t, w.RestoreOriginal(db), ErrNoOriginal)
+	require.ErrorIs(t, w.Trim(db, TrackOffset{Duration: time.Hour}, TrackOffset{}), ErrNoPointsLeft)
+}
+
+func TestWorkout_TrimKeepsRecordedData(t *testing.T) {
+	db := createMemoryDB(t)
+
+	u := defaultUser()
+	require.NoError(t, u.Create(db))
+
+	content := strings.ReplaceAll(tcxTwoLaps, "</DistanceMeters>", "</DistanceMeters><Calories>45</Calories>")
+
+	w, err := u.AddWorkout(db, WorkoutTypeRunning, "", "laps.tcx", []byte(content))
+	require.NoError(t, err)
+	require.Len(t, w.Data.Laps, 2)
+	require.InDelta(t, 90, w.Data.Calories, 0.1)
+
+	// Trimming the last minute drops the second lap, and its share of the
+	// calories
+	require.NoError(t, w.Trim(db, TrackOffset{}, TrackOffset{Duration: time.Minute}))
+
+	w, err = GetWorkoutDetails(db.Preload("Data.Laps"), int(w.ID))
+	require.NoError(t, err)
+
+	assert.Equal(t, "laps.gpx", w.GPX.Filename)
+	require.Len(t, w.Data.Laps, 1)
+	assert.InDelta(t, 400, w.Data.Laps[0].Distance, 0.1)
+	assert.InDelta(t, 60, w.Data.Calories, 0.1)
+	assert.Equal(t, CaloriesSourceDevice, w.Data.CaloriesSource)
+}
+
+func TestWorkout_Split(t *testing.T) {
+	db := createMemoryDB(t)
+
+	u := defaultUser()
+	require.NoError(t, u.Create(db))
+
+	w := addTrack(t, db, u, 101, 0)
+	start := *w.Date
+
+	second, err := w.Split(db, TrackOffset{Distance: 148.5})
+	require.NoError(t, err)
+
+	w, err = GetWorkoutDetails(db, int(w.ID))
+	require.NoError(t, err)
+
+	second, err = GetWorkoutDetails(db, int(second.ID))
+	require.NoError(t, err)
+
+	assert.Len(t, w.Data.Details.Points, 50)
+	assert.Len(t, second.Data.Details.Points, 51)
+	assert.Equal(t, w.Name+" (2)", second.Name)
+	assert.Equal(t, WorkoutTypeRunning, second.Type)
+	assert.Equal(t, start.Add(50*time.Second), second.Date.UTC())
+	assert.True(t, second.HasOriginal())
+	assert.InDelta(t, 150, second.Data.TotalDistance, 1)
+
+	// Restoring a part only restores its part of the imported file
+	require.NoError(t, second.Trim(db, TrackOffset{Duration: 10 * time.Second}, TrackOffset{}))
+	require.NoError(t, second.RestoreOriginal(db))
+	assert.Len(t, second.Data.Details.Points, 51)
+	assert.Equal(t, start.Add(50*time.Second), second.Date.UTC())
+
+	require.NoError(t, w.RestoreOriginal(db))
+	assert.Len(t, w.Data.Details.Points, 50)
+
+	// Merging the parts again makes the whole imported file theirs
+	require.NoError(t, w.Merge(db, []*Workout{second}))
+	require.NoError(t, w.RestoreOriginal(db))
+	assert.False(t, w.HasOriginal())
+	assert.Len(t, w.Data.Details.Points, 101)
+
+	_, err = w.Split(db, TrackOffset{})
+	require.ErrorIs(t, err, ErrInvalidTrackOffset)
+
+	_, err = w.Split(db, TrackOffset{Duration: time.Hour})
+	require.ErrorIs(t, err, ErrNoPointsLeft)
+}
+
+func TestWorkout_Merge(t *testing.T) {
+	db := createMemoryDB(t)
+
+	u := defaultUser()
+	require.NoError(t, u.Create(db))
+
+	later := addTrack(t, db, u, 101, 10*time.Minute)
+	first := addTrack(t, db, u, 101, 0)
+	laterStart := *later.Date
+
+	candidates, err := later.MergeCandidates(db)
+	require.NoError(t, err)
+	require.Len(t, candidates, 1)
+	assert.Equal(t, first.ID, candidates[0].ID)
+
+	require.ErrorIs(t, later.Merge(db, nil), ErrNothingToMerge)
+	require.NoError(t, later.Merge(db, []*Workout{first}))
+
+	var count int64
+	require.NoError(t, db.Model(&Workout{}).Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+
+	w, err := GetWorkoutDetails(db, int(later.ID))
+	require.NoError(t, err)
+
+	assert.Equal(t, first.Date.UTC(), w.Date.UTC())
+	assert.Len(t, w.Data.Details.Points, 202)
+	assert.InDelta(t, 600, w.Data.TotalDistance, 1)
+
+	// Restoring the original file undoes the merge
+	require.NoError(t, w.RestoreOriginal(db))
+	assert.Len(t, w.Data.Details.Points, 101)
+	assert.Equal(t, laterStart.UTC(), w.Date.UTC())
+
+	workouts, err := u.GetWorkouts(db)
+	require.NoError(t, err)
+	require.Len(t, workouts, 2)
+	assert.Equal(t, first.Date.UTC(), workouts[1].Date.UTC())
+	assert.InDelta(t, 300, workouts[1].Data.TotalDistance, 1)
+
+	restored, err := GetWorkoutDetails(db, int(workouts[1].ID))
+	require.NoError(t, err)
+
+	require.NoError(t, w.Merge(db, []*Workout{restored}))
+	require.NoError(t, db.Model(&MergedFile{}).Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+
+	require.NoError(t, w.Delete(db))
+	require.NoError(t, db.Model(&MergedFile{}).Count(&count).Error)
+	assert.Zero(t, count)
+
+	other := &User{Username: "other-user", Password: "other-password", Name: "other"}
+	require.NoError(t, other.Create(db))
+
+	require.ErrorIs(t, w.Merge(db, []*Workout{addTrack(t, db, other, 11, time.Hour)}), ErrMergeOtherUser)
+}
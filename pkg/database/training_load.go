@@ -0,0 +1,121 @@
+package database
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// chronicLoadWeeks is the number of weeks the chronic training load is
+// averaged over
+const chronicLoadWeeks = 4
+
+// WeeklyTrainingLoad is the training load of a single week, compared to the
+// load of the weeks before it
+type WeeklyTrainingLoad struct {
+	Week    time.Time // The first day (Monday) of the week
+	Acute   float64   // The total training load of the week
+	Chronic float64   // The average weekly training load of the last four weeks
+	Ratio   float64   // The acute:chronic workload ratio; 0 when there is no chronic load
+}
+
+// GetTrainingLoad returns the weekly training load of the user since the given
+// time, up to and including the current week
+func (u *User) GetTrainingLoad(since time.Time) ([]WeeklyTrainingLoad, error) {
+	first := startOfWeek(since)
+	start := first.AddDate(0, 0, -7*(chronicLoadWeeks-1))
+
+	var rows []struct {
+		Date         time.Time
+		TrainingLoad float64
+	}
+
+	err := u.db.
+		Table("workouts").
+		Select("workouts.date as date", "map_data.training_load as training_load").
+		Joins("join map_data on workouts.id = map_data.workout_id").
+		Where("user_id = ?", u.ID).
+		Where("workouts.date >= ?", start).
+		Where("map_data.training_load > 0").
+		Where("workouts.type <> ?", WorkoutTypeMultisport).
+		Scopes(countedInStatistics).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	load := map[time.Time]float64{}
+	for _, r := range rows {
+		load[startOfWeek(r.Date)] += r.TrainingLoad
+	}
+
+	last := startOfWeek(time.Now())
+	weeks := []WeeklyTrainingLoad{}
+
+	for week := first; !week.After(last); week = week.AddDate(0, 0, 7) {
+		wl := WeeklyTrainingLoad{
+			Week:  week,
+			Acute: load[week],
+		}
+
+		for i := range chronicLoadWeeks {
+			wl.Chronic += load[week.AddDate(0, 0, -7*i)]
+		}
+
+		wl.Chronic /= chronicLoadWeeks
+
+		if wl.Chronic > 0 {
+			wl.Ratio = wl.Acute / wl.Chronic
+		}
+
+		weeks = append(weeks, wl)
+	}
+
+	return weeks, nil
+}
+
+// startOfWeek returns midnight (UTC) of the Monday of the week of t
+func startOfWeek(t time.Time) time.Time {
+	t = t.UTC()
+	offset := (int(t.Weekday()) + 6) % 7
+
+	return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, time.UTC)
+}
+
+// SinceTime returns the start of the time range, relative to now; unknown
+// values fall back to one year
+func (sc *StatConfig) SinceTime(now time.Time) time.Time {
+	fields := strings.Fields(sc.GetSince())
+	if len(fields) != 2 {
+		return now.AddDate(-1, 0, 0)
+	}
+
+	n, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return now.AddDate(-1, 0, 0)
+	}
+
+	switch strings.TrimSuffix(fields[1], "s") {
+	case "day":
+		return now.AddDate(0, 0, -n)
+	case "week":
+		return now.AddDate(0, 0, -7*n)
+	case "month":
+		return now.AddDate(0, -n, 0)
+	case "year":
+		return now.AddDate(-n, 0, 0)
+	default:
+		return now.AddDate(-1, 0, 0)
+	}
+}
+
+// HasTrainingLoad returns whether there is any training load in the statistics
+func (s *Statistics) HasTrainingLoad() bool {
+	for _, w := range s.TrainingLoad {
+		if w.Acute > 0 {
+			return true
+		}
+	}
+
+	return false
+}
@@ -38,9 +38,11 @@ type User struct {
 	Active   bool   `form:"active"`                                                // Whether the user is active
 	Admin    bool   `form:"admin"`                                                 // Whether the user is an admin
 
-	Profile   Profile     // The user's profile settings
-	Workouts  []Workout   `json:"-"` // The user's workouts
-	Equipment []Equipment `json:"-"` // The user's equipment
+	Profile      Profile       // The user's profile settings
+	Workouts     []Workout     `json:"-"` // The user's workouts
+	Equipment    []Equipment   `json:"-"` // The user's equipment
+	PrivacyZones []PrivacyZone `json:"-"` // The user's privacy zones, hidden from shared workouts
+	Goals        []Goal        `json:"-"` // The user's goals
 
 	db *gorm.DB
 }
@@ -243,9 +245,13 @@ func (u *User) Delete(db *gorm.DB) error {
 func (u *User) GetWorkout(db *gorm.DB, id int) (*Workout, error) {
 	var w *Workout
 
-	db = db.Preload("Data").Preload("Data.Details").Preload("GPX").Preload("Equipment")
+	q := db.Preload("Data").Preload("Data.Details").Preload("GPX").Preload("Equipment")
 
-	if err := db.Where(&Workout{UserID: u.ID}).First(&w, id).Error; err != nil {
+	if err := q.Where(&Workout{UserID: u.ID}).First(&w, id).Error; err != nil {
+		return nil, err
+	}
+
+	if err := w.LoadExercises(db.Session(&gorm.Session{NewDB: true})); err != nil {
 		return nil, err
 	}
 
@@ -271,6 +277,10 @@ func (u *User) AddWorkout(db *gorm.DB, workoutType WorkoutType, notes string, fi
 		return nil, ErrNoUser
 	}
 
+	if workoutType != WorkoutTypeAutoDetect && !u.CanUseWorkoutType(workoutType) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidWorkoutType, workoutType)
+	}
+
 	w, err := NewWorkout(u, workoutType, notes, filename, content)
 	if err != nil {
 		return nil, fmt.Errorf("%w: %s", ErrInvalidData, err)
@@ -280,6 +290,28 @@ func (u *User) AddWorkout(db *gorm.DB, workoutType WorkoutType, notes string, fi
 		return nil, err
 	}
 
+	if err := w.MatchSegments(db); err != nil {
+		return nil, err
+	}
+
+	if err := u.setDefaultEquipment(db, w); err != nil {
+		return nil, err
+	}
+
+	if err := w.createLegs(db); err != nil {
+		return nil, err
+	}
+
+	if err := w.markDuplicate(db); err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+// setDefaultEquipment links the user's default equipment for the type of the
+// workout to the workout
+func (u *User) setDefaultEquipment(db *gorm.DB, w *Workout) error {
 	var equipment []*Equipment
 
 	for i, e := range u.Equipment {
@@ -288,17 +320,13 @@ func (u *User) AddWorkout(db *gorm.DB, workoutType WorkoutType, notes string, fi
 		}
 	}
 
-	if err := db.Model(&w).Association("Equipment").Replace(equipment); err != nil {
-		return nil, err
-	}
-
-	return w, nil
+	return db.Model(w).Association("Equipment").Replace(equipment)
 }
 
 func (u *User) GetAllEquipment(db *gorm.DB) ([]*Equipment, error) {
 	var w []*Equipment
 
-	if err := db.Preload("Workouts").Where(&Equipment{UserID: u.ID}).Order("name DESC").Find(&w).Error; err != nil {
+	if err := preloadMaintenance(db).Where(&Equipment{UserID: u.ID}).Order("name DESC").Find(&w).Error; err != nil {
 		return nil, err
 	}
 
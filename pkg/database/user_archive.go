@@ -0,0 +1,789 @@
+package database
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	ArchiveVersion      = 2               // The version of the archive format; older versions can still be imported
+	ArchiveManifestName = "manifest.json" // The name of the manifest in the archive
+	archiveFilesDir     = "files"         // The directory in the archive with the original files
+)
+
+var (
+	ErrArchiveNoManifest      = errors.New("archive has no manifest")
+	ErrArchiveVersion         = errors.New("unsupported archive version")
+	ErrArchiveFileNotFound    = errors.New("file not found in archive")
+	ErrArchiveInvalidWorkouts = errors.New("archive contains invalid workouts")
+)
+
+// ArchiveManifest describes all data of a user in an account archive; the
+// original files of the workouts are stored next to the manifest
+type ArchiveManifest struct {
+	Version             int                  // The version of the archive format
+	CreatedAt           time.Time            // When the archive was created
+	Username            string               // The username of the exported user
+	Name                string               // The name of the exported user
+	Profile             Profile              // The user's profile settings
+	Equipment           []Equipment          // The user's equipment, with its maintenance items and services
+	PrivacyZones        []PrivacyZone        // The user's privacy zones
+	WorkoutTypes        []CustomWorkoutType  `json:",omitempty"` // The user's personal workout types
+	WorkoutTypeMappings []WorkoutTypeMapping `json:",omitempty"` // The user's personal sport name mappings
+	Exercises           []Exercise           `json:",omitempty"` // The user's exercises
+	Goals               []Goal               `json:",omitempty"` // The user's goals
+	Measurements        []Measurement        `json:",omitempty"` // The user's body measurements
+	Workouts            []ArchiveWorkout     // The user's workouts
+}
+
+// ArchiveWorkout is a workout in an account archive
+type ArchiveWorkout struct {
+	ID         uint              // The ID of the workout in the exported instance
+	Name       string            // The name of the workout
+	Date       *time.Time        // The timestamp the workout was recorded
+	Type       WorkoutType       // The type of the workout
+	Notes      string            // The notes associated with the workout, in markdown
+	Visibility WorkoutVisibility // Who can see the workout
+	Equipment  []uint            // The IDs of the equipment (in the archive) used for this workout
+	File       string            `json:",omitempty"` // The path of the original file in the archive, if any
+	Data       *MapData          `json:",omitempty"` // The summary of a workout without file
+	Exercises  []WorkoutExercise `json:",omitempty"` // The exercises and their sets; the exercise IDs are those in the archive
+
+	ExcludeFromStatistics bool `json:",omitempty"` // Whether the workout is left out of the statistics
+
+	Original      string              `json:",omitempty"` // The path of the imported file the file was derived from, by trimming, splitting or merging
+	OriginalStart *time.Time          `json:",omitempty"` // The start of the part of the imported file the workout was split from
+	OriginalEnd   *time.Time          `json:",omitempty"` // The end of that part, exclusive
+	Merged        []ArchiveMergedFile `json:",omitempty"` // The imported files of the workouts that were merged into the workout
+}
+
+// ArchiveMergedFile is the imported file of a workout that was merged into an
+// archived workout, to restore it
+type ArchiveMergedFile struct {
+	Name       string            // The name of the merged workout
+	Notes      string            // The notes of the merged workout
+	Type       WorkoutType       // The type of the merged workout
+	Visibility WorkoutVisibility // The visibility of the merged workout
+	File       string            // The path of the imported file in the archive
+	Start      *time.Time        `json:",omitempty"` // The start of the part of the file that belonged to the merged workout
+	End        *time.Time        `json:",omitempty"` // The end of that part, exclusive
+}
+
+// ArchiveImportResult summarizes the import of an account archive
+type ArchiveImportResult struct {
+	Workouts   int      // The number of workouts that were imported
+	Equipment  int      // The number of equipment that was created
+	Duplicates []string // The names of the workouts that were skipped, because they already exist
+}
+
+// ExportArchive writes a zip archive with all data of the user to w
+func (u *User) ExportArchive(db *gorm.DB, w io.Writer) error {
+	m := ArchiveManifest{
+		Version:   ArchiveVersion,
+		CreatedAt: time.Now(),
+		Username:  u.Username,
+		Name:      u.Name,
+		Profile:   u.Profile,
+	}
+
+	if err := db.
+		Preload("MaintenanceItems", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Preload("Services", func(db *gorm.DB) *gorm.DB { return db.Order("date") }).
+		Where(&Equipment{UserID: u.ID}).Order("id").Find(&m.Equipment).Error; err != nil {
+		return err
+	}
+
+	if err := u.exportArchiveSettings(db, &m); err != nil {
+		return err
+	}
+
+	// The legs of multisport workouts are not archived; they are imported
+	// again from the file of their workout
+	var workouts []*Workout
+	if err := db.Preload("Data").Preload("GPX").Preload("Equipment").
+		Preload("Exercises", func(db *gorm.DB) *gorm.DB { return db.Order("position") }).
+		Preload("Exercises.Sets", func(db *gorm.DB) *gorm.DB { return db.Order("number") }).
+		Where(&Workout{UserID: u.ID}).Where("parent_id IS NULL").Order("date").Find(&workouts).Error; err != nil {
+		return err
+	}
+
+	z := zip.NewWriter(w)
+
+	for _, wo := range workouts {
+		aw := ArchiveWorkout{
+			ID:         wo.ID,
+			Name:       wo.Name,
+			Date:       wo.Date,
+			Type:       wo.Type,
+			Notes:      wo.Notes,
+			Visibility: wo.Visibility,
+			Equipment:  wo.EquipmentIDs(),
+			Exercises:  wo.Exercises,
+
+			ExcludeFromStatistics: wo.ExcludeFromStatistics,
+		}
+
+		if !wo.HasFile() {
+			aw.Data = wo.Data
+			m.Workouts = append(m.Workouts, aw)
+
+			continue
+		}
+
+		if err := aw.exportFiles(db, z, wo); err != nil {
+			return err
+		}
+
+		m.Workouts = append(m.Workouts, aw)
+	}
+
+	f, err := z.Create(ArchiveManifestName)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+
+	if err := enc.Encode(m); err != nil {
+		return err
+	}
+
+	return z.Close()
+}
+
+// exportArchiveSettings adds the privacy zones, personal workout types and
+// mappings, exercises, goals and measurements of the user to the manifest
+func (u *User) exportArchiveSettings(db *gorm.DB, m *ArchiveManifest) error {
+	var err error
+
+	if m.PrivacyZones, err = u.GetPrivacyZones(db); err != nil {
+		return err
+	}
+
+	if m.WorkoutTypes, err = u.GetCustomWorkoutTypes(db); err != nil {
+		return err
+	}
+
+	if m.WorkoutTypeMappings, err = u.GetWorkoutTypeMappings(db); err != nil {
+		return err
+	}
+
+	if m.Exercises, err = u.GetExercises(db); err != nil {
+		return err
+	}
+
+	if m.Goals, err = u.GetGoals(db); err != nil {
+		return err
+	}
+
+	if m.Measurements, err = u.GetMeasurements(db); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// exportFiles writes the file of the workout to the archive, and the imported
+// files it was derived from
+func (aw *ArchiveWorkout) exportFiles(db *gorm.DB, z *zip.Writer, wo *Workout) error {
+	dir := path.Join(archiveFilesDir, strconv.FormatUint(uint64(wo.ID), 10))
+
+	aw.File = path.Join(dir, path.Base(wo.GPX.Filename))
+	if err := writeArchiveFile(z, aw.File, wo.GPX.Content); err != nil {
+		return err
+	}
+
+	if !wo.HasOriginal() {
+		return nil
+	}
+
+	aw.Original = path.Join(dir, "original", path.Base(wo.GPX.OriginalFilename))
+	aw.OriginalStart = wo.GPX.OriginalStart
+	aw.OriginalEnd = wo.GPX.OriginalEnd
+
+	if err := writeArchiveFile(z, aw.Original, wo.GPX.OriginalContent); err != nil {
+		return err
+	}
+
+	merged, err := wo.mergedFiles(db)
+	if err != nil {
+		return err
+	}
+
+	for i, f := range merged {
+		am := ArchiveMergedFile{
+			Name:       f.Name,
+			Notes:      f.Notes,
+			Type:       f.Type,
+			Visibility: f.Visibility,
+			File:       path.Join(dir, "merged", strconv.Itoa(i+1), path.Base(f.Filename)),
+			Start:      f.Start,
+			End:        f.End,
+		}
+
+		if err := writeArchiveFile(z, am.File, f.Content); err != nil {
+			return err
+		}
+
+		aw.Merged = append(aw.Merged, am)
+	}
+
+	return nil
+}
+
+func writeArchiveFile(z *zip.Writer, name string, content []byte) error {
+	f, err := z.Create(name)
+	if err != nil {
+		return err
+	}
+
+	_, err = f.Write(content)
+
+	return err
+}
+
+// ReadArchiveManifest reads the manifest of an account archive
+func ReadArchiveManifest(z *zip.Reader) (*ArchiveManifest, error) {
+	f, err := z.Open(ArchiveManifestName)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrArchiveNoManifest, err)
+	}
+	defer f.Close()
+
+	m := &ArchiveManifest{}
+	if err := json.NewDecoder(f).Decode(m); err != nil {
+		return nil, err
+	}
+
+	if m.Version < 1 || m.Version > ArchiveVersion {
+		return nil, fmt.Errorf("%w: %d", ErrArchiveVersion, m.Version)
+	}
+
+	return m, nil
+}
+
+// ImportArchive recreates the profile, privacy zones, workout types,
+// measurements, equipment, exercises, goals and workouts from an account
+// archive for the user; workouts that already exist are skipped and reported
+// as duplicates
+func (u *User) ImportArchive(db *gorm.DB, r io.ReaderAt, size int64) (*ArchiveImportResult, error) {
+	z, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := ReadArchiveManifest(z)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ArchiveImportResult{}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := u.importArchiveProfile(tx, m); err != nil {
+			return err
+		}
+
+		if err := u.importArchivePrivacyZones(tx, m); err != nil {
+			return err
+		}
+
+		if err := u.importArchiveWorkoutTypes(tx, m); err != nil {
+			return err
+		}
+
+		// The measurements come before the workouts, for their calories
+		if len(m.Measurements) > 0 {
+			if _, err := u.ImportMeasurements(tx, m.Measurements); err != nil {
+				return err
+			}
+		}
+
+		equipment, err := u.importArchiveEquipment(tx, m, result)
+		if err != nil {
+			return err
+		}
+
+		exercises, err := u.importArchiveExercises(tx, m)
+		if err != nil {
+			return err
+		}
+
+		if err := u.importArchiveGoals(tx, m); err != nil {
+			return err
+		}
+
+		for i := range m.Workouts {
+			if err := u.importArchiveWorkout(tx, z, &m.Workouts[i], equipment, exercises, result); err != nil {
+				return fmt.Errorf("%w: %q: %w", ErrArchiveInvalidWorkouts, m.Workouts[i].Name, err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		// The workout types that were added are gone again
+		return nil, errors.Join(err, LoadWorkoutTypes(db))
+	}
+
+	return result, nil
+}
+
+func (u *User) importArchiveProfile(db *gorm.DB, m *ArchiveManifest) error {
+	p := m.Profile
+	p.Model = u.Profile.Model
+	p.UserID = u.ID
+	p.User = nil
+
+	if err := db.Save(&p).Error; err != nil {
+		return err
+	}
+
+	u.Profile = p
+
+	return nil
+}
+
+func (u *User) importArchivePrivacyZones(db *gorm.DB, m *ArchiveManifest) error {
+	existing, err := u.GetPrivacyZones(db)
+	if err != nil {
+		return err
+	}
+
+	for _, z := range m.PrivacyZones {
+		if hasPrivacyZoneNamed(existing, z.Name) {
+			continue
+		}
+
+		z.Model = gorm.Model{}
+		z.UserID = u.ID
+		z.User = nil
+
+		if err := z.Save(db); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func hasPrivacyZoneNamed(zones []PrivacyZone, name string) bool {
+	for _, z := range zones {
+		if z.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// importArchiveWorkoutTypes creates the personal workout types and sport name
+// mappings of the archive; types the user can already use, and sport names
+// the user already mapped, are skipped
+func (u *User) importArchiveWorkoutTypes(db *gorm.DB, m *ArchiveManifest) error {
+	for _, t := range m.WorkoutTypes {
+		if u.CanUseWorkoutType(t.Name) {
+			continue
+		}
+
+		t.Model = gorm.Model{}
+		t.UserID = &u.ID
+		t.User = nil
+
+		if err := t.Save(db); err != nil {
+			return err
+		}
+	}
+
+	for _, tm := range m.WorkoutTypeMappings {
+		tm.Model = gorm.Model{}
+		tm.UserID = &u.ID
+		tm.User = nil
+
+		err := tm.Save(db)
+		switch {
+		case errors.Is(err, ErrMappingExists):
+			continue
+		case err != nil:
+			return err
+		}
+	}
+
+	return nil
+}
+
+// importArchiveExercises adds the exercises of the archive to the user's
+// exercises, and returns them by their ID in the archive; exercises with the
+// same name as existing exercises of the user are reused
+func (u *User) importArchiveExercises(db *gorm.DB, m *ArchiveManifest) (map[uint]*Exercise, error) {
+	exercises := map[uint]*Exercise{}
+
+	for _, ae := range m.Exercises {
+		e, err := u.GetOrCreateExercise(db, ae.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		if e.Notes == "" && ae.Notes != "" {
+			e.Notes = ae.Notes
+
+			if err := e.Save(db); err != nil {
+				return nil, err
+			}
+		}
+
+		exercises[ae.ID] = e
+	}
+
+	return exercises, nil
+}
+
+func (u *User) importArchiveGoals(db *gorm.DB, m *ArchiveManifest) error {
+	existing, err := u.GetGoals(db)
+	if err != nil {
+		return err
+	}
+
+	for _, g := range m.Goals {
+		if hasSameGoal(existing, &g) {
+			continue
+		}
+
+		g.Model = gorm.Model{}
+		g.UserID = u.ID
+		g.User = nil
+
+		if err := g.Save(db); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func hasSameGoal(goals []Goal, g *Goal) bool {
+	for _, e := range goals {
+		if e.Name == g.Name && e.Type == g.Type && e.Metric == g.Metric && e.Period == g.Period {
+			return true
+		}
+	}
+
+	return false
+}
+
+// importArchiveEquipment creates the equipment of the archive, with its
+// maintenance items and services, and returns the equipment by its ID in the
+// archive; equipment with the same name as existing equipment of the user is
+// reused as it is
+func (u *User) importArchiveEquipment(db *gorm.DB, m *ArchiveManifest, result *ArchiveImportResult) (map[uint]*Equipment, error) {
+	equipment := map[uint]*Equipment{}
+
+	for _, e := range m.Equipment {
+		archiveID := e.ID
+
+		existing := &Equipment{}
+
+		err := db.Where(&Equipment{Name: e.Name}).First(existing).Error
+		if err == nil && existing.UserID != u.ID {
+			// Equipment names are unique, and this one belongs to someone else;
+			// it may have been imported under the user's name before
+			e.Name += " (" + u.Username + ")"
+			existing = &Equipment{}
+			err = db.Where(&Equipment{Name: e.Name}).First(existing).Error
+		}
+
+		switch {
+		case err == nil && existing.UserID == u.ID:
+			equipment[archiveID] = existing
+
+			continue
+		case err != nil && !errors.Is(err, gorm.ErrRecordNotFound):
+			return nil, err
+		}
+
+		items, services := e.MaintenanceItems, e.Services
+
+		e.Model = gorm.Model{}
+		e.UserID = u.ID
+		e.Workouts = nil
+		e.MaintenanceItems = nil
+		e.Services = nil
+
+		if err := e.Save(db); err != nil {
+			return nil, err
+		}
+
+		if err := e.importArchiveMaintenance(db, items, services); err != nil {
+			return nil, err
+		}
+
+		equipment[archiveID] = &e
+		result.Equipment++
+	}
+
+	return equipment, nil
+}
+
+// importArchiveMaintenance creates the archived maintenance items and services
+// for the equipment; services keep the item they were done for
+func (e *Equipment) importArchiveMaintenance(db *gorm.DB, items []MaintenanceItem, services []EquipmentService) error {
+	itemIDs := map[uint]uint{}
+
+	for _, i := range items {
+		archiveID := i.ID
+
+		i.Model = gorm.Model{}
+		i.EquipmentID = e.ID
+		i.Equipment = nil
+
+		if err := i.Save(db); err != nil {
+			return err
+		}
+
+		itemIDs[archiveID] = i.ID
+	}
+
+	for _, s := range services {
+		s.Model = gorm.Model{}
+		s.EquipmentID = e.ID
+		s.Equipment = nil
+		s.MaintenanceItem = nil
+
+		if s.MaintenanceItemID != nil {
+			id, ok := itemIDs[*s.MaintenanceItemID]
+			if ok {
+				s.MaintenanceItemID = &id
+			} else {
+				s.MaintenanceItemID = nil
+			}
+		}
+
+		if err := s.Save(db); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (u *User) importArchiveWorkout(
+	db *gorm.DB, z *zip.Reader, aw *ArchiveWorkout,
+	equipment map[uint]*Equipment, exercises map[uint]*Exercise, result *ArchiveImportResult,
+) error {
+	w, err := aw.workout(u, z)
+	if err != nil {
+		return err
+	}
+
+	duplicate, err := u.isDuplicateWorkout(db, w)
+	if err != nil {
+		return err
+	}
+
+	if duplicate {
+		result.Duplicates = append(result.Duplicates, aw.Name)
+		return nil
+	}
+
+	if err := w.Create(db); err != nil {
+		return err
+	}
+
+	if err := aw.importOriginal(db, z, w); err != nil {
+		return err
+	}
+
+	if err := w.MatchSegments(db); err != nil {
+		return err
+	}
+
+	if err := w.createLegs(db); err != nil {
+		return err
+	}
+
+	var used []*Equipment
+
+	for _, id := range aw.Equipment {
+		if e, ok := equipment[id]; ok {
+			used = append(used, e)
+		}
+	}
+
+	if len(used) > 0 {
+		if err := db.Model(w).Association("Equipment").Replace(used); err != nil {
+			return err
+		}
+	}
+
+	if err := aw.importSets(db, w, exercises); err != nil {
+		return err
+	}
+
+	result.Workouts++
+
+	return nil
+}
+
+// importOriginal restores the imported files the file of the workout was
+// derived from, and updates the workout from them
+func (aw *ArchiveWorkout) importOriginal(db *gorm.DB, z *zip.Reader, w *Workout) error {
+	if aw.Original == "" || w.GPX == nil {
+		return nil
+	}
+
+	content, err := readArchiveFile(z, aw.Original)
+	if err != nil {
+		return err
+	}
+
+	w.GPX.OriginalContent = content
+	w.GPX.OriginalFilename = aw.Original
+	w.GPX.OriginalStart = aw.OriginalStart
+	w.GPX.OriginalEnd = aw.OriginalEnd
+
+	if err := db.Save(w.GPX).Error; err != nil {
+		return err
+	}
+
+	for _, am := range aw.Merged {
+		content, err := readArchiveFile(z, am.File)
+		if err != nil {
+			return err
+		}
+
+		f := MergedFile{
+			GPXDataID:  w.GPX.ID,
+			Name:       am.Name,
+			Notes:      am.Notes,
+			Type:       am.Type,
+			Visibility: am.Visibility,
+			Filename:   am.File,
+			Content:    content,
+			Start:      am.Start,
+			End:        am.End,
+		}
+
+		if err := db.Create(&f).Error; err != nil {
+			return err
+		}
+	}
+
+	return w.UpdateData(db)
+}
+
+// importSets adds the archived sets to the workout, for the exercises by
+// their ID in the archive
+func (aw *ArchiveWorkout) importSets(db *gorm.DB, w *Workout, exercises map[uint]*Exercise) error {
+	if !w.Type.IsRepetition() {
+		return nil
+	}
+
+	for _, we := range aw.Exercises {
+		e, ok := exercises[we.ExerciseID]
+		if !ok {
+			continue
+		}
+
+		for _, s := range we.Sets {
+			s.Model = gorm.Model{}
+			s.WorkoutExerciseID = 0
+			s.WorkoutExercise = nil
+
+			if err := w.AddSet(db, e, &s); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// isDuplicateWorkout returns whether the workout's file was already imported,
+// or the user already has a workout at the same time
+func (u *User) isDuplicateWorkout(db *gorm.DB, w *Workout) (bool, error) {
+	var count int64
+
+	if w.GPX != nil {
+		if err := db.Model(&GPXData{}).Where("checksum = ?", w.GPX.Checksum).Count(&count).Error; err != nil {
+			return false, err
+		}
+
+		if count > 0 {
+			return true, nil
+		}
+	}
+
+	if err := db.Model(&Workout{}).Where(&Workout{UserID: u.ID, Date: w.Date}).Count(&count).Error; err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// workout converts the archived workout to a new workout for the user
+func (aw *ArchiveWorkout) workout(u *User, z *zip.Reader) (*Workout, error) {
+	var w *Workout
+
+	if aw.File == "" {
+		if aw.Data == nil {
+			return nil, ErrInvalidData
+		}
+
+		data := *aw.Data
+		data.Model = gorm.Model{}
+		data.WorkoutID = 0
+		data.Details = nil
+
+		w = &Workout{User: u, UserID: u.ID, Data: &data}
+	} else {
+		content, err := readArchiveFile(z, aw.File)
+		if err != nil {
+			return nil, err
+		}
+
+		if w, err = NewWorkout(u, aw.Type, aw.Notes, aw.File, content); err != nil {
+			return nil, err
+		}
+	}
+
+	w.Name = aw.Name
+	w.Type = aw.Type
+	w.Notes = aw.Notes
+	w.Visibility = aw.Visibility.OrDefault()
+	w.ExcludeFromStatistics = aw.ExcludeFromStatistics
+
+	if aw.Date != nil {
+		w.Date = aw.Date
+	}
+
+	if w.Date == nil {
+		return nil, ErrInvalidData
+	}
+
+	return w, nil
+}
+
+func readArchiveFile(z *zip.Reader, name string) ([]byte, error) {
+	f, err := z.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrArchiveFileNotFound, name)
+	}
+	defer f.Close()
+
+	buf := &bytes.Buffer{}
+	if _, err := io.Copy(buf, f); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
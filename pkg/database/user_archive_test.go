@@ -0,0 +1,314 @@
+package database
+
+import (
+	"archive/zip"
+	"bytes"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createArchiveUser(t *testing.T) *User {
+	populateGPXFS()
+
+	db := createMemoryDB(t)
+
+	u := defaultUser()
+	u.Profile.Timezone = "Europe/Brussels"
+	require.NoError(t, u.Create(db))
+
+	e := &Equipment{Name: "shoes", UserID: u.ID, DefaultFor: []WorkoutType{WorkoutTypeRunning}}
+	require.NoError(t, e.Save(db))
+	require.NoError(t, (&PrivacyZone{Name: "home", UserID: u.ID, Lat: 1, Lng: 2, Radius: 100}).Save(db))
+
+	u.Equipment = []Equipment{*e}
+
+	f, err := gpxFS.ReadFile("sample1.gpx")
+	require.NoError(t, err)
+
+	w, err := u.AddWorkout(db, WorkoutTypeRunning, "from file", "sample1.gpx", f)
+	require.NoError(t, err)
+
+	w.Name = "renamed"
+	require.NoError(t, w.Save(db))
+
+	d := time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC)
+	require.NoError(t, (&Workout{
+		Name: "manual", UserID: u.ID, Type: WorkoutTypeWalking, Date: &d,
+		Visibility: WorkoutVisibilityPrivate, Data: &MapData{TotalDistance: 3000, TotalDuration: time.Hour},
+	}).Create(db))
+
+	u, err = GetUserByID(db, int(u.ID))
+	require.NoError(t, err)
+
+	return u
+}
+
+func exportArchive(t *testing.T, u *User) []byte {
+	buf := &bytes.Buffer{}
+	require.NoError(t, u.ExportArchive(u.db, buf))
+
+	return buf.Bytes()
+}
+
+func TestUser_ExportArchive(t *testing.T) {
+	u := createArchiveUser(t)
+	b := exportArchive(t, u)
+
+	z, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	require.NoError(t, err)
+
+	m, err := ReadArchiveManifest(z)
+	require.NoError(t, err)
+
+	assert.Equal(t, "my-username", m.Username)
+	assert.Equal(t, "Europe/Brussels", m.Profile.Timezone)
+	assert.Len(t, m.Equipment, 1)
+	assert.Len(t, m.PrivacyZones, 1)
+	require.Len(t, m.Workouts, 2)
+
+	assert.Equal(t, "renamed", m.Workouts[0].Name)
+	assert.NotEmpty(t, m.Workouts[0].File)
+	assert.Nil(t, m.Workouts[0].Data)
+	assert.Equal(t, []uint{m.Equipment[0].ID}, m.Workouts[0].Equipment)
+
+	assert.Equal(t, "manual", m.Workouts[1].Name)
+	assert.Empty(t, m.Workouts[1].File)
+	require.NotNil(t, m.Workouts[1].Data)
+
+	_, err = readArchiveFile(z, m.Workouts[0].File)
+	require.NoError(t, err)
+}
+
+func TestUser_ImportArchive(t *testing.T) {
+	u := createArchiveUser(t)
+	db := u.db
+	b := exportArchive(t, u)
+
+	// Importing in the same account skips everything
+	result, err := u.ImportArchive(db, bytes.NewReader(b), int64(len(b)))
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.Workouts)
+	assert.Equal(t, 0, result.Equipment)
+	assert.Equal(t, []string{"renamed", "manual"}, result.Duplicates)
+
+	// Delete the workouts, and restore them from the archive
+	ws, err := u.GetWorkouts(db)
+	require.NoError(t, err)
+
+	for _, w := range ws {
+		require.NoError(t, w.Delete(db))
+	}
+
+	result, err = u.ImportArchive(db, bytes.NewReader(b), int64(len(b)))
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Workouts)
+	assert.Empty(t, result.Duplicates)
+
+	ws, err = u.GetWorkouts(db)
+	require.NoError(t, err)
+	require.Len(t, ws, 2)
+
+	manual := ws[0]
+	assert.Equal(t, "manual", manual.Name)
+	assert.Equal(t, WorkoutVisibilityPrivate, manual.Visibility)
+	assert.InDelta(t, 3000, manual.Data.TotalDistance, 0.1)
+
+	restored, err := u.GetWorkout(db, int(ws[1].ID))
+	require.NoError(t, err)
+	assert.Equal(t, "renamed", restored.Name)
+	assert.True(t, restored.HasFile())
+	require.Len(t, restored.Equipment, 1)
+	assert.Equal(t, "shoes", restored.Equipment[0].Name)
+
+	// A new user gets its own equipment, but the file is a duplicate
+	other := &User{Username: "other", Password: "pwd", Name: "other"}
+	require.NoError(t, other.Create(db))
+
+	result, err = other.ImportArchive(db, bytes.NewReader(b), int64(len(b)))
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Workouts)
+	assert.Equal(t, 1, result.Equipment)
+	assert.Equal(t, []string{"renamed"}, result.Duplicates)
+
+	other, err = GetUserByID(db, int(other.ID))
+	require.NoError(t, err)
+	assert.Equal(t, "Europe/Brussels", other.Profile.Timezone)
+
+	es, err := other.GetAllEquipment(db)
+	require.NoError(t, err)
+	require.Len(t, es, 1)
+	assert.Equal(t, "shoes (other)", es[0].Name)
+}
+
+func TestUser_ImportArchiveSettings(t *testing.T) {
+	u := createArchiveUser(t)
+	db := u.db
+	e := &u.Equipment[0]
+
+	require.NoError(t, (&CustomWorkoutType{Name: "padel", UserID: &u.ID, Distance: true}).Save(db))
+	require.NoError(t, (&WorkoutTypeMapping{SportName: "padel tennis", Type: "padel", UserID: &u.ID}).Save(db))
+	require.NoError(t, (&Goal{UserID: u.ID, Name: "run", Metric: GoalMetricDistance, Period: GoalPeriodMonth, Target: 100000}).Save(db))
+	require.NoError(t, (&Measurement{UserID: u.ID, Date: time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC), Weight: ptr(70)}).Save(db))
+
+	item := &MaintenanceItem{EquipmentID: e.ID, Name: "laces", Metric: MaintenanceMetricDistance, Interval: 500000}
+	require.NoError(t, item.Save(db))
+	require.NoError(t, (&EquipmentService{EquipmentID: e.ID, MaintenanceItemID: &item.ID, Notes: "new laces"}).Save(db))
+
+	squat, err := u.GetOrCreateExercise(db, "Squat")
+	require.NoError(t, err)
+
+	lifting := strengthWorkout(t, u, time.Date(2024, 1, 3, 10, 0, 0, 0, time.UTC))
+	require.NoError(t, lifting.AddSet(db, squat, &ExerciseSet{Repetitions: 5, Weight: 100, RPE: 8}))
+	require.NoError(t, lifting.AddSet(db, squat, &ExerciseSet{Repetitions: 3, Weight: 110}))
+
+	b := exportArchive(t, u)
+
+	other := &User{Username: "other", Password: "pwd", Name: "other"}
+	require.NoError(t, other.Create(db))
+
+	result, err := other.ImportArchive(db, bytes.NewReader(b), int64(len(b)))
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Workouts)
+
+	assert.True(t, other.CanUseWorkoutType("padel"))
+
+	mappings, err := other.GetWorkoutTypeMappings(db)
+	require.NoError(t, err)
+	require.Len(t, mappings, 1)
+	assert.Equal(t, WorkoutType("padel"), mappings[0].Type)
+
+	goals, err := other.GetGoals(db)
+	require.NoError(t, err)
+	require.Len(t, goals, 1)
+	assert.Equal(t, "run", goals[0].Name)
+
+	measurements, err := other.GetMeasurements(db)
+	require.NoError(t, err)
+	require.Len(t, measurements, 1)
+	assert.InDelta(t, 70, *measurements[0].Weight, 0.01)
+
+	es, err := other.GetAllEquipment(db)
+	require.NoError(t, err)
+	require.Len(t, es, 1)
+
+	restored, err := GetEquipment(db, int(es[0].ID))
+	require.NoError(t, err)
+	require.Len(t, restored.MaintenanceItems, 1)
+	require.Len(t, restored.Services, 1)
+	assert.Equal(t, "laces", restored.MaintenanceItems[0].Name)
+	assert.Equal(t, restored.MaintenanceItems[0].ID, *restored.Services[0].MaintenanceItemID)
+
+	ws, err := other.GetWorkouts(db)
+	require.NoError(t, err)
+
+	i := slices.IndexFunc(ws, func(w *Workout) bool { return w.Type == WorkoutTypeWeightLifting })
+	require.GreaterOrEqual(t, i, 0)
+
+	w, err := other.GetWorkout(db, int(ws[i].ID))
+	require.NoError(t, err)
+	require.Len(t, w.Exercises, 1)
+	assert.Equal(t, "Squat", w.Exercises[0].Exercise.Name)
+	require.Len(t, w.Exercises[0].Sets, 2)
+	assert.InDelta(t, 8, w.Exercises[0].Sets[0].RPE, 0.01)
+	assert.Equal(t, 8, w.Data.TotalRepetitions)
+
+	// Importing again adds nothing
+	result, err = other.ImportArchive(db, bytes.NewReader(b), int64(len(b)))
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.Workouts)
+
+	goals, err = other.GetGoals(db)
+	require.NoError(t, err)
+	assert.Len(t, goals, 1)
+
+	measurements, err = other.GetMeasurements(db)
+	require.NoError(t, err)
+	assert.Len(t, measurements, 1)
+
+	exercises, err := other.GetExercises(db)
+	require.NoError(t, err)
+	assert.Len(t, exercises, 1)
+}
+
+func TestUser_ImportArchiveOriginals(t *testing.T) {
+	db := createMemoryDB(t)
+
+	u := defaultUser()
+	require.NoError(t, u.Create(db))
+
+	later := addTrack(t, db, u, 101, 10*time.Minute)
+	first := addTrack(t, db, u, 101, 0)
+	require.NoError(t, later.Merge(db, []*Workout{first}))
+
+	w, err := GetWorkoutDetails(db, int(later.ID))
+	require.NoError(t, err)
+	require.NoError(t, w.Trim(db, TrackOffset{}, TrackOffset{Duration: time.Minute}))
+
+	points := len(w.Data.Details.Points)
+
+	u, err = GetUserByID(db, int(u.ID))
+	require.NoError(t, err)
+
+	b := exportArchive(t, u)
+	require.NoError(t, w.Delete(db))
+
+	result, err := u.ImportArchive(db, bytes.NewReader(b), int64(len(b)))
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Workouts)
+
+	ws, err := u.GetWorkouts(db)
+	require.NoError(t, err)
+	require.Len(t, ws, 1)
+
+	restored, err := GetWorkoutDetails(db, int(ws[0].ID))
+	require.NoError(t, err)
+	assert.True(t, restored.HasOriginal())
+	assert.Len(t, restored.Data.Details.Points, points)
+
+	// Both imported files are restored
+	require.NoError(t, restored.RestoreOriginal(db))
+	assert.False(t, restored.HasOriginal())
+	assert.Len(t, restored.Data.Details.Points, 101)
+
+	ws, err = u.GetWorkouts(db)
+	require.NoError(t, err)
+	assert.Len(t, ws, 2)
+}
+
+func TestUser_ImportArchiveInvalid(t *testing.T) {
+	u := createArchiveUser(t)
+
+	_, err := u.ImportArchive(u.db, bytes.NewReader([]byte("not a zip")), 9)
+	require.Error(t, err)
+
+	buf := &bytes.Buffer{}
+	z := zip.NewWriter(buf)
+	f, err := z.Create(ArchiveManifestName)
+	require.NoError(t, err)
+	_, err = f.Write([]byte(`{"Version": 99}`))
+	require.NoError(t, err)
+	require.NoError(t, z.Close())
+
+	_, err = u.ImportArchive(u.db, bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.ErrorIs(t, err, ErrArchiveVersion)
+
+	// Archives of the first version can still be imported
+	buf.Reset()
+	z = zip.NewWriter(buf)
+	f, err = z.Create(ArchiveManifestName)
+	require.NoError(t, err)
+	_, err = f.Write([]byte(`{"Version": 1, "PrivacyZones": [{"name": "work", "lat": 1, "lng": 2, "radius": 50}]}`))
+	require.NoError(t, err)
+	require.NoError(t, z.Close())
+
+	_, err = u.ImportArchive(u.db, bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+
+	zones, err := u.GetPrivacyZones(u.db)
+	require.NoError(t, err)
+	assert.Len(t, zones, 2)
+}
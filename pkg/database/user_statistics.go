@@ -10,6 +10,8 @@ type (
 		UserID       uint                              // The user ID
 		BucketFormat string                            // The bucket format in strftime format
 		Buckets      map[WorkoutType]map[string]Bucket // The statistics buckets
+		TrainingLoad []WeeklyTrainingLoad              // The training load per week
+		Measurements MeasurementTrends                 // The body measurements over the time range
 	}
 
 	// Bucket is the consolidation of workout information for a given time bucket
@@ -20,9 +22,12 @@ type (
 		Distance            float64       `json:",omitempty"` // The total distance in the bucket
 		Up                  float64       `json:",omitempty"` // The total up elevation in the bucket
 		Duration            time.Duration `json:",omitempty"` // The total duration in the bucket
+		Repetitions         int           `json:",omitempty"` // The total number of repetitions in the bucket
 		AverageSpeed        float64       `json:",omitempty"` // The average speed in the bucket
 		AverageSpeedNoPause float64       `json:",omitempty"` // The average speed without pause in the bucket
 		MaxSpeed            float64       `json:",omitempty"` // The max speed in the bucket
+		TrainingLoad        float64       `json:",omitempty"` // The total training load in the bucket
+		Calories            float64       `json:",omitempty"` // The total energy expenditure in the bucket, in kilocalories
 	}
 
 	// float64Record is a single record if the value is a float64
@@ -41,13 +46,19 @@ type (
 
 	// WorkoutRecord is the collection of records for a single workout type
 	WorkoutRecord struct {
-		WorkoutType         WorkoutType    // The type of the workout
-		Active              bool           // Whether there is any data in the record
-		AverageSpeed        float64Record  // The record with the maximum average speed
-		AverageSpeedNoPause float64Record  // The record with the maximum average speed without pause
-		MaxSpeed            float64Record  // The record with the maximum max speed
-		Distance            float64Record  // The record with the maximum distance
-		TotalUp             float64Record  // The record with the maximum up elevation
-		Duration            durationRecord // The record with the maximum duration
+		WorkoutType         WorkoutType        // The type of the workout
+		Active              bool               // Whether there is any data in the record
+		AverageSpeed        float64Record      // The record with the maximum average speed
+		AverageSpeedNoPause float64Record      // The record with the maximum average speed without pause
+		MaxSpeed            float64Record      // The record with the maximum max speed
+		Distance            float64Record      // The record with the maximum distance
+		TotalUp             float64Record      // The record with the maximum up elevation
+		Duration            durationRecord     // The record with the maximum duration
+		BestEfforts         []BestEffortRecord `json:",omitempty"` // The all-time best efforts over standard distances and durations
+		BestEffortsPerYear  []YearBestEfforts  `json:",omitempty"` // The best efforts per year, most recent year first
+		AveragePower        float64Record      // The record with the maximum average power
+		NormalizedPower     float64Record      // The record with the maximum normalized power
+		MaxPower            float64Record      // The record with the maximum max power
+		PowerCurve          []PowerCurveRecord `json:",omitempty"` // The all-time best average power over standard durations
 	}
 )
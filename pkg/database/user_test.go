@@ -312,3 +312,23 @@ func TestDatabaseUserWorkouts(t *testing.T) {
 	w2.Type = WorkoutTypeWalking
 	require.NoError(t, w2.Save(db))
 }
+
+func TestProfile_AffectsWorkoutData(t *testing.T) {
+	old := Profile{FTP: 250, Language: "en"}
+
+	p := old
+	p.Language = "nl"
+	p.ShareHideDistance = 200
+	assert.False(t, p.AffectsWorkoutData(old))
+
+	for _, change := range []func(p *Profile){
+		func(p *Profile) { p.FTP = 260 },
+		func(p *Profile) { p.HeartRate.Max = 185 },
+		func(p *Profile) { p.TrackCleaning.Enabled = true },
+		func(p *Profile) { p.Climbs.Scoring = ClimbScoringFiets },
+	} {
+		p := old
+		change(&p)
+		assert.True(t, p.AffectsWorkoutData(old))
+	}
+}
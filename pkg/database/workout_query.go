@@ -0,0 +1,240 @@
+package database
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	DefaultWorkoutsPerPage = 25
+	MaxWorkoutsPerPage     = 100
+)
+
+var ErrInvalidWorkoutOrder = errors.New("invalid sort key")
+
+// likeEscaper escapes the wildcards of LIKE, so they are searched for as they
+// are; the escape character is the backslash
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+type WorkoutOrder string
+
+const (
+	WorkoutOrderDate     WorkoutOrder = "date"     // Sort by the date of the workout
+	WorkoutOrderDistance WorkoutOrder = "distance" // Sort by the total distance
+	WorkoutOrderDuration WorkoutOrder = "duration" // Sort by the total duration
+	WorkoutOrderSpeed    WorkoutOrder = "speed"    // Sort by the average speed
+)
+
+func WorkoutOrders() []WorkoutOrder {
+	return []WorkoutOrder{
+		WorkoutOrderDate,
+		WorkoutOrderDistance,
+		WorkoutOrderDuration,
+		WorkoutOrderSpeed,
+	}
+}
+
+func (wo WorkoutOrder) String() string {
+	return string(wo)
+}
+
+func (wo WorkoutOrder) IsValid() bool {
+	return slices.Contains(WorkoutOrders(), wo)
+}
+
+func (wo WorkoutOrder) column() string {
+	switch wo {
+	case WorkoutOrderDistance:
+		return "map_data.total_distance"
+	case WorkoutOrderDuration:
+		return "map_data.total_duration"
+	case WorkoutOrderSpeed:
+		return "CASE WHEN map_data.total_duration > 0 THEN map_data.total_distance / map_data.total_duration ELSE 0 END"
+	default:
+		return "workouts.date"
+	}
+}
+
+// WorkoutQuery filters, sorts and paginates a list of workouts; zero values
+// mean the filter is not applied
+type WorkoutQuery struct {
+	Type        WorkoutType   // Only workouts of this type; without a type, legs of multisport workouts are left out
+	Since       *time.Time    // Only workouts on or after this time
+	Until       *time.Time    // Only workouts before this time
+	EquipmentID uint          // Only workouts that used this equipment
+	MinDistance float64       // The minimum total distance, in meters
+	MaxDistance float64       // The maximum total distance, in meters
+	MinDuration time.Duration // The minimum total duration
+	MaxDuration time.Duration // The maximum total duration
+	Search      string        // Text to find in the name, notes or address
+
+	OrderBy   WorkoutOrder // The sort key; defaults to the date
+	Ascending bool         // Whether to sort ascending instead of descending
+
+	Page    int // The page to return, starting at 1
+	PerPage int // The number of workouts per page
+}
+
+// WorkoutPage is one page of workouts matching a query
+type WorkoutPage struct {
+	Workouts []*Workout // The workouts on this page
+	Page     int        // The number of this page, starting at 1
+	PerPage  int        // The maximum number of workouts per page
+	Total    int64      // The total number of workouts matching the query
+}
+
+func (p *WorkoutPage) Pages() int {
+	if p.PerPage <= 0 {
+		return 1
+	}
+
+	return max(1, int(math.Ceil(float64(p.Total)/float64(p.PerPage))))
+}
+
+func (p *WorkoutPage) HasPrevious() bool {
+	return p.Page > 1
+}
+
+func (p *WorkoutPage) HasNext() bool {
+	return p.Page < p.Pages()
+}
+
+func (p *WorkoutPage) Previous() int {
+	return p.Page - 1
+}
+
+func (p *WorkoutPage) Next() int {
+	return p.Page + 1
+}
+
+// Normalize fills in defaults and checks the values of the query
+func (q *WorkoutQuery) Normalize() error {
+	if q.OrderBy == "" {
+		q.OrderBy = WorkoutOrderDate
+	}
+
+	if !q.OrderBy.IsValid() {
+		return ErrInvalidWorkoutOrder
+	}
+
+	if q.Page < 1 {
+		q.Page = 1
+	}
+
+	if q.PerPage < 1 {
+		q.PerPage = DefaultWorkoutsPerPage
+	}
+
+	q.PerPage = min(q.PerPage, MaxWorkoutsPerPage)
+
+	return nil
+}
+
+// Scope is a gorm scope that applies the filters of the query; it does not
+// sort or paginate
+func (q *WorkoutQuery) Scope(db *gorm.DB) *gorm.DB {
+	db = db.Joins("LEFT JOIN map_data ON map_data.workout_id = workouts.id AND map_data.deleted_at IS NULL")
+
+	if q.Type != "" {
+		db = db.Where("workouts.type = ?", q.Type)
+	} else {
+		// The legs of multisport workouts are listed on their workout, unless
+		// their type is asked for
+		db = db.Where("workouts.parent_id IS NULL")
+	}
+
+	if q.Since != nil {
+		db = db.Where("workouts.date >= ?", q.Since)
+	}
+
+	if q.Until != nil {
+		db = db.Where("workouts.date < ?", q.Until)
+	}
+
+	if q.EquipmentID != 0 {
+		db = db.Where("workouts.id IN (?)",
+			db.Session(&gorm.Session{NewDB: true}).
+				Table("workout_equipment").
+				Select("workout_id").
+				Where("equipment_id = ?", q.EquipmentID))
+	}
+
+	if q.MinDistance > 0 {
+		db = db.Where("map_data.total_distance >= ?", q.MinDistance)
+	}
+
+	if q.MaxDistance > 0 {
+		db = db.Where("map_data.total_distance <= ?", q.MaxDistance)
+	}
+
+	if q.MinDuration > 0 {
+		db = db.Where("map_data.total_duration >= ?", q.MinDuration)
+	}
+
+	if q.MaxDuration > 0 {
+		db = db.Where("map_data.total_duration <= ?", q.MaxDuration)
+	}
+
+	if s := strings.TrimSpace(q.Search); s != "" {
+		// The escape character is a parameter, since MySQL would read '\' in
+		// the query itself as an escaped quote
+		s = "%" + likeEscaper.Replace(strings.ToLower(s)) + "%"
+		db = db.Where(
+			"LOWER(workouts.name) LIKE ? ESCAPE ? OR LOWER(workouts.notes) LIKE ? ESCAPE ? OR LOWER(map_data.address_string) LIKE ? ESCAPE ?",
+			s, `\`, s, `\`, s, `\`,
+		)
+	}
+
+	return db
+}
+
+func (q *WorkoutQuery) order() string {
+	direction := " DESC"
+	if q.Ascending {
+		direction = " ASC"
+	}
+
+	return q.OrderBy.column() + direction + ", workouts.id" + direction
+}
+
+// GetWorkoutsPage returns the page of workouts matching the query
+func GetWorkoutsPage(db *gorm.DB, q WorkoutQuery) (*WorkoutPage, error) {
+	if err := q.Normalize(); err != nil {
+		return nil, err
+	}
+
+	// Make the statement safe to reuse for both queries
+	db = db.Session(&gorm.Session{})
+
+	p := &WorkoutPage{
+		Page:    q.Page,
+		PerPage: q.PerPage,
+	}
+
+	if err := db.Model(&Workout{}).Scopes(q.Scope).Count(&p.Total).Error; err != nil {
+		return nil, err
+	}
+
+	if err := db.
+		Scopes(q.Scope).
+		Select("workouts.*").
+		Preload("Data").
+		Order(q.order()).
+		Limit(q.PerPage).
+		Offset((q.Page - 1) * q.PerPage).
+		Find(&p.Workouts).Error; err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// GetWorkoutsPage returns the page of the user's workouts matching the query
+func (u *User) GetWorkoutsPage(db *gorm.DB, q WorkoutQuery) (*WorkoutPage, error) {
+	return GetWorkoutsPage(db.Where("workouts.user_id = ?", u.ID), q)
+}
@@ -0,0 +1,148 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func createQueryWorkouts(t *testing.T) (*User, []*Workout) {
+	db := createMemoryDB(t)
+
+	u := defaultUser()
+	require.NoError(t, u.Create(db))
+	u.SetDB(db)
+
+	other := &User{Username: "other", Password: "pwd", Name: "other"}
+	require.NoError(t, other.Create(db))
+
+	workouts := []*Workout{}
+
+	for i, d := range []struct {
+		name     string
+		notes    string
+		wt       WorkoutType
+		distance float64
+		duration time.Duration
+	}{
+		{"Morning run", "easy", WorkoutTypeRunning, 5000, 30 * time.Minute},
+		{"Long ride", "windy", WorkoutTypeCycling, 80000, 3 * time.Hour},
+		{"Evening run", "intervals at 100%", WorkoutTypeRunning, 10000, 45 * time.Minute},
+		{"Walk with the dog", "", WorkoutTypeWalking, 3000, time.Hour},
+	} {
+		date := time.Date(2024, 1, 1+i, 10, 0, 0, 0, time.UTC)
+		w := &Workout{
+			Name:   d.name,
+			Notes:  d.notes,
+			Type:   d.wt,
+			Date:   &date,
+			UserID: u.ID,
+			Data: &MapData{
+				TotalDistance: d.distance,
+				TotalDuration: d.duration,
+				AddressString: "Brussels",
+			},
+		}
+		require.NoError(t, w.Create(db))
+
+		workouts = append(workouts, w)
+	}
+
+	date := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	require.NoError(t, (&Workout{Name: "Other run", Type: WorkoutTypeRunning, Date: &date, UserID: other.ID, Data: &MapData{}}).Create(db))
+
+	return u, workouts
+}
+
+func workoutNames(p *WorkoutPage) []string {
+	names := []string{}
+	for _, w := range p.Workouts {
+		names = append(names, w.Name)
+	}
+
+	return names
+}
+
+func TestWorkoutQuery_Normalize(t *testing.T) {
+	q := WorkoutQuery{PerPage: 1000}
+	require.NoError(t, q.Normalize())
+	assert.Equal(t, WorkoutOrderDate, q.OrderBy)
+	assert.Equal(t, 1, q.Page)
+	assert.Equal(t, MaxWorkoutsPerPage, q.PerPage)
+
+	q = WorkoutQuery{OrderBy: "name"}
+	require.ErrorIs(t, q.Normalize(), ErrInvalidWorkoutOrder)
+}
+
+func TestUser_GetWorkoutsPage(t *testing.T) {
+	u, _ := createQueryWorkouts(t)
+	db := u.db
+
+	p, err := u.GetWorkoutsPage(db, WorkoutQuery{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), p.Total)
+	assert.Equal(t, []string{"Walk with the dog", "Evening run", "Long ride", "Morning run"}, workoutNames(p))
+	assert.NotNil(t, p.Workouts[0].Data)
+
+	p, err = u.GetWorkoutsPage(db, WorkoutQuery{Type: WorkoutTypeRunning})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Evening run", "Morning run"}, workoutNames(p))
+
+	p, err = u.GetWorkoutsPage(db, WorkoutQuery{MinDistance: 4000, MaxDistance: 20000, OrderBy: WorkoutOrderDistance, Ascending: true})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Morning run", "Evening run"}, workoutNames(p))
+
+	p, err = u.GetWorkoutsPage(db, WorkoutQuery{MinDuration: time.Hour})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Walk with the dog", "Long ride"}, workoutNames(p))
+
+	since := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2024, 1, 4, 0, 0, 0, 0, time.UTC)
+	p, err = u.GetWorkoutsPage(db, WorkoutQuery{Since: &since, Until: &until})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Evening run", "Long ride"}, workoutNames(p))
+
+	p, err = u.GetWorkoutsPage(db, WorkoutQuery{Search: "WINDY"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Long ride"}, workoutNames(p))
+
+	p, err = u.GetWorkoutsPage(db, WorkoutQuery{Search: "brussels", Type: WorkoutTypeWalking})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Walk with the dog"}, workoutNames(p))
+
+	// Wildcards are searched for as they are
+	p, err = u.GetWorkoutsPage(db, WorkoutQuery{Search: "%"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Evening run"}, workoutNames(p))
+
+	p, err = u.GetWorkoutsPage(db, WorkoutQuery{Search: "_"})
+	require.NoError(t, err)
+	assert.Empty(t, workoutNames(p))
+
+	p, err = u.GetWorkoutsPage(db, WorkoutQuery{OrderBy: WorkoutOrderSpeed})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Long ride", "Evening run", "Morning run", "Walk with the dog"}, workoutNames(p))
+
+	p, err = u.GetWorkoutsPage(db, WorkoutQuery{PerPage: 3, Page: 2})
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), p.Total)
+	assert.Equal(t, 2, p.Pages())
+	assert.True(t, p.HasPrevious())
+	assert.False(t, p.HasNext())
+	assert.Equal(t, []string{"Morning run"}, workoutNames(p))
+}
+
+func TestUser_GetWorkoutsPageByEquipment(t *testing.T) {
+	u, workouts := createQueryWorkouts(t)
+	db := u.db
+
+	e := &Equipment{Name: "bike", UserID: u.ID}
+	require.NoError(t, e.Save(db))
+	require.NoError(t, db.Model(workouts[1]).Association("Equipment").Append(e))
+
+	p, err := u.GetWorkoutsPage(db, WorkoutQuery{EquipmentID: e.ID})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Long ride"}, workoutNames(p))
+}
@@ -1,6 +1,9 @@
 package database
 
-import "slices"
+import (
+	"slices"
+	"sync"
+)
 
 type (
 	WorkoutType string
@@ -20,6 +23,8 @@ const (
 	WorkoutTypeKayaking      WorkoutType = "kayaking"
 	WorkoutTypeGolfing       WorkoutType = "golfing"
 	WorkoutTypeHiking        WorkoutType = "hiking"
+	WorkoutTypeMultisport    WorkoutType = "multisport"
+	WorkoutTypeTransition    WorkoutType = "transition"
 	WorkoutTypePushups       WorkoutType = "push-ups"
 	WorkoutTypeWeightLifting WorkoutType = "weight lifting"
 
@@ -47,22 +52,65 @@ var workoutTypeConfigs = map[WorkoutType]WorkoutTypeConfiguration{
 	WorkoutTypeKayaking:     {Location: true, Distance: true, Repetition: false, Weight: false},
 	WorkoutTypeGolfing:      {Location: true, Distance: true, Repetition: false, Weight: false},
 	WorkoutTypeHiking:       {Location: true, Distance: true, Repetition: false, Weight: false},
+	WorkoutTypeMultisport:   {Location: true, Distance: true, Repetition: false, Weight: false},
+	WorkoutTypeTransition:   {Location: true, Distance: false, Repetition: false, Weight: false},
 
 	WorkoutTypePushups:       {Location: false, Distance: false, Repetition: true, Weight: false},
 	WorkoutTypeWeightLifting: {Location: false, Distance: false, Repetition: true, Weight: true},
 }
 
 var (
+	// workoutTypesLock guards the custom workout types, the sport name mappings
+	// and the cached collections derived from them
+	workoutTypesLock    sync.RWMutex
+	customWorkoutTypes  map[WorkoutType][]CustomWorkoutType
+	workoutTypeMappings []WorkoutTypeMapping
 	workoutTypes        []WorkoutType
 	workoutTypesByClass map[string][]WorkoutType
 )
 
+// workoutTypeConfig returns the configuration of a built-in or custom workout
+// type
+func workoutTypeConfig(wt WorkoutType) (WorkoutTypeConfiguration, bool) {
+	if c, ok := workoutTypeConfigs[wt]; ok {
+		return c, true
+	}
+
+	ct, ok := customWorkoutType(wt)
+	if !ok {
+		return WorkoutTypeConfiguration{}, false
+	}
+
+	return ct.Configuration(), true
+}
+
+// allWorkoutTypeConfigs returns the configuration of all built-in and custom
+// workout types; the lock must be held
+func allWorkoutTypeConfigs() map[WorkoutType]WorkoutTypeConfiguration {
+	r := make(map[WorkoutType]WorkoutTypeConfiguration, len(workoutTypeConfigs)+len(customWorkoutTypes))
+
+	for k, c := range workoutTypeConfigs {
+		r[k] = c
+	}
+
+	for k, types := range customWorkoutTypes {
+		r[k] = types[0].Configuration()
+	}
+
+	return r
+}
+
+// WorkoutTypes returns the built-in workout types, merged with the custom
+// types of the instance and of all users
 func WorkoutTypes() []WorkoutType {
+	workoutTypesLock.Lock()
+	defer workoutTypesLock.Unlock()
+
 	if len(workoutTypes) > 0 {
 		return workoutTypes
 	}
 
-	for k := range workoutTypeConfigs {
+	for k := range allWorkoutTypeConfigs() {
 		workoutTypes = append(workoutTypes, k)
 	}
 
@@ -72,6 +120,9 @@ func WorkoutTypes() []WorkoutType {
 }
 
 func getOrSetByClass(class string, fn func(c WorkoutTypeConfiguration) bool) []WorkoutType {
+	workoutTypesLock.Lock()
+	defer workoutTypesLock.Unlock()
+
 	if workoutTypesByClass == nil {
 		workoutTypesByClass = make(map[string][]WorkoutType)
 	}
@@ -82,7 +133,7 @@ func getOrSetByClass(class string, fn func(c WorkoutTypeConfiguration) bool) []W
 
 	keys := []WorkoutType{}
 
-	for k, c := range workoutTypeConfigs {
+	for k, c := range allWorkoutTypeConfigs() {
 		if !fn(c) {
 			continue
 		}
@@ -91,7 +142,7 @@ func getOrSetByClass(class string, fn func(c WorkoutTypeConfiguration) bool) []W
 	}
 
 	slices.Sort(keys)
-	workoutTypesByClass[WorkoutTypeClassDistance] = keys
+	workoutTypesByClass[class] = keys
 
 	return keys
 }
@@ -130,25 +181,53 @@ func (wt WorkoutType) String() string {
 	return string(wt)
 }
 
+// IsValid returns whether the type is a known workout type; "auto" is not
+// considered valid, since it is only a hint for the importer
+func (wt WorkoutType) IsValid() bool {
+	_, ok := workoutTypeConfig(wt)
+	return ok
+}
+
 func (wt WorkoutType) IsDistance() bool {
-	return workoutTypeConfigs[wt].Distance
+	c, _ := workoutTypeConfig(wt)
+	return c.Distance
 }
 
 func (wt WorkoutType) IsRepetition() bool {
-	return workoutTypeConfigs[wt].Repetition
+	c, _ := workoutTypeConfig(wt)
+	return c.Repetition
 }
 
 func (wt WorkoutType) IsDuration() bool {
-	_, ok := workoutTypeConfigs[wt]
+	_, ok := workoutTypeConfig(wt)
 	return ok
 }
 
 func (wt WorkoutType) IsWeight() bool {
-	return workoutTypeConfigs[wt].Weight
+	c, _ := workoutTypeConfig(wt)
+	return c.Weight
 }
 
 func (wt WorkoutType) IsLocation() bool {
-	return workoutTypeConfigs[wt].Location
+	c, _ := workoutTypeConfig(wt)
+	return c.Location
+}
+
+// IsSwimming returns whether the pace of the type is shown per 100 m or 100 yd
+func (wt WorkoutType) IsSwimming() bool {
+	return wt == WorkoutTypeSwimming
+}
+
+// IsMultisport returns whether workouts of the type are made up of legs of
+// other types, eg. a triathlon
+func (wt WorkoutType) IsMultisport() bool {
+	return wt == WorkoutTypeMultisport
+}
+
+// IsBuiltin returns whether the type is one of the built-in workout types
+func (wt WorkoutType) IsBuiltin() bool {
+	_, ok := workoutTypeConfigs[wt]
+	return ok
 }
 
 func AsWorkoutType(s string) WorkoutType {
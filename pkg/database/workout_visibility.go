@@ -0,0 +1,91 @@
+package database
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+var ErrWorkoutNotVisible = errors.New("workout not found")
+
+type WorkoutVisibility string
+
+const (
+	// We need to add each of these visibilities to the "messages.html" partial view.
+	WorkoutVisibilityPrivate  WorkoutVisibility = "private"  // Only the owner can see the workout
+	WorkoutVisibilityInstance WorkoutVisibility = "instance" // Every user of this instance can see the workout
+	WorkoutVisibilityPublic   WorkoutVisibility = "public"   // Everyone can see the workout
+
+	DefaultWorkoutVisibility = WorkoutVisibilityInstance
+)
+
+func WorkoutVisibilities() []WorkoutVisibility {
+	return []WorkoutVisibility{
+		WorkoutVisibilityPrivate,
+		WorkoutVisibilityInstance,
+		WorkoutVisibilityPublic,
+	}
+}
+
+// sharedWorkoutVisibilities are the visibilities that allow other users to see a workout
+func sharedWorkoutVisibilities() []WorkoutVisibility {
+	return []WorkoutVisibility{
+		WorkoutVisibilityInstance,
+		WorkoutVisibilityPublic,
+	}
+}
+
+func (wv WorkoutVisibility) String() string {
+	return string(wv)
+}
+
+func (wv WorkoutVisibility) IsValid() bool {
+	switch wv {
+	case WorkoutVisibilityPrivate, WorkoutVisibilityInstance, WorkoutVisibilityPublic:
+		return true
+	default:
+		return false
+	}
+}
+
+// OrDefault returns the visibility, or the default visibility if it is not valid
+func (wv WorkoutVisibility) OrDefault() WorkoutVisibility {
+	if !wv.IsValid() {
+		return DefaultWorkoutVisibility
+	}
+
+	return wv
+}
+
+// IsVisibleTo returns whether the user (which may be nil for anonymous
+// visitors) is allowed to see this workout
+func (w *Workout) IsVisibleTo(u *User) bool {
+	if w == nil {
+		return false
+	}
+
+	if u != nil && u.ID != 0 && w.UserID == u.ID {
+		return true
+	}
+
+	switch w.Visibility.OrDefault() {
+	case WorkoutVisibilityPublic:
+		return true
+	case WorkoutVisibilityInstance:
+		return u.IsActive()
+	default:
+		return false
+	}
+}
+
+// WorkoutsVisibleTo is a gorm scope that limits the workouts to those the
+// user (which may be nil for anonymous visitors) is allowed to see
+func WorkoutsVisibleTo(u *User) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if u == nil || u.ID == 0 {
+			return db.Where("workouts.visibility = ?", WorkoutVisibilityPublic)
+		}
+
+		return db.Where("workouts.user_id = ? OR workouts.visibility IN ?", u.ID, sharedWorkoutVisibilities())
+	}
+}
@@ -0,0 +1,92 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestWorkoutVisibility_OrDefault(t *testing.T) {
+	assert.Equal(t, WorkoutVisibilityPrivate, WorkoutVisibilityPrivate.OrDefault())
+	assert.Equal(t, WorkoutVisibilityPublic, WorkoutVisibilityPublic.OrDefault())
+	assert.Equal(t, DefaultWorkoutVisibility, WorkoutVisibility("").OrDefault())
+	assert.Equal(t, DefaultWorkoutVisibility, WorkoutVisibility("invalid").OrDefault())
+}
+
+func TestWorkout_IsVisibleTo(t *testing.T) {
+	owner := &User{Model: gorm.Model{ID: 1}, Username: "owner", Password: "pwd", Active: true}
+	other := &User{Model: gorm.Model{ID: 2}, Username: "other", Password: "pwd", Active: true}
+
+	w := &Workout{UserID: owner.ID}
+
+	for _, v := range WorkoutVisibilities() {
+		w.Visibility = v
+		assert.True(t, w.IsVisibleTo(owner), v)
+	}
+
+	w.Visibility = WorkoutVisibilityPrivate
+	assert.False(t, w.IsVisibleTo(other))
+	assert.False(t, w.IsVisibleTo(nil))
+
+	w.Visibility = WorkoutVisibilityInstance
+	assert.True(t, w.IsVisibleTo(other))
+	assert.False(t, w.IsVisibleTo(nil))
+
+	w.Visibility = WorkoutVisibilityPublic
+	assert.True(t, w.IsVisibleTo(other))
+	assert.True(t, w.IsVisibleTo(nil))
+}
+
+func TestWorkout_DefaultVisibilityFromProfile(t *testing.T) {
+	populateGPXFS()
+
+	u := defaultUser()
+	u.Profile.DefaultVisibility = WorkoutVisibilityPrivate
+
+	f1, err := gpxFS.ReadFile("sample1.gpx")
+	require.NoError(t, err)
+
+	w, err := NewWorkout(u, WorkoutTypeAutoDetect, "", "file.gpx", f1)
+	require.NoError(t, err)
+	assert.Equal(t, WorkoutVisibilityPrivate, w.Visibility)
+}
+
+func TestWorkoutsVisibleTo(t *testing.T) {
+	populateGPXFS()
+
+	db := createMemoryDB(t)
+
+	owner := defaultUser()
+	owner.Active = true
+	require.NoError(t, owner.Create(db))
+
+	other := &User{Username: "other", Password: "pwd", Name: "other", Active: true}
+	require.NoError(t, other.Create(db))
+
+	w := defaultWorkout(t)
+	w.User = owner
+	w.UserID = owner.ID
+	w.Visibility = WorkoutVisibilityPrivate
+	require.NoError(t, w.Save(db))
+
+	ws, err := GetWorkouts(db.Scopes(WorkoutsVisibleTo(owner)))
+	require.NoError(t, err)
+	assert.Len(t, ws, 1)
+
+	ws, err = GetWorkouts(db.Scopes(WorkoutsVisibleTo(other)))
+	require.NoError(t, err)
+	assert.Empty(t, ws)
+
+	w.Visibility = WorkoutVisibilityInstance
+	require.NoError(t, w.Save(db))
+
+	ws, err = GetWorkouts(db.Scopes(WorkoutsVisibleTo(other)))
+	require.NoError(t, err)
+	assert.Len(t, ws, 1)
+
+	ws, err = GetWorkouts(db.Scopes(WorkoutsVisibleTo(nil)))
+	require.NoError(t, err)
+	assert.Empty(t, ws)
+}
@@ -22,16 +22,31 @@ var ErrInvalidData = errors.New("could not convert data to a GPX structure")
 
 type Workout struct {
 	gorm.Model
-	Name      string      `gorm:"not null"`                                  // The name of the workout
-	Date      *time.Time  `gorm:"not null;uniqueIndex:idx_start_user"`       // The timestamp the workout was recorded
-	UserID    uint        `gorm:"not null;index;uniqueIndex:idx_start_user"` // The ID of the user who owns the workout
-	Dirty     bool        // Whether the workout has been modified and the details should be re-rendered
-	User      *User       // The user who owns the workout
-	Notes     string      // The notes associated with the workout, in markdown
-	Type      WorkoutType // The type of the workout
-	Data      *MapData    `json:",omitempty"`                                    // The map data associated with the workout
-	GPX       *GPXData    `json:",omitempty"`                                    // The file data associated with the workout
-	Equipment []Equipment `json:",omitempty" gorm:"many2many:workout_equipment"` // Which equipment is used for this workout
+	Name       string            `gorm:"not null"`                                      // The name of the workout
+	Date       *time.Time        `gorm:"not null;uniqueIndex:idx_start_user_leg"`       // The timestamp the workout was recorded
+	UserID     uint              `gorm:"not null;index;uniqueIndex:idx_start_user_leg"` // The ID of the user who owns the workout
+	Dirty      bool              // Whether the workout has been modified and the details should be re-rendered
+	User       *User             // The user who owns the workout
+	Notes      string            // The notes associated with the workout, in markdown
+	Type       WorkoutType       // The type of the workout
+	Visibility WorkoutVisibility `gorm:"not null;default:instance"`                     // Who can see the workout
+	ShareToken *string           `gorm:"uniqueIndex" json:"-"`                          // The token of the public share link, if the workout is shared
+	Data       *MapData          `json:",omitempty"`                                    // The map data associated with the workout
+	GPX        *GPXData          `json:",omitempty"`                                    // The file data associated with the workout
+	Equipment  []Equipment       `json:",omitempty" gorm:"many2many:workout_equipment"` // Which equipment is used for this workout
+
+	SegmentEfforts []SegmentEffort   `json:"-"`          // The efforts on segments in this workout
+	Exercises      []WorkoutExercise `json:",omitempty"` // The exercises performed in this workout, in order
+
+	ParentID *uint     `gorm:"index" json:",omitempty"`                           // The ID of the multisport workout this workout is a leg of
+	Leg      int       `gorm:"not null;default:0;uniqueIndex:idx_start_user_leg"` // The position of the leg in its multisport workout, starting at 1; 0 for other workouts
+	Parent   *Workout  `json:"-"`                                                 // The multisport workout this workout is a leg of
+	Legs     []Workout `gorm:"foreignKey:ParentID" json:",omitempty"`             // The legs of a multisport workout, in order
+
+	ElevationSource ElevationSource `gorm:"not null;default:device"` // Where the elevation of the points comes from
+
+	DuplicateOfID         *uint `gorm:"index" json:",omitempty"` // The ID of the workout this workout looks like another recording of, until it is resolved
+	ExcludeFromStatistics bool  `gorm:"not null;default:false"`  // Whether the workout is left out of the statistics, totals and records
 }
 
 type GPXData struct {
@@ -40,6 +55,12 @@ type GPXData struct {
 	Content   []byte `gorm:"type:text"`            // The file content
 	Checksum  []byte `gorm:"not null;uniqueIndex"` // The checksum of the content
 	Filename  string // The filename of the file
+
+	OriginalContent  []byte       `gorm:"type:text" json:"-"` // The imported file, if the content was derived from it by trimming, splitting or merging
+	OriginalFilename string       `json:"-"`                  // The filename of the imported file
+	OriginalStart    *time.Time   `json:"-"`                  // The start of the part of the imported file the workout was split from; empty for the start of the file
+	OriginalEnd      *time.Time   `json:"-"`                  // The end of that part, exclusive; empty for the end of the file
+	MergedFiles      []MergedFile `json:"-"`                  // The files of the workouts that were merged into the workout
 }
 
 func (w *Workout) Filename() string {
@@ -58,8 +79,11 @@ func (w *Workout) HasFile() bool {
 	return w.GPX.Filename != "" && w.GPX.Content != nil
 }
 
+// HasTracks returns whether the workout has a track to show on a map; the
+// center is only calculated from points with real coordinates, so it is zero
+// for workouts recorded without a position (eg. indoors)
 func (w *Workout) HasTracks() bool {
-	if w.Data.Center.IsZero() {
+	if w.Data == nil || w.Data.Center.IsZero() {
 		return false
 	}
 
@@ -130,6 +154,13 @@ func (w *Workout) MarkdownNotes() template.HTML {
 	return template.HTML(safeHTML) //nolint:gosec // We escaped all unsafe HTML with bluemonday
 }
 
+func checksum(content []byte) []byte {
+	h := sha256.New()
+	h.Write(content)
+
+	return h.Sum(nil)
+}
+
 func (d *GPXData) Save(db *gorm.DB) error {
 	if d.Content == nil {
 		return ErrInvalidData
@@ -155,24 +186,39 @@ func NewWorkout(u *User, workoutType WorkoutType, notes string, filename string,
 		filename = data.Name + ".gpx"
 	}
 
-	h := sha256.New()
-	h.Write(content)
-
 	if workoutType == WorkoutTypeAutoDetect {
-		workoutType = autoDetectWorkoutType(data, gpxContent)
+		workoutType = u.autoDetectWorkoutType(data, gpxContent)
+	}
+
+	data.CleanTrack(workoutType, u.Profile.TrackCleaning)
+
+	elevationSource := defaultElevationSource(gpxContent.Creator)
+	data.UpdateElevation(elevationSource)
+
+	if err := data.setRecordedData(filename, content); err != nil {
+		return nil, err
 	}
 
+	data.UpdateHeartRate(u.Profile.HeartRate)
+	data.UpdatePower(u.Profile.FTP)
+	data.UpdateSwimming(workoutType)
+	data.UpdateBestEfforts()
+	data.UpdateClimbs(u.Profile.Climbs)
+
 	w := Workout{
-		User:   u,
-		UserID: u.ID,
-		Name:   gpxName(gpxContent),
-		Data:   data,
-		Notes:  notes,
-		Type:   workoutType,
-		Date:   gpxDate(gpxContent),
+		User:       u,
+		UserID:     u.ID,
+		Name:       gpxName(gpxContent),
+		Data:       data,
+		Notes:      notes,
+		Type:       workoutType,
+		Visibility: u.Profile.DefaultVisibility.OrDefault(),
+		Date:       gpxDate(gpxContent),
+
+		ElevationSource: elevationSource,
 		GPX: &GPXData{
 			Content:  content,
-			Checksum: h.Sum(nil),
+			Checksum: checksum(content),
 			Filename: filename,
 		},
 	}
@@ -200,17 +246,21 @@ func workoutTypeFromGpxTrackType(gpxType string) (WorkoutType, bool) {
 		return WorkoutTypeGolfing, true
 	case "hiking":
 		return WorkoutTypeHiking, true
+	case "multisport", "triathlon":
+		return WorkoutTypeMultisport, true
+	case "transition":
+		return WorkoutTypeTransition, true
 	default:
 		return WorkoutTypeAutoDetect, false
 	}
 }
 
-func autoDetectWorkoutType(data *MapData, gpxContent *gpx.GPX) WorkoutType {
+func (u *User) autoDetectWorkoutType(data *MapData, gpxContent *gpx.GPX) WorkoutType {
 	// If the GPX file mentions a workout type (for the first track), use it
 	if len(gpxContent.Tracks) > 0 {
 		firstTrack := &gpxContent.Tracks[0]
 
-		if workoutType, ok := workoutTypeFromGpxTrackType(firstTrack.Type); ok {
+		if workoutType, ok := u.workoutTypeFromSportName(firstTrack.Type); ok {
 			return workoutType
 		}
 	}
@@ -257,7 +307,7 @@ func GetWorkoutDetails(db *gorm.DB, id int) (*Workout, error) {
 func GetWorkout(db *gorm.DB, id int) (*Workout, error) {
 	var w Workout
 
-	if err := db.Preload("Data").Preload("User").Preload("Equipment").First(&w, id).Error; err != nil {
+	if err := preloadLegs(db).Preload("Data").Preload("User").Preload("Equipment").First(&w, id).Error; err != nil {
 		return nil, err
 	}
 
@@ -265,7 +315,53 @@ func GetWorkout(db *gorm.DB, id int) (*Workout, error) {
 }
 
 func (w *Workout) Delete(db *gorm.DB) error {
-	return db.Unscoped().Select("GPX", "Data").Delete(w).Error
+	if err := w.deleteExercises(db); err != nil {
+		return err
+	}
+
+	if err := w.deleteLegs(db); err != nil {
+		return err
+	}
+
+	if err := w.clearDuplicateMarks(db); err != nil {
+		return err
+	}
+
+	if err := w.deleteDataChildren(db); err != nil {
+		return err
+	}
+
+	if err := w.deleteMergedFiles(db); err != nil {
+		return err
+	}
+
+	return db.Unscoped().Select("GPX", "Data", "SegmentEfforts").Delete(w).Error
+}
+
+// deleteDataChildren removes the laps, best efforts, swim lengths and climbs
+// of the workout's map data, which are not deleted with the map data itself
+func (w *Workout) deleteDataChildren(db *gorm.DB) error {
+	if w.ID == 0 {
+		return nil
+	}
+
+	m := w.Data
+	if m == nil || m.ID == 0 {
+		m = &MapData{}
+		if err := db.Where(&MapData{WorkoutID: w.ID}).Limit(1).Find(m).Error; err != nil {
+			return err
+		}
+	}
+
+	for _, deleteChildren := range []func(*gorm.DB) error{
+		m.deleteLaps, m.deleteBestEfforts, m.deleteSwimLengths, m.deleteClimbs,
+	} {
+		if err := deleteChildren(db); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 func (w *Workout) Create(db *gorm.DB) error {
@@ -273,6 +369,10 @@ func (w *Workout) Create(db *gorm.DB) error {
 		return ErrInvalidData
 	}
 
+	if err := w.updateCalories(db); err != nil {
+		return err
+	}
+
 	return db.Create(w).Error
 }
 
@@ -281,11 +381,19 @@ func (w *Workout) Save(db *gorm.DB) error {
 		return ErrInvalidData
 	}
 
+	if err := w.updateCalories(db); err != nil {
+		return err
+	}
+
 	if err := w.Data.Save(db); err != nil {
 		return err
 	}
 
-	return db.Save(w).Error
+	if err := db.Save(w).Error; err != nil {
+		return err
+	}
+
+	return w.updateLegs(db)
 }
 
 func (w *Workout) AsGPX() (*gpx.GPX, error) {
@@ -310,10 +418,42 @@ func (w *Workout) setData(data *MapData) {
 	w.Data.CreatedAt = dataCreatedAt
 }
 
+// profile returns the profile of the owner of the workout
+func (w *Workout) profile(db *gorm.DB) (Profile, error) {
+	if w.User != nil && w.User.Profile.ID != 0 {
+		return w.User.Profile, nil
+	}
+
+	p := Profile{}
+	if err := db.Where(&Profile{UserID: w.UserID}).Limit(1).Find(&p).Error; err != nil {
+		return Profile{}, err
+	}
+
+	return p, nil
+}
+
+// heartRateSettings returns the heart rate settings of the owner of the
+// workout
+func (w *Workout) heartRateSettings(db *gorm.DB) (HeartRateSettings, error) {
+	p, err := w.profile(db)
+	if err != nil {
+		return HeartRateSettings{}, err
+	}
+
+	return p.HeartRate, nil
+}
+
 func (w *Workout) UpdateData(db *gorm.DB) error {
 	if !w.HasFile() {
-		// We only update data from (stored) GPX data
-		return nil
+		// Without a file, the data was entered by hand and is kept; only the
+		// calories depend on the user's settings and weight
+		if w.Data == nil {
+			return nil
+		}
+
+		w.Dirty = false
+
+		return w.Save(db)
 	}
 
 	gpxContent, err := w.AsGPX()
@@ -321,7 +461,44 @@ func (w *Workout) UpdateData(db *gorm.DB) error {
 		return err
 	}
 
-	w.setData(gpxAsMapData(gpxContent))
+	p, err := w.profile(db)
+	if err != nil {
+		return err
+	}
+
+	data := gpxAsMapData(gpxContent)
+	data.CleanTrack(w.Type, p.TrackCleaning)
+	data.UpdateElevation(w.ElevationSource)
+
+	if err := w.setRecordedData(db, data); err != nil {
+		return err
+	}
+
+	data.UpdateHeartRate(p.HeartRate)
+	data.UpdatePower(p.FTP)
+	data.UpdateSwimming(w.Type)
+	data.UpdateBestEfforts()
+	data.UpdateClimbs(p.Climbs)
+
+	if w.Data != nil {
+		if err := w.Data.deleteLaps(db); err != nil {
+			return err
+		}
+
+		if err := w.Data.deleteBestEfforts(db); err != nil {
+			return err
+		}
+
+		if err := w.Data.deleteSwimLengths(db); err != nil {
+			return err
+		}
+
+		if err := w.Data.deleteClimbs(db); err != nil {
+			return err
+		}
+	}
+
+	w.setData(data)
 
 	if err := w.Data.Save(db); err != nil {
 		return err
@@ -329,7 +506,11 @@ func (w *Workout) UpdateData(db *gorm.DB) error {
 
 	w.Dirty = false
 
-	return w.Save(db)
+	if err := w.Save(db); err != nil {
+		return err
+	}
+
+	return w.MatchSegments(db)
 }
 
 func (w *Workout) HasElevation() bool {
@@ -344,6 +525,10 @@ func (w *Workout) HasHeartRate() bool {
 	return w.HasExtraMetric("heart-rate")
 }
 
+func (w *Workout) HasPower() bool {
+	return w.HasExtraMetric("power")
+}
+
 func (w *Workout) HasHeading() bool {
 	return w.HasExtraMetric("heading")
 }
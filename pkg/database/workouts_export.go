@@ -0,0 +1,76 @@
+package database
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/jovandeginste/workout-tracker/pkg/converters"
+)
+
+// AsActivity converts the workout to a format independent activity, which can
+// be exported; the details of the workout should be loaded
+func (w *Workout) AsActivity() *converters.Activity {
+	a := &converters.Activity{
+		Name:  w.Name,
+		Type:  w.Type.String(),
+		Notes: w.Notes,
+	}
+
+	if w.Date != nil {
+		a.Start = *w.Date
+	}
+
+	if w.Data == nil {
+		return a
+	}
+
+	a.TotalDistance = w.Data.TotalDistance
+	a.TotalDuration = w.Data.TotalDuration
+
+	if w.Data.Details == nil {
+		return a
+	}
+
+	for _, p := range w.Data.Details.Points {
+		a.Points = append(a.Points, converters.ActivityPoint{
+			Time:          p.Time,
+			Lat:           p.Lat,
+			Lng:           p.Lng,
+			TotalDistance: p.TotalDistance,
+			Elevation:     p.ExtraMetrics.GetPtr("elevation"),
+			HeartRate:     p.ExtraMetrics.GetPtr("heart-rate"),
+			Cadence:       p.ExtraMetrics.GetPtr("cadence"),
+			Power:         p.ExtraMetrics.GetPtr("power"),
+		})
+	}
+
+	return a
+}
+
+// Export converts the workout to the given format
+func (w *Workout) Export(format converters.ExportFormat) ([]byte, error) {
+	return converters.Export(format, w.AsActivity())
+}
+
+// ExportFilename returns the name of the file when exporting the workout to
+// the given format
+func (w *Workout) ExportFilename(format converters.ExportFormat) string {
+	name := w.Name
+	if w.HasFile() {
+		name = strings.TrimSuffix(w.GPX.Filename, filepath.Ext(w.GPX.Filename))
+	}
+
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`/\:*?"<>|`, r) {
+			return '_'
+		}
+
+		return r
+	}, name)
+
+	if name == "" {
+		name = "workout"
+	}
+
+	return name + format.Extension()
+}
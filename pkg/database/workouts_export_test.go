@@ -0,0 +1,129 @@
+package database
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jovandeginste/workout-tracker/pkg/converters"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkout_Export(t *testing.T) {
+	populateGPXFS()
+
+	w := defaultWorkout(t)
+	w.Data.Details.Points[0].ExtraMetrics.Set("heart-rate", 142)
+	w.Data.Details.Points[0].ExtraMetrics.Set("cadence", 85)
+	w.Data.Details.Points[0].ExtraMetrics.Set("power", 250)
+
+	for _, f := range converters.ExportFormats() {
+		t.Run(f.String(), func(t *testing.T) {
+			content, err := w.Export(f)
+			require.NoError(t, err)
+
+			g, err := converters.Parse(w.ExportFilename(f), content)
+			require.NoError(t, err)
+
+			data := gpxAsMapData(g)
+			require.NotNil(t, data.Details)
+			assert.Len(t, data.Details.Points, len(w.Data.Details.Points))
+			assert.InDelta(t, w.Data.TotalDistance, data.TotalDistance, 1)
+
+			first := w.Data.Details.Points[0]
+			assert.InDelta(t, first.Lat, data.Details.Points[0].Lat, 0.0001)
+			assert.InDelta(t, first.Lng, data.Details.Points[0].Lng, 0.0001)
+			assert.True(t, first.Time.Equal(data.Details.Points[0].Time))
+
+			if f == converters.ExportFormatTCX {
+				// The TCX parser does not read the extensions either
+				assert.Contains(t, string(content), `xmlns:ns3="http://www.garmin.com/xmlschemas/ActivityExtension/v2"`)
+				assert.Contains(t, string(content), "<ns3:TPX>\n")
+				assert.Contains(t, string(content), "<ns3:Watts>250</ns3:Watts>")
+			}
+
+			if f != converters.ExportFormatTCX {
+				// The TCX parser does not read heart rate or cadence
+				assert.InDelta(t, 142, data.Details.Points[0].ExtraMetrics.Get("heart-rate"), 0.1)
+				assert.InDelta(t, 85, data.Details.Points[0].ExtraMetrics.Get("cadence"), 0.1)
+				assert.InDelta(t, 250, data.Details.Points[0].ExtraMetrics.Get("power"), 0.1)
+			}
+		})
+	}
+}
+
+func TestWorkout_ExportManual(t *testing.T) {
+	d := time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC)
+	w := &Workout{
+		Name: "Manual/run",
+		Type: WorkoutTypeRunning,
+		Date: &d,
+		Data: &MapData{TotalDistance: 5000, TotalDuration: 30 * time.Minute},
+	}
+
+	assert.Equal(t, "Manual_run.fit", w.ExportFilename(converters.ExportFormatFIT))
+
+	for _, f := range converters.ExportFormats() {
+		content, err := w.Export(f)
+		require.NoError(t, err, f)
+		assert.NotEmpty(t, content, f)
+	}
+
+	content, err := w.Export(converters.ExportFormatTCX)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "<DistanceMeters>5000</DistanceMeters>")
+	assert.Contains(t, string(content), `Sport="Running"`)
+
+	_, err = w.Export("kml")
+	require.ErrorIs(t, err, converters.ErrUnsupportedFormat)
+}
+
+func TestWorkout_ExportWithoutPositions(t *testing.T) {
+	db := createMemoryDB(t)
+
+	u := defaultUser()
+	require.NoError(t, u.Create(db))
+
+	start := time.Date(2024, 3, 4, 18, 0, 0, 0, time.UTC)
+	hr := 150.0
+	a := &converters.Activity{
+		Name:          "Treadmill",
+		Type:          "running",
+		Start:         start,
+		TotalDistance: 1000,
+		TotalDuration: 5 * time.Minute,
+	}
+
+	for i := range 6 {
+		a.Points = append(a.Points, converters.ActivityPoint{
+			Time:          start.Add(time.Duration(i) * time.Minute),
+			TotalDistance: float64(i) * 200,
+			HeartRate:     &hr,
+		})
+	}
+
+	content, err := converters.ExportFIT(a)
+	require.NoError(t, err)
+
+	w, err := u.AddWorkout(db, WorkoutTypeRunning, "", "treadmill.fit", content)
+	require.NoError(t, err)
+
+	w, err = GetWorkoutDetails(db, int(w.ID))
+	require.NoError(t, err)
+	require.Len(t, w.Data.Details.Points, len(a.Points))
+
+	for f, point := range map[converters.ExportFormat]string{
+		converters.ExportFormatGPX: "<trkpt>",
+		converters.ExportFormatTCX: "<Trackpoint>",
+	} {
+		t.Run(f.String(), func(t *testing.T) {
+			content, err := w.Export(f)
+			require.NoError(t, err)
+
+			assert.Equal(t, len(a.Points), strings.Count(string(content), point))
+			assert.NotContains(t, string(content), "lat=")
+			assert.NotContains(t, string(content), "<Position>")
+		})
+	}
+}
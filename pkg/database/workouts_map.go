@@ -16,6 +16,9 @@ import (
 
 var online = true
 
+// stoppedSpeedThreshold is the speed below which a workout is paused, in km/h
+const stoppedSpeedThreshold = 1.0
+
 var correctAltitudeCreators = []string{
 	"Garmin", "Garmin Connect",
 	"Apple Watch",
@@ -69,8 +72,33 @@ type MapData struct {
 	TotalUp          float64         // The total distance up of the workout
 	TotalDown        float64         // The total distance down of the workout
 	Details          *MapDataDetails `json:",omitempty"` // The details of the workout
+	Laps             []Lap           `json:",omitempty"` // The laps of the workout, as recorded by the device
+	BestEfforts      []BestEffort    `json:",omitempty"` // The best efforts of the workout over standard distances and durations
+	Climbs           []Climb         `json:",omitempty"` // The categorised climbs of the workout
 	TotalRepetitions int             // The number of repetitions of the workout
 	TotalWeight      float64         // The weight of the workout
+	AverageHeartRate float64         // The average heart rate of the workout, in beats per minute
+	MaxHeartRate     float64         // The maximum heart rate of the workout, in beats per minute
+	TimeInZones      []time.Duration `gorm:"serializer:json" json:",omitempty"` // The time spent in each heart rate zone
+	TrainingLoad     float64         // The training load (TRIMP) of the workout
+	Calories         float64         // The energy expenditure of the workout, in kilocalories
+	CaloriesSource   CaloriesSource  // Where the energy expenditure comes from
+
+	AveragePower        float64           // The average power of the workout, in watts
+	NormalizedPower     float64           // The normalized power of the workout, in watts
+	MaxPower            float64           // The maximum power of the workout, in watts
+	PowerCurve          []PowerCurvePoint `gorm:"serializer:json" json:",omitempty"` // The best average power over standard durations
+	IntensityFactor     float64           // The normalized power as a fraction of the functional threshold power
+	TrainingStressScore float64           // The training stress score (TSS) of the workout
+
+	PoolLength        float64      // The length of the pool of a pool swim, in meters
+	PoolLengthYards   bool         // Whether the pool length was set in yards
+	Lengths           []SwimLength `json:",omitempty"` // The lengths of a pool swim, as recorded by the device
+	AverageStrokeRate float64      // The average stroke rate of a swim, in strokes per minute
+
+	RawStats *RawTrackStats `gorm:"serializer:json" json:",omitempty"` // The totals of the track before cleaning; nil if the track was not cleaned
+
+	DEMElevation bool // Whether the elevation comes from the DEM tiles instead of the device
 }
 
 type MapDataDetails struct {
@@ -162,14 +190,21 @@ func center(gpxContent *gpx.GPX) MapCenter {
 		return MapCenter{}
 	}
 
-	lat, lng := 0.0, 0.0
+	lat, lng, size := 0.0, 0.0, 0.0
 
 	for _, pt := range points {
+		if !pointHasDistance(pt) {
+			continue
+		}
+
 		lat += pt.Point.Latitude
 		lng += pt.Point.Longitude
+		size++
 	}
 
-	size := float64(len(points))
+	if size == 0 {
+		return MapCenter{}
+	}
 
 	return MapCenter{
 		Lat: lat / size,
@@ -177,6 +212,11 @@ func center(gpxContent *gpx.GPX) MapCenter {
 	}
 }
 
+// HasPosition returns whether the point was recorded with a position
+func (m *MapPoint) HasPosition() bool {
+	return m.Lat != 0 || m.Lng != 0
+}
+
 func (m *MapCenter) IsZero() bool {
 	return m.Lat == 0 && m.Lng == 0
 }
@@ -199,25 +239,22 @@ func (m *MapCenter) Address() *geo.Address {
 	return r
 }
 
-// allGPXPoints returns the first track segment's points
+// allGPXPoints returns the points of all track segments, including the points
+// without a position
 func allGPXPoints(gpxContent *gpx.GPX) []gpx.GPXPoint {
 	var points []gpx.GPXPoint
 
 	for _, track := range gpxContent.Tracks {
 		for _, segment := range track.Segments {
-			for _, p := range segment.Points {
-				if !pointHasDistance(p) {
-					continue
-				}
-
-				points = append(points, p)
-			}
+			points = append(points, segment.Points...)
 		}
 	}
 
 	return points
 }
 
+// pointHasDistance returns whether the point has a position, so the distance
+// to other points can be calculated
 func pointHasDistance(p gpx.GPXPoint) bool {
 	if math.IsNaN(p.Latitude) || math.IsNaN(p.Longitude) {
 		return false
@@ -261,6 +298,43 @@ func distanceBetween(p1 gpx.GPXPoint, p2 gpx.GPXPoint) float64 {
 	return gpx.HaversineDistance(p1.Latitude, p1.Longitude, p2.Latitude, p2.Longitude)
 }
 
+// pointDistance returns the distance from the previous point; when one of the
+// points has no position, the distance or speed recorded by the device is
+// used instead, or else the speed of the previous interval
+func pointDistance(prev, pt gpx.GPXPoint, prevMetrics, ptMetrics ExtraMetrics, seconds, prevSpeed float64) float64 {
+	if pointHasDistance(prev) && pointHasDistance(pt) {
+		return distanceBetween(prev, pt)
+	}
+
+	d, ok := ptMetrics["distance"]
+	prevD, prevOK := prevMetrics["distance"]
+
+	if ok && prevOK {
+		return max(0, d-prevD)
+	}
+
+	if v, ok := ptMetrics["speed"]; ok {
+		return v * seconds
+	}
+
+	return prevSpeed * seconds
+}
+
+// positionedSegment returns the points of the segment that have a position
+func positionedSegment(segment gpx.GPXTrackSegment) gpx.GPXTrackSegment {
+	points := make([]gpx.GPXPoint, 0, len(segment.Points))
+
+	for _, p := range segment.Points {
+		if pointHasDistance(p) {
+			points = append(points, p)
+		}
+	}
+
+	segment.Points = points
+
+	return segment
+}
+
 func createMapData(gpxContent *gpx.GPX) *MapData {
 	if len(gpxContent.Tracks) == 0 {
 		return nil
@@ -279,8 +353,16 @@ func createMapData(gpxContent *gpx.GPX) *MapData {
 				continue
 			}
 
-			totalDistance += segment.Length3D()
 			totalDuration += time.Duration(segment.Duration()) * time.Second
+
+			// Points without a position are left out here, and accounted for
+			// from the points of the map data
+			segment := positionedSegment(segment)
+			if len(segment.Points) == 0 {
+				continue
+			}
+
+			totalDistance += segment.Length3D()
 			pauseDuration += (time.Duration(segment.MovingData().StoppedTime)) * time.Second
 			minElevation = min(minElevation, segment.ElevationBounds().MinElevation)
 			maxElevation = max(maxElevation, segment.ElevationBounds().MaxElevation)
@@ -319,6 +401,103 @@ func createMapData(gpxContent *gpx.GPX) *MapData {
 	return data
 }
 
+// updateFromPoints calculates the distance, maximum speed and pause duration
+// from the points, for workouts where no point has a position
+func (m *MapData) updateFromPoints() {
+	m.TotalDistance, m.MaxSpeed, m.PauseDuration = 0, 0, 0
+
+	m.addPointTotals(m.Details.Points)
+}
+
+// addPointTotals adds the distance, maximum speed and pause duration of the
+// points to the totals
+func (m *MapData) addPointTotals(points []MapPoint) {
+	for _, p := range points {
+		m.TotalDistance += p.Distance
+
+		if p.Duration <= 0 {
+			continue
+		}
+
+		speed := p.AverageSpeed()
+		m.MaxSpeed = max(m.MaxSpeed, speed)
+
+		if speed*3.6 <= stoppedSpeedThreshold {
+			m.PauseDuration += p.Duration
+		}
+	}
+}
+
+// updateGaps accounts for the points without a position in a track that has
+// positions elsewhere. Gaps at the start or end of the track are added to the
+// totals from what the device recorded. A gap in the middle of the track is
+// bridged by the track totals in a straight line: when the device recorded
+// less than that, the straight line is spread over the points of the gap,
+// and when it recorded more, the difference is added to the total distance.
+func (m *MapData) updateGaps(positioned []bool) {
+	points := m.Details.Points
+	last := -1
+
+	for i := range points {
+		if !positioned[i] {
+			continue
+		}
+
+		if i > last+1 {
+			m.updateGap(last, i)
+		}
+
+		last = i
+	}
+
+	if last < len(points)-1 {
+		m.addPointTotals(points[last+1:])
+	}
+
+	totalDistance := 0.0
+
+	for i := range points {
+		totalDistance += points[i].Distance
+		points[i].TotalDistance = totalDistance
+	}
+}
+
+// updateGap accounts for the points without a position between the points
+// with index from and to; from is -1 for a gap at the start of the track
+func (m *MapData) updateGap(from, to int) {
+	gap := m.Details.Points[from+1 : to+1]
+
+	if from < 0 {
+		m.addPointTotals(gap)
+		return
+	}
+
+	recorded := 0.0
+	duration := time.Duration(0)
+
+	for _, p := range gap {
+		recorded += p.Distance
+		duration += p.Duration
+	}
+
+	a, b := m.Details.Points[from], m.Details.Points[to]
+	straight := gpx.HaversineDistance(a.Lat, a.Lng, b.Lat, b.Lng)
+
+	if recorded >= straight {
+		m.TotalDistance += recorded - straight
+		return
+	}
+
+	for i := range gap {
+		switch {
+		case duration > 0:
+			gap[i].Distance += (straight - recorded) * gap[i].Duration.Seconds() / duration.Seconds()
+		case i == len(gap)-1:
+			gap[i].Distance += straight - recorded
+		}
+	}
+}
+
 func (m *MapData) correctNaN() {
 	if math.IsNaN(m.MinElevation) {
 		m.MinElevation = 0
@@ -352,34 +531,52 @@ func gpxAsMapData(gpxContent *gpx.GPX) *MapData {
 	totalDist := 0.0
 	totalTime := 0.0
 	prevPoint := points[0]
+	prevMetrics := ExtraMetrics{}
+	prevSpeed := 0.0
+	positioned := make([]bool, len(points))
 
 	data.Details = &MapDataDetails{}
 
 	for i, pt := range points {
-		if !pointHasDistance(pt) {
-			continue
+		lat, lng := pt.Point.Latitude, pt.Point.Longitude
+		elevation := pt.Elevation.Value()
+
+		positioned[i] = pointHasDistance(pt)
+
+		if positioned[i] {
+			elevation = correctAltitude(gpxContent.Creator, lat, lng, elevation)
+		} else {
+			// Points without a position are stored at (0, 0), like the center
+			// of a workout without a position
+			lat, lng = 0, 0
 		}
 
+		extraMetrics := ExtraMetrics{}
+		extraMetrics.Set("elevation", elevation)
+		extraMetrics.ParseGPXExtensions(pt.Extensions)
+
 		dist := 0.0
 		t := 0.0
 
 		if i > 0 {
-			dist = distanceBetween(prevPoint, pt)
 			t = pt.TimeDiff(&prevPoint)
+			dist = pointDistance(prevPoint, pt, prevMetrics, extraMetrics, t, prevSpeed)
+
+			if t > 0 {
+				prevSpeed = dist / t
+			}
 
 			prevPoint = pt
 		}
 
+		prevMetrics = extraMetrics
+
 		totalDist += dist
 		totalTime += t
 
-		extraMetrics := ExtraMetrics{}
-		extraMetrics.Set("elevation", correctAltitude(gpxContent.Creator, pt.Point.Latitude, pt.Point.Longitude, pt.Elevation.Value()))
-		extraMetrics.ParseGPXExtensions(pt.Extensions)
-
 		data.Details.Points = append(data.Details.Points, MapPoint{
-			Lat:           pt.Point.Latitude,
-			Lng:           pt.Point.Longitude,
+			Lat:           lat,
+			Lng:           lng,
 			Time:          pt.Timestamp,
 			Distance:      dist,
 			TotalDistance: totalDist,
@@ -389,5 +586,12 @@ func gpxAsMapData(gpxContent *gpx.GPX) *MapData {
 		})
 	}
 
+	switch {
+	case !slices.Contains(positioned, true):
+		data.updateFromPoints()
+	case slices.Contains(positioned, false):
+		data.updateGaps(positioned)
+	}
+
 	return data
 }
@@ -2,7 +2,9 @@ package database
 
 import (
 	"testing"
+	"time"
 
+	"github.com/jovandeginste/workout-tracker/pkg/converters"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -66,6 +68,88 @@ func TestWorkout_Parse(t *testing.T) {
 	assert.Nil(t, w.Data.Address)
 }
 
+func TestWorkout_ParseWithoutPosition(t *testing.T) {
+	start := time.Date(2024, 1, 2, 18, 0, 0, 0, time.UTC)
+	hr := 140.0
+	a := &converters.Activity{Name: "Treadmill", Type: "running", Start: start, TotalDuration: 10 * time.Minute}
+
+	// A treadmill run at 3 m/s, with a pause in the middle
+	dist := 0.0
+
+	for i := range 61 {
+		if i > 0 && (i < 20 || i > 25) {
+			dist += 30
+		}
+
+		a.Points = append(a.Points, converters.ActivityPoint{
+			Time:          start.Add(time.Duration(i) * 10 * time.Second),
+			TotalDistance: dist,
+			HeartRate:     &hr,
+		})
+	}
+
+	content, err := converters.ExportFIT(a)
+	require.NoError(t, err)
+
+	w, err := NewWorkout(defaultUser(), WorkoutTypeAutoDetect, "", "treadmill.fit", content)
+	require.NoError(t, err)
+
+	assert.Equal(t, WorkoutTypeRunning, w.Type)
+	assert.False(t, w.HasTracks())
+	assert.True(t, w.Data.Center.IsZero())
+
+	require.Len(t, w.Data.Details.Points, 61)
+	assert.False(t, w.Data.Details.Points[1].HasPosition())
+	assert.InDelta(t, 140, w.Data.Details.Points[1].ExtraMetrics.Get("heart-rate"), 0.1)
+
+	assert.InDelta(t, dist, w.Data.TotalDistance, 1)
+	assert.InDelta(t, 3, w.Data.MaxSpeed, 0.01)
+	assert.Equal(t, time.Minute, w.Data.PauseDuration)
+	assert.Equal(t, 10*time.Minute, w.Data.TotalDuration)
+}
+
+func TestWorkout_ParseWithPositionGaps(t *testing.T) {
+	start := time.Date(2024, 1, 2, 18, 0, 0, 0, time.UTC)
+	a := &converters.Activity{Name: "Run", Type: "running", Start: start, TotalDuration: 5 * time.Minute}
+
+	// A run north at 3 m/s; the GPS has no fix for the first 100 seconds and
+	// loses it again in a tunnel later on
+	dist := 0.0
+
+	for i := range 31 {
+		if i > 0 {
+			dist += 30
+		}
+
+		p := converters.ActivityPoint{Time: start.Add(time.Duration(i) * 10 * time.Second), TotalDistance: dist}
+		if i >= 10 && (i < 15 || i > 19) {
+			p.Lat, p.Lng = 51+(dist-270)/111195, 4
+		}
+
+		a.Points = append(a.Points, p)
+	}
+
+	content, err := converters.ExportFIT(a)
+	require.NoError(t, err)
+
+	w, err := NewWorkout(defaultUser(), WorkoutTypeRunning, "", "run.fit", content)
+	require.NoError(t, err)
+
+	assert.True(t, w.HasTracks())
+	require.Len(t, w.Data.Details.Points, 31)
+	assert.False(t, w.Data.Details.Points[9].HasPosition())
+	assert.True(t, w.Data.Details.Points[10].HasPosition())
+
+	// The first point with a position continues at the speed of the gap
+	assert.InDelta(t, 30, w.Data.Details.Points[10].Distance, 1)
+
+	assert.InDelta(t, dist, w.Data.TotalDistance, 5)
+	assert.InDelta(t, dist, w.Data.Details.Points[30].TotalDistance, 5)
+	assert.InDelta(t, 3, w.Data.MaxSpeed, 0.2)
+	assert.Zero(t, w.Data.PauseDuration)
+	assert.Equal(t, 5*time.Minute, w.Data.TotalDuration)
+}
+
 func TestWorkout_UpdateData(t *testing.T) {
 	db := createMemoryDB(t)
 	w := defaultWorkout(t)
@@ -0,0 +1,188 @@
+// Package dem looks up elevations in digital elevation model (DEM) tiles on
+// the local disk, eg. SRTM tiles (HGT files) or Copernicus DEM tiles (GeoTIFF
+// files). Tiles are never downloaded; elevations are relative to the geoid,
+// like the elevations in the tiles.
+package dem
+
+import (
+	"errors"
+	"io/fs"
+	"log/slog"
+	"math"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+var (
+	d                *directory
+	ErrNotConfigured = errors.New("dem: tiles directory not set")
+	ErrNoTile        = errors.New("dem: no tile covers the location")
+	ErrNoData        = errors.New("dem: the tile has no data for the location")
+	ErrUnsupported   = errors.New("dem: unsupported tile format")
+)
+
+// maxCachedTiles is the number of tiles that are kept in memory; a tile with 1
+// arc second resolution is about 50MB
+const maxCachedTiles = 4
+
+type directory struct {
+	path   string
+	logger *slog.Logger
+
+	m       sync.Mutex
+	indexed bool
+	hgt     map[string]string // The HGT files, by lower case base name
+	tiffs   []*tiffFile       // The GeoTIFF files, with their bounds
+	rasters map[string]*raster
+}
+
+// SetDirectory sets the directory with the DEM tiles; subdirectories are
+// searched too. An empty path disables DEM lookups.
+func SetDirectory(l *slog.Logger, path string) {
+	if path == "" {
+		d = nil
+		return
+	}
+
+	if l == nil {
+		l = slog.Default()
+	}
+
+	d = &directory{
+		path:   path,
+		logger: l,
+	}
+}
+
+// Enabled returns whether a tiles directory is set
+func Enabled() bool {
+	return d != nil
+}
+
+// Elevation returns the elevation at the location, interpolated between the
+// samples of the tile that covers it
+func Elevation(lat, lng float64) (float64, error) {
+	if d == nil {
+		return 0, ErrNotConfigured
+	}
+
+	if math.IsNaN(lat) || math.IsNaN(lng) {
+		return 0, ErrNoTile
+	}
+
+	r, err := d.raster(lat, lng)
+	if err != nil {
+		return 0, err
+	}
+
+	return r.elevation(lat, lng)
+}
+
+// raster returns the raster of the tile that covers the location, reading it
+// from disk if it is not cached
+func (dir *directory) raster(lat, lng float64) (*raster, error) {
+	dir.m.Lock()
+	defer dir.m.Unlock()
+
+	if err := dir.index(); err != nil {
+		return nil, err
+	}
+
+	path, load := dir.tile(lat, lng)
+	if path == "" {
+		return nil, ErrNoTile
+	}
+
+	if r, ok := dir.rasters[path]; ok {
+		return r, nil
+	}
+
+	r, err := load()
+	if err != nil {
+		dir.logger.Warn("Could not read DEM tile " + path + ": " + err.Error())
+		return nil, err
+	}
+
+	if len(dir.rasters) >= maxCachedTiles {
+		clear(dir.rasters)
+	}
+
+	dir.rasters[path] = r
+
+	return r, nil
+}
+
+// tile returns the path of the tile that covers the location, and the function
+// to read it; HGT tiles are preferred, since their name gives their bounds
+func (dir *directory) tile(lat, lng float64) (string, func() (*raster, error)) {
+	// Locations on the edge of a tile are also on the edge of the neighbouring
+	// tiles
+	for _, south := range edges(lat) {
+		for _, west := range edges(lng) {
+			if p, ok := dir.hgt[hgtName(south, west)]; ok {
+				return p, func() (*raster, error) { return readHGT(p, south, west) }
+			}
+		}
+	}
+
+	for _, t := range dir.tiffs {
+		if t.covers(lat, lng) {
+			return t.path, t.read
+		}
+	}
+
+	return "", nil
+}
+
+// index searches the directory for tiles, once
+func (dir *directory) index() error {
+	if dir.indexed {
+		return nil
+	}
+
+	dir.hgt = map[string]string{}
+	dir.rasters = map[string]*raster{}
+
+	err := filepath.WalkDir(dir.path, func(p string, e fs.DirEntry, err error) error {
+		if err != nil || e.IsDir() {
+			return err
+		}
+
+		name := strings.ToLower(e.Name())
+
+		switch filepath.Ext(name) {
+		case ".hgt":
+			dir.hgt[name] = p
+		case ".tif", ".tiff":
+			t, err := openTIFF(p)
+			if err != nil {
+				dir.logger.Warn("Skipping DEM tile " + p + ": " + err.Error())
+				return nil
+			}
+
+			dir.tiffs = append(dir.tiffs, t)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	dir.logger.Info("Found DEM tiles", "hgt", len(dir.hgt), "geotiff", len(dir.tiffs))
+	dir.indexed = true
+
+	return nil
+}
+
+// edges returns the south-west corners, in one dimension, of the tiles that
+// cover the coordinate
+func edges(v float64) []float64 {
+	f := math.Floor(v)
+	if f == v {
+		return []float64{f, f - 1}
+	}
+
+	return []float64{f}
+}
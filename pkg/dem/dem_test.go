@@ -0,0 +1,278 @@
+package dem
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeHGT writes an HGT tile with size samples per row; the value of a sample
+// is given by its row and column
+func writeHGT(t *testing.T, dir, name string, size int, value func(row, col int) int16) {
+	t.Helper()
+
+	content := make([]byte, 2*size*size)
+
+	for row := range size {
+		for col := range size {
+			binary.BigEndian.PutUint16(content[2*(row*size+col):], uint16(value(row, col)))
+		}
+	}
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), content, 0o600))
+}
+
+type testTIFF struct {
+	width, height int
+	west, north   float64 // The corner of the first pixel
+	step          float64 // The size of the pixels, in degrees
+	int16         bool    // Whether the samples are 16 bit integers instead of 32 bit floats
+	deflate       bool
+	predictor     int
+	value         func(row, col int) float64
+}
+
+// samples returns the samples of a row, with the predictor applied
+func (tt *testTIFF) samples(row int) []byte {
+	var b []byte
+
+	switch {
+	case tt.int16:
+		prev := uint16(0)
+
+		for col := range tt.width {
+			v := uint16(int16(tt.value(row, col)))
+			d := v
+
+			if tt.predictor == predictorHorizontal {
+				d = v - prev
+				prev = v
+			}
+
+			b = binary.LittleEndian.AppendUint16(b, d)
+		}
+	case tt.predictor == predictorFloat:
+		b = make([]byte, 4*tt.width)
+
+		for col := range tt.width {
+			bits := math.Float32bits(float32(tt.value(row, col)))
+			for i := range 4 {
+				b[i*tt.width+col] = byte(bits >> (24 - 8*i))
+			}
+		}
+
+		for i := len(b) - 1; i > 0; i-- {
+			b[i] -= b[i-1]
+		}
+	default:
+		for col := range tt.width {
+			b = binary.LittleEndian.AppendUint32(b, math.Float32bits(float32(tt.value(row, col))))
+		}
+	}
+
+	return b
+}
+
+// write writes the tile as a little-endian GeoTIFF with a single strip
+func (tt *testTIFF) write(t *testing.T, path string) {
+	t.Helper()
+
+	var data []byte
+	for row := range tt.height {
+		data = append(data, tt.samples(row)...)
+	}
+
+	compression := compressionNone
+	if tt.deflate {
+		compression = compressionDeflate
+
+		var buf bytes.Buffer
+
+		z := zlib.NewWriter(&buf)
+		_, err := z.Write(data)
+		require.NoError(t, err)
+		require.NoError(t, z.Close())
+
+		data = buf.Bytes()
+	}
+
+	bits, format := 32, sampleFormatFloat
+	if tt.int16 {
+		bits, format = 16, sampleFormatInt
+	}
+
+	predictor := max(tt.predictor, predictorNone)
+
+	type entry struct {
+		tag, typ uint16
+		count    uint32
+		value    []byte
+	}
+
+	le := binary.LittleEndian
+	short := func(v int) []byte { return le.AppendUint16(nil, uint16(v)) }
+	long := func(v int) []byte { return le.AppendUint32(nil, uint32(v)) }
+	doubles := func(v ...float64) []byte {
+		var b []byte
+		for _, f := range v {
+			b = le.AppendUint64(b, math.Float64bits(f))
+		}
+
+		return b
+	}
+
+	entries := []entry{
+		{tagImageWidth, 4, 1, long(tt.width)},
+		{tagImageLength, 4, 1, long(tt.height)},
+		{tagBitsPerSample, 3, 1, short(bits)},
+		{tagCompression, 3, 1, short(compression)},
+		{tagStripOffsets, 4, 1, nil},
+		{tagSamplesPerPixel, 3, 1, short(1)},
+		{tagRowsPerStrip, 4, 1, long(tt.height)},
+		{tagStripByteCounts, 4, 1, long(len(data))},
+		{tagPredictor, 3, 1, short(predictor)},
+		{tagSampleFormat, 3, 1, short(format)},
+		{tagModelPixelScale, 12, 3, doubles(tt.step, tt.step, 0)},
+		{tagModelTiepoint, 12, 6, doubles(0, 0, 0, tt.west, tt.north, 0)},
+		{tagGDALNoData, 2, 7, []byte("-9999\x00\x00")},
+	}
+
+	extra := 8 + 2 + 12*len(entries) + 4
+
+	var ifd, values []byte
+
+	ifd = le.AppendUint16(ifd, uint16(len(entries)))
+
+	for _, e := range entries {
+		if e.tag == tagStripOffsets {
+			e.value = long(extra + 1000)
+		}
+
+		ifd = le.AppendUint16(ifd, e.tag)
+		ifd = le.AppendUint16(ifd, e.typ)
+		ifd = le.AppendUint32(ifd, e.count)
+
+		if len(e.value) <= 4 {
+			ifd = append(ifd, append(e.value, make([]byte, 4-len(e.value))...)...)
+			continue
+		}
+
+		ifd = le.AppendUint32(ifd, uint32(extra+len(values)))
+		values = append(values, e.value...)
+	}
+
+	require.Less(t, len(values), 1000)
+
+	content := []byte("II")
+	content = le.AppendUint16(content, 42)
+	content = le.AppendUint32(content, 8)
+	content = append(content, ifd...)
+	content = le.AppendUint32(content, 0)
+	content = append(content, values...)
+	content = append(content, make([]byte, extra+1000-len(content))...)
+	content = append(content, data...)
+
+	require.NoError(t, os.WriteFile(path, content, 0o600))
+}
+
+func setDirectory(t *testing.T, dir string) {
+	t.Helper()
+
+	SetDirectory(nil, dir)
+	t.Cleanup(func() { SetDirectory(nil, "") })
+}
+
+func TestElevation_NotConfigured(t *testing.T) {
+	SetDirectory(nil, "")
+
+	assert.False(t, Enabled())
+
+	_, err := Elevation(50.5, 4.5)
+	require.ErrorIs(t, err, ErrNotConfigured)
+}
+
+func TestHGTName(t *testing.T) {
+	assert.Equal(t, "n50e004.hgt", hgtName(50.5, 4.5))
+	assert.Equal(t, "s23w044.hgt", hgtName(-22.9, -43.2))
+	assert.Equal(t, "n00w001.hgt", hgtName(0.5, -0.5))
+}
+
+func TestElevation_HGT(t *testing.T) {
+	dir := t.TempDir()
+	writeHGT(t, dir, "N50E004.hgt", 11, func(row, col int) int16 {
+		if row == 0 && col == 10 {
+			return hgtVoid
+		}
+
+		return int16(1000 - 10*row + col)
+	})
+	setDirectory(t, dir)
+
+	// Samples are 0.1 degree apart, from the north-west corner
+	e, err := Elevation(50.5, 4.5)
+	require.NoError(t, err)
+	assert.InDelta(t, 955, e, 0.001)
+
+	e, err = Elevation(50.55, 4.25)
+	require.NoError(t, err)
+	assert.InDelta(t, 957.5, e, 0.001)
+
+	// Void samples are left out
+	e, err = Elevation(51, 4.95)
+	require.NoError(t, err)
+	assert.InDelta(t, 1009, e, 0.001)
+
+	_, err = Elevation(52.5, 4.5)
+	require.ErrorIs(t, err, ErrNoTile)
+}
+
+func TestElevation_GeoTIFF(t *testing.T) {
+	for name, tt := range map[string]testTIFF{
+		"float":           {},
+		"float deflate":   {deflate: true, predictor: predictorFloat},
+		"int16":           {int16: true},
+		"int16 predictor": {int16: true, deflate: true, predictor: predictorHorizontal},
+	} {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+
+			tt.width, tt.height = 10, 10
+			tt.west, tt.north, tt.step = 20, 11, 0.1
+			tt.value = func(row, col int) float64 {
+				if row == 9 && col == 9 {
+					return -9999
+				}
+
+				return float64(100 + 10*col - row)
+			}
+			tt.write(t, filepath.Join(dir, "Copernicus_DSM_10_N10_00_E020_00_DEM.tif"))
+
+			setDirectory(t, dir)
+
+			// The tie point is the corner of the first pixel, so the center of
+			// pixel (5, 5) is at 10.45, 20.55
+			e, err := Elevation(10.45, 20.55)
+			require.NoError(t, err)
+			assert.InDelta(t, 145, e, 0.001)
+
+			e, err = Elevation(10.45, 20.5)
+			require.NoError(t, err)
+			assert.InDelta(t, 140, e, 0.001)
+
+			// No data samples are left out
+			e, err = Elevation(10.05, 20.9)
+			require.NoError(t, err)
+			assert.InDelta(t, 171, e, 0.001)
+
+			_, err = Elevation(10.5, 21.5)
+			require.ErrorIs(t, err, ErrNoTile)
+		})
+	}
+}
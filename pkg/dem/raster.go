@@ -0,0 +1,133 @@
+package dem
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+)
+
+// hgtVoid is the value of the samples without data in HGT files
+const hgtVoid = -32768
+
+// raster is a grid of elevation samples, from north to south and from west to
+// east
+type raster struct {
+	width, height int
+	north, west   float64 // The location of the first (north-west) sample
+	latStep       float64 // The degrees between two rows
+	lngStep       float64 // The degrees between two columns
+	values        []float32
+	noData        float64 // The value of samples without data
+	hasNoData     bool    // Whether the tile has a value for samples without data
+}
+
+func (r *raster) at(row, col int) (float64, bool) {
+	v := float64(r.values[row*r.width+col])
+	if math.IsNaN(v) || (r.hasNoData && v == r.noData) {
+		return 0, false
+	}
+
+	return v, true
+}
+
+// elevation returns the elevation at the location, interpolated between the
+// four samples around it; samples without data are left out
+func (r *raster) elevation(lat, lng float64) (float64, error) {
+	y := (r.north - lat) / r.latStep
+	x := (lng - r.west) / r.lngStep
+
+	if y < -1 || x < -1 || y > float64(r.height) || x > float64(r.width) {
+		return 0, ErrNoTile
+	}
+
+	// Locations within half a sample of the edge are outside the samples of
+	// tiles with an area per sample; they get the value of the edge
+	y = min(max(y, 0), float64(r.height-1))
+	x = min(max(x, 0), float64(r.width-1))
+
+	row, col := int(y), int(x)
+	fy, fx := y-float64(row), x-float64(col)
+
+	var sum, weights float64
+
+	for _, s := range []struct {
+		row, col int
+		weight   float64
+	}{
+		{row, col, (1 - fy) * (1 - fx)},
+		{row, col + 1, (1 - fy) * fx},
+		{row + 1, col, fy * (1 - fx)},
+		{row + 1, col + 1, fy * fx},
+	} {
+		if s.weight == 0 || s.row >= r.height || s.col >= r.width {
+			continue
+		}
+
+		v, ok := r.at(s.row, s.col)
+		if !ok {
+			continue
+		}
+
+		sum += v * s.weight
+		weights += s.weight
+	}
+
+	if weights == 0 {
+		return 0, ErrNoData
+	}
+
+	return sum / weights, nil
+}
+
+// hgtName returns the name of the HGT tile that covers the location, in lower
+// case, eg. "n50e004.hgt"
+func hgtName(lat, lng float64) string {
+	ns, ew := "n", "e"
+
+	latF, lngF := int(math.Floor(lat)), int(math.Floor(lng))
+	if latF < 0 {
+		ns, latF = "s", -latF
+	}
+
+	if lngF < 0 {
+		ew, lngF = "w", -lngF
+	}
+
+	return fmt.Sprintf("%s%02d%s%03d.hgt", ns, latF, ew, lngF)
+}
+
+// readHGT reads an SRTM HGT file: a square grid of big-endian 16 bit samples
+// (1201 or 3601 per row), covering one degree from the south-west corner in
+// its name; the edges overlap with the neighbouring tiles
+func readHGT(path string, south, west float64) (*raster, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	n := len(content) / 2
+
+	size := int(math.Sqrt(float64(n)))
+	if size < 2 || size*size != n {
+		return nil, fmt.Errorf("%w: HGT file with %d bytes", ErrUnsupported, len(content))
+	}
+
+	r := &raster{
+		width:     size,
+		height:    size,
+		north:     south + 1,
+		west:      west,
+		latStep:   1 / float64(size-1),
+		lngStep:   1 / float64(size-1),
+		values:    make([]float32, n),
+		noData:    hgtVoid,
+		hasNoData: true,
+	}
+
+	for i := range r.values {
+		r.values[i] = float32(int16(binary.BigEndian.Uint16(content[2*i:])))
+	}
+
+	return r, nil
+}
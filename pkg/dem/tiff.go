@@ -0,0 +1,456 @@
+package dem
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// The TIFF and GeoTIFF tags that are used to read elevation tiles
+const (
+	tagImageWidth       = 256
+	tagImageLength      = 257
+	tagBitsPerSample    = 258
+	tagCompression      = 259
+	tagStripOffsets     = 273
+	tagSamplesPerPixel  = 277
+	tagRowsPerStrip     = 278
+	tagStripByteCounts  = 279
+	tagPredictor        = 317
+	tagTileWidth        = 322
+	tagTileLength       = 323
+	tagTileOffsets      = 324
+	tagTileByteCounts   = 325
+	tagSampleFormat     = 339
+	tagModelPixelScale  = 33550
+	tagModelTiepoint    = 33922
+	tagGeoKeyDirectory  = 34735
+	tagGDALNoData       = 42113
+	geoKeyRasterType    = 1025
+	rasterPixelIsPoint  = 2
+	maxTagSize          = 64 << 20
+	compressionNone     = 1
+	compressionDeflate  = 8
+	compressionDeflate2 = 32946
+	predictorNone       = 1
+	predictorHorizontal = 2
+	predictorFloat      = 3
+	sampleFormatUint    = 1
+	sampleFormatInt     = 2
+	sampleFormatFloat   = 3
+)
+
+// tiffFile is a GeoTIFF elevation tile in geographic coordinates (eg. a
+// Copernicus DEM tile), with one sample per pixel: 16 bit integers or 32 bit
+// floats, in strips or tiles, uncompressed or compressed with deflate
+type tiffFile struct {
+	path         string
+	order        binary.ByteOrder
+	width        int
+	height       int
+	bits         int
+	sampleFormat int
+	compression  int
+	predictor    int
+	blockWidth   int
+	blockHeight  int
+	offsets      []uint64
+	byteCounts   []uint64
+
+	north, west      float64 // The location of the center of the first (north-west) pixel
+	latStep, lngStep float64 // The size of a pixel, in degrees
+	noData           float64
+	hasNoData        bool
+}
+
+type tiffEntry struct {
+	typ  uint16
+	data []byte
+}
+
+// openTIFF reads the header of a GeoTIFF file, to find its bounds and the
+// layout of its samples
+func openTIFF(path string) (*tiffFile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	header := make([]byte, 8)
+	if _, err := io.ReadFull(f, header); err != nil {
+		return nil, err
+	}
+
+	t := &tiffFile{path: path}
+
+	switch string(header[:2]) {
+	case "II":
+		t.order = binary.LittleEndian
+	case "MM":
+		t.order = binary.BigEndian
+	default:
+		return nil, fmt.Errorf("%w: not a TIFF file", ErrUnsupported)
+	}
+
+	switch t.order.Uint16(header[2:]) {
+	case 42:
+	case 43:
+		return nil, fmt.Errorf("%w: BigTIFF", ErrUnsupported)
+	default:
+		return nil, fmt.Errorf("%w: not a TIFF file", ErrUnsupported)
+	}
+
+	entries, err := readTIFFEntries(f, t.order, int64(t.order.Uint32(header[4:])))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := t.parse(entries); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+func readTIFFEntries(f io.ReaderAt, order binary.ByteOrder, offset int64) (map[uint16]tiffEntry, error) {
+	b := make([]byte, 2)
+	if _, err := f.ReadAt(b, offset); err != nil {
+		return nil, err
+	}
+
+	raw := make([]byte, 12*int(order.Uint16(b)))
+	if _, err := f.ReadAt(raw, offset+2); err != nil {
+		return nil, err
+	}
+
+	entries := map[uint16]tiffEntry{}
+
+	for i := 0; i < len(raw); i += 12 {
+		e := tiffEntry{typ: order.Uint16(raw[i+2:])}
+
+		size := tiffTypeSize(e.typ) * int64(order.Uint32(raw[i+4:]))
+		if size == 0 || size > maxTagSize {
+			continue
+		}
+
+		if size <= 4 {
+			e.data = raw[i+8 : i+8+int(size)]
+		} else {
+			e.data = make([]byte, size)
+			if _, err := f.ReadAt(e.data, int64(order.Uint32(raw[i+8:]))); err != nil {
+				return nil, err
+			}
+		}
+
+		entries[order.Uint16(raw[i:])] = e
+	}
+
+	return entries, nil
+}
+
+func tiffTypeSize(typ uint16) int64 {
+	switch typ {
+	case 1, 2, 6, 7: // BYTE, ASCII, SBYTE, UNDEFINED
+		return 1
+	case 3, 8: // SHORT, SSHORT
+		return 2
+	case 4, 9, 11: // LONG, SLONG, FLOAT
+		return 4
+	case 5, 10, 12: // RATIONAL, SRATIONAL, DOUBLE
+		return 8
+	default:
+		return 0
+	}
+}
+
+func (e tiffEntry) uints(order binary.ByteOrder) []uint64 {
+	var r []uint64
+
+	switch e.typ {
+	case 1:
+		for _, v := range e.data {
+			r = append(r, uint64(v))
+		}
+	case 3:
+		for i := 0; i+2 <= len(e.data); i += 2 {
+			r = append(r, uint64(order.Uint16(e.data[i:])))
+		}
+	case 4:
+		for i := 0; i+4 <= len(e.data); i += 4 {
+			r = append(r, uint64(order.Uint32(e.data[i:])))
+		}
+	}
+
+	return r
+}
+
+func (e tiffEntry) floats(order binary.ByteOrder) []float64 {
+	var r []float64
+
+	switch e.typ {
+	case 11:
+		for i := 0; i+4 <= len(e.data); i += 4 {
+			r = append(r, float64(math.Float32frombits(order.Uint32(e.data[i:]))))
+		}
+	case 12:
+		for i := 0; i+8 <= len(e.data); i += 8 {
+			r = append(r, math.Float64frombits(order.Uint64(e.data[i:])))
+		}
+	default:
+		for _, v := range e.uints(order) {
+			r = append(r, float64(v))
+		}
+	}
+
+	return r
+}
+
+func (t *tiffFile) value(entries map[uint16]tiffEntry, tag uint16, def int) int {
+	v := entries[tag].uints(t.order)
+	if len(v) == 0 {
+		return def
+	}
+
+	return int(v[0])
+}
+
+func (t *tiffFile) parse(entries map[uint16]tiffEntry) error {
+	t.width = t.value(entries, tagImageWidth, 0)
+	t.height = t.value(entries, tagImageLength, 0)
+	t.bits = t.value(entries, tagBitsPerSample, 1)
+	t.sampleFormat = t.value(entries, tagSampleFormat, sampleFormatUint)
+	t.compression = t.value(entries, tagCompression, compressionNone)
+	t.predictor = t.value(entries, tagPredictor, predictorNone)
+
+	if t.width < 1 || t.height < 1 {
+		return fmt.Errorf("%w: no image size", ErrUnsupported)
+	}
+
+	if t.value(entries, tagSamplesPerPixel, 1) != 1 {
+		return fmt.Errorf("%w: more than one sample per pixel", ErrUnsupported)
+	}
+
+	switch {
+	case t.bits == 16 && (t.sampleFormat == sampleFormatInt || t.sampleFormat == sampleFormatUint):
+		if t.predictor != predictorNone && t.predictor != predictorHorizontal {
+			return fmt.Errorf("%w: predictor %d", ErrUnsupported, t.predictor)
+		}
+	case t.bits == 32 && t.sampleFormat == sampleFormatFloat:
+		if t.predictor != predictorNone && t.predictor != predictorFloat {
+			return fmt.Errorf("%w: predictor %d", ErrUnsupported, t.predictor)
+		}
+	default:
+		return fmt.Errorf("%w: %d bit samples of format %d", ErrUnsupported, t.bits, t.sampleFormat)
+	}
+
+	switch t.compression {
+	case compressionNone, compressionDeflate, compressionDeflate2:
+	default:
+		return fmt.Errorf("%w: compression %d", ErrUnsupported, t.compression)
+	}
+
+	if _, ok := entries[tagTileWidth]; ok {
+		t.blockWidth = t.value(entries, tagTileWidth, 0)
+		t.blockHeight = t.value(entries, tagTileLength, 0)
+		t.offsets = entries[tagTileOffsets].uints(t.order)
+		t.byteCounts = entries[tagTileByteCounts].uints(t.order)
+	} else {
+		t.blockWidth = t.width
+		t.blockHeight = min(t.value(entries, tagRowsPerStrip, t.height), t.height)
+		t.offsets = entries[tagStripOffsets].uints(t.order)
+		t.byteCounts = entries[tagStripByteCounts].uints(t.order)
+	}
+
+	if t.blockWidth < 1 || t.blockHeight < 1 || len(t.offsets) != t.blocksAcross()*t.blocksDown() || len(t.byteCounts) != len(t.offsets) {
+		return fmt.Errorf("%w: invalid strips or tiles", ErrUnsupported)
+	}
+
+	return t.parseGeoreference(entries)
+}
+
+// parseGeoreference sets the location of the first pixel and the size of the
+// pixels from the tie point and the pixel scale
+func (t *tiffFile) parseGeoreference(entries map[uint16]tiffEntry) error {
+	scale := entries[tagModelPixelScale].floats(t.order)
+	tie := entries[tagModelTiepoint].floats(t.order)
+
+	if len(scale) < 2 || len(tie) < 6 || scale[0] <= 0 || scale[1] <= 0 {
+		return fmt.Errorf("%w: no georeference", ErrUnsupported)
+	}
+
+	t.lngStep, t.latStep = scale[0], scale[1]
+	t.west = tie[3] - tie[0]*t.lngStep
+	t.north = tie[4] + tie[1]*t.latStep
+
+	if t.rasterType(entries) != rasterPixelIsPoint {
+		// The tie point is the corner of the pixel, not its center
+		t.west += t.lngStep / 2
+		t.north -= t.latStep / 2
+	}
+
+	if nd, ok := entries[tagGDALNoData]; ok {
+		v, err := strconv.ParseFloat(strings.Trim(string(nd.data), "\x00 "), 64)
+		if err == nil {
+			t.noData, t.hasNoData = v, true
+		}
+	}
+
+	return nil
+}
+
+// rasterType returns the GeoTIFF raster type: whether the pixels are areas (the
+// default) or points
+func (t *tiffFile) rasterType(entries map[uint16]tiffEntry) int {
+	keys := entries[tagGeoKeyDirectory].uints(t.order)
+
+	for i := 4; i+4 <= len(keys); i += 4 {
+		if keys[i] == geoKeyRasterType && keys[i+1] == 0 {
+			return int(keys[i+3])
+		}
+	}
+
+	return 0
+}
+
+func (t *tiffFile) blocksAcross() int {
+	return (t.width + t.blockWidth - 1) / t.blockWidth
+}
+
+func (t *tiffFile) blocksDown() int {
+	return (t.height + t.blockHeight - 1) / t.blockHeight
+}
+
+// covers returns whether the location is within the pixels of the tile
+func (t *tiffFile) covers(lat, lng float64) bool {
+	south := t.north - float64(t.height-1)*t.latStep
+	east := t.west + float64(t.width-1)*t.lngStep
+
+	return lat <= t.north+t.latStep/2 && lat >= south-t.latStep/2 &&
+		lng >= t.west-t.lngStep/2 && lng <= east+t.lngStep/2
+}
+
+// read reads the samples of the tile
+func (t *tiffFile) read() (*raster, error) {
+	f, err := os.Open(t.path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := &raster{
+		width:     t.width,
+		height:    t.height,
+		north:     t.north,
+		west:      t.west,
+		latStep:   t.latStep,
+		lngStep:   t.lngStep,
+		values:    make([]float32, t.width*t.height),
+		noData:    t.noData,
+		hasNoData: t.hasNoData,
+	}
+
+	for i := range t.offsets {
+		block, err := t.readBlock(f, i)
+		if err != nil {
+			return nil, err
+		}
+
+		top, left := (i/t.blocksAcross())*t.blockHeight, (i%t.blocksAcross())*t.blockWidth
+
+		for row := 0; row < t.blockHeight && top+row < t.height; row++ {
+			n := min(t.blockWidth, t.width-left)
+			copy(r.values[(top+row)*t.width+left:][:n], block[row*t.blockWidth:][:n])
+		}
+	}
+
+	return r, nil
+}
+
+// readBlock reads, decompresses and decodes a strip or tile
+func (t *tiffFile) readBlock(f io.ReaderAt, i int) ([]float32, error) {
+	raw := make([]byte, t.byteCounts[i])
+	if _, err := f.ReadAt(raw, int64(t.offsets[i])); err != nil {
+		return nil, err
+	}
+
+	if t.compression != compressionNone {
+		z, err := zlib.NewReader(bytes.NewReader(raw))
+		if err != nil {
+			return nil, err
+		}
+
+		if raw, err = io.ReadAll(z); err != nil {
+			return nil, err
+		}
+	}
+
+	size := t.bits / 8
+	if len(raw) < t.blockWidth*t.blockHeight*size {
+		return nil, fmt.Errorf("%w: block %d is too short", ErrUnsupported, i)
+	}
+
+	values := make([]float32, t.blockWidth*t.blockHeight)
+
+	for row := range t.blockHeight {
+		line := raw[row*t.blockWidth*size:][:t.blockWidth*size]
+		out := values[row*t.blockWidth:][:t.blockWidth]
+
+		switch t.bits {
+		case 16:
+			t.decode16(line, out)
+		case 32:
+			t.decode32(line, out)
+		}
+	}
+
+	return values, nil
+}
+
+func (t *tiffFile) decode16(line []byte, out []float32) {
+	var prev uint16
+
+	for col := range out {
+		v := t.order.Uint16(line[2*col:])
+		if t.predictor == predictorHorizontal {
+			v += prev
+			prev = v
+		}
+
+		if t.sampleFormat == sampleFormatInt {
+			out[col] = float32(int16(v))
+		} else {
+			out[col] = float32(v)
+		}
+	}
+}
+
+// decode32 decodes a row of floats; the floating point predictor stores the
+// differences of the bytes, with the most significant bytes of all samples
+// first
+func (t *tiffFile) decode32(line []byte, out []float32) {
+	if t.predictor != predictorFloat {
+		for col := range out {
+			out[col] = math.Float32frombits(t.order.Uint32(line[4*col:]))
+		}
+
+		return
+	}
+
+	for i := 1; i < len(line); i++ {
+		line[i] += line[i-1]
+	}
+
+	w := len(out)
+
+	for col := range out {
+		bits := uint32(line[col])<<24 | uint32(line[w+col])<<16 | uint32(line[2*w+col])<<8 | uint32(line[3*w+col])
+		out[col] = math.Float32frombits(bits)
+	}
+}
@@ -48,12 +48,18 @@ func categoryIcon(what string) string {
 		return iconDefaults + " icon-solid icon-heart-pulse"
 	case "cadence":
 		return iconDefaults + " icon-solid icon-stopwatch"
+	case "power":
+		return iconDefaults + " icon-solid icon-bolt"
 	case "heading":
 		return iconDefaults + " icon-solid icon-compass"
 	case "date":
 		return iconDefaults + " icon-regular icon-calendar"
 	case "pause":
 		return iconDefaults + " icon-regular icon-hourglass"
+	case "visibility":
+		return iconDefaults + " icon-solid icon-eye"
+	case "laps":
+		return iconDefaults + " icon-solid icon-rectangle-list"
 	default:
 		return ""
 	}
@@ -104,10 +110,62 @@ func sportIcon(what string) string {
 		return iconDefaults + " icon-solid icon-sailboat"
 	case "hiking":
 		return iconDefaults + " icon-solid icon-person-hiking"
+	case "multisport":
+		return iconDefaults + " icon-solid icon-medal"
+	case "transition":
+		return iconDefaults + " icon-solid icon-right-left"
 	case "push-ups":
 		return iconDefaults + " icon-solid icon-dumbbell"
 	case "weight lifting":
 		return iconDefaults + " icon-solid icon-dumbbell"
+	default:
+		return customSportIcon(what)
+	}
+}
+
+// SportIcons returns the icons that can be picked for custom workout types
+func SportIcons() []string {
+	return []string{
+		"baseball", "basketball", "bowling", "climbing", "fighting", "football",
+		"heart", "hockey", "horse-riding", "nordic-skiing", "racket", "skating",
+		"soccer", "volleyball", "water", "yoga",
+	}
+}
+
+func customSportIcon(what string) string {
+	switch what {
+	case "baseball":
+		return iconDefaults + " icon-solid icon-baseball-bat-ball"
+	case "basketball":
+		return iconDefaults + " icon-solid icon-basketball"
+	case "bowling":
+		return iconDefaults + " icon-solid icon-bowling-ball"
+	case "climbing":
+		return iconDefaults + " icon-solid icon-mountain"
+	case "fighting":
+		return iconDefaults + " icon-solid icon-hand-fist"
+	case "football":
+		return iconDefaults + " icon-solid icon-football"
+	case "heart":
+		return iconDefaults + " icon-solid icon-heart-pulse"
+	case "hockey":
+		return iconDefaults + " icon-solid icon-hockey-puck"
+	case "horse-riding":
+		return iconDefaults + " icon-solid icon-horse"
+	case "nordic-skiing":
+		return iconDefaults + " icon-solid icon-person-skiing-nordic"
+	case "racket":
+		return iconDefaults + " icon-solid icon-table-tennis-paddle-ball"
+	case "skating":
+		return iconDefaults + " icon-solid icon-person-skating"
+	case "soccer":
+		return iconDefaults + " icon-solid icon-futbol"
+	case "volleyball":
+		return iconDefaults + " icon-solid icon-volleyball"
+	case "water":
+		return iconDefaults + " icon-solid icon-water"
+	case "yoga":
+		return iconDefaults + " icon-solid icon-spa"
 	default:
 		return ""
 	}
@@ -129,6 +187,20 @@ func pageIcon(what string) string {
 		return iconDefaults + " icon-solid icon-dumbbell"
 	case "equipment":
 		return iconDefaults + " icon-solid icon-bicycle"
+	case "segment", "segments":
+		return iconDefaults + " icon-solid icon-route"
+	case "climb", "climbs":
+		return iconDefaults + " icon-solid icon-mountain-sun"
+	case "goal":
+		return iconDefaults + " icon-solid icon-bullseye"
+	case "exercise", "exercises":
+		return iconDefaults + " icon-solid icon-dumbbell"
+	case "maintenance":
+		return iconDefaults + " icon-solid icon-wrench"
+	case "measurement", "measurements":
+		return iconDefaults + " icon-solid icon-weight-scale"
+	case "calories":
+		return iconDefaults + " icon-solid icon-fire-flame-curved"
 	case "add", "workout-add", "equipment-add":
 		return iconDefaults + " icon-solid icon-circle-plus"
 	default:
@@ -146,6 +218,10 @@ func utilityIcon(what string) string {
 		return iconDefaults + " icon-solid icon-arrows-rotate"
 	case "delete":
 		return iconDefaults + " icon-solid icon-trash"
+	case "track":
+		return iconDefaults + " icon-solid icon-scissors"
+	case "duplicate":
+		return iconDefaults + " icon-solid icon-clone"
 	case "note":
 		return iconDefaults + " icon-solid icon-quote-left"
 	case "users":
@@ -172,6 +248,10 @@ func utilityIcon(what string) string {
 		return iconDefaults + " icon-solid icon-square-check"
 	case "totals":
 		return iconDefaults + " icon-solid icon-calculator"
+	case "share":
+		return iconDefaults + " icon-solid icon-users"
+	case "privacy":
+		return iconDefaults + " icon-solid icon-eye-slash"
 	default:
 		return ""
 	}
@@ -27,3 +27,9 @@ func TestIconFor_Types(t *testing.T) {
 	assert.Contains(t, IconFor("dashboard"), "icon-chart-line")
 	assert.Contains(t, IconFor("running"), "icon-person-running")
 }
+
+func TestIconFor_SportIcons(t *testing.T) {
+	for _, icon := range SportIcons() {
+		assert.NotContains(t, IconFor(icon), "icon-question", icon)
+	}
+}
@@ -9,6 +9,8 @@ const (
 	MilesPerKM   = 0.621371192
 	FeetPerMeter = 3.2808399
 	MeterPerMile = 1609.344
+	PoundsPerKG  = 2.20462262
+	MeterPerYard = 0.9144
 )
 
 func HumanDistanceMile(d float64) string {
@@ -38,6 +40,19 @@ func HumanTempoMile(mps float64) string {
 	return fmt.Sprintf("%d:%02d", int(wholeMinutes), int(seconds))
 }
 
+// HumanSwimTempo100Yd returns the time to swim 100 yards at the speed
+func HumanSwimTempo100Yd(mps float64) string {
+	if mps == 0 || math.IsNaN(mps) {
+		return InvalidValue
+	}
+
+	return humanSwimTempo(100 * MeterPerYard / mps)
+}
+
 func HumanElevationFt(m float64) string {
 	return fmt.Sprintf("%.2f", FeetPerMeter*m)
 }
+
+func HumanWeightLbs(kg float64) string {
+	return fmt.Sprintf("%.1f", PoundsPerKG*kg)
+}
@@ -34,6 +34,26 @@ func HumanTempoKM(mps float64) string {
 	return fmt.Sprintf("%d:%02d", int(wholeMinutes), int(seconds))
 }
 
+// HumanSwimTempo100M returns the time to swim 100 meters at the speed
+func HumanSwimTempo100M(mps float64) string {
+	if mps == 0 || math.IsNaN(mps) {
+		return InvalidValue
+	}
+
+	return humanSwimTempo(100 / mps)
+}
+
+// humanSwimTempo formats the seconds per 100 units as minutes and seconds
+func humanSwimTempo(seconds float64) string {
+	wholeMinutes := math.Floor(seconds / 60)
+
+	return fmt.Sprintf("%d:%02d", int(wholeMinutes), int(seconds-60*wholeMinutes))
+}
+
 func HumanElevationM(m float64) string {
 	return fmt.Sprintf("%.2f", m)
 }
+
+func HumanWeightKG(kg float64) string {
+	return fmt.Sprintf("%.1f", kg)
+}
@@ -58,6 +58,26 @@ func HumanTempoFor(unit string) func(float64) string {
 	}
 }
 
+// HumanSwimTempoFor returns the swim tempo function for the distance unit:
+// per 100 yards for miles, else per 100 meters
+func HumanSwimTempoFor(unit string) func(float64) string {
+	switch unit {
+	case "mi":
+		return HumanSwimTempo100Yd
+	default:
+		return HumanSwimTempo100M
+	}
+}
+
+func HumanWeightFor(unit string) func(float64) string {
+	switch unit {
+	case "lbs":
+		return HumanWeightLbs
+	default:
+		return HumanWeightKG
+	}
+}
+
 func BoolToHTML(b bool) template.HTML {
 	if b {
 		return `<i class="text-green-500 fas fa-check"></i>`
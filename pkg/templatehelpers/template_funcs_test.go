@@ -43,6 +43,17 @@ func TestHumanTempoKM(t *testing.T) {
 	assert.Equal(t, "5:01", HumanTempoKM(3.32))
 }
 
+func TestHumanWeightFor(t *testing.T) {
+	assert.Equal(t, "80.0", HumanWeightFor("kg")(80))
+	assert.Equal(t, "176.4", HumanWeightFor("lbs")(80))
+}
+
+func TestHumanSwimTempoFor(t *testing.T) {
+	assert.Equal(t, "1:40", HumanSwimTempoFor("km")(1))
+	assert.Equal(t, "1:31", HumanSwimTempoFor("mi")(1))
+	assert.Equal(t, InvalidValue, HumanSwimTempoFor("km")(0))
+}
+
 func TestBoolToHTML(t *testing.T) {
 	assert.Equal(t, template.HTML("<i class=\"text-green-500 fas fa-check\"></i>"), BoolToHTML(true))
 	assert.Equal(t, template.HTML("<i class=\"text-rose-500 fas fa-times\"></i>"), BoolToHTML(false))